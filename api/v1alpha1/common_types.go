@@ -2,31 +2,306 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // KubernetesConfig will be the JSON struct for Basic MongoDB Config
 type KubernetesConfig struct {
-	Image             string                       `json:"image"`
-	ImagePullPolicy   corev1.PullPolicy            `json:"imagePullPolicy,omitempty"`
-	Resources         *corev1.ResourceRequirements `json:"resources,omitempty"`
-	ImagePullSecret   *string                      `json:"imagePullSecret,omitempty"`
-	NodeSelector      map[string]string            `json:"nodeSelector,omitempty"`
-	Affinity          *corev1.Affinity             `json:"mongoAffinity,omitempty"`
-	Tolerations       *[]corev1.Toleration         `json:"tolerations,omitempty"`
-	PriorityClassName string                       `json:"priorityClassName,omitempty"`
-	SecurityContext   *corev1.PodSecurityContext   `json:"securityContext,omitempty"`
+	Image string `json:"image"`
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	ImagePullPolicy              corev1.PullPolicy            `json:"imagePullPolicy,omitempty"`
+	Resources                    *corev1.ResourceRequirements `json:"resources,omitempty"`
+	ImagePullSecret              *string                      `json:"imagePullSecret,omitempty"`
+	ImagePullSecrets             []string                     `json:"imagePullSecrets,omitempty"`
+	NodeSelector                 map[string]string            `json:"nodeSelector,omitempty"`
+	Affinity                     *corev1.Affinity             `json:"mongoAffinity,omitempty"`
+	Tolerations                  *[]corev1.Toleration         `json:"tolerations,omitempty"`
+	PriorityClassName            string                       `json:"priorityClassName,omitempty"`
+	PriorityClass                *PriorityClassConfig         `json:"priorityClass,omitempty"`
+	SchedulerName                string                       `json:"schedulerName,omitempty"`
+	RuntimeClassName             *string                      `json:"runtimeClassName,omitempty"`
+	ServiceAccountName           string                       `json:"serviceAccountName,omitempty"`
+	CreateServiceAccount         bool                         `json:"createServiceAccount,omitempty"`
+	AutomountServiceAccountToken *bool                        `json:"automountServiceAccountToken,omitempty"`
+	// ServiceAccountImagePullSecrets are attached to the ServiceAccount created by the operator, the pods pull with them in addition to the pod level imagePullSecrets
+	ServiceAccountImagePullSecrets []string `json:"serviceAccountImagePullSecrets,omitempty"`
+	// +kubebuilder:validation:Enum=ClusterFirst;ClusterFirstWithHostNet;Default;None
+	DNSPolicy       corev1.DNSPolicy           `json:"dnsPolicy,omitempty"`
+	DNSConfig       *corev1.PodDNSConfig       `json:"dnsConfig,omitempty"`
+	HostNetwork     bool                       `json:"hostNetwork,omitempty"`
+	HostAliases     []corev1.HostAlias         `json:"hostAliases,omitempty"`
+	SecurityContext *corev1.PodSecurityContext `json:"securityContext,omitempty"`
+	// Sysctls are added to the sysctls of the pod security context, without replacing the default security context
+	Sysctls []corev1.Sysctl `json:"sysctls,omitempty"`
+	// SeccompProfile is the seccomp profile of the pods, it defaults to RuntimeDefault
+	SeccompProfile *corev1.SeccompProfile `json:"seccompProfile,omitempty"`
+	// +kubebuilder:validation:Enum=OnRootMismatch;Always
+	FSGroupChangePolicy      *corev1.PodFSGroupChangePolicy `json:"fsGroupChangePolicy,omitempty"`
+	ContainerSecurityContext *corev1.SecurityContext        `json:"containerSecurityContext,omitempty"`
+	Service                  *ServiceConfig                 `json:"service,omitempty"`
+	HeadlessService          *HeadlessServiceConfig         `json:"headlessService,omitempty"`
+	ExternalNameService      *ExternalNameServiceConfig     `json:"externalNameService,omitempty"`
+	PublishNotReadyAddresses *bool                          `json:"publishNotReadyAddresses,omitempty"`
+	EnvVars                  []corev1.EnvVar                `json:"env,omitempty"`
+	EnvFrom                  []corev1.EnvFromSource         `json:"envFrom,omitempty"`
+	ExtraVolumes             []corev1.Volume                `json:"extraVolumes,omitempty"`
+	ExtraVolumeMounts        []corev1.VolumeMount           `json:"extraVolumeMounts,omitempty"`
+	ExtraPorts               []corev1.ContainerPort         `json:"extraPorts,omitempty"`
+	ExtraServicePorts        []corev1.ServicePort           `json:"extraServicePorts,omitempty"`
+	InitImage                string                         `json:"initImage,omitempty"`
+	PostStart                *PostStartHook                 `json:"postStart,omitempty"`
+	// ProductionMode marks the deployment as production, the operator then warns about images which are not pinned
+	ProductionMode bool `json:"productionMode,omitempty"`
+	// +kubebuilder:validation:Enum=ping;replicaSetMember
+	ReadinessProbeMode string       `json:"readinessProbeMode,omitempty"`
+	Probes             *ProbeConfig `json:"probes,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	MinReadySeconds        int32                  `json:"minReadySeconds,omitempty"`
+	ProjectedVolume        *ProjectedVolumeConfig `json:"projectedVolume,omitempty"`
+	ScratchVolume          *ScratchVolumeConfig   `json:"scratchVolume,omitempty"`
+	TerminationMessagePath string                 `json:"terminationMessagePath,omitempty"`
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	ContainerName string `json:"containerName,omitempty"`
+	// +kubebuilder:validation:Enum=File;FallbackToLogsOnError
+	TerminationMessagePolicy corev1.TerminationMessagePolicy `json:"terminationMessagePolicy,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	PreStopDelaySeconds *int32 `json:"preStopDelaySeconds,omitempty"`
+	FsyncOnShutdown     bool   `json:"fsyncOnShutdown,omitempty"`
+	// +kubebuilder:validation:Enum=Reject;Recreate
+	ImmutableFieldChangePolicy      string            `json:"immutableFieldChangePolicy,omitempty"`
+	PodAnnotations                  map[string]string `json:"podAnnotations,omitempty"`
+	HoldApplicationUntilProxyStarts *bool             `json:"holdApplicationUntilProxyStarts,omitempty"`
+}
+
+// ServiceConfig is the JSON struct for exposing MongoDB with a client service
+type ServiceConfig struct {
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	ServiceType              corev1.ServiceType `json:"serviceType,omitempty"`
+	NodePort                 *int32             `json:"nodePort,omitempty"`
+	LoadBalancerSourceRanges []string           `json:"loadBalancerSourceRanges,omitempty"`
+	ServiceAnnotations       map[string]string  `json:"annotations,omitempty"`
+	ServiceLabels            map[string]string  `json:"labels,omitempty"`
+	// +kubebuilder:validation:Enum=Cluster;Local
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+	// +kubebuilder:validation:Enum=None;ClientIP
+	SessionAffinity corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=86400
+	SessionAffinityTimeoutSeconds *int32 `json:"sessionAffinityTimeoutSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+}
+
+// HeadlessServiceConfig is the JSON struct for the labels and annotations of MongoDB headless service only
+type HeadlessServiceConfig struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ExternalNameServiceConfig is the JSON struct for an ExternalName alias of MongoDB service, e.g. in the namespace of an application
+type ExternalNameServiceConfig struct {
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+	// +kubebuilder:validation:MaxLength=63
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ProbeConfig is the JSON struct for overriding the handlers and timings of MongoDB container probes
+type ProbeConfig struct {
+	LivenessProbe   *Probe                 `json:"livenessProbe,omitempty"`
+	ReadinessProbe  *Probe                 `json:"readinessProbe,omitempty"`
+	StartupProbe    *Probe                 `json:"startupProbe,omitempty"`
+	ReadinessScript *ReadinessScriptConfig `json:"readinessScript,omitempty"`
+}
+
+// ReadinessScriptConfig is the JSON struct for a readiness script maintained in a configmap and run by the readiness probe
+type ReadinessScriptConfig struct {
+	// +kubebuilder:validation:MinLength=1
+	ConfigMapName string `json:"configMapName"`
+	Key           string `json:"key,omitempty"`
+	// +kubebuilder:validation:Pattern=`^/`
+	MountPath string `json:"mountPath,omitempty"`
+}
+
+// Probe is the JSON struct for a MongoDB container probe, unset fields keep the operator defaults
+type Probe struct {
+	corev1.Handler `json:",inline"`
+	// +kubebuilder:validation:Minimum=0
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	SuccessThreshold *int32 `json:"successThreshold,omitempty"`
+}
+
+// ProjectedVolumeConfig is the JSON struct for mounting multiple secrets and configmaps as a single volume
+type ProjectedVolumeConfig struct {
+	MountPath string `json:"mountPath,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=511
+	DefaultMode *int32 `json:"defaultMode,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	Sources []corev1.VolumeProjection `json:"sources"`
+}
+
+// ScratchVolumeConfig is the JSON struct for mounting an emptyDir volume as writable scratch space for MongoDB
+type ScratchVolumeConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// +kubebuilder:validation:Enum="";Memory
+	Medium    corev1.StorageMedium `json:"medium,omitempty"`
+	SizeLimit *resource.Quantity   `json:"sizeLimit,omitempty"`
+}
+
+// PriorityClassConfig is the JSON struct for a PriorityClass created by the operator for MongoDB pods
+type PriorityClassConfig struct {
+	Create bool `json:"create,omitempty"`
+	// +kubebuilder:validation:Maximum=1000000000
+	Value int32 `json:"value"`
+	// +kubebuilder:validation:Enum=PreemptLowerPriority;Never
+	PreemptionPolicy *corev1.PreemptionPolicy `json:"preemptionPolicy,omitempty"`
+	Description      string                   `json:"description,omitempty"`
+}
+
+// MaintenanceWindow is the JSON struct for the recurring time range in which disruptive changes are applied
+type MaintenanceWindow struct {
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	StartTime string          `json:"startTime"`
+	Duration  metav1.Duration `json:"duration"`
+	// +kubebuilder:validation:items:Enum=Sunday;Monday;Tuesday;Wednesday;Thursday;Friday;Saturday
+	Days []string `json:"days,omitempty"`
 }
 
 // MongoDBSecurity is the JSON struct for MongoDB security configuration
 type MongoDBSecurity struct {
 	MongoDBAdminUser string                 `json:"mongoDBAdminUser"`
 	SecretRef        ExistingPasswordSecret `json:"secretRef"`
+	Users            []MongoDBUser          `json:"users,omitempty"`
+	Roles            []MongoDBCustomRole    `json:"roles,omitempty"`
+	// ConnectionSecretName is the secret the admin credentials and connection string are kept in sync in for the applications
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	// +kubebuilder:validation:MaxLength=253
+	ConnectionSecretName string `json:"connectionSecretName,omitempty"`
+}
+
+// MongoDBUser is the JSON struct for an application user of MongoDB managed by the operator
+type MongoDBUser struct {
+	// +kubebuilder:validation:MinLength=1
+	Name              string                 `json:"name"`
+	Database          string                 `json:"db,omitempty"`
+	PasswordSecretRef ExistingPasswordSecret `json:"passwordSecretRef"`
+	// +kubebuilder:validation:MinItems=1
+	Roles []MongoDBRoleRef `json:"roles"`
+}
+
+// MongoDBRoleRef is the JSON struct for a role granted to a user or inherited by a custom role
+type MongoDBRoleRef struct {
+	// +kubebuilder:validation:MinLength=1
+	Name     string `json:"name"`
+	Database string `json:"db,omitempty"`
+}
+
+// MongoDBCustomRole is the JSON struct for a user defined role of MongoDB managed by the operator
+type MongoDBCustomRole struct {
+	// +kubebuilder:validation:MinLength=1
+	Name       string             `json:"name"`
+	Database   string             `json:"db,omitempty"`
+	Privileges []MongoDBPrivilege `json:"privileges,omitempty"`
+	Roles      []MongoDBRoleRef   `json:"roles,omitempty"`
+}
+
+// MongoDBPrivilege is the JSON struct for the actions a custom role allows on a resource
+type MongoDBPrivilege struct {
+	Resource MongoDBPrivilegeResource `json:"resource"`
+	// +kubebuilder:validation:MinItems=1
+	Actions []string `json:"actions"`
+}
+
+// MongoDBPrivilegeResource is the JSON struct for the resource of a privilege, the cluster or a database and collection where unset means all
+type MongoDBPrivilegeResource struct {
+	Database   *string `json:"db,omitempty"`
+	Collection *string `json:"collection,omitempty"`
+	Cluster    bool    `json:"cluster,omitempty"`
+}
+
+// MongoDBConfig is the JSON struct for mongod process configuration
+type MongoDBConfig struct {
+	ExtraArgs                 []string `json:"extraArgs,omitempty"`
+	Command                   []string `json:"command,omitempty"`
+	WiredTigerCacheAutoTuning *bool    `json:"wiredTigerCacheAutoTuning,omitempty"`
+	// +kubebuilder:validation:Minimum=1024
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	OplogSizeMB *int32 `json:"oplogSizeMB,omitempty"`
+	// OplogMinRetentionHours keeps the oplog entries for at least the given hours on replica set members, the oplog grows beyond its size when needed
+	// +kubebuilder:validation:Minimum=0
+	OplogMinRetentionHours *int32          `json:"oplogMinRetentionHours,omitempty"`
+	Logging                *MongoDBLogging `json:"logging,omitempty"`
+	// +kubebuilder:validation:Pattern=`^/`
+	DBPath string         `json:"dbPath,omitempty"`
+	WarmUp *MongoDBWarmUp `json:"warmUp,omitempty"`
+	// +kubebuilder:validation:Enum=wiredTiger;inMemory
+	StorageEngine string           `json:"storageEngine,omitempty"`
+	BindIP        []string         `json:"bindIp,omitempty"`
+	AuditLog      *MongoDBAuditLog `json:"auditLog,omitempty"`
+}
+
+// MongoDBAuditLog is the JSON struct for writing the audit log of mongod to a dedicated persistent volume, auditing requires MongoDB Enterprise or Percona Server for MongoDB
+type MongoDBAuditLog struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// +kubebuilder:validation:Enum=JSON;BSON
+	Format string `json:"format,omitempty"`
+	// Filter is the JSON document selecting the audited events, all of them are audited when it is empty
+	Filter string `json:"filter,omitempty"`
+	// +kubebuilder:validation:Pattern=`^/`
+	MountPath        string  `json:"mountPath,omitempty"`
+	StorageSize      string  `json:"storageSize,omitempty"`
+	StorageClassName *string `json:"storageClass,omitempty"`
+}
+
+// PostStartHook is the JSON struct for a command run in the MongoDB container once mongod is up, e.g. to create indexes
+type PostStartHook struct {
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// MongoDBWarmUp is the JSON struct for loading the indexes of collections into the cache before a MongoDB pod becomes ready
+type MongoDBWarmUp struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	Collections []string `json:"collections"`
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// MongoDBLogging is the JSON struct for the log verbosity and log file of mongod process
+type MongoDBLogging struct {
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=5
+	Verbosity *int32 `json:"verbosity,omitempty"`
+	LogPath   string `json:"logPath,omitempty"`
+	// +kubebuilder:validation:Enum=rename;reopen
+	LogRotate string `json:"logRotate,omitempty"`
 }
 
 // MongoDBMonitoring is the JSON struct for monitoring MongoDB
 type MongoDBMonitoring struct {
-	EnableExporter  bool                         `json:"enableExporter,omitempty"`
-	Image           string                       `json:"image"`
+	EnableExporter bool   `json:"enableExporter,omitempty"`
+	Image          string `json:"image"`
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
 	ImagePullPolicy corev1.PullPolicy            `json:"imagePullPolicy,omitempty"`
 	Resources       *corev1.ResourceRequirements `json:"resources,omitempty"`
 }
@@ -42,4 +317,27 @@ type Storage struct {
 	AccessModes      []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty" protobuf:"bytes,1,rep,name=accessModes,casttype=PersistentVolumeAccessMode"`
 	StorageClassName *string                             `json:"storageClass,omitempty" protobuf:"bytes,5,opt,name=storageClassName"`
 	StorageSize      string                              `json:"storageSize,omitempty" protobuf:"bytes,5,opt,name=storageClassName"`
+	// +kubebuilder:validation:Enum=Filesystem;Block
+	VolumeMode        *corev1.PersistentVolumeMode `json:"volumeMode,omitempty"`
+	VolumePermissions bool                         `json:"volumePermissions,omitempty"`
+}
+
+// MongoDBRestore is the JSON struct for restoring MongoDB from an existing backup on bootstrap
+type MongoDBRestore struct {
+	S3BackupPath string  `json:"s3BackupPath"`
+	S3Endpoint   *string `json:"s3Endpoint,omitempty"`
+	S3Region     *string `json:"s3Region,omitempty"`
+	S3SecretRef  *string `json:"s3SecretRef,omitempty"`
+	Image        string  `json:"image,omitempty"`
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	ImagePullPolicy corev1.PullPolicy            `json:"imagePullPolicy,omitempty"`
+	Resources       *corev1.ResourceRequirements `json:"resources,omitempty"`
+	CABundle        *CABundleSource              `json:"caBundle,omitempty"`
+}
+
+// CABundleSource is the JSON struct for a CA bundle trusted for outbound TLS, it is read from either a configmap or a secret
+type CABundleSource struct {
+	ConfigMapName *string `json:"configMapName,omitempty"`
+	SecretName    *string `json:"secretName,omitempty"`
+	Key           string  `json:"key,omitempty"`
 }
@@ -27,10 +27,25 @@ type MongoDBSpec struct {
 	MongoDBSecurity         *MongoDBSecurity   `json:"mongoDBSecurity"`
 	MongoDBMonitoring       *MongoDBMonitoring `json:"mongoDBMonitoring,omitempty"`
 	MongoDBAdditionalConfig *string            `json:"mongoDBAdditionalConfig,omitempty"`
+	MongoDBConfig           *MongoDBConfig     `json:"mongoDBConfig,omitempty"`
+	MongoDBRestore          *MongoDBRestore    `json:"mongoDBRestore,omitempty"`
+	MaintenanceWindow       *MaintenanceWindow `json:"maintenanceWindow,omitempty"`
 }
 
 // MongoDBStatus defines the observed state of MongoDB
 type MongoDBStatus struct {
+	RestoreCompleted      bool   `json:"restoreCompleted,omitempty"`
+	Paused                bool   `json:"paused,omitempty"`
+	ConnectionURI         string `json:"connectionURI,omitempty"`
+	ExternalConnectionURI string `json:"externalConnectionURI,omitempty"`
+	// ManagedUsers are the application users created by the operator, as <db>.<name>
+	ManagedUsers []string `json:"managedUsers,omitempty"`
+	// ManagedRoles are the custom roles created by the operator, as <db>.<name>, they are dropped once they are removed from the spec
+	ManagedRoles []string `json:"managedRoles,omitempty"`
+	// Warnings are the configuration warnings emitted as events, a warning is only emitted again once it changed
+	Warnings []string `json:"warnings,omitempty"`
+	// UpdateDeferred is set while changes of the pod template wait for the maintenance window, the event is only emitted when they are deferred first
+	UpdateDeferred bool `json:"updateDeferred,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -17,6 +17,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -30,6 +31,27 @@ type MongoDBClusterSpec struct {
 	MongoDBMonitoring       *MongoDBMonitoring          `json:"mongoDBMonitoring,omitempty"`
 	PodDisruptionBudget     *MongoDBPodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
 	MongoDBAdditionalConfig *string                     `json:"mongoDBAdditionalConfig,omitempty"`
+	MongoDBConfig           *MongoDBConfig              `json:"mongoDBConfig,omitempty"`
+	MongoDBRestore          *MongoDBRestore             `json:"mongoDBRestore,omitempty"`
+	PerPodService           *MongoDBPerPodService       `json:"perPodService,omitempty"`
+	AnalyticsNode           *MongoDBAnalyticsNode       `json:"analyticsNode,omitempty"`
+	MemberConfig            []MongoDBMemberConfig       `json:"memberConfig,omitempty"`
+	Sharding                *MongoDBSharding            `json:"sharding,omitempty"`
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_-]+$`
+	// +kubebuilder:validation:MaxLength=64
+	ReplicaSetName    string                       `json:"replicaSetName,omitempty"`
+	ArbiterResources  *corev1.ResourceRequirements `json:"arbiterResources,omitempty"`
+	MaintenanceWindow *MaintenanceWindow           `json:"maintenanceWindow,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	ReplicationLagThresholdSeconds *int64                 `json:"replicationLagThresholdSeconds,omitempty"`
+	MaintenanceJob                 *MongoDBMaintenanceJob `json:"maintenanceJob,omitempty"`
+	VolumeSnapshot                 *MongoDBVolumeSnapshot `json:"volumeSnapshot,omitempty"`
+	// +kubebuilder:validation:Pattern=`^[0-9]+\.[0-9]+$`
+	FeatureCompatibilityVersion string `json:"featureCompatibilityVersion,omitempty"`
+	// ClusterDomain is the DNS domain of the Kubernetes cluster the member hosts are fully qualified with, it defaults to cluster.local
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`
+	ClusterDomain   string                  `json:"clusterDomain,omitempty"`
+	PrimaryRecovery *MongoDBPrimaryRecovery `json:"primaryRecovery,omitempty"`
 }
 
 // MongoDBPodDisruptionBudget defines the struct for MongoDB cluster
@@ -39,8 +61,114 @@ type MongoDBPodDisruptionBudget struct {
 	MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
 }
 
+// MongoDBPerPodService defines the struct for exposing each MongoDB cluster member with its own service
+type MongoDBPerPodService struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// +kubebuilder:validation:Enum=NodePort;LoadBalancer
+	ServiceType        corev1.ServiceType `json:"serviceType,omitempty"`
+	ServiceAnnotations map[string]string  `json:"annotations,omitempty"`
+	// +kubebuilder:validation:Enum=Cluster;Local
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+}
+
+// MongoDBAnalyticsNode defines the struct for a hidden MongoDB cluster member dedicated to analytics
+type MongoDBAnalyticsNode struct {
+	Enabled bool              `json:"enabled,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+}
+
+// MongoDBMemberConfig defines the struct for the replica set configuration of a MongoDB cluster member
+type MongoDBMemberConfig struct {
+	// +kubebuilder:validation:Minimum=0
+	Member int32 `json:"member"`
+	Hidden bool  `json:"hidden,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1000
+	Priority *int32 `json:"priority,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1
+	Votes *int32 `json:"votes,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	SecondaryDelaySecs *int64            `json:"secondaryDelaySecs,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
+}
+
+// MongoDBPrimaryRecovery defines the struct for recovering a MongoDB cluster which has been without a primary for too long
+type MongoDBPrimaryRecovery struct {
+	// ForceReconfig allows the operator to force the member configuration once a majority of the voting members is reachable, writes which only reached the unreachable members are lost
+	ForceReconfig bool `json:"forceReconfig,omitempty"`
+	// +kubebuilder:validation:Minimum=30
+	NoPrimaryTimeoutSeconds *int32 `json:"noPrimaryTimeoutSeconds,omitempty"`
+}
+
+// MongoDBSharding defines the struct for running MongoDB cluster as a sharded cluster
+type MongoDBSharding struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	Shards *int32 `json:"shards,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	ConfigServerSize *int32 `json:"configServerSize,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	MongosSize   *int32             `json:"mongosSize,omitempty"`
+	MongosProbes *MongosProbeConfig `json:"mongosProbes,omitempty"`
+}
+
+// MongosProbeConfig defines the struct for overriding the handlers and timings of the mongos probes, by default they succeed once mongos can read from the config servers
+type MongosProbeConfig struct {
+	ReadinessProbe *Probe `json:"readinessProbe,omitempty"`
+	StartupProbe   *Probe `json:"startupProbe,omitempty"`
+}
+
+// MongoDBMaintenanceJob defines the struct for running compact or reIndex on the secondaries of MongoDB cluster on a schedule
+type MongoDBMaintenanceJob struct {
+	Enabled  bool   `json:"enabled,omitempty"`
+	Schedule string `json:"schedule"`
+	// +kubebuilder:validation:Enum=compact;reIndex
+	Command string `json:"command"`
+	// +kubebuilder:validation:MinItems=1
+	Collections []string                     `json:"collections"`
+	Image       string                       `json:"image,omitempty"`
+	Resources   *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// MongoDBVolumeSnapshot defines the struct for backing up the data volumes of MongoDB cluster secondaries with CSI VolumeSnapshots
+type MongoDBVolumeSnapshot struct {
+	Enabled                 bool            `json:"enabled,omitempty"`
+	Interval                metav1.Duration `json:"interval"`
+	VolumeSnapshotClassName *string         `json:"volumeSnapshotClassName,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	Retention *int32 `json:"retention,omitempty"`
+}
+
 // MongoDBClusterStatus defines the observed state of MongoDBCluster
 type MongoDBClusterStatus struct {
+	RestoreCompleted            bool               `json:"restoreCompleted,omitempty"`
+	CurrentPrimary              string             `json:"currentPrimary,omitempty"`
+	ElectionTerm                int64              `json:"electionTerm,omitempty"`
+	Paused                      bool               `json:"paused,omitempty"`
+	ReplicaSetName              string             `json:"replicaSetName,omitempty"`
+	ConnectionURI               string             `json:"connectionURI,omitempty"`
+	ExternalConnectionURI       string             `json:"externalConnectionURI,omitempty"`
+	MaxLagSeconds               int64              `json:"maxLagSeconds,omitempty"`
+	Conditions                  []metav1.Condition `json:"conditions,omitempty"`
+	LastSnapshotTime            *metav1.Time       `json:"lastSnapshotTime,omitempty"`
+	FeatureCompatibilityVersion string             `json:"featureCompatibilityVersion,omitempty"`
+	NoPrimarySince              *metav1.Time       `json:"noPrimarySince,omitempty"`
+	// DataMembers is the number of pods the data bearing members of the replica set config run on, the pods of removed members are kept until they left the replica set
+	DataMembers int32 `json:"dataMembers,omitempty"`
+	// ManagedUsers are the application users created by the operator, as <db>.<name>
+	ManagedUsers []string `json:"managedUsers,omitempty"`
+	// ManagedRoles are the custom roles created by the operator, as <db>.<name>, they are dropped once they are removed from the spec
+	ManagedRoles []string `json:"managedRoles,omitempty"`
+	// Warnings are the configuration warnings emitted as events, a warning is only emitted again once it changed
+	Warnings []string `json:"warnings,omitempty"`
+	// UpdateDeferred is set while changes of the pod template wait for the maintenance window, the event is only emitted when they are deferred first
+	UpdateDeferred bool `json:"updateDeferred,omitempty"`
+	// SnapshotStartTime is the start of the volume snapshots in progress, the secondaries are snapshotted one after another across reconciliations
+	SnapshotStartTime *metav1.Time `json:"snapshotStartTime,omitempty"`
 }
 
 //+kubebuilder:object:root=true
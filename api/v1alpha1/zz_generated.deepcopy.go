@@ -23,9 +23,35 @@ package v1alpha1
 
 import (
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CABundleSource) DeepCopyInto(out *CABundleSource) {
+	*out = *in
+	if in.ConfigMapName != nil {
+		in, out := &in.ConfigMapName, &out.ConfigMapName
+		*out = new(string)
+		**out = **in
+	}
+	if in.SecretName != nil {
+		in, out := &in.SecretName, &out.SecretName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CABundleSource.
+func (in *CABundleSource) DeepCopy() *CABundleSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CABundleSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExistingPasswordSecret) DeepCopyInto(out *ExistingPasswordSecret) {
 	*out = *in
@@ -51,6 +77,50 @@ func (in *ExistingPasswordSecret) DeepCopy() *ExistingPasswordSecret {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalNameServiceConfig) DeepCopyInto(out *ExternalNameServiceConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalNameServiceConfig.
+func (in *ExternalNameServiceConfig) DeepCopy() *ExternalNameServiceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalNameServiceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeadlessServiceConfig) DeepCopyInto(out *HeadlessServiceConfig) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeadlessServiceConfig.
+func (in *HeadlessServiceConfig) DeepCopy() *HeadlessServiceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(HeadlessServiceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubernetesConfig) DeepCopyInto(out *KubernetesConfig) {
 	*out = *in
@@ -64,6 +134,11 @@ func (in *KubernetesConfig) DeepCopyInto(out *KubernetesConfig) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
 		*out = make(map[string]string, len(*in))
@@ -87,11 +162,170 @@ func (in *KubernetesConfig) DeepCopyInto(out *KubernetesConfig) {
 			}
 		}
 	}
+	if in.PriorityClass != nil {
+		in, out := &in.PriorityClass, &out.PriorityClass
+		*out = new(PriorityClassConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RuntimeClassName != nil {
+		in, out := &in.RuntimeClassName, &out.RuntimeClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.AutomountServiceAccountToken != nil {
+		in, out := &in.AutomountServiceAccountToken, &out.AutomountServiceAccountToken
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ServiceAccountImagePullSecrets != nil {
+		in, out := &in.ServiceAccountImagePullSecrets, &out.ServiceAccountImagePullSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DNSConfig != nil {
+		in, out := &in.DNSConfig, &out.DNSConfig
+		*out = new(v1.PodDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostAliases != nil {
+		in, out := &in.HostAliases, &out.HostAliases
+		*out = make([]v1.HostAlias, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.SecurityContext != nil {
 		in, out := &in.SecurityContext, &out.SecurityContext
 		*out = new(v1.PodSecurityContext)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Sysctls != nil {
+		in, out := &in.Sysctls, &out.Sysctls
+		*out = make([]v1.Sysctl, len(*in))
+		copy(*out, *in)
+	}
+	if in.SeccompProfile != nil {
+		in, out := &in.SeccompProfile, &out.SeccompProfile
+		*out = new(v1.SeccompProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FSGroupChangePolicy != nil {
+		in, out := &in.FSGroupChangePolicy, &out.FSGroupChangePolicy
+		*out = new(v1.PodFSGroupChangePolicy)
+		**out = **in
+	}
+	if in.ContainerSecurityContext != nil {
+		in, out := &in.ContainerSecurityContext, &out.ContainerSecurityContext
+		*out = new(v1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ServiceConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HeadlessService != nil {
+		in, out := &in.HeadlessService, &out.HeadlessService
+		*out = new(HeadlessServiceConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalNameService != nil {
+		in, out := &in.ExternalNameService, &out.ExternalNameService
+		*out = new(ExternalNameServiceConfig)
+		**out = **in
+	}
+	if in.PublishNotReadyAddresses != nil {
+		in, out := &in.PublishNotReadyAddresses, &out.PublishNotReadyAddresses
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EnvVars != nil {
+		in, out := &in.EnvVars, &out.EnvVars
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.EnvFrom != nil {
+		in, out := &in.EnvFrom, &out.EnvFrom
+		*out = make([]v1.EnvFromSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraVolumes != nil {
+		in, out := &in.ExtraVolumes, &out.ExtraVolumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraVolumeMounts != nil {
+		in, out := &in.ExtraVolumeMounts, &out.ExtraVolumeMounts
+		*out = make([]v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraPorts != nil {
+		in, out := &in.ExtraPorts, &out.ExtraPorts
+		*out = make([]v1.ContainerPort, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExtraServicePorts != nil {
+		in, out := &in.ExtraServicePorts, &out.ExtraServicePorts
+		*out = make([]v1.ServicePort, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PostStart != nil {
+		in, out := &in.PostStart, &out.PostStart
+		*out = new(PostStartHook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(ProbeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ProjectedVolume != nil {
+		in, out := &in.ProjectedVolume, &out.ProjectedVolume
+		*out = new(ProjectedVolumeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScratchVolume != nil {
+		in, out := &in.ScratchVolume, &out.ScratchVolume
+		*out = new(ScratchVolumeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PreStopDelaySeconds != nil {
+		in, out := &in.PreStopDelaySeconds, &out.PreStopDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.HoldApplicationUntilProxyStarts != nil {
+		in, out := &in.HoldApplicationUntilProxyStarts, &out.HoldApplicationUntilProxyStarts
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesConfig.
@@ -104,13 +338,34 @@ func (in *KubernetesConfig) DeepCopy() *KubernetesConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongoDB) DeepCopyInto(out *MongoDB) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDB.
@@ -131,13 +386,55 @@ func (in *MongoDB) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBAnalyticsNode) DeepCopyInto(out *MongoDBAnalyticsNode) {
+	*out = *in
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBAnalyticsNode.
+func (in *MongoDBAnalyticsNode) DeepCopy() *MongoDBAnalyticsNode {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBAnalyticsNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBAuditLog) DeepCopyInto(out *MongoDBAuditLog) {
+	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBAuditLog.
+func (in *MongoDBAuditLog) DeepCopy() *MongoDBAuditLog {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBAuditLog)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongoDBCluster) DeepCopyInto(out *MongoDBCluster) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCluster.
@@ -229,6 +526,68 @@ func (in *MongoDBClusterSpec) DeepCopyInto(out *MongoDBClusterSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.MongoDBConfig != nil {
+		in, out := &in.MongoDBConfig, &out.MongoDBConfig
+		*out = new(MongoDBConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MongoDBRestore != nil {
+		in, out := &in.MongoDBRestore, &out.MongoDBRestore
+		*out = new(MongoDBRestore)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PerPodService != nil {
+		in, out := &in.PerPodService, &out.PerPodService
+		*out = new(MongoDBPerPodService)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AnalyticsNode != nil {
+		in, out := &in.AnalyticsNode, &out.AnalyticsNode
+		*out = new(MongoDBAnalyticsNode)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MemberConfig != nil {
+		in, out := &in.MemberConfig, &out.MemberConfig
+		*out = make([]MongoDBMemberConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Sharding != nil {
+		in, out := &in.Sharding, &out.Sharding
+		*out = new(MongoDBSharding)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ArbiterResources != nil {
+		in, out := &in.ArbiterResources, &out.ArbiterResources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindow)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicationLagThresholdSeconds != nil {
+		in, out := &in.ReplicationLagThresholdSeconds, &out.ReplicationLagThresholdSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaintenanceJob != nil {
+		in, out := &in.MaintenanceJob, &out.MaintenanceJob
+		*out = new(MongoDBMaintenanceJob)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeSnapshot != nil {
+		in, out := &in.VolumeSnapshot, &out.VolumeSnapshot
+		*out = new(MongoDBVolumeSnapshot)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrimaryRecovery != nil {
+		in, out := &in.PrimaryRecovery, &out.PrimaryRecovery
+		*out = new(MongoDBPrimaryRecovery)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBClusterSpec.
@@ -244,6 +603,40 @@ func (in *MongoDBClusterSpec) DeepCopy() *MongoDBClusterSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongoDBClusterStatus) DeepCopyInto(out *MongoDBClusterStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastSnapshotTime != nil {
+		in, out := &in.LastSnapshotTime, &out.LastSnapshotTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NoPrimarySince != nil {
+		in, out := &in.NoPrimarySince, &out.NoPrimarySince
+		*out = (*in).DeepCopy()
+	}
+	if in.ManagedUsers != nil {
+		in, out := &in.ManagedUsers, &out.ManagedUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedRoles != nil {
+		in, out := &in.ManagedRoles, &out.ManagedRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Warnings != nil {
+		in, out := &in.Warnings, &out.Warnings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SnapshotStartTime != nil {
+		in, out := &in.SnapshotStartTime, &out.SnapshotStartTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBClusterStatus.
@@ -257,35 +650,209 @@ func (in *MongoDBClusterStatus) DeepCopy() *MongoDBClusterStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MongoDBList) DeepCopyInto(out *MongoDBList) {
+func (in *MongoDBConfig) DeepCopyInto(out *MongoDBConfig) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]MongoDB, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WiredTigerCacheAutoTuning != nil {
+		in, out := &in.WiredTigerCacheAutoTuning, &out.WiredTigerCacheAutoTuning
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OplogSizeMB != nil {
+		in, out := &in.OplogSizeMB, &out.OplogSizeMB
+		*out = new(int32)
+		**out = **in
+	}
+	if in.OplogMinRetentionHours != nil {
+		in, out := &in.OplogMinRetentionHours, &out.OplogMinRetentionHours
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(MongoDBLogging)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WarmUp != nil {
+		in, out := &in.WarmUp, &out.WarmUp
+		*out = new(MongoDBWarmUp)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BindIP != nil {
+		in, out := &in.BindIP, &out.BindIP
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AuditLog != nil {
+		in, out := &in.AuditLog, &out.AuditLog
+		*out = new(MongoDBAuditLog)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBList.
-func (in *MongoDBList) DeepCopy() *MongoDBList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBConfig.
+func (in *MongoDBConfig) DeepCopy() *MongoDBConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(MongoDBList)
+	out := new(MongoDBConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *MongoDBList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBCustomRole) DeepCopyInto(out *MongoDBCustomRole) {
+	*out = *in
+	if in.Privileges != nil {
+		in, out := &in.Privileges, &out.Privileges
+		*out = make([]MongoDBPrivilege, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]MongoDBRoleRef, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBCustomRole.
+func (in *MongoDBCustomRole) DeepCopy() *MongoDBCustomRole {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBCustomRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBList) DeepCopyInto(out *MongoDBList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MongoDB, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBList.
+func (in *MongoDBList) DeepCopy() *MongoDBList {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MongoDBList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBLogging) DeepCopyInto(out *MongoDBLogging) {
+	*out = *in
+	if in.Verbosity != nil {
+		in, out := &in.Verbosity, &out.Verbosity
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBLogging.
+func (in *MongoDBLogging) DeepCopy() *MongoDBLogging {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBLogging)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBMaintenanceJob) DeepCopyInto(out *MongoDBMaintenanceJob) {
+	*out = *in
+	if in.Collections != nil {
+		in, out := &in.Collections, &out.Collections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBMaintenanceJob.
+func (in *MongoDBMaintenanceJob) DeepCopy() *MongoDBMaintenanceJob {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBMaintenanceJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBMemberConfig) DeepCopyInto(out *MongoDBMemberConfig) {
+	*out = *in
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Votes != nil {
+		in, out := &in.Votes, &out.Votes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SecondaryDelaySecs != nil {
+		in, out := &in.SecondaryDelaySecs, &out.SecondaryDelaySecs
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBMemberConfig.
+func (in *MongoDBMemberConfig) DeepCopy() *MongoDBMemberConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBMemberConfig)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -308,6 +875,33 @@ func (in *MongoDBMonitoring) DeepCopy() *MongoDBMonitoring {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBPerPodService) DeepCopyInto(out *MongoDBPerPodService) {
+	*out = *in
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBPerPodService.
+func (in *MongoDBPerPodService) DeepCopy() *MongoDBPerPodService {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBPerPodService)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongoDBPodDisruptionBudget) DeepCopyInto(out *MongoDBPodDisruptionBudget) {
 	*out = *in
@@ -333,10 +927,145 @@ func (in *MongoDBPodDisruptionBudget) DeepCopy() *MongoDBPodDisruptionBudget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBPrimaryRecovery) DeepCopyInto(out *MongoDBPrimaryRecovery) {
+	*out = *in
+	if in.NoPrimaryTimeoutSeconds != nil {
+		in, out := &in.NoPrimaryTimeoutSeconds, &out.NoPrimaryTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBPrimaryRecovery.
+func (in *MongoDBPrimaryRecovery) DeepCopy() *MongoDBPrimaryRecovery {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBPrimaryRecovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBPrivilege) DeepCopyInto(out *MongoDBPrivilege) {
+	*out = *in
+	in.Resource.DeepCopyInto(&out.Resource)
+	if in.Actions != nil {
+		in, out := &in.Actions, &out.Actions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBPrivilege.
+func (in *MongoDBPrivilege) DeepCopy() *MongoDBPrivilege {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBPrivilege)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBPrivilegeResource) DeepCopyInto(out *MongoDBPrivilegeResource) {
+	*out = *in
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		*out = new(string)
+		**out = **in
+	}
+	if in.Collection != nil {
+		in, out := &in.Collection, &out.Collection
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBPrivilegeResource.
+func (in *MongoDBPrivilegeResource) DeepCopy() *MongoDBPrivilegeResource {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBPrivilegeResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBRestore) DeepCopyInto(out *MongoDBRestore) {
+	*out = *in
+	if in.S3Endpoint != nil {
+		in, out := &in.S3Endpoint, &out.S3Endpoint
+		*out = new(string)
+		**out = **in
+	}
+	if in.S3Region != nil {
+		in, out := &in.S3Region, &out.S3Region
+		*out = new(string)
+		**out = **in
+	}
+	if in.S3SecretRef != nil {
+		in, out := &in.S3SecretRef, &out.S3SecretRef
+		*out = new(string)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(CABundleSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBRestore.
+func (in *MongoDBRestore) DeepCopy() *MongoDBRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBRoleRef) DeepCopyInto(out *MongoDBRoleRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBRoleRef.
+func (in *MongoDBRoleRef) DeepCopy() *MongoDBRoleRef {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBRoleRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongoDBSecurity) DeepCopyInto(out *MongoDBSecurity) {
 	*out = *in
 	in.SecretRef.DeepCopyInto(&out.SecretRef)
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]MongoDBUser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]MongoDBCustomRole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBSecurity.
@@ -349,6 +1078,41 @@ func (in *MongoDBSecurity) DeepCopy() *MongoDBSecurity {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBSharding) DeepCopyInto(out *MongoDBSharding) {
+	*out = *in
+	if in.Shards != nil {
+		in, out := &in.Shards, &out.Shards
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ConfigServerSize != nil {
+		in, out := &in.ConfigServerSize, &out.ConfigServerSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MongosSize != nil {
+		in, out := &in.MongosSize, &out.MongosSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MongosProbes != nil {
+		in, out := &in.MongosProbes, &out.MongosProbes
+		*out = new(MongosProbeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBSharding.
+func (in *MongoDBSharding) DeepCopy() *MongoDBSharding {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBSharding)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongoDBSpec) DeepCopyInto(out *MongoDBSpec) {
 	*out = *in
@@ -373,6 +1137,21 @@ func (in *MongoDBSpec) DeepCopyInto(out *MongoDBSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.MongoDBConfig != nil {
+		in, out := &in.MongoDBConfig, &out.MongoDBConfig
+		*out = new(MongoDBConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MongoDBRestore != nil {
+		in, out := &in.MongoDBRestore, &out.MongoDBRestore
+		*out = new(MongoDBRestore)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindow != nil {
+		in, out := &in.MaintenanceWindow, &out.MaintenanceWindow
+		*out = new(MaintenanceWindow)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBSpec.
@@ -388,6 +1167,21 @@ func (in *MongoDBSpec) DeepCopy() *MongoDBSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MongoDBStatus) DeepCopyInto(out *MongoDBStatus) {
 	*out = *in
+	if in.ManagedUsers != nil {
+		in, out := &in.ManagedUsers, &out.ManagedUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedRoles != nil {
+		in, out := &in.ManagedRoles, &out.ManagedRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Warnings != nil {
+		in, out := &in.Warnings, &out.Warnings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBStatus.
@@ -400,6 +1194,335 @@ func (in *MongoDBStatus) DeepCopy() *MongoDBStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBUser) DeepCopyInto(out *MongoDBUser) {
+	*out = *in
+	in.PasswordSecretRef.DeepCopyInto(&out.PasswordSecretRef)
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]MongoDBRoleRef, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBUser.
+func (in *MongoDBUser) DeepCopy() *MongoDBUser {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBUser)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBVolumeSnapshot) DeepCopyInto(out *MongoDBVolumeSnapshot) {
+	*out = *in
+	out.Interval = in.Interval
+	if in.VolumeSnapshotClassName != nil {
+		in, out := &in.VolumeSnapshotClassName, &out.VolumeSnapshotClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBVolumeSnapshot.
+func (in *MongoDBVolumeSnapshot) DeepCopy() *MongoDBVolumeSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBVolumeSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongoDBWarmUp) DeepCopyInto(out *MongoDBWarmUp) {
+	*out = *in
+	if in.Collections != nil {
+		in, out := &in.Collections, &out.Collections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongoDBWarmUp.
+func (in *MongoDBWarmUp) DeepCopy() *MongoDBWarmUp {
+	if in == nil {
+		return nil
+	}
+	out := new(MongoDBWarmUp)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MongosProbeConfig) DeepCopyInto(out *MongosProbeConfig) {
+	*out = *in
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(Probe)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MongosProbeConfig.
+func (in *MongosProbeConfig) DeepCopy() *MongosProbeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MongosProbeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostStartHook) DeepCopyInto(out *PostStartHook) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostStartHook.
+func (in *PostStartHook) DeepCopy() *PostStartHook {
+	if in == nil {
+		return nil
+	}
+	out := new(PostStartHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PriorityClassConfig) DeepCopyInto(out *PriorityClassConfig) {
+	*out = *in
+	if in.PreemptionPolicy != nil {
+		in, out := &in.PreemptionPolicy, &out.PreemptionPolicy
+		*out = new(v1.PreemptionPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PriorityClassConfig.
+func (in *PriorityClassConfig) DeepCopy() *PriorityClassConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PriorityClassConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Probe) DeepCopyInto(out *Probe) {
+	*out = *in
+	in.Handler.DeepCopyInto(&out.Handler)
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SuccessThreshold != nil {
+		in, out := &in.SuccessThreshold, &out.SuccessThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Probe.
+func (in *Probe) DeepCopy() *Probe {
+	if in == nil {
+		return nil
+	}
+	out := new(Probe)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeConfig) DeepCopyInto(out *ProbeConfig) {
+	*out = *in
+	if in.LivenessProbe != nil {
+		in, out := &in.LivenessProbe, &out.LivenessProbe
+		*out = new(Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(Probe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessScript != nil {
+		in, out := &in.ReadinessScript, &out.ReadinessScript
+		*out = new(ReadinessScriptConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeConfig.
+func (in *ProbeConfig) DeepCopy() *ProbeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectedVolumeConfig) DeepCopyInto(out *ProjectedVolumeConfig) {
+	*out = *in
+	if in.DefaultMode != nil {
+		in, out := &in.DefaultMode, &out.DefaultMode
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]v1.VolumeProjection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectedVolumeConfig.
+func (in *ProjectedVolumeConfig) DeepCopy() *ProjectedVolumeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectedVolumeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReadinessScriptConfig) DeepCopyInto(out *ReadinessScriptConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReadinessScriptConfig.
+func (in *ReadinessScriptConfig) DeepCopy() *ReadinessScriptConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ReadinessScriptConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScratchVolumeConfig) DeepCopyInto(out *ScratchVolumeConfig) {
+	*out = *in
+	if in.SizeLimit != nil {
+		in, out := &in.SizeLimit, &out.SizeLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScratchVolumeConfig.
+func (in *ScratchVolumeConfig) DeepCopy() *ScratchVolumeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ScratchVolumeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceConfig) DeepCopyInto(out *ServiceConfig) {
+	*out = *in
+	if in.NodePort != nil {
+		in, out := &in.NodePort, &out.NodePort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ServiceLabels != nil {
+		in, out := &in.ServiceLabels, &out.ServiceLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SessionAffinityTimeoutSeconds != nil {
+		in, out := &in.SessionAffinityTimeoutSeconds, &out.SessionAffinityTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceConfig.
+func (in *ServiceConfig) DeepCopy() *ServiceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Storage) DeepCopyInto(out *Storage) {
 	*out = *in
@@ -413,6 +1536,11 @@ func (in *Storage) DeepCopyInto(out *Storage) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.VolumeMode != nil {
+		in, out := &in.VolumeMode, &out.VolumeMode
+		*out = new(v1.PersistentVolumeMode)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Storage.
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	mongogo "mongodb-operator/mongo"
+)
+
+var log = logf.Log.WithName("controller_backoff")
+
+const (
+	transientErrorBaseDelay = time.Second * 5
+	transientErrorMaxDelay  = time.Minute * 5
+)
+
+// transientErrorBackoff tracks the consecutive transient errors of each object to delay its requeue exponentially
+type transientErrorBackoff struct {
+	mutex    sync.Mutex
+	failures map[types.NamespacedName]int
+}
+
+// handle is a method to requeue the object with backoff on transient errors, other errors are left to the controller rate limiter
+func (b *transientErrorBackoff) handle(key types.NamespacedName, result ctrl.Result, err error) (ctrl.Result, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if err == nil {
+		delete(b.failures, key)
+		return result, nil
+	}
+	if !mongogo.IsTransientError(err) {
+		return result, err
+	}
+	if b.failures == nil {
+		b.failures = make(map[types.NamespacedName]int)
+	}
+	b.failures[key]++
+	delay := getBackoffDelay(b.failures[key])
+	log.Info("Transient error during reconciliation, requeueing with backoff", "Name", key.Name, "Namespace", key.Namespace, "Failures", b.failures[key], "RequeueAfter", delay, "Error", err.Error())
+	return ctrl.Result{RequeueAfter: delay}, nil
+}
+
+// forget is a method to drop the consecutive transient errors of an object, it is called once the object is deleted
+func (b *transientErrorBackoff) forget(key types.NamespacedName) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.failures, key)
+}
+
+// getBackoffDelay is a method to get the exponential delay for the number of consecutive failures, with up to 50% of jitter
+func getBackoffDelay(failures int) time.Duration {
+	delay := transientErrorMaxDelay
+	if failures < 16 {
+		delay = transientErrorBaseDelay << uint(failures-1)
+	}
+	if delay > transientErrorMaxDelay {
+		delay = transientErrorMaxDelay
+	}
+	// the jitter keeps the objects failing together from being requeued at the same time
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
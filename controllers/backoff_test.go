@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestGetBackoffDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "first failure", failures: 1, want: transientErrorBaseDelay},
+		{name: "second failure", failures: 2, want: transientErrorBaseDelay * 2},
+		{name: "fifth failure", failures: 5, want: transientErrorBaseDelay * 16},
+		{name: "capped", failures: 7, want: transientErrorMaxDelay},
+		{name: "capped without overflow", failures: 100, want: transientErrorMaxDelay},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				if delay := getBackoffDelay(test.failures); delay < test.want/2 || delay > test.want {
+					t.Fatalf("getBackoffDelay(%d) = %s, want between %s and %s", test.failures, delay, test.want/2, test.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTransientErrorBackoff(t *testing.T) {
+	key := types.NamespacedName{Name: "mongodb", Namespace: "database"}
+	transient := mongo.CommandError{Code: 91, Message: "shutdown in progress"}
+	var backoff transientErrorBackoff
+	for failures := 1; failures <= 3; failures++ {
+		result, err := backoff.handle(key, ctrl.Result{RequeueAfter: time.Second * 10}, transient)
+		if err != nil || backoff.failures[key] != failures {
+			t.Fatalf("handle() error = %v, failures = %d, want %d", err, backoff.failures[key], failures)
+		}
+		if want := transientErrorBaseDelay << uint(failures-1); result.RequeueAfter < want/2 || result.RequeueAfter > want {
+			t.Errorf("handle() RequeueAfter = %s, want between %s and %s", result.RequeueAfter, want/2, want)
+		}
+	}
+	permanent := errors.New("invalid spec")
+	if _, err := backoff.handle(key, ctrl.Result{}, permanent); err != permanent || backoff.failures[key] != 3 {
+		t.Errorf("handle() error = %v, failures = %d, want the error to be returned without backoff", err, backoff.failures[key])
+	}
+	if _, err := backoff.handle(key, ctrl.Result{}, nil); err != nil {
+		t.Fatalf("handle() error = %v", err)
+	}
+	if _, found := backoff.failures[key]; found {
+		t.Errorf("handle() failures = %d, want them reset on success", backoff.failures[key])
+	}
+	result, _ := backoff.handle(key, ctrl.Result{}, transient)
+	if result.RequeueAfter > transientErrorBaseDelay {
+		t.Errorf("handle() RequeueAfter = %s after reset, want at most %s", result.RequeueAfter, transientErrorBaseDelay)
+	}
+	backoff.forget(key)
+	if _, found := backoff.failures[key]; found {
+		t.Errorf("forget() failures = %d, want them dropped", backoff.failures[key])
+	}
+}
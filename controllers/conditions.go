@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+)
+
+const (
+	// degradedCondition is the status condition reporting a MongoDB cluster which is running with reduced redundancy
+	degradedCondition = "Degraded"
+	// lagReportingResolution is the change of replication lag in seconds required to update the status, so a fluctuating lag doesn't retrigger the reconciliation
+	lagReportingResolution int64 = 5
+)
+
+// isReplicationLagChanged is a method to check if the reported replication lag differs enough from the measured one
+func isReplicationLagChanged(reported int64, measured int64) bool {
+	difference := reported - measured
+	if difference < 0 {
+		difference = -difference
+	}
+	return difference >= lagReportingResolution || (measured == 0 && reported != 0)
+}
+
+// setReplicationLagCondition sets the Degraded condition based on the replication lag and returns if the condition changed
+func setReplicationLagCondition(conditions *[]metav1.Condition, generation int64, lag int64, threshold int64) bool {
+	condition := metav1.Condition{
+		Type:               degradedCondition,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "ReplicationLagWithinThreshold",
+		Message:            fmt.Sprintf("Maximum replication lag of %ds is within the threshold of %ds", lag, threshold),
+	}
+	if lag > threshold {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ReplicationLagExceeded"
+		condition.Message = fmt.Sprintf("Maximum replication lag of %ds exceeds the threshold of %ds", lag, threshold)
+	}
+	return setDegradedCondition(conditions, condition)
+}
+
+// setNoPrimaryCondition sets the Degraded condition for a replica set which has been without a primary for too long and returns if the condition changed
+func setNoPrimaryCondition(conditions *[]metav1.Condition, generation int64, since time.Time) bool {
+	return setDegradedCondition(conditions, metav1.Condition{
+		Type:               degradedCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "NoPrimary",
+		Message:            fmt.Sprintf("No member of the replica set is primary since %s", since.UTC().Format(time.RFC3339)),
+	})
+}
+
+// setDegradedCondition sets the Degraded condition unless only its message or transition time differ, and returns if the condition changed
+func setDegradedCondition(conditions *[]metav1.Condition, condition metav1.Condition) bool {
+	current := meta.FindStatusCondition(*conditions, degradedCondition)
+	if current != nil && current.Status == condition.Status && current.Reason == condition.Reason && current.ObservedGeneration == condition.ObservedGeneration {
+		return false
+	}
+	meta.SetStatusCondition(conditions, condition)
+	return true
+}
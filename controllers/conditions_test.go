@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetReplicationLagCondition(t *testing.T) {
+	tests := []struct {
+		name        string
+		conditions  []metav1.Condition
+		lag         int64
+		wantStatus  metav1.ConditionStatus
+		wantReason  string
+		wantChanged bool
+	}{
+		{name: "caught up", lag: 0, wantStatus: metav1.ConditionFalse, wantReason: "ReplicationLagWithinThreshold", wantChanged: true},
+		{name: "at the threshold", lag: 30, wantStatus: metav1.ConditionFalse, wantReason: "ReplicationLagWithinThreshold", wantChanged: true},
+		{name: "above the threshold", lag: 31, wantStatus: metav1.ConditionTrue, wantReason: "ReplicationLagExceeded", wantChanged: true},
+		{
+			name:       "lag changed within the threshold",
+			conditions: []metav1.Condition{{Type: degradedCondition, Status: metav1.ConditionFalse, ObservedGeneration: 1, Reason: "ReplicationLagWithinThreshold", Message: "Maximum replication lag of 2s is within the threshold of 30s"}},
+			lag:        10,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "ReplicationLagWithinThreshold",
+		},
+		{
+			name:        "recovered",
+			conditions:  []metav1.Condition{{Type: degradedCondition, Status: metav1.ConditionTrue, ObservedGeneration: 1, Reason: "ReplicationLagExceeded"}},
+			lag:         0,
+			wantStatus:  metav1.ConditionFalse,
+			wantReason:  "ReplicationLagWithinThreshold",
+			wantChanged: true,
+		},
+		{
+			name:        "primary elected",
+			conditions:  []metav1.Condition{{Type: degradedCondition, Status: metav1.ConditionTrue, ObservedGeneration: 1, Reason: "NoPrimary"}},
+			lag:         0,
+			wantStatus:  metav1.ConditionFalse,
+			wantReason:  "ReplicationLagWithinThreshold",
+			wantChanged: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conditions := test.conditions
+			if changed := setReplicationLagCondition(&conditions, 1, test.lag, 30); changed != test.wantChanged {
+				t.Errorf("setReplicationLagCondition() = %v, want %v", changed, test.wantChanged)
+			}
+			condition := meta.FindStatusCondition(conditions, degradedCondition)
+			if condition == nil || condition.Status != test.wantStatus || condition.Reason != test.wantReason {
+				t.Errorf("setReplicationLagCondition() condition = %v, want %s with reason %s", condition, test.wantStatus, test.wantReason)
+			}
+		})
+	}
+}
+
+func TestSetNoPrimaryCondition(t *testing.T) {
+	conditions := []metav1.Condition{{Type: degradedCondition, Status: metav1.ConditionFalse, ObservedGeneration: 1, Reason: "ReplicationLagWithinThreshold"}}
+	since := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+	if !setNoPrimaryCondition(&conditions, 1, since) {
+		t.Fatalf("setNoPrimaryCondition() = false, want the condition to change")
+	}
+	condition := meta.FindStatusCondition(conditions, degradedCondition)
+	if condition.Status != metav1.ConditionTrue || condition.Reason != "NoPrimary" {
+		t.Errorf("setNoPrimaryCondition() condition = %v, want True with reason NoPrimary", condition)
+	}
+	if setNoPrimaryCondition(&conditions, 1, since.Add(time.Minute)) {
+		t.Errorf("setNoPrimaryCondition() = true, want the condition unchanged")
+	}
+}
+
+func TestIsReplicationLagChanged(t *testing.T) {
+	tests := []struct {
+		name     string
+		reported int64
+		measured int64
+		want     bool
+	}{
+		{name: "unchanged", reported: 10, measured: 10},
+		{name: "below the resolution", reported: 10, measured: 14},
+		{name: "grown", reported: 10, measured: 15, want: true},
+		{name: "shrunk", reported: 10, measured: 5, want: true},
+		{name: "caught up", reported: 3, measured: 0, want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if changed := isReplicationLagChanged(test.reported, test.measured); changed != test.want {
+				t.Errorf("isReplicationLagChanged() = %v, want %v", changed, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// recordStatefulSetEvents emits events for the changes applied on the MongoDB statefulset during reconciliation
+func recordStatefulSetEvents(recorder record.EventRecorder, object runtime.Object, previous *appsv1.StatefulSet, current *appsv1.StatefulSet) {
+	if current == nil {
+		return
+	}
+	if previous == nil {
+		recorder.Eventf(object, corev1.EventTypeNormal, "StatefulSetCreated", "Created StatefulSet %s", current.Name)
+		return
+	}
+	if previous.Spec.Replicas != nil && current.Spec.Replicas != nil && *previous.Spec.Replicas != *current.Spec.Replicas {
+		recorder.Eventf(object, corev1.EventTypeNormal, "StatefulSetScaled", "Scaled StatefulSet %s from %d to %d replicas", current.Name, *previous.Spec.Replicas, *current.Spec.Replicas)
+		return
+	}
+	if previous.Generation != current.Generation {
+		recorder.Eventf(object, corev1.EventTypeNormal, "StatefulSetUpdated", "Updated StatefulSet %s", current.Name)
+	}
+}
+
+// configWarning is a configuration warning with the reason of its event
+type configWarning struct {
+	reason  string
+	message string
+}
+
+// recordWarningEvents emits the warnings which were not emitted yet and returns the current warnings to be stored in the status
+func recordWarningEvents(recorder record.EventRecorder, object runtime.Object, recorded []string, warnings ...configWarning) []string {
+	var current []string
+	for _, warning := range warnings {
+		if warning.message == "" {
+			continue
+		}
+		current = append(current, warning.message)
+		if !containsString(recorded, warning.message) {
+			recorder.Event(object, corev1.EventTypeWarning, warning.reason, warning.message)
+		}
+	}
+	return current
+}
+
+// containsString checks if the list contains the value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// recordUpdateDeferredEvent emits the UpdateDeferred event once the changes are deferred and returns if the reported state has to be updated
+func recordUpdateDeferredEvent(recorder record.EventRecorder, object runtime.Object, reported bool, deferred bool) bool {
+	if deferred && !reported {
+		recorder.Event(object, corev1.EventTypeNormal, "UpdateDeferred", "Deferred StatefulSet changes until the maintenance window opens")
+	}
+	return deferred != reported
+}
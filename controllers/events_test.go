@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func newTestStatefulSet(replicas int32, generation int64) *appsv1.StatefulSet {
+	statefulSet := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "mongodb-cluster", Generation: generation}}
+	statefulSet.Spec.Replicas = &replicas
+	return statefulSet
+}
+
+func TestRecordStatefulSetEvents(t *testing.T) {
+	tests := []struct {
+		name      string
+		previous  *appsv1.StatefulSet
+		current   *appsv1.StatefulSet
+		wantEvent string
+	}{
+		{name: "no statefulset"},
+		{name: "created", current: newTestStatefulSet(3, 1), wantEvent: "Normal StatefulSetCreated Created StatefulSet mongodb-cluster"},
+		{name: "scaled", previous: newTestStatefulSet(3, 1), current: newTestStatefulSet(5, 2), wantEvent: "Normal StatefulSetScaled Scaled StatefulSet mongodb-cluster from 3 to 5 replicas"},
+		{name: "updated", previous: newTestStatefulSet(3, 1), current: newTestStatefulSet(3, 2), wantEvent: "Normal StatefulSetUpdated Updated StatefulSet mongodb-cluster"},
+		{name: "unchanged", previous: newTestStatefulSet(3, 1), current: newTestStatefulSet(3, 1)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(1)
+			recordStatefulSetEvents(recorder, &opstreelabsinv1alpha1.MongoDBCluster{}, test.previous, test.current)
+			var event string
+			select {
+			case event = <-recorder.Events:
+			default:
+			}
+			if event != test.wantEvent {
+				t.Errorf("recordStatefulSetEvents() event = %q, want %q", event, test.wantEvent)
+			}
+		})
+	}
+}
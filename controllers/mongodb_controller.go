@@ -19,11 +19,14 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -33,11 +36,24 @@ import (
 	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
 )
 
+const (
+	// mongoDBFinalizer is the finalizer for releasing external resources before MongoDB objects are deleted
+	mongoDBFinalizer = "mongodb.opstreelabs.in/finalizer"
+	// pausedAnnotation is the annotation for pausing the reconciliation of MongoDB objects
+	pausedAnnotation = "mongodb.opstreelabs.in/paused"
+	// dryRunAnnotation is the annotation for logging the generated manifests of MongoDB objects instead of applying them
+	dryRunAnnotation = "mongodb.opstreelabs.in/dry-run"
+)
+
 // MongoDBReconciler reconciles a MongoDB object
 type MongoDBReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ResyncPeriod is the interval MongoDB objects are reconciled at without any change
+	ResyncPeriod time.Duration
+	backoff      transientErrorBackoff
 }
 
 //+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbs,verbs=get;list;watch;create;update;patch;delete
@@ -45,20 +61,62 @@ type MongoDBReconciler struct {
 //+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbs/finalizers,verbs=update
 //+kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups="",resources=configmaps;events;services;secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=configmaps;events;services;secrets;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=scheduling.k8s.io,resources=priorityclasses,verbs=get;list;watch;create;update;delete
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;update;patch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 func (r *MongoDBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	result, err := r.reconcile(ctx, req)
+	return r.backoff.handle(req.NamespacedName, withResync(result, err, r.ResyncPeriod), err)
+}
+
+// reconcile is the reconciliation of MongoDB objects, transient errors are requeued with backoff by Reconcile
+func (r *MongoDBReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	instance := &opstreelabsinv1alpha1.MongoDB{}
 	err := r.Client.Get(context.TODO(), req.NamespacedName, instance)
 	if err != nil {
 		if errors.IsNotFound(err) {
+			r.backoff.forget(req.NamespacedName)
 			return ctrl.Result{RequeueAfter: time.Second * 10}, nil
 		}
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
-	if err := controllerutil.SetControllerReference(instance, instance, r.Scheme); err != nil {
-		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	if instance.GetDeletionTimestamp() != nil {
+		if controllerutil.ContainsFinalizer(instance, mongoDBFinalizer) {
+			if err := k8sgo.CleanupMongoStandaloneResources(instance); err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+			controllerutil.RemoveFinalizer(instance, mongoDBFinalizer)
+			if err := r.Client.Update(ctx, instance); err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+	if !controllerutil.ContainsFinalizer(instance, mongoDBFinalizer) {
+		controllerutil.AddFinalizer(instance, mongoDBFinalizer)
+		if err := r.Client.Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	paused := isReconcilePaused(instance.Annotations)
+	if paused != instance.Status.Paused {
+		instance.Status.Paused = paused
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	if paused {
+		return ctrl.Result{}, nil
+	}
+	if instance.Annotations[dryRunAnnotation] == "true" {
+		if err := k8sgo.PreviewMongoStandaloneManifests(instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+		return ctrl.Result{}, nil
 	}
 	if !k8sgo.CheckSecretExist(instance.Namespace, fmt.Sprintf("%s-%s", instance.ObjectMeta.Name, "standalone-monitoring")) {
 		err = k8sgo.CreateMongoMonitoringSecret(instance)
@@ -66,29 +124,104 @@ func (r *MongoDBReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 			return ctrl.Result{RequeueAfter: time.Second * 10}, err
 		}
 	}
-	err = k8sgo.CreateMongoStandaloneSetup(instance)
+	err = k8sgo.CreateMongoStandaloneRBAC(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	err = k8sgo.CreateMongoStandalonePriorityClass(instance)
 	if err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
+	warnings := recordWarningEvents(r.Recorder, instance, instance.Status.Warnings,
+		configWarning{reason: "MutableImageTag", message: k8sgo.CheckMongoStandaloneImages(instance)},
+		configWarning{reason: "InMemoryStorageEngine", message: k8sgo.CheckMongoStandaloneStorageEngine(instance)},
+	)
+	if !reflect.DeepEqual(warnings, instance.Status.Warnings) {
+		instance.Status.Warnings = warnings
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	previousSTS, err := k8sgo.GetStateFulSet(instance.Namespace, fmt.Sprintf("%s-%s", instance.ObjectMeta.Name, "standalone"))
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	err = k8sgo.CreateMongoStandaloneSetup(instance)
+	deferred := k8sgo.IsUpdateDeferred(err)
+	if err != nil && !deferred {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "StatefulSetFailed", "Failed to reconcile StatefulSet: %v", err)
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if recordUpdateDeferredEvent(r.Recorder, instance, instance.Status.UpdateDeferred, deferred) {
+		instance.Status.UpdateDeferred = deferred
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
 	err = k8sgo.CreateMongoStandaloneService(instance)
 	if err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
+	connectionURI, externalConnectionURI, err := k8sgo.GetMongoStandaloneConnectionURIs(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if connectionURI != instance.Status.ConnectionURI || externalConnectionURI != instance.Status.ExternalConnectionURI {
+		instance.Status.ConnectionURI = connectionURI
+		instance.Status.ExternalConnectionURI = externalConnectionURI
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	err = k8sgo.CreateMongoStandaloneConnectionSecret(instance, connectionURI)
+	if err != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "ConnectionSecretFailed", "Failed to sync the connection secret: %v", err)
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
 	mongoDBSTS, err := k8sgo.GetStateFulSet(instance.Namespace, fmt.Sprintf("%s-%s", instance.ObjectMeta.Name, "standalone"))
 	if err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
+	recordStatefulSetEvents(r.Recorder, instance, previousSTS, mongoDBSTS)
 	if int(mongoDBSTS.Status.ReadyReplicas) != int(1) {
-		return ctrl.Result{RequeueAfter: time.Second * 60}, nil
+		return getStandaloneResult(instance, deferred, time.Second*60), nil
 	} else {
+		if instance.Spec.MongoDBRestore != nil && !instance.Status.RestoreCompleted {
+			instance.Status.RestoreCompleted = true
+			if err := r.Client.Status().Update(ctx, instance); err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+		}
 		if !k8sgo.CheckMonitoringUser(instance) {
 			err = k8sgo.CreateMongoDBMonitoringUser(instance)
 			if err != nil {
 				return ctrl.Result{RequeueAfter: time.Second * 10}, err
 			}
 		}
+		managedUsers, managedRoles, err := k8sgo.ReconcileMongoStandaloneUsers(instance)
+		if err != nil {
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "UsersFailed", "Failed to reconcile users: %v", err)
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+		if !reflect.DeepEqual(managedUsers, instance.Status.ManagedUsers) || !reflect.DeepEqual(managedRoles, instance.Status.ManagedRoles) {
+			instance.Status.ManagedUsers = managedUsers
+			instance.Status.ManagedRoles = managedRoles
+			if err := r.Client.Status().Update(ctx, instance); err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+		}
+	}
+	return getStandaloneResult(instance, deferred, time.Second*10), nil
+}
+
+// getStandaloneResult returns the periodic requeue of MongoDB standalone, it is shortened so the deferred changes are applied once the maintenance window opens
+func getStandaloneResult(instance *opstreelabsinv1alpha1.MongoDB, deferred bool, requeueAfter time.Duration) ctrl.Result {
+	if deferred {
+		if delay := k8sgo.GetMaintenanceWindowDelay(instance.Spec.MaintenanceWindow, time.Now()); delay < requeueAfter {
+			requeueAfter = delay
+		}
 	}
-	return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	return ctrl.Result{RequeueAfter: requeueAfter}
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -19,11 +19,16 @@ package controllers
 import (
 	"context"
 	"fmt"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"strings"
 	"time"
 
 	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
@@ -33,37 +38,140 @@ import (
 // MongoDBClusterReconciler reconciles a MongoDBCluster object
 type MongoDBClusterReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	// ResyncPeriod is the interval MongoDBCluster objects are reconciled at without any change
+	ResyncPeriod time.Duration
+	backoff      transientErrorBackoff
 }
 
 //+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbclusters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbclusters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=opstreelabs.in,resources=mongodbclusters/finalizers,verbs=update
 //+kubebuilder:rbac:groups="policy",resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create;update;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 func (r *MongoDBClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	result, err := r.reconcile(ctx, req)
+	return r.backoff.handle(req.NamespacedName, withResync(result, err, r.ResyncPeriod), err)
+}
+
+// reconcile is the reconciliation of MongoDBCluster objects, transient errors are requeued with backoff by Reconcile
+func (r *MongoDBClusterReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	instance := &opstreelabsinv1alpha1.MongoDBCluster{}
 	err := r.Client.Get(context.TODO(), req.NamespacedName, instance)
 	if err != nil {
 		if errors.IsNotFound(err) {
+			r.backoff.forget(req.NamespacedName)
 			return ctrl.Result{RequeueAfter: time.Second * 10}, nil
 		}
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
-	if err := controllerutil.SetControllerReference(instance, instance, r.Scheme); err != nil {
+	if instance.GetDeletionTimestamp() != nil {
+		if controllerutil.ContainsFinalizer(instance, mongoDBFinalizer) {
+			if err := k8sgo.CleanupMongoClusterResources(instance); err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+			controllerutil.RemoveFinalizer(instance, mongoDBFinalizer)
+			if err := r.Client.Update(ctx, instance); err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+		}
+		return ctrl.Result{}, nil
+	}
+	if !controllerutil.ContainsFinalizer(instance, mongoDBFinalizer) {
+		controllerutil.AddFinalizer(instance, mongoDBFinalizer)
+		if err := r.Client.Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	paused := isReconcilePaused(instance.Annotations)
+	if paused != instance.Status.Paused {
+		instance.Status.Paused = paused
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	if paused {
+		return ctrl.Result{}, nil
+	}
+	if instance.Annotations[dryRunAnnotation] == "true" {
+		if err := k8sgo.PreviewMongoClusterManifests(instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	err = k8sgo.CreateMongoClusterRBAC(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	err = k8sgo.CreateMongoClusterPriorityClass(instance)
+	if err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
+	warnings := recordWarningEvents(r.Recorder, instance, instance.Status.Warnings,
+		configWarning{reason: "MutableImageTag", message: k8sgo.CheckMongoClusterImages(instance)},
+		configWarning{reason: "InMemoryStorageEngine", message: k8sgo.CheckMongoClusterStorageEngine(instance)},
+	)
+	if !reflect.DeepEqual(warnings, instance.Status.Warnings) {
+		instance.Status.Warnings = warnings
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	err = k8sgo.CreateMongoClusterKeyFileSecret(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if instance.Spec.Sharding != nil && instance.Spec.Sharding.Enabled {
+		return r.reconcileShardedCluster(ctx, instance)
+	}
 	if !k8sgo.CheckSecretExist(instance.Namespace, fmt.Sprintf("%s-%s", instance.ObjectMeta.Name, "cluster-monitoring")) {
 		err = k8sgo.CreateMongoClusterMonitoringSecret(instance)
 		if err != nil {
 			return ctrl.Result{RequeueAfter: time.Second * 10}, err
 		}
 	}
+	previousSTS, err := k8sgo.GetStateFulSet(instance.Namespace, fmt.Sprintf("%s-%s", instance.ObjectMeta.Name, "cluster"))
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
 	err = k8sgo.CreateMongoClusterSetup(instance)
-	if err != nil {
+	deferred := k8sgo.IsUpdateDeferred(err)
+	if err != nil && !deferred {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "StatefulSetFailed", "Failed to reconcile StatefulSet: %v", err)
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	// new volumes are only provisioned when the statefulset is created or scaled up
+	if previousSTS == nil || previousSTS.Spec.Replicas == nil || *previousSTS.Spec.Replicas < *instance.Spec.MongoDBClusterSize {
+		if warning := k8sgo.CheckMongoClusterStorageBinding(instance); warning != "" {
+			r.Recorder.Event(instance, corev1.EventTypeWarning, "ImmediateVolumeBinding", warning)
+		}
+	}
+	err = k8sgo.CreateMongoClusterArbiter(instance)
+	if k8sgo.IsUpdateDeferred(err) {
+		deferred = true
+	} else if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	err = k8sgo.RollMongoDBClusterPods(instance)
+	rolling := k8sgo.IsRolloutInProgress(err)
+	if k8sgo.IsUpdateDeferred(err) {
+		deferred = true
+	} else if err != nil && !rolling {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "RolloutFailed", "Failed to update the pods of the replica set: %v", err)
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
+	if recordUpdateDeferredEvent(r.Recorder, instance, instance.Status.UpdateDeferred, deferred) {
+		instance.Status.UpdateDeferred = deferred
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
 	err = k8sgo.CreateMongoClusterMonitoringService(instance)
 	if err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
@@ -72,26 +180,321 @@ func (r *MongoDBClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	if err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
+	err = k8sgo.CreateMongoClusterPerPodServices(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	connectionURI, externalConnectionURI, err := k8sgo.GetMongoClusterConnectionURIs(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if connectionURI != instance.Status.ConnectionURI || externalConnectionURI != instance.Status.ExternalConnectionURI {
+		instance.Status.ConnectionURI = connectionURI
+		instance.Status.ExternalConnectionURI = externalConnectionURI
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	err = k8sgo.CreateMongoClusterConnectionSecret(instance, connectionURI)
+	if err != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "ConnectionSecretFailed", "Failed to sync the connection secret: %v", err)
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
 	mongoDBSTS, err := k8sgo.GetStateFulSet(instance.Namespace, fmt.Sprintf("%s-%s", instance.ObjectMeta.Name, "cluster"))
 	if err != nil {
 		return ctrl.Result{RequeueAfter: time.Second * 10}, err
 	}
-	if int(mongoDBSTS.Status.ReadyReplicas) != int(*instance.Spec.MongoDBClusterSize) {
+	recordStatefulSetEvents(r.Recorder, instance, previousSTS, mongoDBSTS)
+	// the pods of members which are being removed from the replica set may not be ready anymore
+	if int(mongoDBSTS.Status.ReadyReplicas) < int(*instance.Spec.MongoDBClusterSize) {
+		if rolling {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+		}
+		return ctrl.Result{RequeueAfter: time.Second * 60}, nil
+	}
+	arbiterReady, err := k8sgo.CheckMongoClusterArbiterReady(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if !arbiterReady {
 		return ctrl.Result{RequeueAfter: time.Second * 60}, nil
 	}
 	state, err := k8sgo.CheckMongoClusterStateInitialized(instance)
 	if err != nil || !state {
 		err = k8sgo.InitializeMongoDBCluster(instance)
 		if err != nil {
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "ReplicaSetInitFailed", "Failed to initiate replica set: %v", err)
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+		r.Recorder.Event(instance, corev1.EventTypeNormal, "ReplicaSetInitialized", "Initiated replica set")
+	}
+	hasPrimary, err := k8sgo.CheckMongoDBClusterPrimary(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if !hasPrimary {
+		return r.reconcileNoPrimary(ctx, instance)
+	}
+	if instance.Status.NoPrimarySince != nil {
+		instance.Status.NoPrimarySince = nil
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	dataMembers, err := k8sgo.ReconcileMongoDBClusterMembers(instance)
+	membershipChanging := k8sgo.IsMembershipChangeInProgress(err)
+	if err != nil && !membershipChanging {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "ReplicaSetReconfigFailed", "Failed to reconfigure replica set members: %v", err)
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if dataMembers != instance.Status.DataMembers {
+		instance.Status.DataMembers = dataMembers
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	resized, err := k8sgo.ReconcileMongoClusterOplogSize(instance)
+	if len(resized) > 0 {
+		r.Recorder.Eventf(instance, corev1.EventTypeNormal, "OplogResized", "Resized the oplog of %s", strings.Join(resized, ", "))
+	}
+	if err != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "OplogResizeFailed", "Failed to resize the oplog: %v", err)
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if instance.Status.ReplicaSetName == "" {
+		instance.Status.ReplicaSetName = k8sgo.GetMongoDBReplicaSetName(instance)
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	primary, term, err := k8sgo.GetMongoDBClusterPrimary(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if setCurrentPrimary(r.Recorder, instance, primary, term) {
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+		// the load balancer client service follows the primary
+		if err := k8sgo.CreateMongoClusterService(instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	lag, err := k8sgo.GetMongoDBClusterReplicationLag(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	conditionChanged := setReplicationLagCondition(&instance.Status.Conditions, instance.Generation, lag, k8sgo.GetReplicationLagThreshold(instance))
+	if conditionChanged || isReplicationLagChanged(instance.Status.MaxLagSeconds, lag) {
+		if conditionChanged && lag > k8sgo.GetReplicationLagThreshold(instance) {
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "ReplicationLagExceeded", "Maximum replication lag of %ds exceeds the threshold", lag)
+		}
+		instance.Status.MaxLagSeconds = lag
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	if instance.Spec.MongoDBRestore != nil && !instance.Status.RestoreCompleted {
+		instance.Status.RestoreCompleted = true
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
 			return ctrl.Result{RequeueAfter: time.Second * 10}, err
 		}
 	}
+	err = k8sgo.CreateMongoClusterMaintenanceJob(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
 	if !k8sgo.CheckMongoDBClusterMonitoringUser(instance) {
 		err = k8sgo.CreateMongoDBClusterMonitoringUser(instance)
 		if err != nil {
 			return ctrl.Result{RequeueAfter: time.Second * 10}, err
 		}
 	}
+	managedUsers, managedRoles, err := k8sgo.ReconcileMongoClusterUsers(instance)
+	if err != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "UsersFailed", "Failed to reconcile users: %v", err)
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if !reflect.DeepEqual(managedUsers, instance.Status.ManagedUsers) || !reflect.DeepEqual(managedRoles, instance.Status.ManagedRoles) {
+		instance.Status.ManagedUsers = managedUsers
+		instance.Status.ManagedRoles = managedRoles
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	var result ctrl.Result
+	if rolling || membershipChanging {
+		// the pods are not watched, so the rollout and the serial member changes are driven by polling
+		result.RequeueAfter = time.Second * 10
+	}
+	if instance.Spec.FeatureCompatibilityVersion != "" && instance.Spec.FeatureCompatibilityVersion != instance.Status.FeatureCompatibilityVersion {
+		changed, err := k8sgo.ReconcileMongoDBClusterFeatureCompatibilityVersion(instance)
+		switch {
+		case k8sgo.IsFeatureCompatibilityVersionDowngrade(err):
+			// retrying doesn't help until the spec is changed, which triggers a reconcile anyway
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "FeatureCompatibilityVersionRejected", "Feature compatibility version %s not set: %v", instance.Spec.FeatureCompatibilityVersion, err)
+		case k8sgo.IsUpgradeInProgress(err):
+			// the statefulsets are not watched, so the end of the upgrade is polled
+			if result.RequeueAfter == 0 {
+				result.RequeueAfter = time.Second * 30
+			}
+		case err != nil:
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "FeatureCompatibilityVersionFailed", "Failed to set feature compatibility version %s: %v", instance.Spec.FeatureCompatibilityVersion, err)
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		default:
+			if changed {
+				r.Recorder.Eventf(instance, corev1.EventTypeNormal, "FeatureCompatibilityVersionSet", "Set feature compatibility version to %s", instance.Spec.FeatureCompatibilityVersion)
+			}
+			instance.Status.FeatureCompatibilityVersion = instance.Spec.FeatureCompatibilityVersion
+			if err := r.Client.Status().Update(ctx, instance); err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+		}
+	}
+	if k8sgo.IsVolumeSnapshotEnabled(instance) {
+		if instance.Status.SnapshotStartTime == nil && k8sgo.GetMongoClusterSnapshotDelay(instance, time.Now()) == 0 {
+			instance.Status.SnapshotStartTime = &metav1.Time{Time: time.Now()}
+			if err := r.Client.Status().Update(ctx, instance); err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+		}
+		if instance.Status.SnapshotStartTime != nil {
+			snapshots, completed, err := k8sgo.ReconcileMongoClusterSnapshots(instance, time.Now())
+			if err != nil {
+				r.Recorder.Eventf(instance, corev1.EventTypeWarning, "SnapshotFailed", "Failed to snapshot the data volumes: %v", err)
+				// the next attempt starts over with new snapshots
+				instance.Status.SnapshotStartTime = nil
+				if updateErr := r.Client.Status().Update(ctx, instance); updateErr != nil {
+					return ctrl.Result{RequeueAfter: time.Second * 10}, updateErr
+				}
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+			if !completed {
+				// the snapshot being cut is checked again instead of blocking the reconciliation
+				if result.RequeueAfter == 0 || k8sgo.SnapshotPollInterval < result.RequeueAfter {
+					result.RequeueAfter = k8sgo.SnapshotPollInterval
+				}
+				return result, nil
+			}
+			r.Recorder.Eventf(instance, corev1.EventTypeNormal, "SnapshotCreated", "Created VolumeSnapshots %s", strings.Join(snapshots, ", "))
+			instance.Status.LastSnapshotTime = instance.Status.SnapshotStartTime
+			instance.Status.SnapshotStartTime = nil
+			if err := r.Client.Status().Update(ctx, instance); err != nil {
+				return ctrl.Result{RequeueAfter: time.Second * 10}, err
+			}
+		}
+		if delay := k8sgo.GetMongoClusterSnapshotDelay(instance, time.Now()); result.RequeueAfter == 0 || delay < result.RequeueAfter {
+			result.RequeueAfter = delay
+		}
+	}
+	if deferred {
+		if delay := k8sgo.GetMaintenanceWindowDelay(instance.Spec.MaintenanceWindow, time.Now()); result.RequeueAfter == 0 || delay < result.RequeueAfter {
+			result.RequeueAfter = delay
+		}
+	}
+	return result, nil
+}
+
+// reconcileNoPrimary is the reconciliation of MongoDB cluster while no member is primary, it is reported degraded once the timeout elapses and its configuration is forced when it is allowed and safe
+func (r *MongoDBClusterReconciler) reconcileNoPrimary(ctx context.Context, instance *opstreelabsinv1alpha1.MongoDBCluster) (ctrl.Result, error) {
+	if instance.Status.NoPrimarySince == nil {
+		now := metav1.Now()
+		instance.Status.NoPrimarySince = &now
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
+	// a replica set elects a primary within seconds, so a short loss of the primary is left to the election
+	if time.Since(instance.Status.NoPrimarySince.Time) < k8sgo.GetNoPrimaryTimeout(instance) {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
+	if setNoPrimaryCondition(&instance.Status.Conditions, instance.Generation, instance.Status.NoPrimarySince.Time) {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "NoPrimary", "No member of the replica set is primary since %s", instance.Status.NoPrimarySince.UTC().Format(time.RFC3339))
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	if !k8sgo.IsForceReconfigEnabled(instance) {
+		return ctrl.Result{RequeueAfter: time.Second * 30}, nil
+	}
+	host, err := k8sgo.ForceReconfigureMongoDBCluster(instance)
+	if err != nil {
+		if k8sgo.IsForceReconfigUnsafe(err) {
+			r.Recorder.Eventf(instance, corev1.EventTypeWarning, "ForceReconfigSkipped", "Not forcing the replica set configuration: %v", err)
+			return ctrl.Result{RequeueAfter: time.Second * 30}, nil
+		}
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "ForceReconfigFailed", "Failed to force the replica set configuration: %v", err)
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	r.Recorder.Eventf(instance, corev1.EventTypeWarning, "ForceReconfigured", "Forced the replica set configuration on %s", host)
+	// the timeout starts over, so the replica set gets the time to elect a primary before the configuration is forced again
+	now := metav1.Now()
+	instance.Status.NoPrimarySince = &now
+	if err := r.Client.Status().Update(ctx, instance); err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	return ctrl.Result{RequeueAfter: time.Second * 10}, nil
+}
+
+// reconcileShardedCluster is the reconciliation loop for MongoDB cluster running in sharded mode
+func (r *MongoDBClusterReconciler) reconcileShardedCluster(ctx context.Context, instance *opstreelabsinv1alpha1.MongoDBCluster) (ctrl.Result, error) {
+	err := k8sgo.CreateMongoShardedClusterSetup(instance)
+	deferred := k8sgo.IsUpdateDeferred(err)
+	if err != nil && !deferred {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if recordUpdateDeferredEvent(r.Recorder, instance, instance.Status.UpdateDeferred, deferred) {
+		instance.Status.UpdateDeferred = deferred
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	connectionURI, externalConnectionURI, err := k8sgo.GetMongoClusterConnectionURIs(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if connectionURI != instance.Status.ConnectionURI || externalConnectionURI != instance.Status.ExternalConnectionURI {
+		instance.Status.ConnectionURI = connectionURI
+		instance.Status.ExternalConnectionURI = externalConnectionURI
+		if err := r.Client.Status().Update(ctx, instance); err != nil {
+			return ctrl.Result{RequeueAfter: time.Second * 10}, err
+		}
+	}
+	err = k8sgo.CreateMongoClusterConnectionSecret(instance, connectionURI)
+	if err != nil {
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "ConnectionSecretFailed", "Failed to sync the connection secret: %v", err)
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	ready, err := k8sgo.CheckMongoShardedReplicaSetsReady(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if !ready {
+		return ctrl.Result{RequeueAfter: time.Second * 60}, nil
+	}
+	err = k8sgo.InitializeMongoShardedReplicaSets(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	ready, err = k8sgo.CheckMongosReady(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if !ready {
+		return ctrl.Result{RequeueAfter: time.Second * 60}, nil
+	}
+	err = k8sgo.AddMongoShards(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	err = k8sgo.CreateMongoClusterMaintenanceJob(instance)
+	if err != nil {
+		return ctrl.Result{RequeueAfter: time.Second * 10}, err
+	}
+	if deferred {
+		return ctrl.Result{RequeueAfter: k8sgo.GetMaintenanceWindowDelay(instance.Spec.MaintenanceWindow, time.Now())}, nil
+	}
 	return ctrl.Result{}, nil
 }
 
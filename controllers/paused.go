@@ -0,0 +1,6 @@
+package controllers
+
+// isReconcilePaused returns whether the reconciliation of MongoDB objects is paused with the paused annotation
+func isReconcilePaused(annotations map[string]string) bool {
+	return annotations[pausedAnnotation] == "true"
+}
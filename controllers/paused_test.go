@@ -0,0 +1,24 @@
+package controllers
+
+import "testing"
+
+func TestIsReconcilePaused(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{name: "no annotations"},
+		{name: "other annotations", annotations: map[string]string{"team": "database"}},
+		{name: "paused", annotations: map[string]string{pausedAnnotation: "true"}, want: true},
+		{name: "not paused", annotations: map[string]string{pausedAnnotation: "false"}},
+		{name: "invalid value", annotations: map[string]string{pausedAnnotation: "yes"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if paused := isReconcilePaused(test.annotations); paused != test.want {
+				t.Errorf("isReconcilePaused() = %v, want %v", paused, test.want)
+			}
+		})
+	}
+}
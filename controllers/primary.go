@@ -0,0 +1,21 @@
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+// setCurrentPrimary records the primary and its election term in the status, emits a PrimaryChanged event once a previous primary is known and returns if the primary changed
+func setCurrentPrimary(recorder record.EventRecorder, instance *opstreelabsinv1alpha1.MongoDBCluster, primary string, term int64) bool {
+	if primary == "" || primary == instance.Status.CurrentPrimary {
+		return false
+	}
+	if instance.Status.CurrentPrimary != "" {
+		recorder.Eventf(instance, corev1.EventTypeNormal, "PrimaryChanged", "Primary changed from %s to %s (election term %d)", instance.Status.CurrentPrimary, primary, term)
+	}
+	instance.Status.CurrentPrimary = primary
+	instance.Status.ElectionTerm = term
+	return true
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"testing"
+
+	"k8s.io/client-go/tools/record"
+
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestSetCurrentPrimary(t *testing.T) {
+	tests := []struct {
+		name           string
+		currentPrimary string
+		primary        string
+		wantChanged    bool
+		wantEvent      string
+	}{
+		{name: "first primary", primary: "mongodb-cluster-0", wantChanged: true},
+		{name: "unchanged", currentPrimary: "mongodb-cluster-0", primary: "mongodb-cluster-0"},
+		{name: "no primary", currentPrimary: "mongodb-cluster-0"},
+		{name: "election", currentPrimary: "mongodb-cluster-0", primary: "mongodb-cluster-1", wantChanged: true, wantEvent: "Normal PrimaryChanged Primary changed from mongodb-cluster-0 to mongodb-cluster-1 (election term 4)"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(1)
+			instance := &opstreelabsinv1alpha1.MongoDBCluster{}
+			instance.Status.CurrentPrimary = test.currentPrimary
+			instance.Status.ElectionTerm = 3
+			if changed := setCurrentPrimary(recorder, instance, test.primary, 4); changed != test.wantChanged {
+				t.Errorf("setCurrentPrimary() = %v, want %v", changed, test.wantChanged)
+			}
+			if test.wantChanged && (instance.Status.CurrentPrimary != test.primary || instance.Status.ElectionTerm != 4) {
+				t.Errorf("setCurrentPrimary() status = %s in term %d, want %s in term 4", instance.Status.CurrentPrimary, instance.Status.ElectionTerm, test.primary)
+			}
+			if !test.wantChanged && (instance.Status.CurrentPrimary != test.currentPrimary || instance.Status.ElectionTerm != 3) {
+				t.Errorf("setCurrentPrimary() changed the status to %s in term %d", instance.Status.CurrentPrimary, instance.Status.ElectionTerm)
+			}
+			var event string
+			select {
+			case event = <-recorder.Events:
+			default:
+			}
+			if event != test.wantEvent {
+				t.Errorf("setCurrentPrimary() event = %q, want %q", event, test.wantEvent)
+			}
+		})
+	}
+}
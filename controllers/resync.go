@@ -0,0 +1,21 @@
+package controllers
+
+import (
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// DefaultResyncPeriod is the interval the MongoDB objects are reconciled at without any change, to verify the health of the replica set
+const DefaultResyncPeriod = time.Minute * 5
+
+// withResync is a method to requeue a successful reconciliation after the resync period at the latest, a period of 0 disables the resync
+func withResync(result ctrl.Result, err error, resyncPeriod time.Duration) ctrl.Result {
+	if err != nil || resyncPeriod <= 0 {
+		return result
+	}
+	if result.RequeueAfter == 0 || result.RequeueAfter > resyncPeriod {
+		result.RequeueAfter = resyncPeriod
+	}
+	return result
+}
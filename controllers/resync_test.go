@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestWithResync(t *testing.T) {
+	tests := []struct {
+		name         string
+		result       ctrl.Result
+		err          error
+		resyncPeriod time.Duration
+		want         ctrl.Result
+	}{
+		{name: "resync", resyncPeriod: DefaultResyncPeriod, want: ctrl.Result{RequeueAfter: DefaultResyncPeriod}},
+		{name: "resync disabled", resyncPeriod: 0, want: ctrl.Result{}},
+		{name: "resync disabled keeps the requeue", result: ctrl.Result{RequeueAfter: time.Hour}, want: ctrl.Result{RequeueAfter: time.Hour}},
+		{name: "error", result: ctrl.Result{RequeueAfter: time.Second * 10}, err: errors.New("unreachable"), resyncPeriod: DefaultResyncPeriod, want: ctrl.Result{RequeueAfter: time.Second * 10}},
+		{name: "error without requeue", err: errors.New("unreachable"), resyncPeriod: DefaultResyncPeriod, want: ctrl.Result{}},
+		{name: "shorter requeue", result: ctrl.Result{RequeueAfter: time.Second * 30}, resyncPeriod: DefaultResyncPeriod, want: ctrl.Result{RequeueAfter: time.Second * 30}},
+		{name: "longer requeue", result: ctrl.Result{RequeueAfter: time.Hour}, resyncPeriod: DefaultResyncPeriod, want: ctrl.Result{RequeueAfter: DefaultResyncPeriod}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := withResync(test.result, test.err, test.resyncPeriod); result != test.want {
+				t.Errorf("withResync() = %v, want %v", result, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package k8sgo
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/mongo"
+)
+
+// arbiterMemberID is the replica set member id of the arbiter, the highest one so it never collides with the data bearing members
+const arbiterMemberID = 255
+
+// isArbiterEnabled is a method to check if the arbiter is enabled for mongodb cluster
+func isArbiterEnabled(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	return cr.Spec.EnableArbiter != nil && *cr.Spec.EnableArbiter
+}
+
+// getArbiterName is a method to get the arbiter statefulset and service name of mongodb cluster
+func getArbiterName(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	return fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-arbiter")
+}
+
+// CreateMongoClusterArbiter is a method to create or delete the arbiter statefulset and service of mongodb cluster
+func CreateMongoClusterArbiter(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "StatefulSet")
+	if !isArbiterEnabled(cr) {
+		return deleteMongoClusterArbiter(cr)
+	}
+	if *cr.Spec.MongoDBClusterSize < 2 {
+		err := fmt.Errorf("enableMongoArbiter requires a clusterSize of at least 2")
+		logger.Error(err, "Cannot create arbiter for MongoDB cluster")
+		return err
+	}
+	err := CreateOrUpdateService(getArbiterServiceParams(cr))
+	if err != nil {
+		logger.Error(err, "Cannot create arbiter Service for MongoDB cluster")
+		return err
+	}
+	err = CreateOrUpdateStateFul(getMongoDBArbiterParams(cr))
+	if err != nil && !IsUpdateDeferred(err) {
+		logger.Error(err, "Cannot create arbiter StatefulSet for MongoDB cluster")
+		return err
+	}
+	return err
+}
+
+// deleteMongoClusterArbiter is a method to delete the arbiter statefulset and service once the arbiter is disabled, only the ones created for the cluster are deleted
+func deleteMongoClusterArbiter(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "StatefulSet")
+	stateful, err := GetStateFulSet(cr.Namespace, getArbiterName(cr))
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && isOwnedBy(stateful, mongoClusterAsOwner(cr)) {
+		if err := deleteStateFulSet(cr.Namespace, getArbiterName(cr)); err != nil {
+			logger.Error(err, "Cannot delete arbiter StatefulSet for MongoDB cluster")
+			return err
+		}
+	}
+	service, err := getService(cr.Namespace, getArbiterName(cr))
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil && isOwnedBy(service, mongoClusterAsOwner(cr)) {
+		return deleteService(cr.Namespace, getArbiterName(cr))
+	}
+	return nil
+}
+
+// getArbiterServiceParams is a method to generate the headless service params for the arbiter of mongodb cluster
+func getArbiterServiceParams(cr *opstreelabsinv1alpha1.MongoDBCluster) serviceParameters {
+	params := getMongoDBArbiterParams(cr)
+	return serviceParameters{
+		ServiceMeta:              params.StatefulSetMeta,
+		OwnerDef:                 mongoClusterAsOwner(cr),
+		Namespace:                cr.Namespace,
+		Labels:                   params.Labels,
+		Annotations:              generateAnnotations(),
+		HeadlessService:          true,
+		PublishNotReadyAddresses: getPublishNotReadyAddresses(cr.Spec.KubernetesConfig),
+		Port:                     getMongoDBPort(cr.Spec.MongoDBConfig),
+		PortName:                 "mongo",
+	}
+}
+
+// getMongoDBArbiterParams is a method to generate the statefulset params for the arbiter of mongodb cluster
+func getMongoDBArbiterParams(cr *opstreelabsinv1alpha1.MongoDBCluster) statefulSetParameters {
+	falseProperty := false
+	replicas := int32(1)
+	name := getArbiterName(cr)
+	labels := map[string]string{
+		"app":           name,
+		"mongodb_setup": "cluster",
+		"role":          "arbiter",
+	}
+	params := getMongoDBClusterParams(cr)
+	params.StatefulSetMeta = generateObjectMetaInformation(name, cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations))
+	params.Labels = labels
+	params.Replicas = &replicas
+	// the arbiter doesn't hold data, so it runs without persistence, restore and monitoring
+	params.PVCParameters = pvcParameters{}
+	params.AuditPVCParameters = nil
+	params.RestoreParams = nil
+	params.VolumePermissionsImage = ""
+	// the arbiter is never primary, so it keeps the default rolling update of the statefulset
+	params.UpdateStrategy = ""
+	params.AutomountServiceAccountToken = getAutomountServiceAccountToken(cr.Spec.KubernetesConfig, nil)
+	params.ContainerParams.PersistenceEnabled = &falseProperty
+	params.ContainerParams.MongoDBMonitoring = nil
+	params.ContainerParams.OplogSizeMB = 0
+	params.ContainerParams.OplogMinRetentionHours = 0
+	params.ContainerParams.CacheAutoTuning = false
+	params.ContainerParams.ReadinessProbeMode = ""
+	// the arbiter holds no data to warm up or to initialize
+	params.ContainerParams.WarmUp = nil
+	params.ContainerParams.AuditLog = nil
+	params.ContainerParams.PostStart = nil
+	// the arbiter is never primary and serves no clients, so it doesn't need to drain or step down on shutdown
+	params.ContainerParams.StepDownOnShutdown = false
+	params.ContainerParams.PreStopDelaySeconds = 0
+	params.ContainerParams.FsyncOnShutdown = false
+	params.TerminationGracePeriodSeconds = getTerminationGracePeriod(cr.Spec.KubernetesConfig.TerminationGracePeriodSeconds, 0, false, nil)
+	if cr.Spec.ArbiterResources != nil {
+		params.ContainerParams.Resources = cr.Spec.ArbiterResources
+	}
+	return params
+}
+
+// CheckMongoClusterArbiterReady is a method to check if the arbiter of mongodb cluster is ready, it is always ready when disabled
+func CheckMongoClusterArbiterReady(cr *opstreelabsinv1alpha1.MongoDBCluster) (bool, error) {
+	if !isArbiterEnabled(cr) {
+		return true, nil
+	}
+	stateful, err := GetStateFulSet(cr.Namespace, getArbiterName(cr))
+	if err != nil {
+		return false, err
+	}
+	return stateful.Status.ReadyReplicas == 1, nil
+}
+
+// getArbiterMember is a method to generate the replica set member configuration for the arbiter of mongodb cluster
+func getArbiterMember(cr *opstreelabsinv1alpha1.MongoDBCluster) mongogo.MongoDBMember {
+	name := getArbiterName(cr)
+	return mongogo.MongoDBMember{
+		ID:          arbiterMemberID,
+		Host:        mongogo.GetPodHost(fmt.Sprintf("%s-0", name), name, cr.Namespace, cr.Spec.ClusterDomain, getMongoDBPort(cr.Spec.MongoDBConfig)),
+		Priority:    0,
+		Votes:       1,
+		ArbiterOnly: true,
+	}
+}
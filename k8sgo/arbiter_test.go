@@ -0,0 +1,66 @@
+package k8sgo
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/mongo"
+)
+
+func TestGetArbiterMember(t *testing.T) {
+	tests := []struct {
+		name          string
+		port          *int32
+		clusterDomain string
+		wantHost      string
+	}{
+		{name: "default port", wantHost: "mongodb-cluster-arbiter-0.mongodb-cluster-arbiter.database.svc.cluster.local:27017"},
+		{name: "custom port and cluster domain", port: int32Ptr(27018), clusterDomain: "example.org", wantHost: "mongodb-cluster-arbiter-0.mongodb-cluster-arbiter.database.svc.example.org:27018"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := newTestMongoDBCluster(2)
+			cr.Spec.ClusterDomain = test.clusterDomain
+			if test.port != nil {
+				cr.Spec.MongoDBConfig = &opstreelabsinv1alpha1.MongoDBConfig{Port: test.port}
+			}
+			want := mongogo.MongoDBMember{ID: arbiterMemberID, Host: test.wantHost, Votes: 1, ArbiterOnly: true}
+			if member := getArbiterMember(cr); !reflect.DeepEqual(member, want) {
+				t.Errorf("getArbiterMember() = %v, want %v", member, want)
+			}
+		})
+	}
+}
+
+func TestGetMongoDBArbiterParams(t *testing.T) {
+	arbiterResources := &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")}}
+	clusterResources := &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")}}
+	tests := []struct {
+		name             string
+		arbiterResources *corev1.ResourceRequirements
+		want             *corev1.ResourceRequirements
+	}{
+		{name: "cluster resources", want: clusterResources},
+		{name: "arbiter resources", arbiterResources: arbiterResources, want: arbiterResources},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := newTestMongoDBCluster(2)
+			cr.Spec.KubernetesConfig.Resources = clusterResources
+			cr.Spec.ArbiterResources = test.arbiterResources
+			params := getMongoDBArbiterParams(cr)
+			if params.StatefulSetMeta.Name != "mongodb-cluster-arbiter" || *params.Replicas != 1 || params.Labels["role"] != "arbiter" {
+				t.Errorf("getMongoDBArbiterParams() name = %s, replicas = %d, role = %s, want a single arbiter", params.StatefulSetMeta.Name, *params.Replicas, params.Labels["role"])
+			}
+			if *params.ContainerParams.PersistenceEnabled || params.ContainerParams.MongoDBMonitoring != nil {
+				t.Errorf("getMongoDBArbiterParams() enables persistence or monitoring for the arbiter")
+			}
+			if !reflect.DeepEqual(params.ContainerParams.Resources, test.want) {
+				t.Errorf("getMongoDBArbiterParams() resources = %v, want %v", params.ContainerParams.Resources, test.want)
+			}
+		})
+	}
+}
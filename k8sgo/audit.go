@@ -0,0 +1,131 @@
+package k8sgo
+
+import (
+	"encoding/json"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"path"
+	"strings"
+)
+
+const (
+	// auditVolumeName is the claim template of the audit log volume, its claims are named audit-<pod>
+	auditVolumeName = "audit"
+	// defaultAuditMountPath is the directory the audit log volume is mounted on, it is apart from the log directory backed by the scratch volume
+	defaultAuditMountPath = "/data/audit"
+	// defaultAuditStorageSize is the size of the audit log volume when none is set
+	defaultAuditStorageSize = "1Gi"
+	defaultAuditFormat      = "JSON"
+)
+
+// isAuditLogEnabled is a method to check if mongod writes its audit log to the audit volume
+func isAuditLogEnabled(config *opstreelabsinv1alpha1.MongoDBConfig) bool {
+	return config != nil && config.AuditLog != nil && config.AuditLog.Enabled
+}
+
+// getAuditLog is a method to get the audit log configuration of mongod, it is nil when auditing is disabled
+func getAuditLog(config *opstreelabsinv1alpha1.MongoDBConfig) *opstreelabsinv1alpha1.MongoDBAuditLog {
+	if !isAuditLogEnabled(config) {
+		return nil
+	}
+	return config.AuditLog
+}
+
+// getAuditMountPath is a method to get the directory the audit log volume is mounted on, it defaults to /data/audit
+func getAuditMountPath(auditLog *opstreelabsinv1alpha1.MongoDBAuditLog) string {
+	if auditLog.MountPath == "" {
+		return defaultAuditMountPath
+	}
+	return path.Clean(auditLog.MountPath)
+}
+
+// getAuditFormat is a method to get the format of the audit log, it defaults to JSON
+func getAuditFormat(auditLog *opstreelabsinv1alpha1.MongoDBAuditLog) string {
+	if auditLog.Format == "" {
+		return defaultAuditFormat
+	}
+	return auditLog.Format
+}
+
+// getAuditArgs is a method to generate the mongod flags writing the audit log to a file on the audit volume, the flags passed in extraArgs take precedence
+func getAuditArgs(auditLog *opstreelabsinv1alpha1.MongoDBAuditLog, extraArgs []string) []string {
+	if auditLog == nil {
+		return nil
+	}
+	format := getAuditFormat(auditLog)
+	flags := [][2]string{
+		{"--auditDestination", "file"},
+		{"--auditFormat", format},
+		{"--auditPath", path.Join(getAuditMountPath(auditLog), fmt.Sprintf("auditLog.%s", strings.ToLower(format)))},
+	}
+	if auditLog.Filter != "" {
+		flags = append(flags, [2]string{"--auditFilter", auditLog.Filter})
+	}
+	var args []string
+	for _, flag := range flags {
+		if !hasMongoDBArg(extraArgs, flag[0]) {
+			args = append(args, fmt.Sprintf("%s=%s", flag[0], flag[1]))
+		}
+	}
+	return args
+}
+
+// getAuditVolumeMount is a method to mount the audit log volume in the MongoDB container
+func getAuditVolumeMount(auditLog *opstreelabsinv1alpha1.MongoDBAuditLog) corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      auditVolumeName,
+		MountPath: getAuditMountPath(auditLog),
+	}
+}
+
+// getAuditPVCParameters is a method to generate the claim template parameters of the audit log volume, it is nil when auditing is disabled
+func getAuditPVCParameters(config *opstreelabsinv1alpha1.MongoDBConfig, namespace string, labels map[string]string, annotations map[string]string) *pvcParameters {
+	auditLog := getAuditLog(config)
+	if auditLog == nil {
+		return nil
+	}
+	storageSize := auditLog.StorageSize
+	if storageSize == "" {
+		storageSize = defaultAuditStorageSize
+	}
+	return &pvcParameters{
+		Name:             auditVolumeName,
+		Namespace:        namespace,
+		Labels:           labels,
+		Annotations:      annotations,
+		StorageSize:      storageSize,
+		StorageClassName: auditLog.StorageClassName,
+	}
+}
+
+// validateAuditLog is a method to validate that the image supports auditing and the size, directory and filter of the audit log
+func validateAuditLog(config *opstreelabsinv1alpha1.MongoDBConfig, image string) error {
+	auditLog := getAuditLog(config)
+	if auditLog == nil {
+		return nil
+	}
+	// the community build of mongod refuses to start with the audit flags
+	if !isEnterpriseImage(image) {
+		return fmt.Errorf("auditLog requires a MongoDB Enterprise or Percona Server for MongoDB image, %s is not one of them", image)
+	}
+	if auditLog.StorageSize != "" {
+		if _, err := resource.ParseQuantity(auditLog.StorageSize); err != nil {
+			return fmt.Errorf("auditLog storageSize %s is not a valid quantity: %v", auditLog.StorageSize, err)
+		}
+	}
+	if auditLog.Filter != "" && !json.Valid([]byte(auditLog.Filter)) {
+		return fmt.Errorf("auditLog filter must be a JSON document")
+	}
+	mountPath := getAuditMountPath(auditLog)
+	if !path.IsAbs(mountPath) || mountPath == "/" {
+		return fmt.Errorf("auditLog mountPath %s must be an absolute path other than /", mountPath)
+	}
+	for _, reserved := range []string{getMongoDBConfigDBPath(config), "/tmp", logDirectory, "/etc/mongo.d/extra", restoreMountPath} {
+		if mountPath == reserved || strings.HasPrefix(reserved, mountPath+"/") || strings.HasPrefix(mountPath, reserved+"/") {
+			return fmt.Errorf("auditLog mountPath %s overlaps with %s", mountPath, reserved)
+		}
+	}
+	return nil
+}
@@ -1,22 +1,28 @@
 package k8sgo
 
 import (
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
 // generateK8sClient create client for kubernetes
-func generateK8sClient() *kubernetes.Clientset {
+func generateK8sClient() (*kubernetes.Clientset, error) {
 	config, err := generateK8sConfig()
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
-	clientset, err := kubernetes.NewForConfig(config)
+	return kubernetes.NewForConfig(config)
+}
+
+// generateK8sDynamicClient create dynamic client for the kubernetes resources without a typed client
+func generateK8sDynamicClient() (dynamic.Interface, error) {
+	config, err := generateK8sConfig()
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
-	return clientset
+	return dynamic.NewForConfig(config)
 }
 
 // generateK8sConfig will load the kube config file
@@ -0,0 +1,52 @@
+package k8sgo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateK8sClient(t *testing.T) {
+	tests := []struct {
+		name       string
+		kubeConfig string
+		wantErr    bool
+	}{
+		{
+			name: "valid kube config",
+			kubeConfig: `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: test
+`,
+		},
+		{name: "invalid kube config", kubeConfig: "clusters: [", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(path, []byte(test.kubeConfig), 0600); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("KUBECONFIG", path)
+			if _, err := generateK8sClient(); (err != nil) != test.wantErr {
+				t.Errorf("generateK8sClient() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if _, err := generateK8sDynamicClient(); (err != nil) != test.wantErr {
+				t.Errorf("generateK8sDynamicClient() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
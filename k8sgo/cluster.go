@@ -3,7 +3,12 @@ package k8sgo
 import (
 	"fmt"
 	"github.com/thanhpk/randstr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"strconv"
+	"strings"
 )
 
 // CreateMongoClusterService is a method to create service for mongodb cluster
@@ -15,24 +20,94 @@ func CreateMongoClusterService(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
 		"mongodb_setup": "cluster",
 		"role":          "cluster",
 	}
-	params := serviceParameters{
-		ServiceMeta:     generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations()),
-		OwnerDef:        mongoClusterAsOwner(cr),
-		Namespace:       cr.Namespace,
-		Labels:          labels,
-		Annotations:     generateAnnotations(),
-		HeadlessService: true,
-		Port:            mongoDBPort,
-		PortName:        "mongo",
-	}
-	err := CreateOrUpdateService(params)
+	err := CreateOrUpdateService(applyHeadlessServiceConfig(getMongoDBClusterServiceParams(cr), cr.Spec.KubernetesConfig.HeadlessService))
 	if err != nil {
 		logger.Error(err, "Cannot create cluster Service for MongoDB")
 		return err
 	}
+	err = CreateOrUpdateExternalNameService(getMongoDBClusterServiceParams(cr), cr.Spec.KubernetesConfig.ExternalNameService, cr.Spec.ClusterDomain)
+	if err != nil {
+		logger.Error(err, "Cannot create cluster ExternalName Service for MongoDB")
+		return err
+	}
+	clientParams := serviceParameters{
+		ServiceMeta: generateObjectMetaInformation(fmt.Sprintf("%s-%s", appName, "client"), cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations)),
+		OwnerDef:    mongoClusterAsOwner(cr),
+		Namespace:   cr.Namespace,
+		Labels:      labels,
+		Annotations: generateAnnotations(),
+		Port:        getMongoDBPort(cr.Spec.MongoDBConfig),
+		PortName:    "mongo",
+		ExtraPorts:  cr.Spec.KubernetesConfig.ExtraServicePorts,
+	}
+	if isClientServiceSelectingPrimary(cr) {
+		clientParams.Labels = mergeLabels(map[string]string{podNameLabel: cr.Status.CurrentPrimary}, labels)
+	}
+	err = CreateOrDeleteClientService(clientParams, cr.Spec.KubernetesConfig.Service)
+	if err != nil {
+		logger.Error(err, "Cannot create cluster client Service for MongoDB")
+		return err
+	}
+	analyticsServiceName := fmt.Sprintf("%s-%s", appName, "analytics")
+	if cr.Spec.AnalyticsNode != nil && cr.Spec.AnalyticsNode.Enabled {
+		err = CreateOrUpdateService(getAnalyticsServiceParams(cr, appName, analyticsServiceName))
+	} else {
+		err = deleteService(cr.Namespace, analyticsServiceName)
+	}
+	if err != nil {
+		logger.Error(err, "Cannot create cluster analytics Service for MongoDB")
+		return err
+	}
 	return nil
 }
 
+// isClientServiceSelectingPrimary is a method to check if the load balancer client service only selects the current primary, external clients connect directly to a single member as the members advertise internal hostnames
+func isClientServiceSelectingPrimary(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	serviceConfig := cr.Spec.KubernetesConfig.Service
+	return serviceConfig != nil && serviceConfig.ServiceType == corev1.ServiceTypeLoadBalancer && cr.Status.CurrentPrimary != ""
+}
+
+// getMongoDBClusterServiceParams is a method to generate the headless service params for mongodb cluster
+func getMongoDBClusterServiceParams(cr *opstreelabsinv1alpha1.MongoDBCluster) serviceParameters {
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	labels := map[string]string{
+		"app":           appName,
+		"mongodb_setup": "cluster",
+		"role":          "cluster",
+	}
+	return serviceParameters{
+		ServiceMeta:              generateObjectMetaInformation(appName, cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations)),
+		OwnerDef:                 mongoClusterAsOwner(cr),
+		Namespace:                cr.Namespace,
+		Labels:                   labels,
+		Annotations:              generateAnnotations(),
+		HeadlessService:          true,
+		PublishNotReadyAddresses: getPublishNotReadyAddresses(cr.Spec.KubernetesConfig),
+		Port:                     getMongoDBPort(cr.Spec.MongoDBConfig),
+		PortName:                 "mongo",
+		ExtraPorts:               cr.Spec.KubernetesConfig.ExtraServicePorts,
+	}
+}
+
+// getAnalyticsServiceParams is a method to generate service params for the analytics node of mongodb cluster
+func getAnalyticsServiceParams(cr *opstreelabsinv1alpha1.MongoDBCluster, appName string, serviceName string) serviceParameters {
+	labels := map[string]string{
+		"app":           appName,
+		"mongodb_setup": "cluster",
+		"role":          "cluster",
+		podNameLabel:    fmt.Sprintf("%s-%d", appName, *cr.Spec.MongoDBClusterSize-1),
+	}
+	return serviceParameters{
+		ServiceMeta: generateObjectMetaInformation(serviceName, cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations)),
+		OwnerDef:    mongoClusterAsOwner(cr),
+		Namespace:   cr.Namespace,
+		Labels:      labels,
+		Annotations: generateAnnotations(),
+		Port:        getMongoDBPort(cr.Spec.MongoDBConfig),
+		PortName:    "mongo",
+	}
+}
+
 // CreateMongoClusterMonitoringService is a method to create a monitoring service for mongodb cluster
 func CreateMongoClusterMonitoringService(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Service")
@@ -43,7 +118,7 @@ func CreateMongoClusterMonitoringService(cr *opstreelabsinv1alpha1.MongoDBCluste
 		"role":          "cluster",
 	}
 	monitoringParams := serviceParameters{
-		ServiceMeta:     generateObjectMetaInformation(fmt.Sprintf("%s-%s", appName, "metrics"), cr.Namespace, labels, generateAnnotations()),
+		ServiceMeta:     generateObjectMetaInformation(fmt.Sprintf("%s-%s", appName, "metrics"), cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations)),
 		OwnerDef:        mongoClusterAsOwner(cr),
 		Namespace:       cr.Namespace,
 		Labels:          labels,
@@ -60,24 +135,335 @@ func CreateMongoClusterMonitoringService(cr *opstreelabsinv1alpha1.MongoDBCluste
 	return nil
 }
 
+// CreateMongoClusterPerPodServices is a method to create a service for each member of mongodb cluster
+func CreateMongoClusterPerPodServices(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Service")
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	replicas := 0
+	if cr.Spec.PerPodService != nil && cr.Spec.PerPodService.Enabled {
+		replicas = int(*cr.Spec.MongoDBClusterSize)
+	}
+	for node := 0; node < replicas; node++ {
+		err := CreateOrUpdateService(getPerPodServiceParams(cr, appName, node))
+		if err != nil {
+			logger.Error(err, "Cannot create per pod Service for MongoDB cluster")
+			return err
+		}
+	}
+	serviceList, err := listServices(cr.Namespace, fmt.Sprintf("app=%s,%s", appName, podNameLabel))
+	if err != nil {
+		return err
+	}
+	for _, service := range serviceList.Items {
+		node, err := strconv.Atoi(strings.TrimPrefix(service.Labels[podNameLabel], fmt.Sprintf("%s-", appName)))
+		if err != nil || node < replicas {
+			continue
+		}
+		err = deleteService(cr.Namespace, service.Name)
+		if err != nil {
+			logger.Error(err, "Cannot delete per pod Service for MongoDB cluster")
+			return err
+		}
+	}
+	return nil
+}
+
+// getPerPodServiceParams is a method to generate service params for a member of mongodb cluster
+func getPerPodServiceParams(cr *opstreelabsinv1alpha1.MongoDBCluster, appName string, node int) serviceParameters {
+	podName := fmt.Sprintf("%s-%d", appName, node)
+	labels := map[string]string{
+		"app":           appName,
+		"mongodb_setup": "cluster",
+		"role":          "cluster",
+		podNameLabel:    podName,
+	}
+	annotations := generateAnnotations()
+	for key, value := range cr.Spec.PerPodService.ServiceAnnotations {
+		annotations[key] = value
+	}
+	serviceType := cr.Spec.PerPodService.ServiceType
+	if serviceType == "" {
+		serviceType = corev1.ServiceTypeNodePort
+	}
+	return serviceParameters{
+		ServiceMeta:           generateObjectMetaInformation(fmt.Sprintf("%s-%s", podName, "external"), cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(annotations, cr.Annotations)),
+		OwnerDef:              mongoClusterAsOwner(cr),
+		Namespace:             cr.Namespace,
+		Labels:                labels,
+		Annotations:           annotations,
+		Port:                  getMongoDBPort(cr.Spec.MongoDBConfig),
+		PortName:              "mongo",
+		ServiceType:           serviceType,
+		ExternalTrafficPolicy: cr.Spec.PerPodService.ExternalTrafficPolicy,
+		ServicePort:           cr.Spec.PerPodService.Port,
+	}
+}
+
 // CreateMongoClusterSetup is a method to create cluster statefulset for MongoDB
 func CreateMongoClusterSetup(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "StatefulSet")
+	if cr.Spec.AnalyticsNode != nil && cr.Spec.AnalyticsNode.Enabled && *cr.Spec.MongoDBClusterSize < 3 {
+		err := fmt.Errorf("analyticsNode requires a clusterSize of at least 3")
+		logger.Error(err, "Cannot create analytics node for MongoDB cluster")
+		return err
+	}
+	if err := validateMemberConfig(cr); err != nil {
+		logger.Error(err, "Invalid memberConfig for cluster MongoDB")
+		return err
+	}
+	if err := validateSeccompProfile(cr.Spec.KubernetesConfig); err != nil {
+		logger.Error(err, "Invalid seccomp profile for cluster MongoDB")
+		return err
+	}
+	if err := validateSysctls(cr.Spec.KubernetesConfig); err != nil {
+		logger.Error(err, "Invalid sysctls for cluster MongoDB")
+		return err
+	}
+	if err := validateServiceAccountImagePullSecrets(cr.Spec.KubernetesConfig); err != nil {
+		logger.Error(err, "Invalid ServiceAccount for cluster MongoDB")
+		return err
+	}
+	if err := validateClientService(cr.Spec.KubernetesConfig); err != nil {
+		logger.Error(err, "Invalid client service for cluster MongoDB")
+		return err
+	}
+	if err := validateExtraVolumeMounts(cr.Spec.KubernetesConfig.ExtraVolumes, cr.Spec.KubernetesConfig.ExtraVolumeMounts); err != nil {
+		logger.Error(err, "Invalid extra volumes for cluster MongoDB")
+		return err
+	}
+	if err := validateMongoDBUsers(cr.Spec.MongoDBSecurity); err != nil {
+		logger.Error(err, "Invalid users for cluster MongoDB")
+		return err
+	}
+	if err := validateImage(cr.Spec.KubernetesConfig.Image); err != nil {
+		logger.Error(err, "Invalid image for cluster MongoDB")
+		return err
+	}
+	if err := validateMonitoringResources(cr.Spec.MongoDBMonitoring); err != nil {
+		logger.Error(err, "Invalid exporter resources for cluster MongoDB")
+		return err
+	}
+	if err := validateExtraPorts(cr.Spec.KubernetesConfig, getMongoDBPort(cr.Spec.MongoDBConfig), cr.Spec.MongoDBMonitoring != nil); err != nil {
+		logger.Error(err, "Invalid extra ports for cluster MongoDB")
+		return err
+	}
+	if err := validateHostNetwork(cr.Spec.KubernetesConfig, getMongoDBPort(cr.Spec.MongoDBConfig), cr.Spec.MongoDBMonitoring != nil); err != nil {
+		logger.Error(err, "Invalid host network configuration for cluster MongoDB")
+		return err
+	}
+	if err := validateReplicaSetNameChange(cr); err != nil {
+		logger.Error(err, "Invalid replicaSetName for cluster MongoDB")
+		return err
+	}
+	if _, err := getOplogSize(cr.Spec.MongoDBConfig, cr.Spec.Storage); err != nil {
+		logger.Error(err, "Invalid oplog size for cluster MongoDB")
+		return err
+	}
+	if err := validateProjectedVolume(cr.Spec.KubernetesConfig.ProjectedVolume, getMongoDBConfigDBPath(cr.Spec.MongoDBConfig)); err != nil {
+		logger.Error(err, "Invalid projected volume for cluster MongoDB")
+		return err
+	}
+	if err := validateProbes(cr.Spec.KubernetesConfig.Probes); err != nil {
+		logger.Error(err, "Invalid probes for cluster MongoDB")
+		return err
+	}
+	if err := validateReadinessScript(cr.Spec.KubernetesConfig.Probes, getMongoDBConfigDBPath(cr.Spec.MongoDBConfig)); err != nil {
+		logger.Error(err, "Invalid readiness script for cluster MongoDB")
+		return err
+	}
+	if err := validateMaintenanceWindow(cr.Spec.MaintenanceWindow); err != nil {
+		logger.Error(err, "Invalid maintenance window for cluster MongoDB")
+		return err
+	}
+	if err := validateVolumeSnapshot(cr); err != nil {
+		logger.Error(err, "Invalid volumeSnapshot for cluster MongoDB")
+		return err
+	}
+	if err := validateAccessModes(cr.Spec.Storage); err != nil {
+		logger.Error(err, "Invalid storage for cluster MongoDB")
+		return err
+	}
+	if err := validateVolumeMode(cr.Spec.Storage); err != nil {
+		logger.Error(err, "Invalid storage for cluster MongoDB")
+		return err
+	}
+	if err := validateStorageEngine(cr.Spec.MongoDBConfig, cr.Spec.MongoDBRestore, cr.Spec.KubernetesConfig.Image); err != nil {
+		logger.Error(err, "Invalid storageEngine for cluster MongoDB")
+		return err
+	}
+	if err := validateOplogMinRetention(cr.Spec.MongoDBConfig); err != nil {
+		logger.Error(err, "Invalid oplog retention for cluster MongoDB")
+		return err
+	}
+	if err := validateContainerName(cr.Spec.KubernetesConfig.ContainerName); err != nil {
+		logger.Error(err, "Invalid containerName for cluster MongoDB")
+		return err
+	}
+	if err := validateExternalNameService(cr.Spec.KubernetesConfig.ExternalNameService, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster"), cr.Namespace, cr.Spec.ClusterDomain); err != nil {
+		logger.Error(err, "Invalid externalNameService for cluster MongoDB")
+		return err
+	}
+	if err := validateTerminationGracePeriod(cr.Spec.KubernetesConfig.TerminationGracePeriodSeconds, *getInt32OrDefault(cr.Spec.KubernetesConfig.PreStopDelaySeconds, defaultPreStopDelaySeconds)); err != nil {
+		logger.Error(err, "Invalid termination grace period for cluster MongoDB")
+		return err
+	}
+	if cr.Spec.MongoDBConfig != nil {
+		if err := validateExtraArgs(cr.Spec.MongoDBConfig.ExtraArgs); err != nil {
+			logger.Error(err, "Invalid mongoDBConfig for cluster MongoDB")
+			return err
+		}
+		if err := validateMongoDBLogging(cr.Spec.MongoDBConfig.Logging); err != nil {
+			logger.Error(err, "Invalid logging for cluster MongoDB")
+			return err
+		}
+		if err := validateDBPath(cr.Spec.MongoDBConfig.DBPath); err != nil {
+			logger.Error(err, "Invalid dbPath for cluster MongoDB")
+			return err
+		}
+		if err := validateWarmUp(cr.Spec.MongoDBConfig.WarmUp); err != nil {
+			logger.Error(err, "Invalid warmUp for cluster MongoDB")
+			return err
+		}
+		if err := validateAuditLog(cr.Spec.MongoDBConfig, cr.Spec.KubernetesConfig.Image); err != nil {
+			logger.Error(err, "Invalid auditLog for cluster MongoDB")
+			return err
+		}
+		if err := validateBindIP(cr.Spec.MongoDBConfig); err != nil {
+			logger.Error(err, "Invalid bindIp for cluster MongoDB")
+			return err
+		}
+	}
+	if cr.Spec.MongoDBRestore != nil && cr.Spec.Storage == nil {
+		err := fmt.Errorf("mongoDBRestore requires storage to be configured")
+		logger.Error(err, "Cannot restore cluster MongoDB without persistence")
+		return err
+	}
+	if err := validateRestoreCABundle(cr.Spec.MongoDBRestore); err != nil {
+		logger.Error(err, "Invalid mongoDBRestore for cluster MongoDB")
+		return err
+	}
+	// a deferred update still lets the rest of the setup be reconciled
 	err := CreateOrUpdateStateFul(getMongoDBClusterParams(cr))
-	if err != nil {
+	if err != nil && !IsUpdateDeferred(err) {
 		logger.Error(err, "Cannot create cluster StatefulSet for MongoDB")
 		return err
 	}
 	if cr.Spec.PodDisruptionBudget != nil && cr.Spec.PodDisruptionBudget.Enabled {
-		err = CreateOrUpdatePodDisruption(getPodDisruptionParams(cr))
-		if err != nil {
+		if err := CreateOrUpdatePodDisruption(getPodDisruptionParams(cr)); err != nil {
 			logger.Error(err, "Cannot create PodDisruptionBudget for MongoDB")
 			return err
 		}
 	}
+	return err
+}
+
+// CleanupMongoClusterResources is a method to release the external resources of mongodb cluster before deletion
+func CleanupMongoClusterResources(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Service")
+	// cloud load balancers are released with their services, so they don't leak if the namespace deletion races the garbage collector
+	err := deleteLoadBalancerServices(cr.Namespace, fmt.Sprintf("app=%s-%s", cr.ObjectMeta.Name, "cluster"))
+	if err != nil {
+		logger.Error(err, "Cannot delete LoadBalancer Services for MongoDB cluster")
+		return err
+	}
+	err = deleteExternalNameServices(mongoClusterAsOwner(cr), nil, cr.Namespace)
+	if err != nil {
+		logger.Error(err, "Cannot delete ExternalName Service for MongoDB cluster")
+		return err
+	}
+	// priorityclasses are cluster scoped, so they are not garbage collected with mongodb cluster
+	err = deleteMongoDBPriorityClass(cr.Spec.KubernetesConfig, cr.Namespace, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster"), "cluster")
+	if err != nil {
+		logger.Error(err, "Cannot delete PriorityClass for MongoDB cluster")
+		return err
+	}
+	return nil
+}
+
+// CreateMongoClusterRBAC is a method to create or delete the ServiceAccount, Role and RoleBinding for mongodb cluster pods
+func CreateMongoClusterRBAC(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "ServiceAccount")
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	var err error
+	if cr.Spec.KubernetesConfig.CreateServiceAccount && cr.Spec.KubernetesConfig.ServiceAccountName == "" {
+		labels := map[string]string{
+			"app":           appName,
+			"mongodb_setup": "cluster",
+			"role":          "cluster",
+		}
+		secretNames := []string{fmt.Sprintf("%s-%s", appName, "monitoring")}
+		if cr.Spec.MongoDBSecurity != nil && cr.Spec.MongoDBSecurity.SecretRef.Name != nil {
+			secretNames = append(secretNames, *cr.Spec.MongoDBSecurity.SecretRef.Name)
+		}
+		var configMapNames []string
+		if cr.Spec.MongoDBAdditionalConfig != nil {
+			configMapNames = append(configMapNames, *cr.Spec.MongoDBAdditionalConfig)
+		}
+		err = CreateOrUpdateRBAC(rbacParameters{
+			RBACMeta:         generateObjectMetaInformation(appName, cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations)),
+			OwnerDef:         mongoClusterAsOwner(cr),
+			Namespace:        cr.Namespace,
+			Rules:            getRBACRules(secretNames, configMapNames),
+			ImagePullSecrets: cr.Spec.KubernetesConfig.ServiceAccountImagePullSecrets,
+		})
+	} else {
+		err = deleteRBAC(cr.Namespace, appName, mongoClusterAsOwner(cr))
+	}
+	if err != nil {
+		logger.Error(err, "Cannot create ServiceAccount for MongoDB cluster")
+		return err
+	}
 	return nil
 }
 
+// getMongoDBClusterReplicas is a method to get the replicas of the mongodb cluster statefulset, the pods of members which are still part of the replica set are not scaled down
+func getMongoDBClusterReplicas(cr *opstreelabsinv1alpha1.MongoDBCluster) *int32 {
+	if cr.Status.DataMembers > *cr.Spec.MongoDBClusterSize {
+		replicas := cr.Status.DataMembers
+		return &replicas
+	}
+	return cr.Spec.MongoDBClusterSize
+}
+
+// GetMongoDBReplicaSetName is a method to get the replica set name of mongodb cluster, it defaults to the name of the resource
+func GetMongoDBReplicaSetName(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	if cr.Spec.ReplicaSetName != "" {
+		return cr.Spec.ReplicaSetName
+	}
+	return cr.ObjectMeta.Name
+}
+
+// validateReplicaSetNameChange is a method to validate that the replica set name is not changed once the members were started with it, also before the status records it
+func validateReplicaSetNameChange(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	replicaSetName := GetMongoDBReplicaSetName(cr)
+	if cr.Status.ReplicaSetName != "" && cr.Status.ReplicaSetName != replicaSetName {
+		return fmt.Errorf("replicaSetName cannot be changed from %s to %s after the cluster is initialized", cr.Status.ReplicaSetName, replicaSetName)
+	}
+	stateful, err := GetStateFulSet(cr.Namespace, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster"))
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if storedName := getStatefulSetReplicaSetName(stateful); storedName != "" && storedName != replicaSetName {
+		return fmt.Errorf("replicaSetName cannot be changed from %s to %s, the members of StatefulSet %s were started with it", storedName, replicaSetName, stateful.Name)
+	}
+	return nil
+}
+
+// getStatefulSetReplicaSetName is a method to get the replica set name the members of the StatefulSet are started with
+func getStatefulSetReplicaSetName(stateful *appsv1.StatefulSet) string {
+	for _, container := range stateful.Spec.Template.Spec.Containers {
+		for _, envVar := range container.Env {
+			if envVar.Name == "MONGO_REPL" {
+				return envVar.Value
+			}
+		}
+	}
+	return ""
+}
+
 // CreateMongoClusterMonitoringSecret is a method to create secret for monitoring
 func CreateMongoClusterMonitoringSecret(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Secret")
@@ -89,6 +475,27 @@ func CreateMongoClusterMonitoringSecret(cr *opstreelabsinv1alpha1.MongoDBCluster
 	return nil
 }
 
+// isMongoClusterKeyFileRequired is a method to check if the operator provides the keyFile, which the image entrypoint passes unless it is bypassed with a custom command, mongos of sharded cluster always bypasses it and has to share the keyFile with the members
+func isMongoClusterKeyFileRequired(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	if cr.Spec.MongoDBSecurity == nil {
+		return false
+	}
+	return (cr.Spec.Sharding != nil && cr.Spec.Sharding.Enabled) || (cr.Spec.MongoDBConfig != nil && len(cr.Spec.MongoDBConfig.Command) > 0)
+}
+
+// CreateMongoClusterKeyFileSecret is a method to create the keyFile secret of the members when the operator provides the keyFile
+func CreateMongoClusterKeyFileSecret(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	if !isMongoClusterKeyFileRequired(cr) {
+		return nil
+	}
+	labels := map[string]string{
+		"app":           fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster"),
+		"mongodb_setup": "cluster",
+		"role":          "cluster",
+	}
+	return createKeyFileSecret(cr.ObjectMeta.Name, cr.Namespace, labels, mongoClusterAsOwner(cr))
+}
+
 // getMongoDBClusterSecretParams is a method to create secret for MongoDB Monitoring
 func getMongoDBClusterSecretParams(cr *opstreelabsinv1alpha1.MongoDBCluster) secretsParameters {
 	password := randstr.String(16)
@@ -116,34 +523,61 @@ func getMongoDBClusterParams(cr *opstreelabsinv1alpha1.MongoDBCluster) statefulS
 	falseProperty := false
 	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
 	monitoringSecretName := fmt.Sprintf("%s-%s", appName, "monitoring")
+	replicaSetName := GetMongoDBReplicaSetName(cr)
 	labels := map[string]string{
 		"app":           appName,
 		"mongodb_setup": "cluster",
 		"role":          "cluster",
 	}
 	params := statefulSetParameters{
-		StatefulSetMeta: generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations()),
+		StatefulSetMeta: generateObjectMetaInformation(appName, cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations)),
 		OwnerDef:        mongoClusterAsOwner(cr),
 		Namespace:       cr.Namespace,
 		ContainerParams: containerParameters{
-			Image:               cr.Spec.KubernetesConfig.Image,
-			ImagePullPolicy:     cr.Spec.KubernetesConfig.ImagePullPolicy,
-			Resources:           cr.Spec.KubernetesConfig.Resources,
-			MongoReplicaSetName: &cr.ObjectMeta.Name,
-			MongoSetupType:      "cluster",
+			Image:                    cr.Spec.KubernetesConfig.Image,
+			Port:                     getMongoDBPort(cr.Spec.MongoDBConfig),
+			ImagePullPolicy:          cr.Spec.KubernetesConfig.ImagePullPolicy,
+			Resources:                cr.Spec.KubernetesConfig.Resources,
+			EnvVars:                  cr.Spec.KubernetesConfig.EnvVars,
+			EnvFrom:                  cr.Spec.KubernetesConfig.EnvFrom,
+			ExtraVolumeMounts:        cr.Spec.KubernetesConfig.ExtraVolumeMounts,
+			ExtraPorts:               cr.Spec.KubernetesConfig.ExtraPorts,
+			SecurityContext:          cr.Spec.KubernetesConfig.ContainerSecurityContext,
+			ReadinessProbeMode:       cr.Spec.KubernetesConfig.ReadinessProbeMode,
+			TerminationMessagePath:   cr.Spec.KubernetesConfig.TerminationMessagePath,
+			TerminationMessagePolicy: cr.Spec.KubernetesConfig.TerminationMessagePolicy,
+			ContainerName:            cr.Spec.KubernetesConfig.ContainerName,
+			MongoReplicaSetName:      &replicaSetName,
+			MongoSetupType:           "cluster",
+			StepDownOnShutdown:       true,
+			PreStopDelaySeconds:      *getInt32OrDefault(cr.Spec.KubernetesConfig.PreStopDelaySeconds, defaultPreStopDelaySeconds),
+			// the in-memory storage engine has no data files to flush
+			FsyncOnShutdown: cr.Spec.KubernetesConfig.FsyncOnShutdown && !isInMemoryStorageEngine(cr.Spec.MongoDBConfig),
+			PostStart:       cr.Spec.KubernetesConfig.PostStart,
 		},
-		Replicas:          cr.Spec.MongoDBClusterSize,
-		Labels:            labels,
-		Annotations:       generateAnnotations(),
-		NodeSelector:      cr.Spec.KubernetesConfig.NodeSelector,
-		Affinity:          cr.Spec.KubernetesConfig.Affinity,
-		PriorityClassName: cr.Spec.KubernetesConfig.PriorityClassName,
-		Tolerations:       cr.Spec.KubernetesConfig.Tolerations,
-		SecurityContext:   cr.Spec.KubernetesConfig.SecurityContext,
-	}
-
-	if cr.Spec.KubernetesConfig.ImagePullSecret != nil {
-		params.ImagePullSecret = cr.Spec.KubernetesConfig.ImagePullSecret
+		Replicas:                      getMongoDBClusterReplicas(cr),
+		Labels:                        labels,
+		Annotations:                   getPodAnnotations(cr.Spec.KubernetesConfig),
+		NodeSelector:                  cr.Spec.KubernetesConfig.NodeSelector,
+		Affinity:                      cr.Spec.KubernetesConfig.Affinity,
+		PriorityClassName:             getPriorityClassName(cr.Spec.KubernetesConfig, cr.Namespace, appName),
+		SchedulerName:                 cr.Spec.KubernetesConfig.SchedulerName,
+		RuntimeClassName:              cr.Spec.KubernetesConfig.RuntimeClassName,
+		ServiceAccountName:            getServiceAccountName(cr.Spec.KubernetesConfig, appName),
+		Tolerations:                   cr.Spec.KubernetesConfig.Tolerations,
+		SecurityContext:               getPodSecurityContext(cr.Spec.KubernetesConfig),
+		FSGroupChangePolicy:           cr.Spec.KubernetesConfig.FSGroupChangePolicy,
+		ExtraVolumes:                  &cr.Spec.KubernetesConfig.ExtraVolumes,
+		RevisionHistoryLimit:          cr.Spec.KubernetesConfig.RevisionHistoryLimit,
+		MinReadySeconds:               cr.Spec.KubernetesConfig.MinReadySeconds,
+		DNSPolicy:                     cr.Spec.KubernetesConfig.DNSPolicy,
+		DNSConfig:                     cr.Spec.KubernetesConfig.DNSConfig,
+		HostNetwork:                   cr.Spec.KubernetesConfig.HostNetwork,
+		HostAliases:                   cr.Spec.KubernetesConfig.HostAliases,
+		ImagePullSecrets:              getImagePullSecrets(cr.Spec.KubernetesConfig.ImagePullSecret, cr.Spec.KubernetesConfig.ImagePullSecrets),
+		MaintenanceWindow:             cr.Spec.MaintenanceWindow,
+		RecreateOnImmutableChange:     cr.Spec.KubernetesConfig.ImmutableFieldChangePolicy == immutableFieldChangeRecreate,
+		TerminationGracePeriodSeconds: getTerminationGracePeriod(cr.Spec.KubernetesConfig.TerminationGracePeriodSeconds, *getInt32OrDefault(cr.Spec.KubernetesConfig.PreStopDelaySeconds, defaultPreStopDelaySeconds), true, getPersistentStorage(cr.Spec.MongoDBConfig, cr.Spec.Storage)),
 	}
 
 	if cr.Spec.MongoDBSecurity != nil {
@@ -162,20 +596,61 @@ func getMongoDBClusterParams(cr *opstreelabsinv1alpha1.MongoDBCluster) statefulS
 		params.ContainerParams.AdditonalConfig = cr.Spec.MongoDBAdditionalConfig
 		params.AdditionalConfig = cr.Spec.MongoDBAdditionalConfig
 	}
-	if cr.Spec.Storage != nil {
+	if storage := getPersistentStorage(cr.Spec.MongoDBConfig, cr.Spec.Storage); storage != nil {
 		params.ContainerParams.PersistenceEnabled = &trueProperty
 		params.PVCParameters = pvcParameters{
 			Name:             appName,
 			Namespace:        cr.Namespace,
-			Labels:           labels,
-			Annotations:      generateAnnotations(),
-			StorageSize:      cr.Spec.Storage.StorageSize,
-			StorageClassName: cr.Spec.Storage.StorageClassName,
-			AccessModes:      cr.Spec.Storage.AccessModes,
+			Labels:           mergeLabels(labels, cr.Labels),
+			Annotations:      mergeAnnotations(generateAnnotations(), cr.Annotations),
+			StorageSize:      storage.StorageSize,
+			StorageClassName: storage.StorageClassName,
+			AccessModes:      storage.AccessModes,
+			VolumeMode:       storage.VolumeMode,
+		}
+		if storage.VolumePermissions {
+			params.VolumePermissionsImage = getInitImage(cr.Spec.KubernetesConfig.InitImage)
 		}
 	} else {
 		params.ContainerParams.PersistenceEnabled = &falseProperty
 	}
+	params.ContainerParams.CacheAutoTuning = true
+	// the oplog size is validated on setup, so the error can be ignored here
+	params.ContainerParams.OplogSizeMB, _ = getOplogSize(cr.Spec.MongoDBConfig, getPersistentStorage(cr.Spec.MongoDBConfig, cr.Spec.Storage))
+	if isMongoClusterKeyFileRequired(cr) {
+		params.ContainerParams.KeyFileSecret = getKeyFileSecretName(cr.ObjectMeta.Name)
+		params.InitImage = cr.Spec.KubernetesConfig.InitImage
+	}
+	if cr.Spec.MongoDBConfig != nil {
+		params.ContainerParams.ExtraArgs = cr.Spec.MongoDBConfig.ExtraArgs
+		params.ContainerParams.Command = cr.Spec.MongoDBConfig.Command
+		params.ContainerParams.Logging = cr.Spec.MongoDBConfig.Logging
+		params.ContainerParams.DBPath = cr.Spec.MongoDBConfig.DBPath
+		params.ContainerParams.WarmUp = cr.Spec.MongoDBConfig.WarmUp
+		params.ContainerParams.AuditLog = getAuditLog(cr.Spec.MongoDBConfig)
+		params.AuditPVCParameters = getAuditPVCParameters(cr.Spec.MongoDBConfig, cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations))
+		params.ContainerParams.StorageEngine = cr.Spec.MongoDBConfig.StorageEngine
+		params.ContainerParams.BindIP = cr.Spec.MongoDBConfig.BindIP
+		params.ContainerParams.OplogMinRetentionHours = *getInt32OrDefault(cr.Spec.MongoDBConfig.OplogMinRetentionHours, 0)
+		if cr.Spec.MongoDBConfig.WiredTigerCacheAutoTuning != nil {
+			params.ContainerParams.CacheAutoTuning = *cr.Spec.MongoDBConfig.WiredTigerCacheAutoTuning
+		}
+	}
+	params.ProjectedVolume, params.ContainerParams.ExtraVolumeMount = getProjectedVolume(cr.Spec.KubernetesConfig.ProjectedVolume)
+	params.ScratchVolume = getScratchVolume(cr.Spec.KubernetesConfig.ScratchVolume)
+	params.ContainerParams.ScratchVolumeEnabled = params.ScratchVolume != nil
+	if cr.Spec.KubernetesConfig.Probes != nil {
+		params.ContainerParams.LivenessProbe = cr.Spec.KubernetesConfig.Probes.LivenessProbe
+		params.ContainerParams.ReadinessProbe = cr.Spec.KubernetesConfig.Probes.ReadinessProbe
+		params.ContainerParams.StartupProbe = cr.Spec.KubernetesConfig.Probes.StartupProbe
+		params.ContainerParams.ReadinessScript = cr.Spec.KubernetesConfig.Probes.ReadinessScript
+	}
+	if cr.Spec.MongoDBRestore != nil && getPersistentStorage(cr.Spec.MongoDBConfig, cr.Spec.Storage) != nil {
+		params.RestoreParams = getRestoreParams(cr.Spec.MongoDBRestore)
+	}
+	params.AutomountServiceAccountToken = getAutomountServiceAccountToken(cr.Spec.KubernetesConfig, params.RestoreParams)
+	// the pods are replaced by the operator, so the primary is updated last after it stepped down
+	params.UpdateStrategy = appsv1.OnDeleteStatefulSetStrategyType
 	return params
 }
 
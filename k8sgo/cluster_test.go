@@ -0,0 +1,240 @@
+package k8sgo
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/mongo"
+)
+
+func TestGetPerPodServiceParams(t *testing.T) {
+	port := int32(31017)
+	tests := []struct {
+		name            string
+		perPodService   *opstreelabsinv1alpha1.MongoDBPerPodService
+		wantServiceType corev1.ServiceType
+		wantPort        int32
+	}{
+		{name: "node port by default", perPodService: &opstreelabsinv1alpha1.MongoDBPerPodService{Enabled: true}, wantServiceType: corev1.ServiceTypeNodePort, wantPort: mongoDBPort},
+		{
+			name:            "load balancer with a service port",
+			perPodService:   &opstreelabsinv1alpha1.MongoDBPerPodService{Enabled: true, ServiceType: corev1.ServiceTypeLoadBalancer, Port: &port, ServiceAnnotations: map[string]string{"service.beta.kubernetes.io/aws-load-balancer-type": "nlb"}},
+			wantServiceType: corev1.ServiceTypeLoadBalancer,
+			wantPort:        port,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := newTestMongoDBCluster(3)
+			cr.Spec.PerPodService = test.perPodService
+			service := generateServiceDef(getPerPodServiceParams(cr, "mongodb-cluster", 2))
+			if service.Name != "mongodb-cluster-2-external" {
+				t.Errorf("getPerPodServiceParams() name = %s, want mongodb-cluster-2-external", service.Name)
+			}
+			if service.Spec.Selector[podNameLabel] != "mongodb-cluster-2" {
+				t.Errorf("getPerPodServiceParams() selector = %v, want the pod mongodb-cluster-2", service.Spec.Selector)
+			}
+			if service.Spec.Type != test.wantServiceType {
+				t.Errorf("getPerPodServiceParams() type = %s, want %s", service.Spec.Type, test.wantServiceType)
+			}
+			if service.Spec.Ports[0].Port != test.wantPort || service.Spec.Ports[0].TargetPort.IntValue() != mongoDBPort {
+				t.Errorf("getPerPodServiceParams() port = %d -> %s, want %d -> %d", service.Spec.Ports[0].Port, service.Spec.Ports[0].TargetPort.String(), test.wantPort, mongoDBPort)
+			}
+			for key, value := range test.perPodService.ServiceAnnotations {
+				if service.Annotations[key] != value {
+					t.Errorf("getPerPodServiceParams() annotations = %v, missing %s", service.Annotations, key)
+				}
+			}
+		})
+	}
+}
+
+func TestGetMongoDBClusterMembersAnalyticsNode(t *testing.T) {
+	tests := []struct {
+		name          string
+		analyticsNode *opstreelabsinv1alpha1.MongoDBAnalyticsNode
+		wantTags      map[string]string
+	}{
+		{name: "default tags", analyticsNode: &opstreelabsinv1alpha1.MongoDBAnalyticsNode{Enabled: true}, wantTags: map[string]string{"workload": "analytics"}},
+		{name: "custom tags", analyticsNode: &opstreelabsinv1alpha1.MongoDBAnalyticsNode{Enabled: true, Tags: map[string]string{"use": "reporting"}}, wantTags: map[string]string{"use": "reporting"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := newTestMongoDBCluster(3)
+			cr.Spec.AnalyticsNode = test.analyticsNode
+			members := getMongoDBClusterMembers(cr, mongogo.MongoDBParameters{Name: "mongodb", Namespace: "database"})
+			for _, member := range members[:2] {
+				if member.Hidden || member.Priority != 1 || len(member.Tags) != 0 {
+					t.Errorf("getMongoDBClusterMembers() member %d = %+v, want a regular member", member.ID, member)
+				}
+			}
+			analytics := members[2]
+			if !analytics.Hidden || analytics.Priority != 0 || analytics.Votes != 1 || !reflect.DeepEqual(analytics.Tags, test.wantTags) {
+				t.Errorf("getMongoDBClusterMembers() analytics member = %+v, want hidden with priority 0 and tags %v", analytics, test.wantTags)
+			}
+		})
+	}
+}
+
+func TestGetAnalyticsServiceParams(t *testing.T) {
+	cr := newTestMongoDBCluster(5)
+	service := generateServiceDef(getAnalyticsServiceParams(cr, "mongodb-cluster", "mongodb-cluster-analytics"))
+	if service.Name != "mongodb-cluster-analytics" || service.Spec.Selector[podNameLabel] != "mongodb-cluster-4" {
+		t.Errorf("getAnalyticsServiceParams() = %s selecting %v, want mongodb-cluster-analytics selecting the last member", service.Name, service.Spec.Selector)
+	}
+	if service.Spec.ClusterIP == "None" {
+		t.Errorf("getAnalyticsServiceParams() generated a headless service")
+	}
+}
+
+func TestGetMongoDBClusterParamsSchedulerName(t *testing.T) {
+	tests := []struct {
+		name          string
+		schedulerName string
+	}{
+		{name: "default scheduler"},
+		{name: "custom scheduler", schedulerName: "mongodb-scheduler"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := newTestMongoDBCluster(3)
+			cr.Spec.KubernetesConfig.SchedulerName = test.schedulerName
+			cr.Spec.Sharding = &opstreelabsinv1alpha1.MongoDBSharding{Enabled: true}
+			if got := generateStatefulSetDef(getMongoDBClusterParams(cr)).Spec.Template.Spec.SchedulerName; got != test.schedulerName {
+				t.Errorf("getMongoDBClusterParams() schedulerName = %s, want %s", got, test.schedulerName)
+			}
+			if got := generateDeploymentDef(getMongosParams(cr)).Spec.Template.Spec.SchedulerName; got != test.schedulerName {
+				t.Errorf("getMongosParams() schedulerName = %s, want %s", got, test.schedulerName)
+			}
+		})
+	}
+}
+
+func TestGetMongoDBClusterParamsRuntimeClassName(t *testing.T) {
+	gvisor := "gvisor"
+	tests := []struct {
+		name             string
+		runtimeClassName *string
+	}{
+		{name: "default runtime class"},
+		{name: "custom runtime class", runtimeClassName: &gvisor},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := newTestMongoDBCluster(3)
+			cr.Spec.KubernetesConfig.RuntimeClassName = test.runtimeClassName
+			cr.Spec.Sharding = &opstreelabsinv1alpha1.MongoDBSharding{Enabled: true}
+			if got := generateStatefulSetDef(getMongoDBClusterParams(cr)).Spec.Template.Spec.RuntimeClassName; !reflect.DeepEqual(got, test.runtimeClassName) {
+				t.Errorf("getMongoDBClusterParams() runtimeClassName = %v, want %v", got, test.runtimeClassName)
+			}
+			if got := generateDeploymentDef(getMongosParams(cr)).Spec.Template.Spec.RuntimeClassName; !reflect.DeepEqual(got, test.runtimeClassName) {
+				t.Errorf("getMongosParams() runtimeClassName = %v, want %v", got, test.runtimeClassName)
+			}
+		})
+	}
+}
+
+func TestGetMongoDBClusterParamsServiceAccountName(t *testing.T) {
+	tests := []struct {
+		name               string
+		serviceAccountName string
+	}{
+		{name: "default service account"},
+		{name: "custom service account", serviceAccountName: "mongodb-backup"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := newTestMongoDBCluster(3)
+			cr.Spec.KubernetesConfig.ServiceAccountName = test.serviceAccountName
+			cr.Spec.Sharding = &opstreelabsinv1alpha1.MongoDBSharding{Enabled: true}
+			if got := generateStatefulSetDef(getMongoDBClusterParams(cr)).Spec.Template.Spec.ServiceAccountName; got != test.serviceAccountName {
+				t.Errorf("getMongoDBClusterParams() serviceAccountName = %s, want %s", got, test.serviceAccountName)
+			}
+			if got := generateDeploymentDef(getMongosParams(cr)).Spec.Template.Spec.ServiceAccountName; got != test.serviceAccountName {
+				t.Errorf("getMongosParams() serviceAccountName = %s, want %s", got, test.serviceAccountName)
+			}
+		})
+	}
+}
+
+func TestGetMongoDBClusterParamsPodAnnotations(t *testing.T) {
+	tests := []struct {
+		name           string
+		podAnnotations map[string]string
+		want           map[string]string
+	}{
+		{name: "no pod annotations", want: generateAnnotations()},
+		{
+			name:           "pod annotations",
+			podAnnotations: map[string]string{"backup.velero.io/backup-volumes": "mongodb-cluster"},
+			want:           mergeAnnotations(generateAnnotations(), map[string]string{"backup.velero.io/backup-volumes": "mongodb-cluster"}),
+		},
+		{name: "operator annotations take precedence", podAnnotations: map[string]string{"prometheus.io/port": "9100"}, want: generateAnnotations()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := newTestMongoDBCluster(3)
+			cr.Spec.KubernetesConfig.PodAnnotations = test.podAnnotations
+			cr.Spec.Sharding = &opstreelabsinv1alpha1.MongoDBSharding{Enabled: true}
+			statefulSet := generateStatefulSetDef(getMongoDBClusterParams(cr))
+			if got := statefulSet.Spec.Template.Annotations; !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getMongoDBClusterParams() pod annotations = %v, want %v", got, test.want)
+			}
+			if _, found := statefulSet.Annotations["backup.velero.io/backup-volumes"]; found {
+				t.Errorf("getMongoDBClusterParams() sets the pod annotations on the StatefulSet")
+			}
+			if got := generateDeploymentDef(getMongosParams(cr)).Spec.Template.Annotations; !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getMongosParams() pod annotations = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetMongoDBClusterParamsFsyncOnShutdown(t *testing.T) {
+	tests := []struct {
+		name            string
+		fsyncOnShutdown bool
+		storageEngine   string
+		want            bool
+	}{
+		{name: "fsync disabled"},
+		{name: "fsync enabled", fsyncOnShutdown: true, want: true},
+		{name: "in-memory storage engine", fsyncOnShutdown: true, storageEngine: storageEngineInMemory},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := newTestMongoDBCluster(3)
+			cr.Spec.KubernetesConfig.FsyncOnShutdown = test.fsyncOnShutdown
+			cr.Spec.MongoDBConfig = &opstreelabsinv1alpha1.MongoDBConfig{StorageEngine: test.storageEngine}
+			if got := getMongoDBClusterParams(cr).ContainerParams.FsyncOnShutdown; got != test.want {
+				t.Errorf("getMongoDBClusterParams() fsyncOnShutdown = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetMongoDBClusterParamsOplogMinRetention(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention *int32
+		want      int32
+	}{
+		{name: "retention unset"},
+		{name: "retention", retention: int32Ptr(24), want: 24},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := newTestMongoDBCluster(3)
+			cr.Spec.MongoDBConfig = &opstreelabsinv1alpha1.MongoDBConfig{OplogMinRetentionHours: test.retention}
+			if got := getMongoDBClusterParams(cr).ContainerParams.OplogMinRetentionHours; got != test.want {
+				t.Errorf("getMongoDBClusterParams() oplogMinRetentionHours = %d, want %d", got, test.want)
+			}
+			// the arbiter holds no oplog entries to retain
+			if got := getMongoDBArbiterParams(cr).ContainerParams.OplogMinRetentionHours; got != 0 {
+				t.Errorf("getMongoDBArbiterParams() oplogMinRetentionHours = %d, want 0", got)
+			}
+		})
+	}
+}
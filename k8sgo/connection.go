@@ -0,0 +1,93 @@
+package k8sgo
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"net/url"
+)
+
+// GetMongoClusterConnectionURIs is a method to get the internal and external connection URIs of mongodb cluster
+func GetMongoClusterConnectionURIs(cr *opstreelabsinv1alpha1.MongoDBCluster) (string, string, error) {
+	port := getMongoDBPort(cr.Spec.MongoDBConfig)
+	options := getConnectionOptions(cr.Spec.MongoDBSecurity)
+	if cr.Spec.Sharding != nil && cr.Spec.Sharding.Enabled {
+		// applications connect to the mongos routers, which are not a replica set
+		return generateConnectionURI(fmt.Sprintf("%s.%s:%d", getMongosName(cr), cr.Namespace, port), options), "", nil
+	}
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	internalOptions := getConnectionOptions(cr.Spec.MongoDBSecurity)
+	internalOptions.Set("replicaSet", GetMongoDBReplicaSetName(cr))
+	internalURI := generateConnectionURI(fmt.Sprintf("%s.%s:%d", appName, cr.Namespace, port), internalOptions)
+	if !isClientServiceSelectingPrimary(cr) {
+		// a load balancer spreading the connections across the members would send writes to secondaries
+		return internalURI, "", nil
+	}
+	externalAddress, err := getExternalServiceAddress(cr.Namespace, fmt.Sprintf("%s-%s", appName, "client"))
+	if err != nil || externalAddress == "" {
+		return internalURI, "", err
+	}
+	// the replica set members advertise internal hostnames, so external clients cannot use replica set discovery and connect to the primary the load balancer selects
+	options.Set("directConnection", "true")
+	return internalURI, generateConnectionURI(externalAddress, options), nil
+}
+
+// GetMongoStandaloneConnectionURIs is a method to get the internal and external connection URIs of mongodb standalone
+func GetMongoStandaloneConnectionURIs(cr *opstreelabsinv1alpha1.MongoDB) (string, string, error) {
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone")
+	options := getConnectionOptions(cr.Spec.MongoDBSecurity)
+	internalURI := generateConnectionURI(fmt.Sprintf("%s.%s:%d", appName, cr.Namespace, getMongoDBPort(cr.Spec.MongoDBConfig)), options)
+	externalAddress, err := getExternalServiceAddress(cr.Namespace, fmt.Sprintf("%s-%s", appName, "client"))
+	if err != nil || externalAddress == "" {
+		return internalURI, "", err
+	}
+	return internalURI, generateConnectionURI(externalAddress, options), nil
+}
+
+// getConnectionOptions is a method to get the connection URI options based on the enabled features
+func getConnectionOptions(security *opstreelabsinv1alpha1.MongoDBSecurity) url.Values {
+	options := url.Values{}
+	if security != nil {
+		options.Set("authSource", "admin")
+	}
+	return options
+}
+
+// generateConnectionURI is a method to generate a connection URI without credentials for the hosts
+func generateConnectionURI(hosts string, options url.Values) string {
+	if len(options) == 0 {
+		return fmt.Sprintf("mongodb://%s/", hosts)
+	}
+	return fmt.Sprintf("mongodb://%s/?%s", hosts, options.Encode())
+}
+
+// generateCredentialURI is a method to generate a connection URI for the host with the credentials escaped, so passwords containing characters like @, : or / are kept intact
+func generateCredentialURI(username string, password string, host string) string {
+	uri := url.URL{Scheme: "mongodb", User: url.UserPassword(username, password), Host: host, Path: "/"}
+	return uri.String()
+}
+
+// getExternalServiceAddress is a method to get the load balancer address of the client service, it is empty until one is assigned
+func getExternalServiceAddress(namespace string, serviceName string) (string, error) {
+	service, err := getService(namespace, serviceName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	if service.Spec.Type != corev1.ServiceTypeLoadBalancer || len(service.Spec.Ports) == 0 {
+		return "", nil
+	}
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		host := ingress.Hostname
+		if host == "" {
+			host = ingress.IP
+		}
+		if host != "" {
+			return fmt.Sprintf("%s:%d", host, service.Spec.Ports[0].Port), nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,171 @@
+package k8sgo
+
+import (
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"net/url"
+	"reflect"
+)
+
+// connectionSecretParameters is the input struct for the secret the applications connect to MongoDB with
+type connectionSecretParameters struct {
+	SecretMeta       metav1.ObjectMeta
+	OwnerDef         metav1.OwnerReference
+	Username         string
+	Password         string
+	ConnectionString string
+}
+
+// CreateMongoClusterConnectionSecret is a method to sync the admin credentials and connection string of mongodb cluster into the connection secret
+func CreateMongoClusterConnectionSecret(cr *opstreelabsinv1alpha1.MongoDBCluster, connectionURI string) error {
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	return reconcileConnectionSecret(cr.Spec.MongoDBSecurity, cr.Namespace, appName, "cluster", mongoClusterAsOwner(cr), connectionURI)
+}
+
+// CreateMongoStandaloneConnectionSecret is a method to sync the admin credentials and connection string of mongodb standalone into the connection secret
+func CreateMongoStandaloneConnectionSecret(cr *opstreelabsinv1alpha1.MongoDB, connectionURI string) error {
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone")
+	return reconcileConnectionSecret(cr.Spec.MongoDBSecurity, cr.Namespace, appName, "standalone", mongoAsOwner(cr), connectionURI)
+}
+
+// getConnectionSecretLabels is a method to get the labels of the connection secret, they tell it apart from a secret managed outside of the operator
+func getConnectionSecretLabels(appName string, setupType string) map[string]string {
+	return map[string]string{
+		"app":           appName,
+		"mongodb_setup": setupType,
+		"role":          "connection",
+	}
+}
+
+// reconcileConnectionSecret is a method to create or update the connection secret, and to delete the previous one once it is renamed or disabled
+func reconcileConnectionSecret(security *opstreelabsinv1alpha1.MongoDBSecurity, namespace string, appName string, setupType string, owner metav1.OwnerReference, connectionURI string) error {
+	secretLabels := getConnectionSecretLabels(appName, setupType)
+	secretName := ""
+	if security != nil {
+		secretName = security.ConnectionSecretName
+	}
+	if err := deleteStaleConnectionSecrets(namespace, secretName, secretLabels); err != nil {
+		return err
+	}
+	if secretName == "" {
+		return nil
+	}
+	password, err := getMongoDBPassword(secretsParameters{Name: appName, Namespace: namespace, SecretName: *security.SecretRef.Name, SecretKey: *security.SecretRef.Key})
+	if err != nil {
+		return err
+	}
+	connectionString, err := generateConnectionString(connectionURI, security.MongoDBAdminUser, password)
+	if err != nil {
+		return err
+	}
+	return CreateOrUpdateConnectionSecret(connectionSecretParameters{
+		SecretMeta:       generateObjectMetaInformation(secretName, namespace, secretLabels, generateAnnotations()),
+		OwnerDef:         owner,
+		Username:         security.MongoDBAdminUser,
+		Password:         password,
+		ConnectionString: connectionString,
+	})
+}
+
+// generateConnectionString is a method to add the credentials to the connection URI, they are escaped as special characters are allowed in the password
+func generateConnectionString(connectionURI string, username string, password string) (string, error) {
+	uri, err := url.Parse(connectionURI)
+	if err != nil {
+		return "", err
+	}
+	uri.User = url.UserPassword(username, password)
+	return uri.String(), nil
+}
+
+// CreateOrUpdateConnectionSecret method will create or update the connection secret, a secret of the same name managed outside of the operator is never overwritten
+func CreateOrUpdateConnectionSecret(params connectionSecretParameters) error {
+	logger := logGenerator(params.SecretMeta.Name, params.SecretMeta.Namespace, "Secret")
+	secretDef := generateConnectionSecretDef(params)
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	storedSecret, err := client.CoreV1().Secrets(params.SecretMeta.Namespace).Get(context.TODO(), params.SecretMeta.Name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "MongoDB connection secret get action failed")
+			return err
+		}
+		_, err = client.CoreV1().Secrets(params.SecretMeta.Namespace).Create(context.TODO(), secretDef, metav1.CreateOptions{})
+		if err != nil {
+			logger.Error(err, "MongoDB connection secret creation failed")
+			return err
+		}
+		logger.Info("MongoDB connection secret successfully created")
+		return nil
+	}
+	if !hasLabels(storedSecret.Labels, secretDef.Labels) {
+		err = fmt.Errorf("secret %s already exists and is not managed by the operator", params.SecretMeta.Name)
+		logger.Error(err, "MongoDB connection secret cannot be updated")
+		return err
+	}
+	if reflect.DeepEqual(storedSecret.Data, secretDef.Data) {
+		logger.Info("MongoDB connection secret is already in-sync")
+		return nil
+	}
+	secretDef.ResourceVersion = storedSecret.ResourceVersion
+	secretDef.Annotations = preserveExternalAnnotations(storedSecret.Annotations, secretDef.Annotations)
+	_, err = client.CoreV1().Secrets(params.SecretMeta.Namespace).Update(context.TODO(), secretDef, metav1.UpdateOptions{})
+	if err != nil {
+		logger.Error(err, "MongoDB connection secret update failed")
+		return err
+	}
+	logger.Info("MongoDB connection secret successfully updated")
+	return nil
+}
+
+// deleteStaleConnectionSecrets is a method to delete the connection secrets of the MongoDB setup which are not named in its spec anymore
+func deleteStaleConnectionSecrets(namespace string, secretName string, secretLabels map[string]string) error {
+	logger := logGenerator(secretName, namespace, "Secret")
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	secrets, err := client.CoreV1().Secrets(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(secretLabels).String(),
+	})
+	if err != nil {
+		logger.Error(err, "Unable to list the connection secrets of MongoDB")
+		return err
+	}
+	for _, secret := range secrets.Items {
+		if secret.Name == secretName {
+			continue
+		}
+		err = client.CoreV1().Secrets(namespace).Delete(context.TODO(), secret.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "MongoDB connection secret deletion failed", "secret", secret.Name)
+			return err
+		}
+		logger.Info("Deleted the previous MongoDB connection secret", "secret", secret.Name)
+	}
+	return nil
+}
+
+// generateConnectionSecretDef is a method to generate the connection secret definition
+func generateConnectionSecretDef(params connectionSecretParameters) *corev1.Secret {
+	secret := &corev1.Secret{
+		TypeMeta:   generateMetaInformation("Secret", "v1"),
+		ObjectMeta: params.SecretMeta,
+		Type:       corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"username":         []byte(params.Username),
+			"password":         []byte(params.Password),
+			"connectionString": []byte(params.ConnectionString),
+		},
+	}
+	AddOwnerRefToObject(secret, params.OwnerDef)
+	return secret
+}
@@ -0,0 +1,944 @@
+package k8sgo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestValidateMongoDBLogging(t *testing.T) {
+	tests := []struct {
+		name    string
+		logging *opstreelabsinv1alpha1.MongoDBLogging
+		wantErr bool
+	}{
+		{name: "container output", logging: &opstreelabsinv1alpha1.MongoDBLogging{}},
+		{name: "file in the log directory", logging: &opstreelabsinv1alpha1.MongoDBLogging{LogPath: "/var/log/mongodb/mongod.log"}},
+		{name: "file outside of the log directory", logging: &opstreelabsinv1alpha1.MongoDBLogging{LogPath: "/data/db/mongod.log"}, wantErr: true},
+		{name: "file in a subdirectory", logging: &opstreelabsinv1alpha1.MongoDBLogging{LogPath: "/var/log/mongodb/mongod/mongod.log"}, wantErr: true},
+		{name: "rotation without file", logging: &opstreelabsinv1alpha1.MongoDBLogging{LogRotate: "reopen"}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateMongoDBLogging(test.logging); (err != nil) != test.wantErr {
+				t.Errorf("validateMongoDBLogging() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetLogSidecarCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		logging     *opstreelabsinv1alpha1.MongoDBLogging
+		authEnabled bool
+		wantRotate  bool
+	}{
+		{name: "rename is rotated by the sidecar", logging: &opstreelabsinv1alpha1.MongoDBLogging{LogPath: "/var/log/mongodb/mongod.log"}, wantRotate: true},
+		{name: "rename with authentication", logging: &opstreelabsinv1alpha1.MongoDBLogging{LogPath: "/var/log/mongodb/mongod.log", LogRotate: "rename"}, authEnabled: true, wantRotate: true},
+		{name: "reopen is rotated by an external tool", logging: &opstreelabsinv1alpha1.MongoDBLogging{LogPath: "/var/log/mongodb/mongod.log", LogRotate: "reopen"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			command := getLogSidecarCommand(test.logging, mongoDBPort, test.authEnabled)
+			if !strings.HasSuffix(command, "exec tail -n +1 -F /var/log/mongodb/mongod.log") {
+				t.Errorf("getLogSidecarCommand() = %s, want it to tail the log file", command)
+			}
+			if rotate := strings.Contains(command, "logRotate: 1"); rotate != test.wantRotate {
+				t.Errorf("getLogSidecarCommand() rotates = %v, want %v", rotate, test.wantRotate)
+			}
+			if credentials := strings.Contains(command, "MONGO_ROOT_PASSWORD"); credentials != (test.authEnabled && test.wantRotate) {
+				t.Errorf("getLogSidecarCommand() authenticates = %v, want %v", credentials, test.authEnabled && test.wantRotate)
+			}
+		})
+	}
+}
+
+func TestGetMongoDBArgs(t *testing.T) {
+	replicaSetName := "rs0"
+	tests := []struct {
+		name         string
+		params       containerParameters
+		wantArgs     []string
+		unwantedArgs []string
+	}{
+		{
+			name:         "entrypoint passes the required flags",
+			params:       containerParameters{Port: mongoDBPort, MongoReplicaSetName: &replicaSetName, ExtraArgs: []string{"--wiredTigerCacheSizeGB=2"}},
+			wantArgs:     []string{"--wiredTigerCacheSizeGB=2"},
+			unwantedArgs: []string{"--replSet", "--keyFile=" + getKeyFilePath()},
+		},
+		{
+			name:     "custom command keeps the required flags",
+			params:   containerParameters{Port: mongoDBPort, MongoReplicaSetName: &replicaSetName, Command: []string{"mongod"}, KeyFileSecret: "mongodb-keyfile", ExtraArgs: []string{"--oplogSize=2048"}},
+			wantArgs: []string{"--replSet", "rs0", "--keyFile=" + getKeyFilePath(), "--bind_ip_all", "--oplogSize=2048"},
+		},
+		{
+			name:         "custom command of standalone",
+			params:       containerParameters{Port: mongoDBPort, Command: []string{"mongod"}},
+			wantArgs:     []string{"--bind_ip_all"},
+			unwantedArgs: []string{"--replSet", "--keyFile=" + getKeyFilePath()},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := getMongoDBArgs(test.params)
+			for _, want := range test.wantArgs {
+				if !containsArg(args, want) {
+					t.Errorf("getMongoDBArgs() = %v, missing %s", args, want)
+				}
+			}
+			for _, unwanted := range test.unwantedArgs {
+				if containsArg(args, unwanted) {
+					t.Errorf("getMongoDBArgs() = %v, unexpected %s", args, unwanted)
+				}
+			}
+			// the user defined arguments come last, so they are not mistaken for the values of operator flags
+			if len(test.params.ExtraArgs) > 0 && args[len(args)-1] != test.params.ExtraArgs[len(test.params.ExtraArgs)-1] {
+				t.Errorf("getMongoDBArgs() = %v, extra args are not appended", args)
+			}
+		})
+	}
+}
+
+func containsArg(args []string, arg string) bool {
+	for _, value := range args {
+		if value == arg {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGetMongoDBReadinessProbe(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		authEnabled bool
+		wantCommand string
+	}{
+		{name: "ping", wantCommand: "db.adminCommand('ping')"},
+		{name: "replica set member", mode: readinessModeReplicaSetMember, wantCommand: "replSetGetStatus"},
+		{name: "replica set member with authentication", mode: readinessModeReplicaSetMember, authEnabled: true, wantCommand: "MONGO_ROOT_PASSWORD"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			command := strings.Join(getMongoDBReadinessProbe(test.mode, mongoDBPort, test.authEnabled).Handler.Exec.Command, " ")
+			if !strings.Contains(command, test.wantCommand) {
+				t.Errorf("getMongoDBReadinessProbe() command = %s, missing %s", command, test.wantCommand)
+			}
+			if test.mode == readinessModeReplicaSetMember && !strings.Contains(command, "stateStr") {
+				t.Errorf("getMongoDBReadinessProbe() command = %s, doesn't check the member state", command)
+			}
+		})
+	}
+}
+
+func TestGenerateContainerDefName(t *testing.T) {
+	monitoring := true
+	monitoringSecret := "mongodb-monitoring"
+	pullPolicy := corev1.PullIfNotPresent
+	tests := []struct {
+		name          string
+		containerName string
+		want          string
+	}{
+		{name: "default name", want: "mongod"},
+		{name: "custom name", containerName: "database", want: "database"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params := containerParameters{
+				Image:                     "mongo:6.0",
+				ContainerName:             test.containerName,
+				MongoDBMonitoring:         &monitoring,
+				MonitoringImage:           "bitnami/mongodb-exporter:0.11",
+				MonitoringSecret:          &monitoringSecret,
+				MonitoringImagePullPolicy: &pullPolicy,
+				Logging:                   &opstreelabsinv1alpha1.MongoDBLogging{LogPath: "/var/log/mongodb/mongod.log"},
+			}
+			containers := generateContainerDef("mongodb", params)
+			if containers[0].Name != test.want {
+				t.Errorf("generateContainerDef() mongod container = %s, want %s", containers[0].Name, test.want)
+			}
+			names := map[string]bool{}
+			for _, container := range containers {
+				if names[container.Name] {
+					t.Errorf("generateContainerDef() container name %s is used more than once", container.Name)
+				}
+				names[container.Name] = true
+			}
+		})
+	}
+}
+
+func TestGetWiredTigerCacheSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources *corev1.ResourceRequirements
+		want      string
+	}{
+		{name: "no resources"},
+		{name: "no memory limit", resources: &corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")}}},
+		{name: "zero memory limit", resources: &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("0")}}},
+		{name: "floor below 1.5Gi", resources: &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("512Mi")}}, want: "0.25"},
+		{name: "floor at 1.5Gi", resources: &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1536Mi")}}, want: "0.25"},
+		{name: "2Gi", resources: &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")}}, want: "0.5"},
+		{name: "3000Mi is rounded down", resources: &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("3000Mi")}}, want: "0.96"},
+		{name: "4Gi", resources: &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")}}, want: "1.5"},
+		{name: "16Gi", resources: &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("16Gi")}}, want: "7.5"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if cacheSize := getWiredTigerCacheSize(test.resources); cacheSize != test.want {
+				t.Errorf("getWiredTigerCacheSize() = %q, want %q", cacheSize, test.want)
+			}
+		})
+	}
+}
+
+func TestGetMongoDBArgsCacheSize(t *testing.T) {
+	resources := &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")}}
+	tests := []struct {
+		name         string
+		params       containerParameters
+		wantArgs     []string
+		unwantedArgs []string
+	}{
+		{
+			name:     "derived from the memory limit",
+			params:   containerParameters{Port: mongoDBPort, CacheAutoTuning: true, Resources: resources},
+			wantArgs: []string{"--wiredTigerCacheSizeGB=1.5"},
+		},
+		{
+			name:         "explicit cache size takes precedence",
+			params:       containerParameters{Port: mongoDBPort, CacheAutoTuning: true, Resources: resources, ExtraArgs: []string{"--wiredTigerCacheSizeGB=2"}},
+			wantArgs:     []string{"--wiredTigerCacheSizeGB=2"},
+			unwantedArgs: []string{"--wiredTigerCacheSizeGB=1.5"},
+		},
+		{
+			name:         "explicit cache size as separate value",
+			params:       containerParameters{Port: mongoDBPort, CacheAutoTuning: true, Resources: resources, ExtraArgs: []string{"--wiredTigerCacheSizeGB", "2"}},
+			unwantedArgs: []string{"--wiredTigerCacheSizeGB=1.5"},
+		},
+		{
+			name:         "no memory limit",
+			params:       containerParameters{Port: mongoDBPort, CacheAutoTuning: true},
+			unwantedArgs: []string{"--wiredTigerCacheSizeGB=0.25"},
+		},
+		{
+			name:         "auto tuning disabled",
+			params:       containerParameters{Port: mongoDBPort, Resources: resources},
+			unwantedArgs: []string{"--wiredTigerCacheSizeGB=1.5"},
+		},
+		{
+			name:         "in-memory storage engine",
+			params:       containerParameters{Port: mongoDBPort, CacheAutoTuning: true, Resources: resources, StorageEngine: storageEngineInMemory},
+			wantArgs:     []string{"--inMemorySizeGB=1.5"},
+			unwantedArgs: []string{"--wiredTigerCacheSizeGB=1.5"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := getMongoDBArgs(test.params)
+			for _, want := range test.wantArgs {
+				if !containsArg(args, want) {
+					t.Errorf("getMongoDBArgs() = %v, missing %s", args, want)
+				}
+			}
+			for _, unwanted := range test.unwantedArgs {
+				if containsArg(args, unwanted) {
+					t.Errorf("getMongoDBArgs() = %v, unexpected %s", args, unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestGetOplogSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		oplogSizeMB *int32
+		storageSize string
+		want        int32
+		wantErr     bool
+	}{
+		{name: "explicit size", oplogSizeMB: int32Ptr(2048), storageSize: "100Gi", want: 2048},
+		{name: "explicit minimum", oplogSizeMB: int32Ptr(990), want: 990},
+		{name: "zero", oplogSizeMB: int32Ptr(0), wantErr: true},
+		{name: "negative", oplogSizeMB: int32Ptr(-1), wantErr: true},
+		{name: "below the minimum", oplogSizeMB: int32Ptr(500), wantErr: true},
+		{name: "no storage", want: 0},
+		{name: "minimum for small volumes", storageSize: "10Gi", want: 990},
+		{name: "5% of the storage", storageSize: "100Gi", want: 5120},
+		{name: "maximum for large volumes", storageSize: "2Ti", want: 50 * 1024},
+		{name: "invalid storage size", storageSize: "lots", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := &opstreelabsinv1alpha1.MongoDBConfig{OplogSizeMB: test.oplogSizeMB}
+			var storage *opstreelabsinv1alpha1.Storage
+			if test.storageSize != "" {
+				storage = &opstreelabsinv1alpha1.Storage{StorageSize: test.storageSize}
+			}
+			oplogSize, err := getOplogSize(config, storage)
+			if (err != nil) != test.wantErr || oplogSize != test.want {
+				t.Errorf("getOplogSize() = %d, %v, want %d, wantErr %v", oplogSize, err, test.want, test.wantErr)
+			}
+		})
+	}
+}
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+func TestGetDefaultContainerSecurityContext(t *testing.T) {
+	securityContext := getDefaultContainerSecurityContext()
+	if securityContext.AllowPrivilegeEscalation == nil || *securityContext.AllowPrivilegeEscalation {
+		t.Errorf("getDefaultContainerSecurityContext() allowPrivilegeEscalation = %v, want false", securityContext.AllowPrivilegeEscalation)
+	}
+	if securityContext.Capabilities == nil || len(securityContext.Capabilities.Drop) != 1 || securityContext.Capabilities.Drop[0] != "ALL" {
+		t.Errorf("getDefaultContainerSecurityContext() capabilities = %v, want ALL dropped", securityContext.Capabilities)
+	}
+}
+
+func TestGenerateContainerDefSecurityContext(t *testing.T) {
+	privileged := true
+	tests := []struct {
+		name            string
+		securityContext *corev1.SecurityContext
+		want            *corev1.SecurityContext
+	}{
+		{name: "default", want: getDefaultContainerSecurityContext()},
+		{name: "user security context takes precedence", securityContext: &corev1.SecurityContext{Privileged: &privileged}, want: &corev1.SecurityContext{Privileged: &privileged}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			containers := generateContainerDef("mongodb", containerParameters{Image: "mongo:6.0", SecurityContext: test.securityContext})
+			if !reflect.DeepEqual(containers[0].SecurityContext, test.want) {
+				t.Errorf("generateContainerDef() securityContext = %v, want %v", containers[0].SecurityContext, test.want)
+			}
+		})
+	}
+}
+
+func TestMergeEnvironmentVariables(t *testing.T) {
+	managed := []corev1.EnvVar{{Name: "MONGO_MODE", Value: "cluster"}, {Name: "MONGO_ROOT_USERNAME", Value: "admin"}}
+	tests := []struct {
+		name    string
+		userEnv []corev1.EnvVar
+		want    []corev1.EnvVar
+	}{
+		{name: "no user variables", want: managed},
+		{
+			name:    "user variables are appended",
+			userEnv: []corev1.EnvVar{{Name: "TZ", Value: "UTC"}},
+			want:    append(append([]corev1.EnvVar{}, managed...), corev1.EnvVar{Name: "TZ", Value: "UTC"}),
+		},
+		{
+			name:    "managed variables take precedence",
+			userEnv: []corev1.EnvVar{{Name: "MONGO_MODE", Value: "standalone"}},
+			want:    managed,
+		},
+		{
+			name:    "last user definition wins",
+			userEnv: []corev1.EnvVar{{Name: "TZ", Value: "UTC"}, {Name: "GLIBC_TUNABLES", Value: "glibc.pthread.rseq=0"}, {Name: "TZ", Value: "Europe/Berlin"}},
+			want:    append(append([]corev1.EnvVar{}, managed...), corev1.EnvVar{Name: "TZ", Value: "Europe/Berlin"}, corev1.EnvVar{Name: "GLIBC_TUNABLES", Value: "glibc.pthread.rseq=0"}),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			managedEnvVars := append([]corev1.EnvVar{}, managed...)
+			if envVars := mergeEnvironmentVariables(managedEnvVars, test.userEnv); !reflect.DeepEqual(envVars, test.want) {
+				t.Errorf("mergeEnvironmentVariables() = %v, want %v", envVars, test.want)
+			}
+		})
+	}
+}
+
+func TestGetImagePullPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		image      string
+		pullPolicy corev1.PullPolicy
+		want       corev1.PullPolicy
+	}{
+		{name: "pinned tag", image: "mongo:6.0.5", want: corev1.PullIfNotPresent},
+		{name: "digest", image: "mongo@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", want: corev1.PullIfNotPresent},
+		{name: "latest tag", image: "mongo:latest", want: corev1.PullAlways},
+		{name: "no tag", image: "mongo", want: corev1.PullAlways},
+		{name: "explicit policy", image: "mongo:latest", pullPolicy: corev1.PullNever, want: corev1.PullNever},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if pullPolicy := getImagePullPolicy(test.image, test.pullPolicy); pullPolicy != test.want {
+				t.Errorf("getImagePullPolicy() = %s, want %s", pullPolicy, test.want)
+			}
+		})
+	}
+}
+
+func TestApplyProbeConfigHandler(t *testing.T) {
+	customCommand := []string{"sh", "-c", "/opt/health.sh"}
+	tests := []struct {
+		name        string
+		config      *opstreelabsinv1alpha1.Probe
+		wantCommand []string
+		wantHTTPGet bool
+	}{
+		{name: "no probe config", wantCommand: getMongoDBProbe(mongoDBPort).Handler.Exec.Command},
+		{name: "probe config without handler", config: &opstreelabsinv1alpha1.Probe{}, wantCommand: getMongoDBProbe(mongoDBPort).Handler.Exec.Command},
+		{name: "custom exec command", config: &opstreelabsinv1alpha1.Probe{Handler: corev1.Handler{Exec: &corev1.ExecAction{Command: customCommand}}}, wantCommand: customCommand},
+		{name: "custom http get", config: &opstreelabsinv1alpha1.Probe{Handler: corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/health"}}}, wantHTTPGet: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			probe := applyProbeConfig(getMongoDBProbe(mongoDBPort), test.config)
+			if test.wantHTTPGet {
+				if probe.Handler.HTTPGet == nil || probe.Handler.Exec != nil {
+					t.Errorf("applyProbeConfig() handler = %v, want only the http get handler", probe.Handler)
+				}
+				return
+			}
+			if probe.Handler.Exec == nil || !reflect.DeepEqual(probe.Handler.Exec.Command, test.wantCommand) {
+				t.Errorf("applyProbeConfig() handler = %v, want command %v", probe.Handler, test.wantCommand)
+			}
+		})
+	}
+}
+
+func TestValidateProbesHandlers(t *testing.T) {
+	exec := &corev1.ExecAction{Command: []string{"true"}}
+	tests := []struct {
+		name    string
+		probes  *opstreelabsinv1alpha1.ProbeConfig
+		wantErr bool
+	}{
+		{name: "no probes"},
+		{name: "single handler", probes: &opstreelabsinv1alpha1.ProbeConfig{LivenessProbe: &opstreelabsinv1alpha1.Probe{Handler: corev1.Handler{Exec: exec}}}},
+		{name: "exec and http get", probes: &opstreelabsinv1alpha1.ProbeConfig{ReadinessProbe: &opstreelabsinv1alpha1.Probe{Handler: corev1.Handler{Exec: exec, HTTPGet: &corev1.HTTPGetAction{Path: "/health"}}}}, wantErr: true},
+		{name: "exec and tcp socket", probes: &opstreelabsinv1alpha1.ProbeConfig{StartupProbe: &opstreelabsinv1alpha1.Probe{Handler: corev1.Handler{Exec: exec, TCPSocket: &corev1.TCPSocketAction{}}}}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateProbes(test.probes); (err != nil) != test.wantErr {
+				t.Errorf("validateProbes() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateExtraVolumeMounts(t *testing.T) {
+	volumes := []corev1.Volume{{Name: "scripts"}, {Name: "certs"}}
+	tests := []struct {
+		name         string
+		volumeMounts []corev1.VolumeMount
+		wantErr      bool
+	}{
+		{name: "no volume mounts"},
+		{name: "volume mounts referencing the extra volumes", volumeMounts: []corev1.VolumeMount{{Name: "scripts", MountPath: "/scripts"}, {Name: "certs", MountPath: "/certs"}}},
+		{name: "volume mount referencing an unknown volume", volumeMounts: []corev1.VolumeMount{{Name: "scripts", MountPath: "/scripts"}, {Name: "backup", MountPath: "/backup"}}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateExtraVolumeMounts(volumes, test.volumeMounts); (err != nil) != test.wantErr {
+				t.Errorf("validateExtraVolumeMounts() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateHostNetwork(t *testing.T) {
+	tests := []struct {
+		name              string
+		kubernetesConfig  opstreelabsinv1alpha1.KubernetesConfig
+		port              int32
+		monitoringEnabled bool
+		wantErr           bool
+	}{
+		{name: "no host network", port: mongoDBMonitoringPort, monitoringEnabled: true},
+		{name: "host network", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{HostNetwork: true}, port: mongoDBPort, monitoringEnabled: true},
+		{name: "exporter port conflict", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{HostNetwork: true}, port: mongoDBMonitoringPort, monitoringEnabled: true, wantErr: true},
+		{name: "exporter port without monitoring", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{HostNetwork: true}, port: mongoDBMonitoringPort},
+		{name: "node port conflict", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{HostNetwork: true, Service: &opstreelabsinv1alpha1.ServiceConfig{NodePort: int32Ptr(30017)}}, port: 30017, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateHostNetwork(test.kubernetesConfig, test.port, test.monitoringEnabled); (err != nil) != test.wantErr {
+				t.Errorf("validateHostNetwork() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetProjectedVolume(t *testing.T) {
+	sources := []corev1.VolumeProjection{
+		{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "mongodb-tls"}}},
+		{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "mongodb-ca"}}},
+	}
+	tests := []struct {
+		name            string
+		config          *opstreelabsinv1alpha1.ProjectedVolumeConfig
+		wantMode        int32
+		wantMountPath   string
+		wantNoProjected bool
+	}{
+		{name: "no projected volume", wantNoProjected: true},
+		{name: "defaults", config: &opstreelabsinv1alpha1.ProjectedVolumeConfig{Sources: sources}, wantMode: defaultProjectedMode, wantMountPath: defaultProjectedMountPath},
+		{name: "custom mode and mount path", config: &opstreelabsinv1alpha1.ProjectedVolumeConfig{MountPath: "/etc/mongodb", DefaultMode: int32Ptr(0440), Sources: sources}, wantMode: 0440, wantMountPath: "/etc/mongodb"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			volume, volumeMount := getProjectedVolume(test.config)
+			if test.wantNoProjected {
+				if volume != nil || volumeMount != nil {
+					t.Errorf("getProjectedVolume() = %v, %v, want no projected volume", volume, volumeMount)
+				}
+				return
+			}
+			if *volume.DefaultMode != test.wantMode || !reflect.DeepEqual(volume.Sources, sources) {
+				t.Errorf("getProjectedVolume() volume = %v, want mode %o with sources %v", volume, test.wantMode, sources)
+			}
+			if volumeMount.Name != projectedVolumeName || volumeMount.MountPath != test.wantMountPath || !volumeMount.ReadOnly {
+				t.Errorf("getProjectedVolume() volume mount = %v, want read only mount on %s", volumeMount, test.wantMountPath)
+			}
+		})
+	}
+}
+
+func TestValidateProjectedVolume(t *testing.T) {
+	secret := &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "mongodb-tls"}}
+	tests := []struct {
+		name    string
+		config  *opstreelabsinv1alpha1.ProjectedVolumeConfig
+		wantErr bool
+	}{
+		{name: "no projected volume"},
+		{name: "valid sources", config: &opstreelabsinv1alpha1.ProjectedVolumeConfig{Sources: []corev1.VolumeProjection{{Secret: secret}}}},
+		{name: "no sources", config: &opstreelabsinv1alpha1.ProjectedVolumeConfig{}, wantErr: true},
+		{name: "mounted on the data directory", config: &opstreelabsinv1alpha1.ProjectedVolumeConfig{MountPath: "/data/db", Sources: []corev1.VolumeProjection{{Secret: secret}}}, wantErr: true},
+		{name: "empty source", config: &opstreelabsinv1alpha1.ProjectedVolumeConfig{Sources: []corev1.VolumeProjection{{}}}, wantErr: true},
+		{name: "source with multiple types", config: &opstreelabsinv1alpha1.ProjectedVolumeConfig{Sources: []corev1.VolumeProjection{{Secret: secret, ConfigMap: &corev1.ConfigMapProjection{}}}}, wantErr: true},
+		{name: "invalid item mode", config: &opstreelabsinv1alpha1.ProjectedVolumeConfig{Sources: []corev1.VolumeProjection{{Secret: &corev1.SecretProjection{Items: []corev1.KeyToPath{{Key: "tls.key", Path: "tls.key", Mode: int32Ptr(01000)}}}}}}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateProjectedVolume(test.config, "/data/db"); (err != nil) != test.wantErr {
+				t.Errorf("validateProjectedVolume() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetScratchVolume(t *testing.T) {
+	sizeLimit := resource.MustParse("1Gi")
+	tests := []struct {
+		name   string
+		config *opstreelabsinv1alpha1.ScratchVolumeConfig
+		want   *corev1.EmptyDirVolumeSource
+	}{
+		{name: "no scratch volume"},
+		{name: "disabled scratch volume", config: &opstreelabsinv1alpha1.ScratchVolumeConfig{Medium: corev1.StorageMediumMemory}},
+		{name: "scratch volume", config: &opstreelabsinv1alpha1.ScratchVolumeConfig{Enabled: true}, want: &corev1.EmptyDirVolumeSource{}},
+		{name: "memory scratch volume with size limit", config: &opstreelabsinv1alpha1.ScratchVolumeConfig{Enabled: true, Medium: corev1.StorageMediumMemory, SizeLimit: &sizeLimit}, want: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory, SizeLimit: &sizeLimit}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getScratchVolume(test.config); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getScratchVolume() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGenerateContainerDefScratchVolumeMounts(t *testing.T) {
+	tests := []struct {
+		name                 string
+		scratchVolumeEnabled bool
+		wantMountPaths       []string
+	}{
+		{name: "no scratch volume"},
+		{name: "scratch volume", scratchVolumeEnabled: true, wantMountPaths: []string{"/tmp", logDirectory}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			container := generateContainerDef("mongodb", containerParameters{Image: "mongo:4.4", ScratchVolumeEnabled: test.scratchVolumeEnabled})[0]
+			var mountPaths []string
+			for _, volumeMount := range container.VolumeMounts {
+				if volumeMount.Name == scratchVolumeName {
+					mountPaths = append(mountPaths, volumeMount.MountPath)
+				}
+			}
+			if !reflect.DeepEqual(mountPaths, test.wantMountPaths) {
+				t.Errorf("generateContainerDef() scratch volume mounts = %v, want %v", mountPaths, test.wantMountPaths)
+			}
+		})
+	}
+}
+
+func TestApplyProbeConfigTimings(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *opstreelabsinv1alpha1.Probe
+		want   [5]int32
+	}{
+		{name: "default timings", config: &opstreelabsinv1alpha1.Probe{}, want: [5]int32{15, 15, 5, 5, 0}},
+		{name: "custom timings", config: &opstreelabsinv1alpha1.Probe{InitialDelaySeconds: int32Ptr(60), PeriodSeconds: int32Ptr(10), TimeoutSeconds: int32Ptr(3), FailureThreshold: int32Ptr(10), SuccessThreshold: int32Ptr(2)}, want: [5]int32{60, 10, 3, 10, 2}},
+		{name: "partial timings", config: &opstreelabsinv1alpha1.Probe{InitialDelaySeconds: int32Ptr(0)}, want: [5]int32{0, 15, 5, 5, 0}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			probe := applyProbeConfig(getMongoDBProbe(mongoDBPort), test.config)
+			got := [5]int32{probe.InitialDelaySeconds, probe.PeriodSeconds, probe.TimeoutSeconds, probe.FailureThreshold, probe.SuccessThreshold}
+			if got != test.want {
+				t.Errorf("applyProbeConfig() initialDelay, period, timeout, failureThreshold, successThreshold = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateProbesSuccessThreshold(t *testing.T) {
+	tests := []struct {
+		name    string
+		probes  *opstreelabsinv1alpha1.ProbeConfig
+		wantErr bool
+	}{
+		{name: "readiness success threshold", probes: &opstreelabsinv1alpha1.ProbeConfig{ReadinessProbe: &opstreelabsinv1alpha1.Probe{SuccessThreshold: int32Ptr(3)}}},
+		{name: "liveness success threshold of 1", probes: &opstreelabsinv1alpha1.ProbeConfig{LivenessProbe: &opstreelabsinv1alpha1.Probe{SuccessThreshold: int32Ptr(1)}}},
+		{name: "liveness success threshold", probes: &opstreelabsinv1alpha1.ProbeConfig{LivenessProbe: &opstreelabsinv1alpha1.Probe{SuccessThreshold: int32Ptr(3)}}, wantErr: true},
+		{name: "startup success threshold", probes: &opstreelabsinv1alpha1.ProbeConfig{StartupProbe: &opstreelabsinv1alpha1.Probe{SuccessThreshold: int32Ptr(2)}}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateProbes(test.probes); (err != nil) != test.wantErr {
+				t.Errorf("validateProbes() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetTerminationMessagePath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "default path", want: corev1.TerminationMessagePathDefault},
+		{name: "custom path", path: "/data/db/termination-log", want: "/data/db/termination-log"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getTerminationMessagePath(test.path); got != test.want {
+				t.Errorf("getTerminationMessagePath() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetTerminationMessagePolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy corev1.TerminationMessagePolicy
+		want   corev1.TerminationMessagePolicy
+	}{
+		{name: "default policy", want: defaultTerminationMessagePolicy},
+		{name: "custom policy", policy: corev1.TerminationMessageReadFile, want: corev1.TerminationMessageReadFile},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getTerminationMessagePolicy(test.policy); got != test.want {
+				t.Errorf("getTerminationMessagePolicy() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateDBPath(t *testing.T) {
+	tests := []struct {
+		name    string
+		dbPath  string
+		wantErr bool
+	}{
+		{name: "default data directory"},
+		{name: "custom data directory", dbPath: "/var/lib/mongodb"},
+		{name: "relative path", dbPath: "data/db", wantErr: true},
+		{name: "unclean path", dbPath: "/var/lib/mongodb/", wantErr: true},
+		{name: "root directory", dbPath: "/", wantErr: true},
+		{name: "temporary directory", dbPath: "/tmp", wantErr: true},
+		{name: "restore directory", dbPath: restoreMountPath, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateDBPath(test.dbPath); (err != nil) != test.wantErr {
+				t.Errorf("validateDBPath() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateContainerDefDBPath(t *testing.T) {
+	tests := []struct {
+		name          string
+		dbPath        string
+		extraArgs     []string
+		wantMountPath string
+		wantArg       string
+	}{
+		{name: "default data directory", wantMountPath: defaultDBPath},
+		{name: "custom data directory", dbPath: "/var/lib/mongodb", wantMountPath: "/var/lib/mongodb", wantArg: "--dbpath=/var/lib/mongodb"},
+		{name: "explicit dbpath argument", dbPath: "/var/lib/mongodb", extraArgs: []string{"--dbpath", "/var/lib/mongodb"}, wantMountPath: "/var/lib/mongodb"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			container := generateContainerDef("mongodb", containerParameters{Image: "mongo:4.4", DBPath: test.dbPath, ExtraArgs: test.extraArgs})[0]
+			if container.VolumeMounts[0].Name != "mongodb" || container.VolumeMounts[0].MountPath != test.wantMountPath {
+				t.Errorf("generateContainerDef() data volume mount = %v, want %s", container.VolumeMounts[0], test.wantMountPath)
+			}
+			if containsArg(container.Args, "--dbpath=/var/lib/mongodb") != (test.wantArg != "") {
+				t.Errorf("generateContainerDef() args = %v, want dbpath argument %q", container.Args, test.wantArg)
+			}
+		})
+	}
+}
+
+func TestGetReadinessScriptProbe(t *testing.T) {
+	tests := []struct {
+		name        string
+		script      *opstreelabsinv1alpha1.ReadinessScriptConfig
+		wantCommand []string
+	}{
+		{name: "no readiness script", wantCommand: getMongoDBProbe(mongoDBPort).Handler.Exec.Command},
+		{name: "default key and mount path", script: &opstreelabsinv1alpha1.ReadinessScriptConfig{ConfigMapName: "mongodb-probes"}, wantCommand: []string{"/bin/sh", defaultReadinessScriptMountPath + "/" + defaultReadinessScriptKey}},
+		{name: "custom key and mount path", script: &opstreelabsinv1alpha1.ReadinessScriptConfig{ConfigMapName: "mongodb-probes", Key: "ready.sh", MountPath: "/opt/probes/"}, wantCommand: []string{"/bin/sh", "/opt/probes/ready.sh"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			probe := getReadinessScriptProbe(getMongoDBProbe(mongoDBPort), test.script)
+			if !reflect.DeepEqual(probe.Handler.Exec.Command, test.wantCommand) {
+				t.Errorf("getReadinessScriptProbe() command = %v, want %v", probe.Handler.Exec.Command, test.wantCommand)
+			}
+			if probe.PeriodSeconds != getMongoDBProbe(mongoDBPort).PeriodSeconds {
+				t.Errorf("getReadinessScriptProbe() changed the timings of the probe")
+			}
+		})
+	}
+}
+
+func TestValidateReadinessScript(t *testing.T) {
+	script := func(key string, mountPath string) *opstreelabsinv1alpha1.ProbeConfig {
+		return &opstreelabsinv1alpha1.ProbeConfig{ReadinessScript: &opstreelabsinv1alpha1.ReadinessScriptConfig{ConfigMapName: "mongodb-probes", Key: key, MountPath: mountPath}}
+	}
+	withHandler := script("", "")
+	withHandler.ReadinessProbe = &opstreelabsinv1alpha1.Probe{Handler: corev1.Handler{TCPSocket: &corev1.TCPSocketAction{}}}
+	withTimings := script("", "")
+	withTimings.ReadinessProbe = &opstreelabsinv1alpha1.Probe{PeriodSeconds: int32Ptr(5)}
+	tests := []struct {
+		name    string
+		probes  *opstreelabsinv1alpha1.ProbeConfig
+		wantErr bool
+	}{
+		{name: "no probes"},
+		{name: "no readiness script", probes: &opstreelabsinv1alpha1.ProbeConfig{}},
+		{name: "defaults", probes: script("", "")},
+		{name: "custom timings of the readiness probe", probes: withTimings},
+		{name: "readiness probe handler", probes: withHandler, wantErr: true},
+		{name: "key with a directory", probes: script("probes/ready.sh", ""), wantErr: true},
+		{name: "root mount path", probes: script("", "/"), wantErr: true},
+		{name: "data directory mount path", probes: script("", "/data/db"), wantErr: true},
+		{name: "mount path inside the data directory", probes: script("", "/data/db/probes"), wantErr: true},
+		{name: "mount path containing the data directory", probes: script("", "/data"), wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateReadinessScript(test.probes, defaultDBPath); (err != nil) != test.wantErr {
+				t.Errorf("validateReadinessScript() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetMonitoringResources(t *testing.T) {
+	custom := &corev1.ResourceRequirements{Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")}}
+	tests := []struct {
+		name      string
+		resources *corev1.ResourceRequirements
+		want      corev1.ResourceRequirements
+	}{
+		{name: "default resources", want: defaultMonitoringResources},
+		{name: "custom resources", resources: custom, want: *custom},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := getMonitoringResources(test.resources)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getMonitoringResources() = %v, want %v", got, test.want)
+			}
+			// the defaults are shared by every exporter, so they must not be modified through the returned resources
+			if test.resources == nil {
+				got.Limits[corev1.ResourceMemory] = resource.MustParse("1Gi")
+				if reflect.DeepEqual(got, defaultMonitoringResources) {
+					t.Errorf("getMonitoringResources() returns the shared default resources")
+				}
+			}
+		})
+	}
+}
+
+func TestValidateMonitoringResources(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources *corev1.ResourceRequirements
+		wantErr   bool
+	}{
+		{name: "default resources"},
+		{name: "requests within limits", resources: &corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")}, Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}}},
+		{name: "requests without limits", resources: &corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")}}},
+		{name: "negative request", resources: &corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("-50m")}}, wantErr: true},
+		{name: "request above limit", resources: &corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")}, Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")}}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			monitoring := &opstreelabsinv1alpha1.MongoDBMonitoring{Resources: test.resources}
+			if err := validateMonitoringResources(monitoring); (err != nil) != test.wantErr {
+				t.Errorf("validateMonitoringResources() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetMongoDBBindIPArgs(t *testing.T) {
+	tests := []struct {
+		name          string
+		bindIP        []string
+		customCommand bool
+		want          []string
+	}{
+		{name: "entrypoint binds all the interfaces"},
+		{name: "custom command binds all the interfaces", customCommand: true, want: []string{"--bind_ip_all"}},
+		{name: "bind ip", bindIP: []string{"10.0.0.10"}, want: []string{"--bind_ip=localhost,10.0.0.10,$(POD_IP)"}},
+		{name: "bind ip without duplicates", bindIP: []string{"$(POD_IP)", "localhost", "mongodb.example.org"}, want: []string{"--bind_ip=localhost,mongodb.example.org,$(POD_IP)"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getMongoDBBindIPArgs(test.bindIP, test.customCommand); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getMongoDBBindIPArgs() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateBindIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *opstreelabsinv1alpha1.MongoDBConfig
+		wantErr bool
+	}{
+		{name: "no bind ip", config: &opstreelabsinv1alpha1.MongoDBConfig{ExtraArgs: []string{"--bind_ip_all"}}},
+		{name: "ip addresses", config: &opstreelabsinv1alpha1.MongoDBConfig{BindIP: []string{"10.0.0.10", "::1", "$(POD_IP)"}}},
+		{name: "hostname", config: &opstreelabsinv1alpha1.MongoDBConfig{BindIP: []string{"mongodb.example.org"}}},
+		{name: "invalid address", config: &opstreelabsinv1alpha1.MongoDBConfig{BindIP: []string{"10.0.0.10; rm -rf /"}}, wantErr: true},
+		{name: "bind ip in extra args", config: &opstreelabsinv1alpha1.MongoDBConfig{BindIP: []string{"10.0.0.10"}, ExtraArgs: []string{"--bind_ip=0.0.0.0"}}, wantErr: true},
+		{name: "bind ip all in extra args", config: &opstreelabsinv1alpha1.MongoDBConfig{BindIP: []string{"10.0.0.10"}, ExtraArgs: []string{"--bind_ip_all"}}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateBindIP(test.config); (err != nil) != test.wantErr {
+				t.Errorf("validateBindIP() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateExtraPorts(t *testing.T) {
+	tests := []struct {
+		name             string
+		kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig
+		wantErr          bool
+	}{
+		{name: "no extra ports"},
+		{name: "extra ports", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{ExtraPorts: []corev1.ContainerPort{{Name: "sidecar", ContainerPort: 8080}}, ExtraServicePorts: []corev1.ServicePort{{Name: "sidecar", Port: 8080}}}},
+		{name: "invalid port name", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{ExtraPorts: []corev1.ContainerPort{{Name: "sidecar_port", ContainerPort: 8080}}}, wantErr: true},
+		{name: "mongo port name", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{ExtraPorts: []corev1.ContainerPort{{Name: "mongo", ContainerPort: 8080}}}, wantErr: true},
+		{name: "mongo port number", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{ExtraPorts: []corev1.ContainerPort{{Name: "sidecar", ContainerPort: mongoDBPort}}}, wantErr: true},
+		{name: "exporter port number", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{ExtraPorts: []corev1.ContainerPort{{Name: "sidecar", ContainerPort: mongoDBMonitoringPort}}}, wantErr: true},
+		{name: "duplicate extra ports", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{ExtraPorts: []corev1.ContainerPort{{Name: "sidecar", ContainerPort: 8080}, {Name: "admin", ContainerPort: 8080}}}, wantErr: true},
+		{name: "unnamed service port", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{ExtraServicePorts: []corev1.ServicePort{{Port: 8080}}}, wantErr: true},
+		{name: "mongo service port number", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{ExtraServicePorts: []corev1.ServicePort{{Name: "sidecar", Port: mongoDBPort}}}, wantErr: true},
+		{name: "service port with node port", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{ExtraServicePorts: []corev1.ServicePort{{Name: "sidecar", Port: 8080, NodePort: 30080}}}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateExtraPorts(test.kubernetesConfig, mongoDBPort, true); (err != nil) != test.wantErr {
+				t.Errorf("validateExtraPorts() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateOplogMinRetention(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *opstreelabsinv1alpha1.MongoDBConfig
+		wantErr bool
+	}{
+		{name: "no mongodb config"},
+		{name: "retention unset", config: &opstreelabsinv1alpha1.MongoDBConfig{StorageEngine: storageEngineInMemory}},
+		{name: "retention", config: &opstreelabsinv1alpha1.MongoDBConfig{OplogMinRetentionHours: int32Ptr(24)}},
+		{name: "zero retention with in-memory storage engine", config: &opstreelabsinv1alpha1.MongoDBConfig{OplogMinRetentionHours: int32Ptr(0), StorageEngine: storageEngineInMemory}},
+		{name: "negative retention", config: &opstreelabsinv1alpha1.MongoDBConfig{OplogMinRetentionHours: int32Ptr(-1)}, wantErr: true},
+		{name: "retention with in-memory storage engine", config: &opstreelabsinv1alpha1.MongoDBConfig{OplogMinRetentionHours: int32Ptr(24), StorageEngine: storageEngineInMemory}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateOplogMinRetention(test.config); (err != nil) != test.wantErr {
+				t.Errorf("validateOplogMinRetention() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetMongoDBArgsOplogMinRetention(t *testing.T) {
+	tests := []struct {
+		name         string
+		params       containerParameters
+		wantArgs     []string
+		unwantedArgs []string
+	}{
+		{
+			name:         "retention unset",
+			params:       containerParameters{Port: mongoDBPort},
+			unwantedArgs: []string{"--oplogMinRetentionHours=0"},
+		},
+		{
+			name:     "retention",
+			params:   containerParameters{Port: mongoDBPort, OplogMinRetentionHours: 24},
+			wantArgs: []string{"--oplogMinRetentionHours=24"},
+		},
+		{
+			name:         "explicit retention takes precedence",
+			params:       containerParameters{Port: mongoDBPort, OplogMinRetentionHours: 24, ExtraArgs: []string{"--oplogMinRetentionHours=48"}},
+			wantArgs:     []string{"--oplogMinRetentionHours=48"},
+			unwantedArgs: []string{"--oplogMinRetentionHours=24"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args := getMongoDBArgs(test.params)
+			for _, want := range test.wantArgs {
+				if !containsArg(args, want) {
+					t.Errorf("getMongoDBArgs() = %v, missing %s", args, want)
+				}
+			}
+			for _, unwanted := range test.unwantedArgs {
+				if containsArg(args, unwanted) {
+					t.Errorf("getMongoDBArgs() = %v, unexpected %s", args, unwanted)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package k8sgo
+
+import (
+	"context"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// cronJobParameters is the input struct for MongoDB cronjob
+type cronJobParameters struct {
+	CronJobMeta        metav1.ObjectMeta
+	OwnerDef           metav1.OwnerReference
+	Namespace          string
+	Labels             map[string]string
+	Schedule           string
+	Container          corev1.Container
+	ImagePullSecrets   []string
+	NodeSelector       map[string]string
+	Tolerations        *[]corev1.Toleration
+	ServiceAccountName string
+	SecurityContext    *corev1.PodSecurityContext
+}
+
+// CreateOrUpdateCronJob method will create or update MongoDB cronjob
+func CreateOrUpdateCronJob(params cronJobParameters) error {
+	logger := logGenerator(params.CronJobMeta.Name, params.Namespace, "CronJob")
+	cronJobDef := generateCronJobDef(params)
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	storedCronJob, err := client.BatchV1().CronJobs(params.Namespace).Get(context.TODO(), params.CronJobMeta.Name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "MongoDB cronjob get action failed")
+			return err
+		}
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(cronJobDef); err != nil {
+			logger.Error(err, "Unable to patch MongoDB cronjob with comparison object")
+			return err
+		}
+		_, err = client.BatchV1().CronJobs(params.Namespace).Create(context.TODO(), cronJobDef, metav1.CreateOptions{})
+		if err != nil {
+			logger.Error(err, "MongoDB cronjob creation failed")
+			return err
+		}
+		logger.Info("MongoDB cronjob successfully created")
+		return nil
+	}
+	cronJobDef.ResourceVersion = storedCronJob.ResourceVersion
+	cronJobDef.CreationTimestamp = storedCronJob.CreationTimestamp
+	cronJobDef.ManagedFields = storedCronJob.ManagedFields
+	patchResult, err := patch.DefaultPatchMaker.Calculate(storedCronJob, cronJobDef,
+		patch.IgnoreStatusFields(),
+		patch.IgnoreField("kind"),
+		patch.IgnoreField("apiVersion"),
+	)
+	if err != nil {
+		logger.Error(err, "Unable to patch MongoDB cronjob with comparison object")
+		return err
+	}
+	if patchResult.IsEmpty() {
+		logger.Info("MongoDB cronjob is already in-sync")
+		return nil
+	}
+	logger.Info("Changes in CronJob detected, updating...", "patch", string(patchResult.Patch))
+	cronJobDef.Annotations = preserveExternalAnnotations(storedCronJob.Annotations, cronJobDef.Annotations)
+	if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(cronJobDef); err != nil {
+		logger.Error(err, "Unable to patch MongoDB cronjob with comparison object")
+		return err
+	}
+	_, err = client.BatchV1().CronJobs(params.Namespace).Update(context.TODO(), cronJobDef, metav1.UpdateOptions{})
+	if err != nil {
+		logger.Error(err, "MongoDB cronjob update failed")
+		return err
+	}
+	logger.Info("MongoDB cronjob successfully updated")
+	return nil
+}
+
+// deleteCronJob is a method to delete cronjob in Kubernetes
+func deleteCronJob(namespace string, name string) error {
+	logger := logGenerator(name, namespace, "CronJob")
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	propagation := metav1.DeletePropagationBackground
+	err = client.BatchV1().CronJobs(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		logger.Error(err, "MongoDB cronjob deletion failed")
+		return err
+	}
+	logger.Info("MongoDB cronjob successfully deleted")
+	return nil
+}
+
+// generateCronJobDef is a method to generate cronjob definition, the jobs never run concurrently and are not retried
+func generateCronJobDef(params cronJobParameters) *batchv1.CronJob {
+	backoffLimit := int32(0)
+	cronJob := &batchv1.CronJob{
+		TypeMeta:   generateMetaInformation("CronJob", "batch/v1"),
+		ObjectMeta: params.CronJobMeta,
+		Spec: batchv1.CronJobSpec{
+			Schedule:          params.Schedule,
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: params.Labels},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &backoffLimit,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: params.Labels},
+						Spec: corev1.PodSpec{
+							Containers:         []corev1.Container{params.Container},
+							RestartPolicy:      corev1.RestartPolicyNever,
+							NodeSelector:       params.NodeSelector,
+							ServiceAccountName: params.ServiceAccountName,
+							SecurityContext:    params.SecurityContext,
+						},
+					},
+				},
+			},
+		},
+	}
+	if params.Tolerations != nil {
+		cronJob.Spec.JobTemplate.Spec.Template.Spec.Tolerations = *params.Tolerations
+	}
+	if params.SecurityContext == nil {
+		cronJob.Spec.JobTemplate.Spec.Template.Spec.SecurityContext = getDefaultPodSecurityContext()
+	}
+	for _, secret := range params.ImagePullSecrets {
+		cronJob.Spec.JobTemplate.Spec.Template.Spec.ImagePullSecrets = append(cronJob.Spec.JobTemplate.Spec.Template.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: secret})
+	}
+	AddOwnerRefToObject(cronJob, params.OwnerDef)
+	return cronJob
+}
@@ -0,0 +1,183 @@
+package k8sgo
+
+import (
+	"context"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// deploymentParameters is the input struct for MongoDB deployment
+type deploymentParameters struct {
+	DeploymentMeta     metav1.ObjectMeta
+	OwnerDef           metav1.OwnerReference
+	Namespace          string
+	Labels             map[string]string
+	Annotations        map[string]string
+	Replicas           *int32
+	Containers         []corev1.Container
+	InitContainers     []corev1.Container
+	Volumes            []corev1.Volume
+	ImagePullSecrets   []string
+	Affinity           *corev1.Affinity
+	NodeSelector       map[string]string
+	Tolerations        *[]corev1.Toleration
+	PriorityClassName  string
+	SchedulerName      string
+	RuntimeClassName   *string
+	ServiceAccountName string
+	SecurityContext    *corev1.PodSecurityContext
+}
+
+// CreateOrUpdateDeployment method will create or update MongoDB deployment
+func CreateOrUpdateDeployment(params deploymentParameters) error {
+	logger := logGenerator(params.DeploymentMeta.Name, params.Namespace, "Deployment")
+	deploymentDef := generateDeploymentDef(params)
+	storedDeployment, err := GetDeployment(params.Namespace, params.DeploymentMeta.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(deploymentDef); err != nil {
+				logger.Error(err, "Unable to patch MongoDB deployment with comparison object")
+				return err
+			}
+			return createDeployment(params.Namespace, deploymentDef)
+		}
+		return err
+	}
+	return patchDeployment(storedDeployment, deploymentDef, params.Namespace)
+}
+
+// patchDeployment will patch MongoDB deployment
+func patchDeployment(storedDeployment *appsv1.Deployment, newDeployment *appsv1.Deployment, namespace string) error {
+	logger := logGenerator(storedDeployment.Name, namespace, "Deployment")
+	newDeployment.ResourceVersion = storedDeployment.ResourceVersion
+	newDeployment.CreationTimestamp = storedDeployment.CreationTimestamp
+	newDeployment.ManagedFields = storedDeployment.ManagedFields
+
+	patchResult, err := patch.DefaultPatchMaker.Calculate(storedDeployment, newDeployment,
+		patch.IgnoreStatusFields(),
+		patch.IgnoreField("kind"),
+		patch.IgnoreField("apiVersion"),
+	)
+	if err != nil {
+		logger.Error(err, "Unable to patch MongoDB deployment with comparison object")
+		return err
+	}
+	if !patchResult.IsEmpty() {
+		logger.Info("Changes in Deployment detected, updating...", "patch", string(patchResult.Patch))
+		newDeployment.Annotations = preserveExternalAnnotations(storedDeployment.Annotations, newDeployment.Annotations)
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(newDeployment); err != nil {
+			logger.Error(err, "Unable to patch MongoDB deployment with comparison object")
+			return err
+		}
+		return updateDeployment(namespace, newDeployment)
+	}
+	logger.Info("MongoDB deployment is already in-sync")
+	return nil
+}
+
+// createDeployment is a method to create deployment in Kubernetes
+func createDeployment(namespace string, deployment *appsv1.Deployment) error {
+	logger := logGenerator(deployment.Name, namespace, "Deployment")
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	_, err = client.AppsV1().Deployments(namespace).Create(context.TODO(), deployment, metav1.CreateOptions{})
+	if err != nil {
+		logger.Error(err, "MongoDB deployment creation failed")
+		return err
+	}
+	logger.Info("MongoDB deployment successfully created")
+	return nil
+}
+
+// updateDeployment is a method to update deployment in Kubernetes
+func updateDeployment(namespace string, deployment *appsv1.Deployment) error {
+	logger := logGenerator(deployment.Name, namespace, "Deployment")
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	_, err = client.AppsV1().Deployments(namespace).Update(context.TODO(), deployment, metav1.UpdateOptions{})
+	if err != nil {
+		logger.Error(err, "MongoDB deployment update failed")
+		return err
+	}
+	logger.Info("MongoDB deployment successfully updated")
+	return nil
+}
+
+// GetDeployment is a method to get deployment in Kubernetes
+func GetDeployment(namespace string, deployment string) (*appsv1.Deployment, error) {
+	logger := logGenerator(deployment, namespace, "Deployment")
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return nil, err
+	}
+	deploymentInfo, err := client.AppsV1().Deployments(namespace).Get(context.TODO(), deployment, metav1.GetOptions{})
+	if err != nil {
+		logger.Info("MongoDB deployment get action failed")
+		return nil, err
+	}
+	logger.Info("MongoDB deployment get action was successful")
+	return deploymentInfo, nil
+}
+
+// isDeploymentRolledOut is a method to check if the deployment controller observed the latest spec and all desired pods are updated and ready
+func isDeploymentRolledOut(deployment *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return deployment.Status.ObservedGeneration >= deployment.Generation &&
+		deployment.Status.UpdatedReplicas == replicas &&
+		deployment.Status.ReadyReplicas == replicas &&
+		deployment.Status.Replicas == replicas
+}
+
+// generateDeploymentDef is a method to generate deployment definition
+func generateDeploymentDef(params deploymentParameters) *appsv1.Deployment {
+	deployment := &appsv1.Deployment{
+		TypeMeta:   generateMetaInformation("Deployment", "apps/v1"),
+		ObjectMeta: params.DeploymentMeta,
+		Spec: appsv1.DeploymentSpec{
+			Selector: LabelSelectors(params.Labels),
+			Replicas: params.Replicas,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      params.Labels,
+					Annotations: params.Annotations,
+				},
+				Spec: corev1.PodSpec{
+					Containers:         params.Containers,
+					InitContainers:     params.InitContainers,
+					Volumes:            params.Volumes,
+					NodeSelector:       params.NodeSelector,
+					Affinity:           params.Affinity,
+					PriorityClassName:  params.PriorityClassName,
+					SchedulerName:      params.SchedulerName,
+					RuntimeClassName:   params.RuntimeClassName,
+					ServiceAccountName: params.ServiceAccountName,
+					SecurityContext:    params.SecurityContext,
+				},
+			},
+		},
+	}
+	if params.Tolerations != nil {
+		deployment.Spec.Template.Spec.Tolerations = *params.Tolerations
+	}
+	if deployment.Spec.Template.Spec.SecurityContext == nil {
+		deployment.Spec.Template.Spec.SecurityContext = getDefaultPodSecurityContext()
+	}
+	for _, imagePullSecret := range params.ImagePullSecrets {
+		deployment.Spec.Template.Spec.ImagePullSecrets = append(deployment.Spec.Template.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: imagePullSecret})
+	}
+	AddOwnerRefToObject(deployment, params.OwnerDef)
+	return deployment
+}
@@ -0,0 +1,51 @@
+package k8sgo
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func TestIsDeploymentRolledOut(t *testing.T) {
+	replicas := int32(2)
+	tests := []struct {
+		name       string
+		generation int64
+		status     appsv1.DeploymentStatus
+		want       bool
+	}{
+		{
+			name:       "all replicas updated and ready",
+			generation: 3,
+			status:     appsv1.DeploymentStatus{ObservedGeneration: 3, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2},
+			want:       true,
+		},
+		{
+			name:       "single ready replica",
+			generation: 3,
+			status:     appsv1.DeploymentStatus{ObservedGeneration: 3, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 1},
+			want:       false,
+		},
+		{
+			name:       "rollout in progress",
+			generation: 3,
+			status:     appsv1.DeploymentStatus{ObservedGeneration: 3, Replicas: 3, UpdatedReplicas: 1, ReadyReplicas: 2},
+			want:       false,
+		},
+		{
+			name:       "latest spec not observed",
+			generation: 4,
+			status:     appsv1.DeploymentStatus{ObservedGeneration: 3, Replicas: 2, UpdatedReplicas: 2, ReadyReplicas: 2},
+			want:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deployment := &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &replicas}, Status: tt.status}
+			deployment.Generation = tt.generation
+			if got := isDeploymentRolledOut(deployment); got != tt.want {
+				t.Errorf("isDeploymentRolledOut() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
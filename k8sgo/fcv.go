@@ -0,0 +1,144 @@
+package k8sgo
+
+import (
+	"errors"
+	"fmt"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/mongo"
+	"strconv"
+	"strings"
+)
+
+// ErrUpgradeInProgress is returned when the feature compatibility version is not set because members are not upgraded yet
+var ErrUpgradeInProgress = errors.New("feature compatibility version deferred until all members are upgraded")
+
+// ErrFeatureCompatibilityVersionDowngrade is returned when the feature compatibility version is older than the one of the cluster
+var ErrFeatureCompatibilityVersionDowngrade = errors.New("feature compatibility version is never downgraded")
+
+// IsUpgradeInProgress is a method to check if the error is caused by members which are not upgraded yet
+func IsUpgradeInProgress(err error) bool {
+	return errors.Is(err, ErrUpgradeInProgress)
+}
+
+// IsFeatureCompatibilityVersionDowngrade is a method to check if the error is caused by a feature compatibility version older than the one of the cluster
+func IsFeatureCompatibilityVersionDowngrade(err error) bool {
+	return errors.Is(err, ErrFeatureCompatibilityVersionDowngrade)
+}
+
+// ReconcileMongoDBClusterFeatureCompatibilityVersion is a method to set the feature compatibility version of mongodb cluster once every member runs a binary supporting it
+func ReconcileMongoDBClusterFeatureCompatibilityVersion(cr *opstreelabsinv1alpha1.MongoDBCluster) (bool, error) {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Setup")
+	statefulSets := []string{fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")}
+	if isArbiterEnabled(cr) {
+		statefulSets = append(statefulSets, getArbiterName(cr))
+	}
+	for _, name := range statefulSets {
+		statefulSet, err := GetStateFulSet(cr.Namespace, name)
+		if err != nil {
+			return false, err
+		}
+		if !isStatefulSetRolledOut(statefulSet) {
+			logger.Info("Waiting for the rollout before setting the feature compatibility version", "statefulset", name)
+			return false, ErrUpgradeInProgress
+		}
+	}
+	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return false, err
+	}
+	mongoParams := mongogo.MongoDBParameters{
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		Port:      getMongoDBPort(cr.Spec.MongoDBConfig),
+		SetupType: "cluster",
+	}
+	var versions []string
+	for _, member := range getMongoDBClusterMembers(cr, mongoParams) {
+		// buildInfo doesn't require authentication, so arbiters without users can be queried as well
+		memberParams := mongoParams
+		memberParams.MongoURL = fmt.Sprintf("mongodb://%s/", member.Host)
+		version, err := mongogo.GetMongoNodeVersion(memberParams)
+		if err != nil {
+			logger.Error(err, "Unable to get the version of MongoDB member", "member", member.Host)
+			return false, err
+		}
+		versions = append(versions, version)
+	}
+	mongoParams.MongoURL = getMongoDBClusterURL(cr, mongoParams, password)
+	current, err := mongogo.GetMongoFeatureCompatibilityVersion(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to get the feature compatibility version of MongoDB cluster")
+		return false, err
+	}
+	raise, err := shouldSetFeatureCompatibilityVersion(cr.Spec.FeatureCompatibilityVersion, current, versions)
+	if err != nil {
+		logger.Info("Feature compatibility version is not set", "current", current, "reason", err.Error())
+		return false, err
+	}
+	if !raise {
+		return false, nil
+	}
+	major, _, _ := parseMongoDBVersion(cr.Spec.FeatureCompatibilityVersion)
+	err = mongogo.SetMongoFeatureCompatibilityVersion(mongoParams, cr.Spec.FeatureCompatibilityVersion, major >= 7)
+	if err != nil {
+		logger.Error(err, "Unable to set the feature compatibility version of MongoDB cluster")
+		return false, err
+	}
+	logger.Info("Successfully set the feature compatibility version of MongoDB cluster", "from", current, "to", cr.Spec.FeatureCompatibilityVersion)
+	return true, nil
+}
+
+// shouldSetFeatureCompatibilityVersion is a method to check if the feature compatibility version of the cluster must be raised, only once every member is upgraded and never downwards
+func shouldSetFeatureCompatibilityVersion(fcv string, current string, versions []string) (bool, error) {
+	if err := validateFeatureCompatibilityVersion(fcv, versions); err != nil {
+		return false, fmt.Errorf("%w: %v", ErrUpgradeInProgress, err)
+	}
+	if fcv == current {
+		return false, nil
+	}
+	fcvMajor, fcvMinor, _ := parseMongoDBVersion(fcv)
+	major, minor, err := parseMongoDBVersion(current)
+	if err != nil {
+		return false, err
+	}
+	if fcvMajor < major || (fcvMajor == major && fcvMinor < minor) {
+		return false, fmt.Errorf("%w: %s is older than the current version %s", ErrFeatureCompatibilityVersionDowngrade, fcv, current)
+	}
+	return true, nil
+}
+
+// validateFeatureCompatibilityVersion is a method to check that the binary of every member is at least the feature compatibility version
+func validateFeatureCompatibilityVersion(fcv string, versions []string) error {
+	fcvMajor, fcvMinor, err := parseMongoDBVersion(fcv)
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		major, minor, err := parseMongoDBVersion(version)
+		if err != nil {
+			return err
+		}
+		if major < fcvMajor || (major == fcvMajor && minor < fcvMinor) {
+			return fmt.Errorf("member version %s is older than the feature compatibility version %s", version, fcv)
+		}
+	}
+	return nil
+}
+
+// parseMongoDBVersion is a method to parse the major and minor release of MongoDB version
+func parseMongoDBVersion(version string) (int, int, error) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("version %s must be in <major>.<minor> format", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("version %s must be in <major>.<minor> format", version)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("version %s must be in <major>.<minor> format", version)
+	}
+	return major, minor, nil
+}
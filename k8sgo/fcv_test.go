@@ -0,0 +1,64 @@
+package k8sgo
+
+import (
+	"testing"
+)
+
+func TestShouldSetFeatureCompatibilityVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		fcv           string
+		current       string
+		versions      []string
+		want          bool
+		wantUpgrade   bool
+		wantDowngrade bool
+	}{
+		{name: "all members upgraded", fcv: "6.0", current: "5.0", versions: []string{"6.0.5", "6.0.5", "6.0.5"}, want: true},
+		{name: "members on a newer release", fcv: "6.0", current: "5.0", versions: []string{"7.0.2", "6.0.5"}, want: true},
+		{name: "one member not upgraded", fcv: "6.0", current: "5.0", versions: []string{"6.0.5", "5.0.14", "6.0.5"}, wantUpgrade: true},
+		{name: "arbiter not upgraded", fcv: "7.0", current: "6.0", versions: []string{"7.0.2", "7.0.2", "6.0.5"}, wantUpgrade: true},
+		{name: "minor release not upgraded", fcv: "4.4", current: "4.2", versions: []string{"4.4.1", "4.2.9"}, wantUpgrade: true},
+		{name: "already set", fcv: "6.0", current: "6.0", versions: []string{"6.0.5", "6.0.5"}},
+		{name: "downgrade", fcv: "5.0", current: "6.0", versions: []string{"6.0.5", "6.0.5"}, wantDowngrade: true},
+		{name: "minor downgrade", fcv: "4.2", current: "4.4", versions: []string{"4.4.1", "4.4.1"}, wantDowngrade: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := shouldSetFeatureCompatibilityVersion(test.fcv, test.current, test.versions)
+			if got != test.want {
+				t.Errorf("shouldSetFeatureCompatibilityVersion() = %v, want %v", got, test.want)
+			}
+			if IsUpgradeInProgress(err) != test.wantUpgrade || IsFeatureCompatibilityVersionDowngrade(err) != test.wantDowngrade {
+				t.Errorf("shouldSetFeatureCompatibilityVersion() error = %v", err)
+			}
+			if err != nil && !test.wantUpgrade && !test.wantDowngrade {
+				t.Errorf("shouldSetFeatureCompatibilityVersion() unexpected error = %v", err)
+			}
+		})
+	}
+}
+
+func TestParseMongoDBVersion(t *testing.T) {
+	tests := []struct {
+		version   string
+		wantMajor int
+		wantMinor int
+		wantErr   bool
+	}{
+		{version: "6.0", wantMajor: 6, wantMinor: 0},
+		{version: "4.4.18", wantMajor: 4, wantMinor: 4},
+		{version: "7.0.2-rc1", wantMajor: 7, wantMinor: 0},
+		{version: "6", wantErr: true},
+		{version: "latest", wantErr: true},
+		{version: "6.x", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			major, minor, err := parseMongoDBVersion(test.version)
+			if (err != nil) != test.wantErr || major != test.wantMajor || minor != test.wantMinor {
+				t.Errorf("parseMongoDBVersion() = %d, %d, %v, want %d, %d, wantErr %v", major, minor, err, test.wantMajor, test.wantMinor, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package k8sgo
+
+import (
+	"fmt"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"regexp"
+	"strings"
+)
+
+// imageDigestPattern matches the sha256 and sha512 content digests an image can be pinned by
+var imageDigestPattern = regexp.MustCompile(`^(sha256:[a-f0-9]{64}|sha512:[a-f0-9]{128})$`)
+
+// splitImageReference is a method to split the image reference into its name, tag and digest, the tag and digest are empty when they are not set
+func splitImageReference(image string) (string, string, string) {
+	name, digest := image, ""
+	if index := strings.Index(image, "@"); index >= 0 {
+		name, digest = image[:index], image[index+1:]
+	}
+	// a colon before the last slash separates the port of the registry, not the tag
+	if index := strings.LastIndex(name, ":"); index > strings.LastIndex(name, "/") {
+		return name[:index], name[index+1:], digest
+	}
+	return name, "", digest
+}
+
+// enterpriseImageKeywords are the image names of the MongoDB builds shipping the inMemory storage engine and auditing, which the community build lacks
+var enterpriseImageKeywords = []string{"enterprise", "percona"}
+
+// isEnterpriseImage is a method to check if the image is a MongoDB Enterprise or Percona Server for MongoDB build
+func isEnterpriseImage(image string) bool {
+	name, _, _ := splitImageReference(image)
+	// only the repository name counts, not the registry or organization it is pulled from
+	name = strings.ToLower(name[strings.LastIndex(name, "/")+1:])
+	for _, keyword := range enterpriseImageKeywords {
+		if strings.Contains(name, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// isImagePinned is a method to check if the image is pinned by digest or by a tag other than latest
+func isImagePinned(image string) bool {
+	_, tag, digest := splitImageReference(image)
+	return digest != "" || (tag != "" && tag != "latest")
+}
+
+// validateImage is a method to validate that the image has a name and that its digest is a sha256 or sha512 digest
+func validateImage(image string) error {
+	name, _, digest := splitImageReference(image)
+	if name == "" {
+		return fmt.Errorf("image %s must have a name", image)
+	}
+	if strings.Contains(image, "@") && !imageDigestPattern.MatchString(digest) {
+		return fmt.Errorf("image %s must be pinned by a sha256:<64 hex> or sha512:<128 hex> digest", image)
+	}
+	return nil
+}
+
+// getMutableImageWarning is a method to generate the warning for the images which are not pinned by digest or tag in production mode
+func getMutableImageWarning(kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig, monitoring *opstreelabsinv1alpha1.MongoDBMonitoring) string {
+	if !kubernetesConfig.ProductionMode {
+		return ""
+	}
+	images := []string{kubernetesConfig.Image}
+	if monitoring != nil {
+		images = append(images, monitoring.Image)
+	}
+	var mutable []string
+	for _, image := range images {
+		if image != "" && !isImagePinned(image) {
+			mutable = append(mutable, image)
+		}
+	}
+	if len(mutable) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Images %s use a mutable tag in production mode, the pods can run different builds after a restart, pin them by digest with <image>@sha256:<digest>", strings.Join(mutable, ", "))
+}
+
+// CheckMongoClusterImages is a method to warn when the images of mongodb cluster use a mutable tag in production mode
+func CheckMongoClusterImages(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	return getMutableImageWarning(cr.Spec.KubernetesConfig, cr.Spec.MongoDBMonitoring)
+}
+
+// CheckMongoStandaloneImages is a method to warn when the images of mongodb standalone use a mutable tag in production mode
+func CheckMongoStandaloneImages(cr *opstreelabsinv1alpha1.MongoDB) string {
+	return getMutableImageWarning(cr.Spec.KubernetesConfig, cr.Spec.MongoDBMonitoring)
+}
@@ -0,0 +1,77 @@
+package k8sgo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsEnterpriseImage(t *testing.T) {
+	tests := []struct {
+		name  string
+		image string
+		want  bool
+	}{
+		{name: "community image", image: "quay.io/opstree/mongo:v5.0.6", want: false},
+		{name: "official community image", image: "mongo:6.0", want: false},
+		{name: "enterprise image", image: "mongodb/mongodb-enterprise-server:6.0-ubi8", want: true},
+		{name: "percona image", image: "percona/percona-server-mongodb:6.0.4", want: true},
+		{name: "registry is not part of the name", image: "registry.enterprise.local:5000/mongo:6.0", want: false},
+		{name: "tag is not part of the name", image: "mongo:enterprise", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isEnterpriseImage(test.image); got != test.want {
+				t.Errorf("isEnterpriseImage(%q) = %v, want %v", test.image, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSplitImageReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := []struct {
+		name       string
+		image      string
+		wantName   string
+		wantTag    string
+		wantDigest string
+	}{
+		{name: "name only", image: "mongo", wantName: "mongo"},
+		{name: "name and tag", image: "quay.io/opstree/mongo:v5.0.6", wantName: "quay.io/opstree/mongo", wantTag: "v5.0.6"},
+		{name: "name and digest", image: "mongo@" + digest, wantName: "mongo", wantDigest: digest},
+		{name: "name, tag and digest", image: "mongo:6.0@" + digest, wantName: "mongo", wantTag: "6.0", wantDigest: digest},
+		{name: "registry port without tag", image: "registry.local:5000/mongo", wantName: "registry.local:5000/mongo"},
+		{name: "registry port with tag", image: "registry.local:5000/mongo:6.0", wantName: "registry.local:5000/mongo", wantTag: "6.0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name, tag, digest := splitImageReference(test.image)
+			if name != test.wantName || tag != test.wantTag || digest != test.wantDigest {
+				t.Errorf("splitImageReference(%q) = (%q, %q, %q), want (%q, %q, %q)", test.image, name, tag, digest, test.wantName, test.wantTag, test.wantDigest)
+			}
+		})
+	}
+}
+
+func TestIsImagePinned(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := []struct {
+		name  string
+		image string
+		want  bool
+	}{
+		{name: "no tag", image: "mongo", want: false},
+		{name: "latest tag", image: "mongo:latest", want: false},
+		{name: "versioned tag", image: "mongo:6.0", want: true},
+		{name: "digest", image: "mongo@" + digest, want: true},
+		{name: "latest tag with digest", image: "mongo:latest@" + digest, want: true},
+		{name: "registry port without tag", image: "registry.local:5000/mongo", want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isImagePinned(test.image); got != test.want {
+				t.Errorf("isImagePinned(%q) = %v, want %v", test.image, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package k8sgo
+
+import (
+	"context"
+	"fmt"
+	"github.com/thanhpk/randstr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"path"
+)
+
+const (
+	keyFileContainerName    = "keyfile"
+	keyFileVolumeName       = "keyfile"
+	keyFileSecretVolumeName = "keyfile-secret"
+	keyFileMountPath        = "/etc/mongodb/keyfile"
+	keyFileSecretMountPath  = "/etc/mongodb/keyfile-secret"
+	keyFileSecretKey        = "keyfile"
+	// keyFileLength is within the 6 to 1024 base64 characters mongod accepts for a keyFile
+	keyFileLength = 756
+)
+
+// getKeyFileSecretName is a method to get the name of the secret holding the keyFile shared by the members
+func getKeyFileSecretName(name string) string {
+	return fmt.Sprintf("%s-keyfile", name)
+}
+
+// getKeyFilePath is a method to get the path of the keyFile passed to mongod and mongos
+func getKeyFilePath() string {
+	return path.Join(keyFileMountPath, keyFileSecretKey)
+}
+
+// createKeyFileSecret is a method to create the keyFile secret for the internal authentication of the members, an existing keyFile is never rotated
+func createKeyFileSecret(name string, namespace string, labels map[string]string, owner metav1.OwnerReference) error {
+	secretName := getKeyFileSecretName(name)
+	logger := logGenerator(secretName, namespace, "Secret")
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	_, err = client.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !errors.IsNotFound(err) {
+		return err
+	}
+	secret := &corev1.Secret{
+		TypeMeta:   generateMetaInformation("Secret", "v1"),
+		ObjectMeta: generateObjectMetaInformation(secretName, namespace, labels, generateAnnotations()),
+		Data: map[string][]byte{
+			keyFileSecretKey: []byte(randstr.String(keyFileLength)),
+		},
+	}
+	AddOwnerRefToObject(secret, owner)
+	if _, err := client.CoreV1().Secrets(namespace).Create(context.TODO(), secret, metav1.CreateOptions{}); err != nil {
+		logger.Error(err, "MongoDB keyfile secret creation is failed")
+		return err
+	}
+	logger.Info("MongoDB keyfile secret creation is successful")
+	return nil
+}
+
+// getKeyFileVolumes is a method to get the volumes of the keyFile, the secret is copied to an in-memory volume by the keyfile init container
+func getKeyFileVolumes(secretName string) []corev1.Volume {
+	mode := defaultProjectedMode
+	return []corev1.Volume{
+		{
+			Name: keyFileSecretVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: secretName, DefaultMode: &mode},
+			},
+		},
+		{
+			Name:         keyFileVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+		},
+	}
+}
+
+// getKeyFileVolumeMount is a method to mount the keyFile copied by the keyfile init container
+func getKeyFileVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{Name: keyFileVolumeName, MountPath: keyFileMountPath, ReadOnly: true}
+}
+
+// generateKeyFileInitContainer is a method to generate the init container copying the keyFile, the kubelet makes secret files group readable with an fsGroup, which mongod rejects
+func generateKeyFileInitContainer(image string) corev1.Container {
+	return corev1.Container{
+		Name:            keyFileContainerName,
+		Image:           getInitImage(image),
+		ImagePullPolicy: getImagePullPolicy(getInitImage(image), ""),
+		Command:         []string{"/bin/sh", "-c", fmt.Sprintf("cp %s %s && chmod 0400 %[2]s", path.Join(keyFileSecretMountPath, keyFileSecretKey), getKeyFilePath())},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: keyFileSecretVolumeName, MountPath: keyFileSecretMountPath, ReadOnly: true},
+			{Name: keyFileVolumeName, MountPath: keyFileMountPath},
+		},
+		SecurityContext: getDefaultContainerSecurityContext(),
+	}
+}
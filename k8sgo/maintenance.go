@@ -0,0 +1,96 @@
+package k8sgo
+
+import (
+	"errors"
+	"fmt"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"time"
+)
+
+// ErrUpdateDeferred is returned when a disruptive change is skipped because the maintenance window is closed
+var ErrUpdateDeferred = errors.New("disruptive change deferred until the next maintenance window")
+
+// IsUpdateDeferred is a method to check if the error is caused by a closed maintenance window
+func IsUpdateDeferred(err error) bool {
+	return errors.Is(err, ErrUpdateDeferred)
+}
+
+// validateMaintenanceWindow is a method to validate the start time and duration of maintenance window
+func validateMaintenanceWindow(window *opstreelabsinv1alpha1.MaintenanceWindow) error {
+	if window == nil {
+		return nil
+	}
+	if _, err := time.Parse("15:04", window.StartTime); err != nil {
+		return fmt.Errorf("maintenanceWindow startTime %s must be in HH:MM format", window.StartTime)
+	}
+	if window.Duration.Duration <= 0 || window.Duration.Duration > 24*time.Hour {
+		return fmt.Errorf("maintenanceWindow duration must be greater than 0 and at most 24h")
+	}
+	for _, day := range window.Days {
+		if _, ok := getWeekday(day); !ok {
+			return fmt.Errorf("maintenanceWindow day %s is not a valid weekday", day)
+		}
+	}
+	return nil
+}
+
+// isInMaintenanceWindow is a method to check if disruptive changes are allowed at the given time, no window allows them anytime
+func isInMaintenanceWindow(window *opstreelabsinv1alpha1.MaintenanceWindow, now time.Time) bool {
+	if window == nil {
+		return true
+	}
+	now = now.UTC()
+	// a window opened on the previous day can still be open after midnight
+	for _, offset := range []int{-1, 0} {
+		start, ok := getMaintenanceWindowStart(window, now.AddDate(0, 0, offset))
+		if ok && !now.Before(start) && now.Before(start.Add(window.Duration.Duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMaintenanceWindowDelay is a method to get the time until the maintenance window opens, a short delay is used if it is already open
+func GetMaintenanceWindowDelay(window *opstreelabsinv1alpha1.MaintenanceWindow, now time.Time) time.Duration {
+	if isInMaintenanceWindow(window, now) {
+		return time.Second * 10
+	}
+	now = now.UTC()
+	for offset := 0; offset <= 7; offset++ {
+		start, ok := getMaintenanceWindowStart(window, now.AddDate(0, 0, offset))
+		if ok && start.After(now) {
+			return start.Sub(now)
+		}
+	}
+	return 24 * time.Hour
+}
+
+// getMaintenanceWindowStart is a method to get the opening time of maintenance window on the day, false if it does not open that day
+func getMaintenanceWindowStart(window *opstreelabsinv1alpha1.MaintenanceWindow, day time.Time) (time.Time, bool) {
+	startTime, err := time.Parse("15:04", window.StartTime)
+	if err != nil {
+		return time.Time{}, false
+	}
+	if len(window.Days) > 0 {
+		allowed := false
+		for _, name := range window.Days {
+			if weekday, ok := getWeekday(name); ok && weekday == day.Weekday() {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return time.Time{}, false
+		}
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), startTime.Hour(), startTime.Minute(), 0, 0, time.UTC), true
+}
+
+// getWeekday is a method to parse the weekday name used in maintenance window
+func getWeekday(name string) (time.Weekday, bool) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if weekday.String() == name {
+			return weekday, true
+		}
+	}
+	return time.Sunday, false
+}
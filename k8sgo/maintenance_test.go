@@ -0,0 +1,68 @@
+package k8sgo
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+// monday is a Monday in UTC the maintenance windows are tested against
+var monday = time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)
+
+func TestIsInMaintenanceWindow(t *testing.T) {
+	nightly := &opstreelabsinv1alpha1.MaintenanceWindow{StartTime: "22:00", Duration: metav1.Duration{Duration: 3 * time.Hour}}
+	weekly := &opstreelabsinv1alpha1.MaintenanceWindow{StartTime: "22:00", Duration: metav1.Duration{Duration: 3 * time.Hour}, Days: []string{"Monday"}}
+	tests := []struct {
+		name   string
+		window *opstreelabsinv1alpha1.MaintenanceWindow
+		now    time.Time
+		want   bool
+	}{
+		{name: "no window", now: monday.Add(12 * time.Hour), want: true},
+		{name: "before the window", window: nightly, now: monday.Add(21*time.Hour + 59*time.Minute)},
+		{name: "window opens", window: nightly, now: monday.Add(22 * time.Hour), want: true},
+		{name: "after midnight", window: nightly, now: monday.Add(24*time.Hour + 30*time.Minute), want: true},
+		{name: "window closes", window: nightly, now: monday.Add(25 * time.Hour)},
+		{name: "other time zone", window: nightly, now: monday.Add(23 * time.Hour).In(time.FixedZone("UTC+2", 2*60*60)), want: true},
+		{name: "allowed day", window: weekly, now: monday.Add(23 * time.Hour), want: true},
+		{name: "allowed day after midnight", window: weekly, now: monday.Add(24*time.Hour + 30*time.Minute), want: true},
+		{name: "other day", window: weekly, now: monday.Add(-time.Hour)},
+		{name: "other day at the start time", window: weekly, now: monday.Add(46 * time.Hour)},
+	}
+	if monday.Weekday() != time.Monday {
+		t.Fatalf("%s is a %s", monday, monday.Weekday())
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isInMaintenanceWindow(test.window, test.now); got != test.want {
+				t.Errorf("isInMaintenanceWindow() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetMaintenanceWindowDelay(t *testing.T) {
+	nightly := &opstreelabsinv1alpha1.MaintenanceWindow{StartTime: "22:00", Duration: metav1.Duration{Duration: 3 * time.Hour}}
+	weekly := &opstreelabsinv1alpha1.MaintenanceWindow{StartTime: "22:00", Duration: metav1.Duration{Duration: 3 * time.Hour}, Days: []string{"Monday"}}
+	tests := []struct {
+		name   string
+		window *opstreelabsinv1alpha1.MaintenanceWindow
+		now    time.Time
+		want   time.Duration
+	}{
+		{name: "no window", now: monday, want: 10 * time.Second},
+		{name: "window open", window: nightly, now: monday.Add(24 * time.Hour), want: 10 * time.Second},
+		{name: "later today", window: nightly, now: monday.Add(12 * time.Hour), want: 10 * time.Hour},
+		{name: "tomorrow", window: nightly, now: monday.Add(25 * time.Hour), want: 21 * time.Hour},
+		{name: "next week", window: weekly, now: monday.Add(25 * time.Hour), want: 6*24*time.Hour + 21*time.Hour},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := GetMaintenanceWindowDelay(test.window, test.now); got != test.want {
+				t.Errorf("GetMaintenanceWindowDelay() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
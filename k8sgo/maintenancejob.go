@@ -0,0 +1,144 @@
+package k8sgo
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/mongo"
+	"strings"
+)
+
+// isMaintenanceJobEnabled is a method to check if the maintenance cronjob of mongodb cluster is enabled
+func isMaintenanceJobEnabled(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	return cr.Spec.MaintenanceJob != nil && cr.Spec.MaintenanceJob.Enabled
+}
+
+// getMaintenanceJobName is a method to get the name of the maintenance cronjob of mongodb cluster
+func getMaintenanceJobName(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	return fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-maintenance")
+}
+
+// CreateMongoClusterMaintenanceJob is a method to create or delete the cronjob running maintenance commands on the secondaries of mongodb cluster
+func CreateMongoClusterMaintenanceJob(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "CronJob")
+	if !isMaintenanceJobEnabled(cr) {
+		return deleteCronJob(cr.Namespace, getMaintenanceJobName(cr))
+	}
+	if err := validateMaintenanceJob(cr.Spec.MaintenanceJob); err != nil {
+		logger.Error(err, "Invalid maintenanceJob for cluster MongoDB")
+		return err
+	}
+	err := CreateOrUpdateCronJob(getMaintenanceJobParams(cr))
+	if err != nil {
+		logger.Error(err, "Cannot create maintenance CronJob for MongoDB cluster")
+		return err
+	}
+	return nil
+}
+
+// validateMaintenanceJob is a method to validate the schedule and target collections of maintenance job
+func validateMaintenanceJob(job *opstreelabsinv1alpha1.MongoDBMaintenanceJob) error {
+	if len(strings.Fields(job.Schedule)) != 5 && !strings.HasPrefix(job.Schedule, "@") {
+		return fmt.Errorf("maintenanceJob schedule %s must be a cron expression", job.Schedule)
+	}
+	if job.Command != "compact" && job.Command != "reIndex" {
+		return fmt.Errorf("maintenanceJob command %s must be compact or reIndex", job.Command)
+	}
+	if len(job.Collections) == 0 {
+		return fmt.Errorf("maintenanceJob requires at least one collection")
+	}
+	for _, collection := range job.Collections {
+		if !isCollectionNamespace(collection) {
+			return fmt.Errorf("maintenanceJob collection %s must be in <database>.<collection> format", collection)
+		}
+	}
+	return nil
+}
+
+// isCollectionNamespace is a method to check that the collection is in <database>.<collection> format and can be quoted in a mongo shell script
+func isCollectionNamespace(collection string) bool {
+	parts := strings.SplitN(collection, ".", 2)
+	return len(parts) == 2 && parts[0] != "" && parts[1] != "" && !strings.ContainsAny(collection, "'\"\\")
+}
+
+// getMaintenanceJobParams is a method to generate the cronjob params for the maintenance of mongodb cluster
+func getMaintenanceJobParams(cr *opstreelabsinv1alpha1.MongoDBCluster) cronJobParameters {
+	name := getMaintenanceJobName(cr)
+	labels := map[string]string{
+		"app":           name,
+		"mongodb_setup": "cluster",
+		"role":          "maintenance",
+	}
+	image := cr.Spec.MaintenanceJob.Image
+	if image == "" {
+		image = cr.Spec.KubernetesConfig.Image
+	}
+	container := corev1.Container{
+		Name:            "maintenance",
+		Image:           image,
+		ImagePullPolicy: getImagePullPolicy(image, cr.Spec.KubernetesConfig.ImagePullPolicy),
+		Command:         []string{"/bin/sh", "-c", getMaintenanceJobScript(cr)},
+		Env: []corev1.EnvVar{
+			{
+				Name: "MONGO_ROOT_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: *cr.Spec.MongoDBSecurity.SecretRef.Name,
+						},
+						Key: *cr.Spec.MongoDBSecurity.SecretRef.Key,
+					},
+				},
+			},
+			{
+				Name:  "MONGO_ROOT_USERNAME",
+				Value: cr.Spec.MongoDBSecurity.MongoDBAdminUser,
+			},
+		},
+		SecurityContext: getDefaultContainerSecurityContext(),
+	}
+	if cr.Spec.MaintenanceJob.Resources != nil {
+		container.Resources = *cr.Spec.MaintenanceJob.Resources
+	}
+	return cronJobParameters{
+		CronJobMeta:        generateObjectMetaInformation(name, cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations)),
+		OwnerDef:           mongoClusterAsOwner(cr),
+		Namespace:          cr.Namespace,
+		Labels:             labels,
+		Schedule:           cr.Spec.MaintenanceJob.Schedule,
+		Container:          container,
+		ImagePullSecrets:   getImagePullSecrets(cr.Spec.KubernetesConfig.ImagePullSecret, cr.Spec.KubernetesConfig.ImagePullSecrets),
+		NodeSelector:       cr.Spec.KubernetesConfig.NodeSelector,
+		Tolerations:        cr.Spec.KubernetesConfig.Tolerations,
+		ServiceAccountName: getServiceAccountName(cr.Spec.KubernetesConfig, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")),
+		SecurityContext:    cr.Spec.KubernetesConfig.SecurityContext,
+	}
+}
+
+// getMaintenanceJobHosts is a method to get the members the maintenance command runs on, in sharded mode the collections are stored on the members of the shards
+func getMaintenanceJobHosts(cr *opstreelabsinv1alpha1.MongoDBCluster) []string {
+	var hosts []string
+	if cr.Spec.Sharding != nil && cr.Spec.Sharding.Enabled {
+		for _, replicaSet := range getShardedReplicaSets(cr) {
+			if replicaSet.Role == shardRoleShard {
+				hosts = append(hosts, getShardedReplicaSetHosts(cr, replicaSet)...)
+			}
+		}
+		return hosts
+	}
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	for node := 0; node < int(*cr.Spec.MongoDBClusterSize); node++ {
+		hosts = append(hosts, mongogo.GetPodHost(fmt.Sprintf("%s-%d", appName, node), appName, cr.Namespace, cr.Spec.ClusterDomain, getMongoDBPort(cr.Spec.MongoDBConfig)))
+	}
+	return hosts
+}
+
+// getMaintenanceJobScript is a method to generate the script running the maintenance command on one secondary at a time, the primary is skipped
+func getMaintenanceJobScript(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	collections := fmt.Sprintf("[\"%s\"]", strings.Join(cr.Spec.MaintenanceJob.Collections, "\", \""))
+	// 26 is the NamespaceNotFound error code, a shard doesn't hold the collections which are not sharded or have no chunks on it
+	script := fmt.Sprintf("if (db.isMaster().secondary) { %s.forEach(function (ns) { var index = ns.indexOf(\".\"); var result = db.getSiblingDB(ns.substring(0, index)).runCommand({%s: ns.substring(index + 1)}); printjson(result); if (!result.ok && result.code !== 26) { quit(1) } }) }",
+		collections, cr.Spec.MaintenanceJob.Command)
+	return fmt.Sprintf("for host in %s; do echo \"Running %s on $host\"; mongo --host \"$host\" --quiet --username \"$MONGO_ROOT_USERNAME\" --password \"$MONGO_ROOT_PASSWORD\" --authenticationDatabase admin --eval '%s' || exit 1; done",
+		strings.Join(getMaintenanceJobHosts(cr), " "), cr.Spec.MaintenanceJob.Command, script)
+}
@@ -0,0 +1,43 @@
+package k8sgo
+
+import (
+	"strings"
+	"testing"
+
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestGetMaintenanceJobHosts(t *testing.T) {
+	size := int32(2)
+	shards := int32(2)
+	tests := []struct {
+		name      string
+		sharding  *opstreelabsinv1alpha1.MongoDBSharding
+		wantHosts []string
+	}{
+		{
+			name:      "replica set",
+			wantHosts: []string{"mongodb-cluster-0.mongodb-cluster.default.svc.cluster.local:27017", "mongodb-cluster-1.mongodb-cluster.default.svc.cluster.local:27017"},
+		},
+		{
+			name:     "sharded cluster",
+			sharding: &opstreelabsinv1alpha1.MongoDBSharding{Enabled: true, Shards: &shards},
+			wantHosts: []string{
+				"mongodb-shard-0-0.mongodb-shard-0.default.svc.cluster.local:27017", "mongodb-shard-0-1.mongodb-shard-0.default.svc.cluster.local:27017",
+				"mongodb-shard-1-0.mongodb-shard-1.default.svc.cluster.local:27017", "mongodb-shard-1-1.mongodb-shard-1.default.svc.cluster.local:27017",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := &opstreelabsinv1alpha1.MongoDBCluster{}
+			cr.Name = "mongodb"
+			cr.Namespace = "default"
+			cr.Spec.MongoDBClusterSize = &size
+			cr.Spec.Sharding = test.sharding
+			if hosts := getMaintenanceJobHosts(cr); strings.Join(hosts, " ") != strings.Join(test.wantHosts, " ") {
+				t.Errorf("getMaintenanceJobHosts() = %v, want %v", hosts, test.wantHosts)
+			}
+		})
+	}
+}
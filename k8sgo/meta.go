@@ -1,6 +1,8 @@
 package k8sgo
 
 import (
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	mongodbv1alpha1 "mongodb-operator/api/v1alpha1"
 )
@@ -28,6 +30,19 @@ func AddOwnerRefToObject(obj metav1.Object, ownerRef metav1.OwnerReference) {
 	obj.SetOwnerReferences(append(obj.GetOwnerReferences(), ownerRef))
 }
 
+// isOwnedBy is a method to check if the object was created by the operator for the owner
+func isOwnedBy(obj metav1.Object, owner metav1.OwnerReference) bool {
+	if owner.UID == "" {
+		return false
+	}
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.UID == owner.UID {
+			return true
+		}
+	}
+	return false
+}
+
 // mongoAsOwner generates and returns object refernece
 func mongoAsOwner(cr *mongodbv1alpha1.MongoDB) metav1.OwnerReference {
 	trueVar := true
@@ -57,6 +72,22 @@ func LabelSelectors(labels map[string]string) *metav1.LabelSelector {
 	return &metav1.LabelSelector{MatchLabels: labels}
 }
 
+const (
+	// managedByLabel marks the objects created by the operator outside of the lifecycle of the MongoDB resource, objects without it are never changed or deleted
+	managedByLabel = "app.kubernetes.io/managed-by"
+	// managedByOperator is the value of managedByLabel
+	managedByOperator = "mongodb-operator"
+	// externalNameOwnerLabel is the uid of the MongoDB resource an ExternalName alias belongs to, as an alias in another namespace cannot have an owner reference
+	externalNameOwnerLabel = "mongodb.opstreelabs.in/owner-uid"
+)
+
+const (
+	// istioInjectAnnotation enables the istio sidecar injection for a pod
+	istioInjectAnnotation = "sidecar.istio.io/inject"
+	// istioProxyConfigAnnotation overrides the mesh wide istio proxy config for a pod
+	istioProxyConfigAnnotation = "proxy.istio.io/config"
+)
+
 // generateAnnotations generates and returns annotations
 func generateAnnotations() map[string]string {
 	return map[string]string{
@@ -65,3 +96,76 @@ func generateAnnotations() map[string]string {
 		"prometheus.io/port":     "9216",
 	}
 }
+
+// mergeLabels merges the user defined labels into the operator managed labels, operator managed labels always take precedence
+func mergeLabels(operatorLabels map[string]string, userLabels map[string]string) map[string]string {
+	labels := make(map[string]string)
+	for key, value := range userLabels {
+		labels[key] = value
+	}
+	for key, value := range operatorLabels {
+		labels[key] = value
+	}
+	return labels
+}
+
+// mergeAnnotations merges the user defined annotations into the operator managed annotations, operator managed annotations always take precedence
+func mergeAnnotations(operatorAnnotations map[string]string, userAnnotations map[string]string) map[string]string {
+	annotations := make(map[string]string)
+	for key, value := range userAnnotations {
+		if isOperatorManagedAnnotation(key) {
+			continue
+		}
+		annotations[key] = value
+	}
+	for key, value := range operatorAnnotations {
+		annotations[key] = value
+	}
+	return annotations
+}
+
+// getPodAnnotations is a method to get the annotations of MongoDB pods, mongod waits for the istio proxy when the mesh injection is enabled
+func getPodAnnotations(kubernetesConfig mongodbv1alpha1.KubernetesConfig) map[string]string {
+	annotations := mergeAnnotations(generateAnnotations(), kubernetesConfig.PodAnnotations)
+	hold := kubernetesConfig.PodAnnotations[istioInjectAnnotation] == "true"
+	if kubernetesConfig.HoldApplicationUntilProxyStarts != nil {
+		hold = *kubernetesConfig.HoldApplicationUntilProxyStarts
+	}
+	// a proxy config set by the user is kept as is, it replaces the mesh wide one entirely
+	if _, present := annotations[istioProxyConfigAnnotation]; hold && !present {
+		annotations[istioProxyConfigAnnotation] = `{"holdApplicationUntilProxyStarts": true}`
+	}
+	return annotations
+}
+
+// operatorManagedAnnotations are the annotations computed on every reconcile, they are never carried forward from stored objects
+var operatorManagedAnnotations = []string{
+	patch.LastAppliedConfig,
+	corev1.LastAppliedConfigAnnotation,
+}
+
+// isOperatorManagedAnnotation is a method to check if an annotation is computed by the operator
+func isOperatorManagedAnnotation(key string) bool {
+	for _, managedKey := range operatorManagedAnnotations {
+		if key == managedKey {
+			return true
+		}
+	}
+	return false
+}
+
+// preserveExternalAnnotations is a method to carry forward the annotations added outside of the operator from the stored object
+func preserveExternalAnnotations(storedAnnotations map[string]string, newAnnotations map[string]string) map[string]string {
+	if newAnnotations == nil {
+		newAnnotations = make(map[string]string)
+	}
+	for key, value := range storedAnnotations {
+		if isOperatorManagedAnnotation(key) {
+			continue
+		}
+		if _, present := newAnnotations[key]; !present {
+			newAnnotations[key] = value
+		}
+	}
+	return newAnnotations
+}
@@ -0,0 +1,100 @@
+package k8sgo
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestIsOwnedBy(t *testing.T) {
+	tests := []struct {
+		name      string
+		ownerRefs []metav1.OwnerReference
+		owner     metav1.OwnerReference
+		want      bool
+	}{
+		{name: "created for the owner", ownerRefs: []metav1.OwnerReference{{UID: "1234"}}, owner: metav1.OwnerReference{UID: "1234"}, want: true},
+		{name: "created for another owner", ownerRefs: []metav1.OwnerReference{{UID: "5678"}}, owner: metav1.OwnerReference{UID: "1234"}, want: false},
+		{name: "created by the user", owner: metav1.OwnerReference{UID: "1234"}, want: false},
+		{name: "owner without uid", ownerRefs: []metav1.OwnerReference{{}}, owner: metav1.OwnerReference{}, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			serviceAccount := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{OwnerReferences: test.ownerRefs}}
+			if got := isOwnedBy(serviceAccount, test.owner); got != test.want {
+				t.Errorf("isOwnedBy() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPreserveExternalAnnotations(t *testing.T) {
+	tests := []struct {
+		name              string
+		storedAnnotations map[string]string
+		newAnnotations    map[string]string
+		want              map[string]string
+	}{
+		{
+			name:              "external annotations survive",
+			storedAnnotations: map[string]string{"deployment.kubernetes.io/revision": "3", "team": "data"},
+			newAnnotations:    map[string]string{"prometheus.io/scrape": "true"},
+			want:              map[string]string{"deployment.kubernetes.io/revision": "3", "team": "data", "prometheus.io/scrape": "true"},
+		},
+		{
+			name:              "last applied annotations are not carried forward",
+			storedAnnotations: map[string]string{patch.LastAppliedConfig: "{}", corev1.LastAppliedConfigAnnotation: "{}", "team": "data"},
+			want:              map[string]string{"team": "data"},
+		},
+		{
+			name:              "operator owned keys take the generated value",
+			storedAnnotations: map[string]string{"prometheus.io/port": "9216"},
+			newAnnotations:    map[string]string{"prometheus.io/port": "9217"},
+			want:              map[string]string{"prometheus.io/port": "9217"},
+		},
+		{
+			name:           "nothing stored",
+			newAnnotations: map[string]string{"prometheus.io/scrape": "true"},
+			want:           map[string]string{"prometheus.io/scrape": "true"},
+		},
+		{
+			name: "nothing at all",
+			want: map[string]string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := preserveExternalAnnotations(test.storedAnnotations, test.newAnnotations); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("preserveExternalAnnotations() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetPodAnnotations(t *testing.T) {
+	trueProperty := true
+	falseProperty := false
+	holdConfig := `{"holdApplicationUntilProxyStarts": true}`
+	tests := []struct {
+		name             string
+		kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig
+		wantProxyConfig  string
+	}{
+		{name: "no istio"},
+		{name: "istio sidecar injected", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{PodAnnotations: map[string]string{istioInjectAnnotation: "true"}}, wantProxyConfig: holdConfig},
+		{name: "istio sidecar injected without holding", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{PodAnnotations: map[string]string{istioInjectAnnotation: "true"}, HoldApplicationUntilProxyStarts: &falseProperty}},
+		{name: "explicitly holding", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{HoldApplicationUntilProxyStarts: &trueProperty}, wantProxyConfig: holdConfig},
+		{name: "user defined proxy config", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{PodAnnotations: map[string]string{istioInjectAnnotation: "true", istioProxyConfigAnnotation: `{"concurrency": 2}`}}, wantProxyConfig: `{"concurrency": 2}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getPodAnnotations(test.kubernetesConfig)[istioProxyConfigAnnotation]; got != test.wantProxyConfig {
+				t.Errorf("getPodAnnotations() proxy config = %q, want %q", got, test.wantProxyConfig)
+			}
+		})
+	}
+}
@@ -1,27 +1,48 @@
 package k8sgo
 
 import (
+	"errors"
 	"fmt"
 	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
 	"mongodb-operator/mongo"
 	"strings"
 )
 
+// defaultReplicationLagThreshold is the replication lag in seconds above which a mongodb cluster is reported as degraded
+const defaultReplicationLagThreshold int64 = 60
+
+// ErrMembershipChangeInProgress is returned while members are added to or removed from the replica set of mongodb cluster one at a time
+var ErrMembershipChangeInProgress = errors.New("membership change of MongoDB cluster is in progress")
+
+// IsMembershipChangeInProgress is a method to check if the error is caused by a member change which waits for the previous one
+func IsMembershipChangeInProgress(err error) bool {
+	return errors.Is(err, ErrMembershipChangeInProgress)
+}
+
+// maxVotingMembers is the number of voting members a MongoDB replica set is limited to
+const maxVotingMembers = 7
+
 // InitializeMongoDBCluster is a method to create a mongodb cluster
 func InitializeMongoDBCluster(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Setup")
 	serviceName := fmt.Sprintf("%s-%s.%s", cr.ObjectMeta.Name, "cluster", cr.Namespace)
 	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
-	password := getMongoDBPassword(passwordParams)
-	mongoURL := fmt.Sprintf("mongodb://%s:%s@%s:27017/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, serviceName)
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return err
+	}
+	mongoURL := fmt.Sprintf("mongodb://%s:%s@%s:%d/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, serviceName, getMongoDBPort(cr.Spec.MongoDBConfig))
 	mongoParams := mongogo.MongoDBParameters{
 		MongoURL:     mongoURL,
 		Namespace:    cr.Namespace,
 		Name:         cr.ObjectMeta.Name,
+		Port:         getMongoDBPort(cr.Spec.MongoDBConfig),
 		ClusterNodes: cr.Spec.MongoDBClusterSize,
+		ReplicaSet:   GetMongoDBReplicaSetName(cr),
 		SetupType:    "standalone",
 	}
-	err := mongogo.InitiateMongoClusterRS(mongoParams)
+	mongoParams.Members = getMongoDBClusterMembers(cr, mongoParams)
+	err = mongogo.InitiateMongoClusterRS(mongoParams)
 	if err != nil {
 		logger.Error(err, "Unable to create MongoDB cluster")
 		return err
@@ -35,12 +56,16 @@ func CheckMongoClusterStateInitialized(cr *opstreelabsinv1alpha1.MongoDBCluster)
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Setup")
 	serviceName := fmt.Sprintf("%s-%s.%s", cr.ObjectMeta.Name, "cluster", cr.Namespace)
 	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
-	password := getMongoDBPassword(passwordParams)
-	mongoURL := fmt.Sprintf("mongodb://%s:%s@%s:27017/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, serviceName)
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return false, err
+	}
+	mongoURL := fmt.Sprintf("mongodb://%s:%s@%s:%d/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, serviceName, getMongoDBPort(cr.Spec.MongoDBConfig))
 	mongoParams := mongogo.MongoDBParameters{
 		MongoURL:  mongoURL,
 		Namespace: cr.Namespace,
 		Name:      cr.ObjectMeta.Name,
+		Port:      getMongoDBPort(cr.Spec.MongoDBConfig),
 		SetupType: "standalone",
 	}
 	state, err := mongogo.CheckMongoClusterInitialized(mongoParams)
@@ -56,18 +81,25 @@ func CreateMongoDBMonitoringUser(cr *opstreelabsinv1alpha1.MongoDB) error {
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Monitoring User")
 	serviceName := fmt.Sprintf("%s-%s.%s", cr.ObjectMeta.Name, "standalone", cr.Namespace)
 	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
-	password := getMongoDBPassword(passwordParams)
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return err
+	}
 	monitoringPasswordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone-monitoring"), SecretKey: "password"}
-	monitoringPassword := getMongoDBPassword(monitoringPasswordParams)
-	mongoURL := fmt.Sprintf("mongodb://%s:%s@%s:27017/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, serviceName)
+	monitoringPassword, err := getMongoDBPassword(monitoringPasswordParams)
+	if err != nil {
+		return err
+	}
+	mongoURL := fmt.Sprintf("mongodb://%s:%s@%s:%d/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, serviceName, getMongoDBPort(cr.Spec.MongoDBConfig))
 	mongoParams := mongogo.MongoDBParameters{
 		MongoURL:  mongoURL,
 		Namespace: cr.Namespace,
 		Name:      cr.ObjectMeta.Name,
+		Port:      getMongoDBPort(cr.Spec.MongoDBConfig),
 		Password:  monitoringPassword,
 		SetupType: "standalone",
 	}
-	err := mongogo.CreateMonitoringUser(mongoParams)
+	err = mongogo.CreateMonitoringUser(mongoParams)
 	if err != nil {
 		logger.Error(err, "Unable to create monitoring user in MongoDB")
 		return err
@@ -80,26 +112,24 @@ func CreateMongoDBMonitoringUser(cr *opstreelabsinv1alpha1.MongoDB) error {
 func CreateMongoDBClusterMonitoringUser(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Monitoring User")
 	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
-	password := getMongoDBPassword(passwordParams)
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return err
+	}
 	monitoringPasswordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster-monitoring"), SecretKey: "password"}
-	monitoringPassword := getMongoDBPassword(monitoringPasswordParams)
+	monitoringPassword, err := getMongoDBPassword(monitoringPasswordParams)
+	if err != nil {
+		return err
+	}
 	mongoParams := mongogo.MongoDBParameters{
 		Namespace: cr.Namespace,
 		Name:      cr.ObjectMeta.Name,
+		Port:      getMongoDBPort(cr.Spec.MongoDBConfig),
 		Password:  monitoringPassword,
 		SetupType: "cluster",
 	}
-	mongoURL := []string{"mongodb://", cr.Spec.MongoDBSecurity.MongoDBAdminUser, ":", password, "@"}
-	for node := 0; node < int(*cr.Spec.MongoDBClusterSize); node++ {
-		if node != int(*cr.Spec.MongoDBClusterSize) {
-			mongoURL = append(mongoURL, fmt.Sprintf("%s,", mongogo.GetMongoNodeInfo(mongoParams, node)))
-		} else {
-			mongoURL = append(mongoURL, mongogo.GetMongoNodeInfo(mongoParams, node))
-		}
-	}
-	mongoURL = append(mongoURL, fmt.Sprintf("/?replicaSet=%s", cr.ObjectMeta.Name))
-	mongoParams.MongoURL = strings.Join(mongoURL, "")
-	err := mongogo.CreateMonitoringUser(mongoParams)
+	mongoParams.MongoURL = getMongoDBClusterURL(cr, mongoParams, password)
+	err = mongogo.CreateMonitoringUser(mongoParams)
 	if err != nil {
 		logger.Error(err, "Unable to create monitoring user in MongoDB cluster")
 		return err
@@ -112,24 +142,19 @@ func CreateMongoDBClusterMonitoringUser(cr *opstreelabsinv1alpha1.MongoDBCluster
 func CheckMongoDBClusterMonitoringUser(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Monitoring User")
 	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
-	password := getMongoDBPassword(passwordParams)
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return false
+	}
 	monitoringUser := "monitoring"
 	mongoParams := mongogo.MongoDBParameters{
 		Namespace: cr.Namespace,
 		Name:      cr.ObjectMeta.Name,
+		Port:      getMongoDBPort(cr.Spec.MongoDBConfig),
 		UserName:  &monitoringUser,
 		SetupType: "cluster",
 	}
-	mongoURL := []string{"mongodb://", cr.Spec.MongoDBSecurity.MongoDBAdminUser, ":", password, "@"}
-	for node := 0; node < int(*cr.Spec.MongoDBClusterSize); node++ {
-		if node != int(*cr.Spec.MongoDBClusterSize) {
-			mongoURL = append(mongoURL, fmt.Sprintf("%s,", mongogo.GetMongoNodeInfo(mongoParams, node)))
-		} else {
-			mongoURL = append(mongoURL, mongogo.GetMongoNodeInfo(mongoParams, node))
-		}
-	}
-	mongoURL = append(mongoURL, fmt.Sprintf("/?replicaSet=%s", cr.ObjectMeta.Name))
-	mongoParams.MongoURL = strings.Join(mongoURL, "")
+	mongoParams.MongoURL = getMongoDBClusterURL(cr, mongoParams, password)
 	output, err := mongogo.GetMongoDBUser(mongoParams)
 	if err != nil {
 		return false
@@ -143,13 +168,17 @@ func CheckMonitoringUser(cr *opstreelabsinv1alpha1.MongoDB) bool {
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Monitoring User")
 	serviceName := fmt.Sprintf("%s-%s.%s", cr.ObjectMeta.Name, "standalone", cr.Namespace)
 	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
-	password := getMongoDBPassword(passwordParams)
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return false
+	}
 	monitoringUser := "monitoring"
-	mongoURL := fmt.Sprintf("mongodb://%s:%s@%s:27017/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, serviceName)
+	mongoURL := fmt.Sprintf("mongodb://%s:%s@%s:%d/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, serviceName, getMongoDBPort(cr.Spec.MongoDBConfig))
 	mongoParams := mongogo.MongoDBParameters{
 		MongoURL:  mongoURL,
 		Namespace: cr.Namespace,
 		Name:      cr.ObjectMeta.Name,
+		Port:      getMongoDBPort(cr.Spec.MongoDBConfig),
 		UserName:  &monitoringUser,
 		SetupType: "standalone",
 	}
@@ -160,3 +189,257 @@ func CheckMonitoringUser(cr *opstreelabsinv1alpha1.MongoDB) bool {
 	logger.Info("Successfully executed the command to check monitoring user")
 	return output
 }
+
+// GetMongoDBClusterPrimary is a method to get the current primary pod and election term of MongoDB cluster
+func GetMongoDBClusterPrimary(cr *opstreelabsinv1alpha1.MongoDBCluster) (string, int64, error) {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Setup")
+	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return "", 0, err
+	}
+	mongoParams := mongogo.MongoDBParameters{
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		Port:      getMongoDBPort(cr.Spec.MongoDBConfig),
+		SetupType: "cluster",
+	}
+	mongoParams.MongoURL = getMongoDBClusterURL(cr, mongoParams, password)
+	primary, term, err := mongogo.GetMongoClusterPrimary(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to get the primary of MongoDB cluster")
+		return "", 0, err
+	}
+	return strings.Split(primary, ".")[0], term, nil
+}
+
+// GetMongoDBClusterReplicationLag is a method to get the maximum replication lag of the secondaries of mongodb cluster in seconds
+func GetMongoDBClusterReplicationLag(cr *opstreelabsinv1alpha1.MongoDBCluster) (int64, error) {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Setup")
+	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return 0, err
+	}
+	mongoParams := mongogo.MongoDBParameters{
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		Port:      getMongoDBPort(cr.Spec.MongoDBConfig),
+		SetupType: "cluster",
+	}
+	mongoParams.MongoURL = getMongoDBClusterURL(cr, mongoParams, password)
+	lag, err := mongogo.GetMongoClusterReplicationLag(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to get the replication lag of MongoDB cluster")
+		return 0, err
+	}
+	return lag, nil
+}
+
+// GetReplicationLagThreshold is a method to get the replication lag in seconds above which mongodb cluster is degraded
+func GetReplicationLagThreshold(cr *opstreelabsinv1alpha1.MongoDBCluster) int64 {
+	if cr.Spec.ReplicationLagThresholdSeconds != nil {
+		return *cr.Spec.ReplicationLagThresholdSeconds
+	}
+	return defaultReplicationLagThreshold
+}
+
+// ReconcileMongoClusterOplogSize is a method to resize the oplog of the data bearing members, --oplogSize is only applied when a member creates its oplog, it returns the resized members
+func ReconcileMongoClusterOplogSize(cr *opstreelabsinv1alpha1.MongoDBCluster) ([]string, error) {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Setup")
+	if cr.Spec.MongoDBConfig != nil && hasMongoDBArg(cr.Spec.MongoDBConfig.ExtraArgs, "--oplogSize") {
+		// an oplog size passed in extraArgs is managed by the user
+		return nil, nil
+	}
+	oplogSize, err := getOplogSize(cr.Spec.MongoDBConfig, getPersistentStorage(cr.Spec.MongoDBConfig, cr.Spec.Storage))
+	if err != nil || oplogSize == 0 {
+		return nil, err
+	}
+	mongoParams, password, err := getMemberStatusParams(cr)
+	if err != nil {
+		return nil, err
+	}
+	var resized []string
+	for _, member := range getMongoDBClusterMembers(cr, mongoParams) {
+		if member.ArbiterOnly {
+			continue
+		}
+		mongoParams.MongoURL = fmt.Sprintf("mongodb://%s:%s@%s/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, member.Host)
+		currentSize, err := mongogo.GetMongoOplogSize(mongoParams)
+		if err != nil {
+			// the member is resized on a later reconciliation once it is reachable again
+			logger.Info("Unable to get the oplog size of MongoDB cluster member", "host", member.Host, "error", err.Error())
+			continue
+		}
+		if currentSize == int64(oplogSize) {
+			continue
+		}
+		if err := mongogo.ResizeMongoOplog(mongoParams, oplogSize); err != nil {
+			logger.Error(err, "Unable to resize the oplog of MongoDB cluster member", "host", member.Host)
+			return resized, err
+		}
+		logger.Info("Resized the oplog of MongoDB cluster member", "host", member.Host, "from", currentSize, "to", oplogSize)
+		resized = append(resized, member.Host)
+	}
+	return resized, nil
+}
+
+// getMongoDBClusterURL is a method to generate the connection URL for MongoDB cluster
+func getMongoDBClusterURL(cr *opstreelabsinv1alpha1.MongoDBCluster, mongoParams mongogo.MongoDBParameters, password string) string {
+	mongoParams.ClusterDomain = cr.Spec.ClusterDomain
+	var nodes []string
+	for node := 0; node < int(*cr.Spec.MongoDBClusterSize); node++ {
+		nodes = append(nodes, mongogo.GetMongoNodeInfo(mongoParams, node))
+	}
+	return fmt.Sprintf("mongodb://%s:%s@%s/?replicaSet=%s", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, strings.Join(nodes, ","), GetMongoDBReplicaSetName(cr))
+}
+
+// ReconcileMongoDBClusterMembers is a method to apply the member configuration on MongoDB cluster, it returns the number of pods the data bearing members of the replica set config run on
+func ReconcileMongoDBClusterMembers(cr *opstreelabsinv1alpha1.MongoDBCluster) (int32, error) {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Setup")
+	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return 0, err
+	}
+	mongoParams := mongogo.MongoDBParameters{
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		Port:      getMongoDBPort(cr.Spec.MongoDBConfig),
+		SetupType: "cluster",
+	}
+	mongoParams.MongoURL = getMongoDBClusterURL(cr, mongoParams, password)
+	mongoParams.Members = getMongoDBClusterMembers(cr, mongoParams)
+	// a reconfiguration changes the votes of a single member, so it is repeated until every member is configured or the next change has to wait
+	membershipErr := ErrMembershipChangeInProgress
+	for attempt := 0; attempt <= len(mongoParams.Members); attempt++ {
+		changed, pending, err := mongogo.ReconfigureMongoClusterMembers(mongoParams)
+		if err != nil {
+			logger.Error(err, "Unable to reconfigure the members of MongoDB cluster")
+			return 0, err
+		}
+		if !changed {
+			if !pending {
+				membershipErr = nil
+			}
+			break
+		}
+		logger.Info("Successfully reconfigured the members of MongoDB cluster")
+	}
+	if membershipErr != nil {
+		logger.Info("Waiting for the members of MongoDB cluster to be healthy before the next member change")
+	}
+	dataMembers, err := mongogo.GetMongoClusterDataMembers(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to get the data bearing members of MongoDB cluster")
+		return 0, err
+	}
+	return dataMembers, membershipErr
+}
+
+// getMongoDBClusterMembers is a method to generate the replica set member configuration for MongoDB cluster
+func getMongoDBClusterMembers(cr *opstreelabsinv1alpha1.MongoDBCluster, mongoParams mongogo.MongoDBParameters) []mongogo.MongoDBMember {
+	mongoParams.ClusterDomain = cr.Spec.ClusterDomain
+	var members []mongogo.MongoDBMember
+	for node := 0; node < int(*cr.Spec.MongoDBClusterSize); node++ {
+		members = append(members, mongogo.MongoDBMember{
+			ID:       node,
+			Host:     mongogo.GetMongoNodeInfo(mongoParams, node),
+			Priority: 1,
+			Votes:    1,
+		})
+	}
+	if cr.Spec.AnalyticsNode != nil && cr.Spec.AnalyticsNode.Enabled {
+		analyticsNode := &members[len(members)-1]
+		analyticsNode.Hidden = true
+		analyticsNode.Priority = 0
+		analyticsNode.Tags = getAnalyticsNodeTags(cr.Spec.AnalyticsNode)
+	}
+	for _, memberConfig := range cr.Spec.MemberConfig {
+		if int(memberConfig.Member) >= len(members) {
+			continue
+		}
+		applyMemberConfig(&members[memberConfig.Member], memberConfig)
+	}
+	if isArbiterEnabled(cr) {
+		members = append(members, getArbiterMember(cr))
+	}
+	return members
+}
+
+// applyMemberConfig is a method to apply the user defined configuration on a replica set member
+func applyMemberConfig(member *mongogo.MongoDBMember, memberConfig opstreelabsinv1alpha1.MongoDBMemberConfig) {
+	member.Hidden = memberConfig.Hidden
+	if memberConfig.Hidden {
+		member.Priority = 0
+	}
+	if memberConfig.Priority != nil {
+		member.Priority = float64(*memberConfig.Priority)
+	}
+	if memberConfig.Votes != nil {
+		member.Votes = int(*memberConfig.Votes)
+	}
+	if memberConfig.SecondaryDelaySecs != nil {
+		member.SecondaryDelaySecs = *memberConfig.SecondaryDelaySecs
+	}
+	if len(memberConfig.Tags) > 0 {
+		member.Tags = memberConfig.Tags
+	}
+}
+
+// validateMemberConfig is a method to validate the user defined replica set member configuration
+func validateMemberConfig(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	configured := make(map[int32]bool)
+	for _, memberConfig := range cr.Spec.MemberConfig {
+		if memberConfig.Member >= *cr.Spec.MongoDBClusterSize {
+			return fmt.Errorf("memberConfig references member %d which is beyond the clusterSize", memberConfig.Member)
+		}
+		if configured[memberConfig.Member] {
+			return fmt.Errorf("memberConfig references member %d more than once", memberConfig.Member)
+		}
+		configured[memberConfig.Member] = true
+		priority := int32(1)
+		if memberConfig.Priority != nil {
+			priority = *memberConfig.Priority
+		} else if memberConfig.Hidden {
+			priority = 0
+		}
+		if memberConfig.Hidden && priority != 0 {
+			return fmt.Errorf("hidden member %d must have priority 0", memberConfig.Member)
+		}
+		if memberConfig.SecondaryDelaySecs != nil && *memberConfig.SecondaryDelaySecs > 0 && priority != 0 {
+			return fmt.Errorf("delayed member %d must have priority 0", memberConfig.Member)
+		}
+		if memberConfig.Votes != nil && *memberConfig.Votes == 0 && priority != 0 {
+			return fmt.Errorf("non-voting member %d must have priority 0", memberConfig.Member)
+		}
+	}
+	return validateMemberVotes(getMongoDBClusterMembers(cr, mongogo.MongoDBParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace}))
+}
+
+// validateMemberVotes is a method to validate the votes of the replica set members, including the arbiter, against the limits of MongoDB
+func validateMemberVotes(members []mongogo.MongoDBMember) error {
+	votes := 0
+	electable := false
+	for _, member := range members {
+		votes += member.Votes
+		if member.Votes > 0 && member.Priority > 0 {
+			electable = true
+		}
+	}
+	if votes > maxVotingMembers {
+		return fmt.Errorf("replica set has %d voting members while at most %d are allowed, the others need votes 0 and priority 0", votes, maxVotingMembers)
+	}
+	if !electable {
+		return fmt.Errorf("replica set has no voting member with a priority above 0 which can become primary")
+	}
+	return nil
+}
+
+// getAnalyticsNodeTags is a method to get the replica set tags for the analytics node
+func getAnalyticsNodeTags(analyticsNode *opstreelabsinv1alpha1.MongoDBAnalyticsNode) map[string]string {
+	if len(analyticsNode.Tags) > 0 {
+		return analyticsNode.Tags
+	}
+	return map[string]string{"workload": "analytics"}
+}
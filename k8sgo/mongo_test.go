@@ -0,0 +1,125 @@
+package k8sgo
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/mongo"
+)
+
+// newTestMongoDBCluster is a method to generate a MongoDB cluster of the given size with the member configuration
+func newTestMongoDBCluster(size int32, memberConfig ...opstreelabsinv1alpha1.MongoDBMemberConfig) *opstreelabsinv1alpha1.MongoDBCluster {
+	cr := &opstreelabsinv1alpha1.MongoDBCluster{ObjectMeta: metav1.ObjectMeta{Name: "mongodb", Namespace: "database"}}
+	cr.Spec.MongoDBClusterSize = &size
+	cr.Spec.MemberConfig = memberConfig
+	return cr
+}
+
+func TestValidateMemberConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		cr      *opstreelabsinv1alpha1.MongoDBCluster
+		wantErr bool
+	}{
+		{name: "default members", cr: newTestMongoDBCluster(3)},
+		{name: "preferred data center", cr: newTestMongoDBCluster(3, opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 0, Priority: int32Ptr(10)})},
+		{name: "non-voting member with priority 0", cr: newTestMongoDBCluster(3, opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 2, Votes: int32Ptr(0), Priority: int32Ptr(0)})},
+		{name: "non-voting member with the default priority", cr: newTestMongoDBCluster(3, opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 2, Votes: int32Ptr(0)}), wantErr: true},
+		{name: "non-voting member with priority", cr: newTestMongoDBCluster(3, opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 2, Votes: int32Ptr(0), Priority: int32Ptr(1)}), wantErr: true},
+		{name: "hidden member with priority", cr: newTestMongoDBCluster(3, opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 2, Hidden: true, Priority: int32Ptr(1)}), wantErr: true},
+		{name: "seven voters", cr: newTestMongoDBCluster(7)},
+		{name: "more than seven voters", cr: newTestMongoDBCluster(8), wantErr: true},
+		{
+			name: "more than seven members with seven voters",
+			cr: newTestMongoDBCluster(9,
+				opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 7, Votes: int32Ptr(0), Priority: int32Ptr(0)},
+				opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 8, Votes: int32Ptr(0), Priority: int32Ptr(0)},
+			),
+		},
+		{name: "no electable member", cr: newTestMongoDBCluster(1, opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 0, Priority: int32Ptr(0)}), wantErr: true},
+		{name: "member beyond the cluster size", cr: newTestMongoDBCluster(3, opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 3}), wantErr: true},
+		{name: "member configured twice", cr: newTestMongoDBCluster(3, opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 1}, opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 1}), wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateMemberConfig(test.cr); (err != nil) != test.wantErr {
+				t.Errorf("validateMemberConfig() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetMongoDBClusterMembers(t *testing.T) {
+	cr := newTestMongoDBCluster(3,
+		opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 0, Priority: int32Ptr(10)},
+		opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 2, Votes: int32Ptr(0), Priority: int32Ptr(0), Tags: map[string]string{"dc": "west"}},
+	)
+	members := getMongoDBClusterMembers(cr, mongogo.MongoDBParameters{Name: "mongodb", Namespace: "database"})
+	want := []mongogo.MongoDBMember{
+		{ID: 0, Priority: 10, Votes: 1},
+		{ID: 1, Priority: 1, Votes: 1},
+		{ID: 2, Priority: 0, Votes: 0, Tags: map[string]string{"dc": "west"}},
+	}
+	if len(members) != len(want) {
+		t.Fatalf("getMongoDBClusterMembers() = %v, want %d members", members, len(want))
+	}
+	for index, member := range members {
+		if member.ID != want[index].ID || member.Priority != want[index].Priority || member.Votes != want[index].Votes || member.Tags["dc"] != want[index].Tags["dc"] {
+			t.Errorf("getMongoDBClusterMembers() member %d = %+v, want %+v", index, member, want[index])
+		}
+	}
+}
+
+func TestApplyMemberConfig(t *testing.T) {
+	delay := int64(3600)
+	tests := []struct {
+		name         string
+		memberConfig opstreelabsinv1alpha1.MongoDBMemberConfig
+		want         mongogo.MongoDBMember
+	}{
+		{name: "defaults", memberConfig: opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 1}, want: mongogo.MongoDBMember{ID: 1, Priority: 1, Votes: 1}},
+		{name: "hidden member", memberConfig: opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 1, Hidden: true}, want: mongogo.MongoDBMember{ID: 1, Hidden: true, Priority: 0, Votes: 1}},
+		{
+			name:         "delayed hidden member",
+			memberConfig: opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 1, Hidden: true, SecondaryDelaySecs: &delay},
+			want:         mongogo.MongoDBMember{ID: 1, Hidden: true, Priority: 0, Votes: 1, SecondaryDelaySecs: delay},
+		},
+		{
+			name:         "delayed member without votes",
+			memberConfig: opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 1, Priority: int32Ptr(0), Votes: int32Ptr(0), SecondaryDelaySecs: &delay},
+			want:         mongogo.MongoDBMember{ID: 1, Priority: 0, Votes: 0, SecondaryDelaySecs: delay},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			member := mongogo.MongoDBMember{ID: 1, Priority: 1, Votes: 1}
+			applyMemberConfig(&member, test.memberConfig)
+			if !reflect.DeepEqual(member, test.want) {
+				t.Errorf("applyMemberConfig() = %+v, want %+v", member, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateMemberConfigDelayedMember(t *testing.T) {
+	delay := int64(3600)
+	tests := []struct {
+		name         string
+		memberConfig opstreelabsinv1alpha1.MongoDBMemberConfig
+		wantErr      bool
+	}{
+		{name: "hidden delayed member", memberConfig: opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 2, Hidden: true, SecondaryDelaySecs: &delay}},
+		{name: "delayed member with priority 0", memberConfig: opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 2, Priority: int32Ptr(0), SecondaryDelaySecs: &delay}},
+		{name: "delayed member with the default priority", memberConfig: opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 2, SecondaryDelaySecs: &delay}, wantErr: true},
+		{name: "hidden member with priority 0", memberConfig: opstreelabsinv1alpha1.MongoDBMemberConfig{Member: 2, Hidden: true, Priority: int32Ptr(0)}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateMemberConfig(newTestMongoDBCluster(3, test.memberConfig)); (err != nil) != test.wantErr {
+				t.Errorf("validateMemberConfig() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
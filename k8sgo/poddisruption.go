@@ -62,11 +62,7 @@ func patchPodDisruption(storedPdb *policyv1.PodDisruptionBudget, newPdb *policyv
 			"Original", string(patchResult.Original),
 			"Modified", string(patchResult.Modified),
 		)
-		for key, value := range storedPdb.Annotations {
-			if _, present := newPdb.Annotations[key]; !present {
-				newPdb.Annotations[key] = value
-			}
-		}
+		newPdb.Annotations = preserveExternalAnnotations(storedPdb.Annotations, newPdb.Annotations)
 		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(newPdb); err != nil {
 			logger.Error(err, "Unable to patch MongoDB PodDisruptionBudget with comparison object")
 			return err
@@ -80,7 +76,12 @@ func patchPodDisruption(storedPdb *policyv1.PodDisruptionBudget, newPdb *policyv
 // updatePodDisruption is a method to create Pod disruption budget
 func updatePodDisruption(namespace string, pdb *policyv1.PodDisruptionBudget) error {
 	logger := logGenerator(pdb.Name, namespace, "PodDisruptionBudget")
-	_, err := generateK8sClient().PolicyV1beta1().PodDisruptionBudgets(namespace).Update(context.TODO(), pdb, metav1.UpdateOptions{})
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	_, err = client.PolicyV1beta1().PodDisruptionBudgets(namespace).Update(context.TODO(), pdb, metav1.UpdateOptions{})
 	if err != nil {
 		logger.Error(err, "MongoDB PodDisruptionBudget update failed")
 		return err
@@ -92,7 +93,12 @@ func updatePodDisruption(namespace string, pdb *policyv1.PodDisruptionBudget) er
 // createPodDisruption is a method to create Pod disruption budget
 func createPodDisruption(namespace string, pdb *policyv1.PodDisruptionBudget) error {
 	logger := logGenerator(pdb.Name, namespace, "PodDisruptionBudget")
-	_, err := generateK8sClient().PolicyV1beta1().PodDisruptionBudgets(namespace).Create(context.TODO(), pdb, metav1.CreateOptions{})
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	_, err = client.PolicyV1beta1().PodDisruptionBudgets(namespace).Create(context.TODO(), pdb, metav1.CreateOptions{})
 	if err != nil {
 		logger.Error(err, "MongoDB PodDisruptionBudget creation failed")
 		return err
@@ -104,7 +110,12 @@ func createPodDisruption(namespace string, pdb *policyv1.PodDisruptionBudget) er
 // getPodDisruption is a method to get Pod disruption budget
 func getPodDisruption(namespace, name string) (*policyv1.PodDisruptionBudget, error) {
 	logger := logGenerator(name, namespace, "PodDisruptionBudget")
-	pdbInfo, err := generateK8sClient().PolicyV1beta1().PodDisruptionBudgets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return nil, err
+	}
+	pdbInfo, err := client.PolicyV1beta1().PodDisruptionBudgets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
 	if err != nil {
 		logger.Info("Unable to get pod disruption budget")
 		return nil, err
@@ -0,0 +1,48 @@
+package k8sgo
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"strings"
+)
+
+// defaultPostStartTimeoutSeconds is the time the postStart command runs for before it is stopped
+const defaultPostStartTimeoutSeconds int32 = 60
+
+// getMongoDBWaitCommand is a method to generate the shell command which waits up to 2 minutes for mongod to answer a ping
+func getMongoDBWaitCommand(port int32, authEnabled bool) string {
+	return fmt.Sprintf("for i in $(seq 1 60); do mongo --port %d --quiet %s --eval 'db.adminCommand({ping: 1})' >/dev/null 2>&1 && break; sleep 2; done",
+		port, getShellCredentials(authEnabled))
+}
+
+// getPostStartCommand is a method to generate the shell command which runs the postStart command of the user with a timeout, its output goes to the container log
+func getPostStartCommand(postStart *opstreelabsinv1alpha1.PostStartHook) string {
+	timeout := *getInt32OrDefault(postStart.TimeoutSeconds, defaultPostStartTimeoutSeconds)
+	// a failing postStart hook kills the container, so a failed command is only logged
+	return fmt.Sprintf("timeout %d \"$@\" >/proc/1/fd/1 2>&1 || echo \"postStart command failed with exit code $?\" >/proc/1/fd/1", timeout)
+}
+
+// getPostStartHandler is a method to generate the postStart hook which waits for mongod, then warms up the cache and runs the postStart command, the probes only start once it returns
+func getPostStartHandler(warmUp *opstreelabsinv1alpha1.MongoDBWarmUp, postStart *opstreelabsinv1alpha1.PostStartHook, port int32, authEnabled bool) *corev1.Handler {
+	if !isWarmUpEnabled(warmUp) && postStart == nil {
+		return nil
+	}
+	commands := []string{getMongoDBWaitCommand(port, authEnabled)}
+	if isWarmUpEnabled(warmUp) {
+		commands = append(commands, getWarmUpCommand(warmUp, port, authEnabled))
+	}
+	command := []string{"/bin/sh", "-c"}
+	if postStart != nil {
+		// the command of the user is passed as arguments, so it doesn't need to be quoted for the shell
+		commands = append(commands, getPostStartCommand(postStart))
+		command = append(append(command, strings.Join(commands, "; "), "postStart"), postStart.Command...)
+	} else {
+		command = append(command, strings.Join(commands, "; "))
+	}
+	return &corev1.Handler{
+		Exec: &corev1.ExecAction{
+			Command: command,
+		},
+	}
+}
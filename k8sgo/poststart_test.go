@@ -0,0 +1,60 @@
+package k8sgo
+
+import (
+	"strings"
+	"testing"
+
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestGetPostStartHandler(t *testing.T) {
+	warmUp := &opstreelabsinv1alpha1.MongoDBWarmUp{Enabled: true, Collections: []string{"shop.orders"}}
+	tests := []struct {
+		name          string
+		warmUp        *opstreelabsinv1alpha1.MongoDBWarmUp
+		postStart     *opstreelabsinv1alpha1.PostStartHook
+		wantScripts   []string
+		wantArguments []string
+	}{
+		{name: "no postStart hook"},
+		{name: "disabled warm-up", warmUp: &opstreelabsinv1alpha1.MongoDBWarmUp{Collections: []string{"shop.orders"}}},
+		{name: "warm-up", warmUp: warmUp, wantScripts: []string{"db.adminCommand({ping: 1})", "maxTimeMS(60000)"}},
+		{
+			name:          "postStart command",
+			postStart:     &opstreelabsinv1alpha1.PostStartHook{Command: []string{"/scripts/init.sh", "--seed"}},
+			wantScripts:   []string{"db.adminCommand({ping: 1})", "timeout 60 \"$@\""},
+			wantArguments: []string{"postStart", "/scripts/init.sh", "--seed"},
+		},
+		{
+			name:          "warm-up before the postStart command",
+			warmUp:        warmUp,
+			postStart:     &opstreelabsinv1alpha1.PostStartHook{Command: []string{"/scripts/init.sh"}, TimeoutSeconds: int32Ptr(30)},
+			wantScripts:   []string{"maxTimeMS(60000)", "|| true; timeout 30 \"$@\""},
+			wantArguments: []string{"postStart", "/scripts/init.sh"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler := getPostStartHandler(test.warmUp, test.postStart, mongoDBPort, false)
+			if test.wantScripts == nil {
+				if handler != nil {
+					t.Errorf("getPostStartHandler() = %v, want no postStart hook", handler)
+				}
+				return
+			}
+			command := handler.Exec.Command
+			if command[0] != "/bin/sh" || command[1] != "-c" {
+				t.Fatalf("getPostStartHandler() command = %v, want a shell script", command)
+			}
+			for _, want := range test.wantScripts {
+				if !strings.Contains(command[2], want) {
+					t.Errorf("getPostStartHandler() script = %s, missing %s", command[2], want)
+				}
+			}
+			// the command of the user is passed as arguments of the script, so it isn't interpreted by the shell
+			if arguments := strings.Join(command[3:], " "); arguments != strings.Join(test.wantArguments, " ") {
+				t.Errorf("getPostStartHandler() arguments = %s, want %v", arguments, test.wantArguments)
+			}
+		})
+	}
+}
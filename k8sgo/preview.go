@@ -0,0 +1,65 @@
+package k8sgo
+
+import (
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"sigs.k8s.io/yaml"
+)
+
+// PreviewMongoClusterManifests is a method to log the manifests generated for mongodb cluster without applying them
+func PreviewMongoClusterManifests(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Dry Run")
+	return logManifests(logger, getMongoClusterPreviewObjects(cr))
+}
+
+// getMongoClusterPreviewObjects is a method to generate the objects of mongodb cluster which are logged in dry run mode
+func getMongoClusterPreviewObjects(cr *opstreelabsinv1alpha1.MongoDBCluster) []runtime.Object {
+	var objects []runtime.Object
+	if cr.Spec.Sharding != nil && cr.Spec.Sharding.Enabled {
+		for _, replicaSet := range getShardedReplicaSets(cr) {
+			objects = append(objects,
+				generateServiceDef(getShardedServiceParams(cr, replicaSet.Name, replicaSet.Role, true)),
+				generateStatefulSetDef(getShardedReplicaSetParams(cr, replicaSet)),
+			)
+		}
+		objects = append(objects,
+			generateServiceDef(getShardedServiceParams(cr, getMongosName(cr), shardRoleMongos, false)),
+			generateDeploymentDef(getMongosParams(cr)),
+		)
+		return objects
+	}
+	objects = append(objects,
+		generateServiceDef(applyHeadlessServiceConfig(getMongoDBClusterServiceParams(cr), cr.Spec.KubernetesConfig.HeadlessService)),
+		generateStatefulSetDef(getMongoDBClusterParams(cr)),
+	)
+	if isArbiterEnabled(cr) {
+		objects = append(objects,
+			generateServiceDef(getArbiterServiceParams(cr)),
+			generateStatefulSetDef(getMongoDBArbiterParams(cr)),
+		)
+	}
+	return objects
+}
+
+// PreviewMongoStandaloneManifests is a method to log the manifests generated for mongodb standalone without applying them
+func PreviewMongoStandaloneManifests(cr *opstreelabsinv1alpha1.MongoDB) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Dry Run")
+	return logManifests(logger, []runtime.Object{
+		generateServiceDef(applyHeadlessServiceConfig(getMongoDBStandaloneServiceParams(cr), cr.Spec.KubernetesConfig.HeadlessService)),
+		generateStatefulSetDef(getMongoDBStandaloneParams(cr)),
+	})
+}
+
+// logManifests is a method to log the YAML manifests of the generated objects
+func logManifests(logger logr.Logger, objects []runtime.Object) error {
+	for _, object := range objects {
+		manifest, err := yaml.Marshal(object)
+		if err != nil {
+			logger.Error(err, "Unable to generate the manifest in dry run mode")
+			return err
+		}
+		logger.Info("Generated manifest in dry run mode, it is not applied", "Kind", object.GetObjectKind().GroupVersionKind().Kind, "Manifest", string(manifest))
+	}
+	return nil
+}
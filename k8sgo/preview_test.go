@@ -0,0 +1,52 @@
+package k8sgo
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestGetMongoClusterPreviewObjects(t *testing.T) {
+	enabled := true
+	tests := []struct {
+		name     string
+		arbiter  *bool
+		sharding *opstreelabsinv1alpha1.MongoDBSharding
+		want     []string
+	}{
+		{
+			name: "replica set",
+			want: []string{"Service/mongodb-cluster", "StatefulSet/mongodb-cluster"},
+		},
+		{
+			name:    "replica set with arbiter",
+			arbiter: &enabled,
+			want:    []string{"Service/mongodb-cluster", "StatefulSet/mongodb-cluster", "Service/mongodb-cluster-arbiter", "StatefulSet/mongodb-cluster-arbiter"},
+		},
+		{
+			name:     "sharded cluster",
+			sharding: &opstreelabsinv1alpha1.MongoDBSharding{Enabled: true},
+			want:     []string{"Service/mongodb-configsvr", "StatefulSet/mongodb-configsvr", "Service/mongodb-shard-0", "StatefulSet/mongodb-shard-0", "Service/mongodb-mongos", "Deployment/mongodb-mongos"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := newTestMongoDBCluster(3)
+			cr.Spec.EnableArbiter = test.arbiter
+			cr.Spec.Sharding = test.sharding
+			var got []string
+			for _, object := range getMongoClusterPreviewObjects(cr) {
+				accessor, err := meta.Accessor(object)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, object.GetObjectKind().GroupVersionKind().Kind+"/"+accessor.GetName())
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getMongoClusterPreviewObjects() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
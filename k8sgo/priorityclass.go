@@ -0,0 +1,200 @@
+package k8sgo
+
+import (
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"reflect"
+)
+
+// priorityClassParameters is the input struct for MongoDB priorityclass
+type priorityClassParameters struct {
+	PriorityClassMeta metav1.ObjectMeta
+	Value             int32
+	PreemptionPolicy  *corev1.PreemptionPolicy
+	Description       string
+}
+
+// isPriorityClassCreated is a method to check if the priorityclass of MongoDB pods is created by the operator
+func isPriorityClassCreated(kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig) bool {
+	return kubernetesConfig.PriorityClass != nil && kubernetesConfig.PriorityClass.Create
+}
+
+// getPriorityClassName is a method to get the priorityclass of MongoDB pods, the created one is named after the namespace as priorityclasses are cluster scoped
+func getPriorityClassName(kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig, namespace string, appName string) string {
+	if kubernetesConfig.PriorityClassName == "" && isPriorityClassCreated(kubernetesConfig) {
+		return fmt.Sprintf("%s-%s", namespace, appName)
+	}
+	return kubernetesConfig.PriorityClassName
+}
+
+// getPriorityClassLabels is a method to get the labels of the priorityclass of MongoDB pods, the namespace is part of them as priorityclasses are cluster scoped
+func getPriorityClassLabels(namespace string, appName string, setupType string) map[string]string {
+	return map[string]string{
+		"app":           appName,
+		"mongodb_setup": setupType,
+		"role":          "priority",
+		"namespace":     namespace,
+	}
+}
+
+// reconcilePriorityClass is a method to create the priorityclass of MongoDB pods, or to delete it once it is not created by the operator anymore
+func reconcilePriorityClass(kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig, namespace string, appName string, setupType string) error {
+	labels := getPriorityClassLabels(namespace, appName, setupType)
+	if !isPriorityClassCreated(kubernetesConfig) {
+		return deleteMongoDBPriorityClass(kubernetesConfig, namespace, appName, setupType)
+	}
+	config := kubernetesConfig.PriorityClass
+	return CreateOrUpdatePriorityClass(priorityClassParameters{
+		PriorityClassMeta: generateObjectMetaInformation(getPriorityClassName(kubernetesConfig, namespace, appName), "", labels, generateAnnotations()),
+		Value:             config.Value,
+		PreemptionPolicy:  config.PreemptionPolicy,
+		Description:       config.Description,
+	})
+}
+
+// deleteMongoDBPriorityClass is a method to delete the priorityclass created for MongoDB pods, a priorityclass managed outside of the operator doesn't carry its labels and is never deleted
+func deleteMongoDBPriorityClass(kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig, namespace string, appName string, setupType string) error {
+	labels := getPriorityClassLabels(namespace, appName, setupType)
+	names := []string{fmt.Sprintf("%s-%s", namespace, appName)}
+	if kubernetesConfig.PriorityClassName != "" {
+		names = append(names, kubernetesConfig.PriorityClassName)
+	}
+	for _, name := range names {
+		if err := deletePriorityClass(name, labels); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateMongoClusterPriorityClass is a method to create or delete the priorityclass of mongodb cluster pods
+func CreateMongoClusterPriorityClass(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	return reconcilePriorityClass(cr.Spec.KubernetesConfig, cr.Namespace, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster"), "cluster")
+}
+
+// CreateMongoStandalonePriorityClass is a method to create or delete the priorityclass of mongodb standalone pod
+func CreateMongoStandalonePriorityClass(cr *opstreelabsinv1alpha1.MongoDB) error {
+	return reconcilePriorityClass(cr.Spec.KubernetesConfig, cr.Namespace, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone"), "standalone")
+}
+
+// CreateOrUpdatePriorityClass method will create or update MongoDB priorityclass, it is recreated when its immutable value or preemption policy changes
+func CreateOrUpdatePriorityClass(params priorityClassParameters) error {
+	logger := logGenerator(params.PriorityClassMeta.Name, "", "PriorityClass")
+	priorityClassDef := generatePriorityClassDef(params)
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	storedPriorityClass, err := client.SchedulingV1().PriorityClasses().Get(context.TODO(), params.PriorityClassMeta.Name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "MongoDB priorityclass get action failed")
+			return err
+		}
+		_, err = client.SchedulingV1().PriorityClasses().Create(context.TODO(), priorityClassDef, metav1.CreateOptions{})
+		if err != nil {
+			logger.Error(err, "MongoDB priorityclass creation failed")
+			return err
+		}
+		logger.Info("MongoDB priorityclass successfully created")
+		return nil
+	}
+	if !hasLabels(storedPriorityClass.Labels, priorityClassDef.Labels) {
+		err = fmt.Errorf("priorityclass %s already exists and is not managed by the operator", params.PriorityClassMeta.Name)
+		logger.Error(err, "MongoDB priorityclass cannot be updated")
+		return err
+	}
+	if storedPriorityClass.Value != priorityClassDef.Value || !reflect.DeepEqual(storedPriorityClass.PreemptionPolicy, priorityClassDef.PreemptionPolicy) {
+		// running pods keep the priority they were admitted with, only the pods created afterwards get the new one
+		logger.Info("Immutable fields of PriorityClass changed, recreating...", "value", priorityClassDef.Value)
+		err = client.SchedulingV1().PriorityClasses().Delete(context.TODO(), params.PriorityClassMeta.Name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "MongoDB priorityclass deletion failed")
+			return err
+		}
+		_, err = client.SchedulingV1().PriorityClasses().Create(context.TODO(), priorityClassDef, metav1.CreateOptions{})
+		if err != nil {
+			logger.Error(err, "MongoDB priorityclass creation failed")
+			return err
+		}
+		logger.Info("MongoDB priorityclass successfully recreated")
+		return nil
+	}
+	if storedPriorityClass.Description == priorityClassDef.Description {
+		logger.Info("MongoDB priorityclass is already in-sync")
+		return nil
+	}
+	priorityClassDef.ResourceVersion = storedPriorityClass.ResourceVersion
+	priorityClassDef.Annotations = preserveExternalAnnotations(storedPriorityClass.Annotations, priorityClassDef.Annotations)
+	_, err = client.SchedulingV1().PriorityClasses().Update(context.TODO(), priorityClassDef, metav1.UpdateOptions{})
+	if err != nil {
+		logger.Error(err, "MongoDB priorityclass update failed")
+		return err
+	}
+	logger.Info("MongoDB priorityclass successfully updated")
+	return nil
+}
+
+// deletePriorityClass is a method to delete the priorityclass in Kubernetes if it was created by the operator for the labelled MongoDB setup
+func deletePriorityClass(name string, labels map[string]string) error {
+	logger := logGenerator(name, "", "PriorityClass")
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	storedPriorityClass, err := client.SchedulingV1().PriorityClasses().Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		logger.Error(err, "MongoDB priorityclass get action failed")
+		return err
+	}
+	if !hasLabels(storedPriorityClass.Labels, labels) {
+		return nil
+	}
+	err = client.SchedulingV1().PriorityClasses().Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "MongoDB priorityclass deletion failed")
+		return err
+	}
+	logger.Info("MongoDB priorityclass successfully deleted")
+	return nil
+}
+
+// hasLabels is a method to check if all the expected labels are set on an object
+func hasLabels(objectLabels map[string]string, labels map[string]string) bool {
+	for key, value := range labels {
+		if objectLabels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// generatePriorityClassDef is a method to generate priorityclass definition, it has no owner reference as a cluster scoped object cannot be owned by a namespaced one
+func generatePriorityClassDef(params priorityClassParameters) *schedulingv1.PriorityClass {
+	return &schedulingv1.PriorityClass{
+		TypeMeta:         generateMetaInformation("PriorityClass", "scheduling.k8s.io/v1"),
+		ObjectMeta:       params.PriorityClassMeta,
+		Value:            params.Value,
+		PreemptionPolicy: getPreemptionPolicy(params.PreemptionPolicy),
+		Description:      params.Description,
+	}
+}
+
+// getPreemptionPolicy is a method to get the preemption policy of priorityclass, it defaults to PreemptLowerPriority like the API server does
+func getPreemptionPolicy(policy *corev1.PreemptionPolicy) *corev1.PreemptionPolicy {
+	if policy == nil {
+		defaultPolicy := corev1.PreemptLowerPriority
+		return &defaultPolicy
+	}
+	return policy
+}
@@ -0,0 +1,75 @@
+package k8sgo
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestGetPriorityClassName(t *testing.T) {
+	tests := []struct {
+		name             string
+		kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig
+		want             string
+	}{
+		{name: "no priority class"},
+		{name: "existing priority class", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{PriorityClassName: "high-priority"}, want: "high-priority"},
+		{name: "created priority class", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{PriorityClass: &opstreelabsinv1alpha1.PriorityClassConfig{Create: true, Value: 1000}}, want: "database-mongodb-cluster"},
+		{name: "created priority class with explicit name", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{PriorityClassName: "mongodb", PriorityClass: &opstreelabsinv1alpha1.PriorityClassConfig{Create: true, Value: 1000}}, want: "mongodb"},
+		{name: "priority class not created", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{PriorityClass: &opstreelabsinv1alpha1.PriorityClassConfig{Value: 1000}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getPriorityClassName(test.kubernetesConfig, "database", "mongodb-cluster"); got != test.want {
+				t.Errorf("getPriorityClassName() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHasLabels(t *testing.T) {
+	labels := getPriorityClassLabels("database", "mongodb-cluster", "cluster")
+	tests := []struct {
+		name         string
+		objectLabels map[string]string
+		want         bool
+	}{
+		{name: "no labels"},
+		{name: "priority class of another namespace", objectLabels: getPriorityClassLabels("staging", "mongodb-cluster", "cluster")},
+		{name: "priority class of the cluster", objectLabels: labels, want: true},
+		{name: "additional labels", objectLabels: mergeLabels(labels, map[string]string{"team": "database"}), want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := hasLabels(test.objectLabels, labels); got != test.want {
+				t.Errorf("hasLabels() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGeneratePriorityClassDef(t *testing.T) {
+	never := corev1.PreemptNever
+	tests := []struct {
+		name             string
+		preemptionPolicy *corev1.PreemptionPolicy
+		want             corev1.PreemptionPolicy
+	}{
+		{name: "default preemption policy", want: corev1.PreemptLowerPriority},
+		{name: "explicit preemption policy", preemptionPolicy: &never, want: corev1.PreemptNever},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			priorityClass := generatePriorityClassDef(priorityClassParameters{
+				PriorityClassMeta: metav1.ObjectMeta{Name: "database-mongodb-cluster"},
+				Value:             1000,
+				PreemptionPolicy:  test.preemptionPolicy,
+			})
+			if priorityClass.Value != 1000 || *priorityClass.PreemptionPolicy != test.want || len(priorityClass.OwnerReferences) != 0 {
+				t.Errorf("generatePriorityClassDef() value = %d, preemptionPolicy = %s, want 1000 and %s without owner", priorityClass.Value, *priorityClass.PreemptionPolicy, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package k8sgo
+
+import (
+	"context"
+	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+)
+
+// getVolumeClaimTemplateResizes is a method to list the claim templates whose storage size changed with their new size
+func getVolumeClaimTemplateResizes(storedTemplates []corev1.PersistentVolumeClaim, newTemplates []corev1.PersistentVolumeClaim) map[string]resource.Quantity {
+	resizes := map[string]resource.Quantity{}
+	for _, newTemplate := range newTemplates {
+		for _, storedTemplate := range storedTemplates {
+			if storedTemplate.Name != newTemplate.Name {
+				continue
+			}
+			newSize := newTemplate.Spec.Resources.Requests[corev1.ResourceStorage]
+			storedSize := storedTemplate.Spec.Resources.Requests[corev1.ResourceStorage]
+			if newSize.Cmp(storedSize) != 0 {
+				resizes[newTemplate.Name] = newSize
+			}
+		}
+	}
+	return resizes
+}
+
+// getVolumeClaimName is a method to get the name of the claim the StatefulSet creates for the pod from the claim template
+func getVolumeClaimName(templateName string, statefulSetName string, ordinal int32) string {
+	return fmt.Sprintf("%s-%s-%d", templateName, statefulSetName, ordinal)
+}
+
+// expandVolumeClaims is a method to apply the changed storage size of the immutable claim templates on the claims of the StatefulSet pods
+func expandVolumeClaims(storedStateful *appsv1.StatefulSet, newStateful *appsv1.StatefulSet, namespace string) error {
+	resizes := getVolumeClaimTemplateResizes(storedStateful.Spec.VolumeClaimTemplates, newStateful.Spec.VolumeClaimTemplates)
+	if len(resizes) == 0 {
+		return nil
+	}
+	client, err := generateK8sClient()
+	if err != nil {
+		return err
+	}
+	replicas := int32(1)
+	if newStateful.Spec.Replicas != nil {
+		replicas = *newStateful.Spec.Replicas
+	}
+	for _, template := range storedStateful.Spec.VolumeClaimTemplates {
+		size, resized := resizes[template.Name]
+		if !resized {
+			continue
+		}
+		for ordinal := int32(0); ordinal < replicas; ordinal++ {
+			claim, err := client.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), getVolumeClaimName(template.Name, storedStateful.Name, ordinal), metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				// the claim of a pod which is not created yet gets the size of the template and is expanded on the next reconciliation
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if err := expandVolumeClaim(claim, size, namespace); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// expandVolumeClaim is a method to expand the claim to the requested size, claims cannot shrink and only grow on a storageclass allowing volume expansion
+func expandVolumeClaim(claim *corev1.PersistentVolumeClaim, size resource.Quantity, namespace string) error {
+	logger := logGenerator(claim.Name, namespace, "PersistentVolumeClaim")
+	currentSize := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	switch size.Cmp(currentSize) {
+	case 0:
+		return nil
+	case -1:
+		return fmt.Errorf("storage size of PersistentVolumeClaim %s cannot be reduced from %s to %s", claim.Name, currentSize.String(), size.String())
+	}
+	storageClass, err := getStorageClass(claim.Spec.StorageClassName)
+	if err != nil {
+		return fmt.Errorf("unable to check if the storageclass of PersistentVolumeClaim %s allows volume expansion: %w", claim.Name, err)
+	}
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		return fmt.Errorf("storage size of PersistentVolumeClaim %s cannot be expanded from %s to %s, StorageClass %s doesn't allow volume expansion", claim.Name, currentSize.String(), size.String(), storageClass.Name)
+	}
+	client, err := generateK8sClient()
+	if err != nil {
+		return err
+	}
+	claim.Spec.Resources.Requests[corev1.ResourceStorage] = size
+	if _, err := client.CoreV1().PersistentVolumeClaims(namespace).Update(context.TODO(), claim, metav1.UpdateOptions{}); err != nil {
+		logger.Error(err, "MongoDB PersistentVolumeClaim expansion failed")
+		return err
+	}
+	logger.Info("MongoDB PersistentVolumeClaim expanded", "size", size.String())
+	return nil
+}
+
+// getVolumeClaimMismatches is a method to list the existing claims of the pods which don't match their changed claim template, recreating the StatefulSet doesn't change them
+func getVolumeClaimMismatches(storedStateful *appsv1.StatefulSet, newStateful *appsv1.StatefulSet, namespace string) ([]string, error) {
+	client, err := generateK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	replicas := int32(1)
+	if storedStateful.Spec.Replicas != nil {
+		replicas = *storedStateful.Spec.Replicas
+	}
+	var mismatches []string
+	for _, template := range newStateful.Spec.VolumeClaimTemplates {
+		if getVolumeClaimTemplate(storedStateful.Spec.VolumeClaimTemplates, template.Name) == nil {
+			// the claims of an added template are created when the pods are replaced
+			continue
+		}
+		for ordinal := int32(0); ordinal < replicas; ordinal++ {
+			claim, err := client.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), getVolumeClaimName(template.Name, storedStateful.Name, ordinal), metav1.GetOptions{})
+			if errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if !isVolumeClaimMatching(claim, template) {
+				mismatches = append(mismatches, claim.Name)
+			}
+		}
+	}
+	return mismatches, nil
+}
+
+// isVolumeClaimMatching is a method to check if the storage class, access modes and volume mode of the claim are the ones of its template, a template without storage class matches the default one
+func isVolumeClaimMatching(claim *corev1.PersistentVolumeClaim, template corev1.PersistentVolumeClaim) bool {
+	if getStorageClassName(template.Spec.StorageClassName) != "" && getStorageClassName(claim.Spec.StorageClassName) != getStorageClassName(template.Spec.StorageClassName) {
+		return false
+	}
+	return reflect.DeepEqual(getAccessModes(claim.Spec.AccessModes), getAccessModes(template.Spec.AccessModes)) &&
+		isBlockVolumeMode(claim.Spec.VolumeMode) == isBlockVolumeMode(template.Spec.VolumeMode)
+}
@@ -0,0 +1,76 @@
+package k8sgo
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetVolumeClaimTemplateResizes(t *testing.T) {
+	tests := []struct {
+		name           string
+		storedTemplate corev1.PersistentVolumeClaim
+		newTemplate    corev1.PersistentVolumeClaim
+		want           string
+	}{
+		{
+			name:           "unchanged size",
+			storedTemplate: generatePersistentVolumeTemplate(pvcParameters{Name: "mongodb-cluster", StorageSize: "1Gi"}),
+			newTemplate:    generatePersistentVolumeTemplate(pvcParameters{Name: "mongodb-cluster", StorageSize: "1024Mi"}),
+		},
+		{
+			name:           "expanded size",
+			storedTemplate: generatePersistentVolumeTemplate(pvcParameters{Name: "mongodb-cluster", StorageSize: "1Gi"}),
+			newTemplate:    generatePersistentVolumeTemplate(pvcParameters{Name: "mongodb-cluster", StorageSize: "2Gi"}),
+			want:           "2Gi",
+		},
+		{
+			name:           "reduced size",
+			storedTemplate: generatePersistentVolumeTemplate(pvcParameters{Name: "mongodb-cluster", StorageSize: "2Gi"}),
+			newTemplate:    generatePersistentVolumeTemplate(pvcParameters{Name: "mongodb-cluster", StorageSize: "1Gi"}),
+			want:           "1Gi",
+		},
+		{
+			name:           "added template",
+			storedTemplate: generatePersistentVolumeTemplate(pvcParameters{Name: "mongodb-cluster", StorageSize: "1Gi"}),
+			newTemplate:    generatePersistentVolumeTemplate(pvcParameters{Name: "audit", StorageSize: "2Gi"}),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resizes := getVolumeClaimTemplateResizes([]corev1.PersistentVolumeClaim{test.storedTemplate}, []corev1.PersistentVolumeClaim{test.newTemplate})
+			size, resized := resizes[test.newTemplate.Name]
+			if test.want == "" {
+				if resized {
+					t.Errorf("getVolumeClaimTemplateResizes() resized %s to %s, want no resize", test.newTemplate.Name, size.String())
+				}
+				return
+			}
+			if !resized || size.String() != test.want {
+				t.Errorf("getVolumeClaimTemplateResizes() resized %s to %s, want %s", test.newTemplate.Name, size.String(), test.want)
+			}
+		})
+	}
+}
+
+func TestIsVolumeClaimMatching(t *testing.T) {
+	standard, fast := "standard", "fast"
+	tests := []struct {
+		name     string
+		claim    corev1.PersistentVolumeClaimSpec
+		template corev1.PersistentVolumeClaimSpec
+		want     bool
+	}{
+		{name: "same storage class", claim: corev1.PersistentVolumeClaimSpec{StorageClassName: &fast}, template: corev1.PersistentVolumeClaimSpec{StorageClassName: &fast}, want: true},
+		{name: "default storage class", claim: corev1.PersistentVolumeClaimSpec{StorageClassName: &standard}, template: corev1.PersistentVolumeClaimSpec{}, want: true},
+		{name: "changed storage class", claim: corev1.PersistentVolumeClaimSpec{StorageClassName: &standard}, template: corev1.PersistentVolumeClaimSpec{StorageClassName: &fast}},
+		{name: "changed access modes", claim: corev1.PersistentVolumeClaimSpec{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}}, template: corev1.PersistentVolumeClaimSpec{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if matching := isVolumeClaimMatching(&corev1.PersistentVolumeClaim{Spec: test.claim}, corev1.PersistentVolumeClaim{Spec: test.template}); matching != test.want {
+				t.Errorf("isVolumeClaimMatching() = %v, want %v", matching, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package k8sgo
+
+import (
+	"context"
+	"fmt"
+	"github.com/banzaicloud/k8s-objectmatcher/patch"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+// rbacParameters is the input struct for MongoDB ServiceAccount, Role and RoleBinding
+type rbacParameters struct {
+	RBACMeta         metav1.ObjectMeta
+	OwnerDef         metav1.OwnerReference
+	Namespace        string
+	Rules            []rbacv1.PolicyRule
+	ImagePullSecrets []string
+}
+
+// CreateOrUpdateRBAC method will create or update the ServiceAccount of MongoDB pods along with its Role and RoleBinding
+func CreateOrUpdateRBAC(params rbacParameters) error {
+	if err := CreateOrUpdateServiceAccount(params); err != nil {
+		return err
+	}
+	if err := createOrUpdateRole(params); err != nil {
+		return err
+	}
+	return createOrUpdateRoleBinding(params)
+}
+
+// CreateOrUpdateServiceAccount method will create or update MongoDB ServiceAccount
+func CreateOrUpdateServiceAccount(params rbacParameters) error {
+	logger := logGenerator(params.RBACMeta.Name, params.Namespace, "ServiceAccount")
+	serviceAccountDef := generateServiceAccountDef(params)
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	storedServiceAccount, err := client.CoreV1().ServiceAccounts(params.Namespace).Get(context.TODO(), params.RBACMeta.Name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "MongoDB ServiceAccount get action failed")
+			return err
+		}
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(serviceAccountDef); err != nil {
+			logger.Error(err, "Unable to patch MongoDB ServiceAccount with comparison object")
+			return err
+		}
+		_, err = client.CoreV1().ServiceAccounts(params.Namespace).Create(context.TODO(), serviceAccountDef, metav1.CreateOptions{})
+		if err != nil {
+			logger.Error(err, "MongoDB ServiceAccount creation failed")
+			return err
+		}
+		logger.Info("MongoDB ServiceAccount successfully created")
+		return nil
+	}
+	// the token secrets are managed by Kubernetes
+	serviceAccountDef.Secrets = storedServiceAccount.Secrets
+	update, err := isRBACObjectChanged("ServiceAccount", storedServiceAccount, serviceAccountDef, storedServiceAccount.ObjectMeta, &serviceAccountDef.ObjectMeta)
+	if err != nil || !update {
+		return err
+	}
+	_, err = client.CoreV1().ServiceAccounts(params.Namespace).Update(context.TODO(), serviceAccountDef, metav1.UpdateOptions{})
+	if err != nil {
+		logger.Error(err, "MongoDB ServiceAccount update failed")
+		return err
+	}
+	logger.Info("MongoDB ServiceAccount successfully updated")
+	return nil
+}
+
+// createOrUpdateRole method will create or update the Role of MongoDB ServiceAccount
+func createOrUpdateRole(params rbacParameters) error {
+	logger := logGenerator(params.RBACMeta.Name, params.Namespace, "Role")
+	roleDef := generateRoleDef(params)
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	storedRole, err := client.RbacV1().Roles(params.Namespace).Get(context.TODO(), params.RBACMeta.Name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "MongoDB Role get action failed")
+			return err
+		}
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(roleDef); err != nil {
+			logger.Error(err, "Unable to patch MongoDB Role with comparison object")
+			return err
+		}
+		_, err = client.RbacV1().Roles(params.Namespace).Create(context.TODO(), roleDef, metav1.CreateOptions{})
+		if err != nil {
+			logger.Error(err, "MongoDB Role creation failed")
+			return err
+		}
+		logger.Info("MongoDB Role successfully created")
+		return nil
+	}
+	update, err := isRBACObjectChanged("Role", storedRole, roleDef, storedRole.ObjectMeta, &roleDef.ObjectMeta)
+	if err != nil || !update {
+		return err
+	}
+	_, err = client.RbacV1().Roles(params.Namespace).Update(context.TODO(), roleDef, metav1.UpdateOptions{})
+	if err != nil {
+		logger.Error(err, "MongoDB Role update failed")
+		return err
+	}
+	logger.Info("MongoDB Role successfully updated")
+	return nil
+}
+
+// createOrUpdateRoleBinding method will create or update the RoleBinding of MongoDB ServiceAccount
+func createOrUpdateRoleBinding(params rbacParameters) error {
+	logger := logGenerator(params.RBACMeta.Name, params.Namespace, "RoleBinding")
+	roleBindingDef := generateRoleBindingDef(params)
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	storedRoleBinding, err := client.RbacV1().RoleBindings(params.Namespace).Get(context.TODO(), params.RBACMeta.Name, metav1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Error(err, "MongoDB RoleBinding get action failed")
+			return err
+		}
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(roleBindingDef); err != nil {
+			logger.Error(err, "Unable to patch MongoDB RoleBinding with comparison object")
+			return err
+		}
+		_, err = client.RbacV1().RoleBindings(params.Namespace).Create(context.TODO(), roleBindingDef, metav1.CreateOptions{})
+		if err != nil {
+			logger.Error(err, "MongoDB RoleBinding creation failed")
+			return err
+		}
+		logger.Info("MongoDB RoleBinding successfully created")
+		return nil
+	}
+	update, err := isRBACObjectChanged("RoleBinding", storedRoleBinding, roleBindingDef, storedRoleBinding.ObjectMeta, &roleBindingDef.ObjectMeta)
+	if err != nil || !update {
+		return err
+	}
+	_, err = client.RbacV1().RoleBindings(params.Namespace).Update(context.TODO(), roleBindingDef, metav1.UpdateOptions{})
+	if err != nil {
+		logger.Error(err, "MongoDB RoleBinding update failed")
+		return err
+	}
+	logger.Info("MongoDB RoleBinding successfully updated")
+	return nil
+}
+
+// isRBACObjectChanged is a method to compare the stored and generated RBAC objects, the generated object is prepared for the update
+func isRBACObjectChanged(kind string, stored runtime.Object, generated runtime.Object, storedMeta metav1.ObjectMeta, generatedMeta *metav1.ObjectMeta) (bool, error) {
+	logger := logGenerator(storedMeta.Name, storedMeta.Namespace, kind)
+	generatedMeta.ResourceVersion = storedMeta.ResourceVersion
+	generatedMeta.CreationTimestamp = storedMeta.CreationTimestamp
+	generatedMeta.ManagedFields = storedMeta.ManagedFields
+	patchResult, err := patch.DefaultPatchMaker.Calculate(stored, generated,
+		patch.IgnoreField("kind"),
+		patch.IgnoreField("apiVersion"),
+	)
+	if err != nil {
+		logger.Error(err, "Unable to patch MongoDB "+kind+" with comparison object")
+		return false, err
+	}
+	if patchResult.IsEmpty() {
+		logger.Info("MongoDB " + kind + " is already in-sync")
+		return false, nil
+	}
+	logger.Info("Changes in "+kind+" detected, updating...", "patch", string(patchResult.Patch))
+	generatedMeta.Annotations = preserveExternalAnnotations(storedMeta.Annotations, generatedMeta.Annotations)
+	if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(generated); err != nil {
+		logger.Error(err, "Unable to patch MongoDB "+kind+" with comparison object")
+		return false, err
+	}
+	return true, nil
+}
+
+// deleteRBAC is a method to delete the ServiceAccount, Role and RoleBinding of MongoDB pods once they are not used anymore, only the objects created by the operator for the owner are deleted
+func deleteRBAC(namespace string, name string, owner metav1.OwnerReference) error {
+	logger := logGenerator(name, namespace, "ServiceAccount")
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	roleBinding, err := client.RbacV1().RoleBindings(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "MongoDB RoleBinding get action failed")
+		return err
+	}
+	if err == nil && isOwnedBy(roleBinding, owner) {
+		err = client.RbacV1().RoleBindings(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "MongoDB RoleBinding deletion is failed")
+			return err
+		}
+		logger.Info("MongoDB RoleBinding is not used anymore and deleted")
+	}
+	role, err := client.RbacV1().Roles(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "MongoDB Role get action failed")
+		return err
+	}
+	if err == nil && isOwnedBy(role, owner) {
+		err = client.RbacV1().Roles(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "MongoDB Role deletion is failed")
+			return err
+		}
+		logger.Info("MongoDB Role is not used anymore and deleted")
+	}
+	serviceAccount, err := client.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "MongoDB ServiceAccount get action failed")
+		return err
+	}
+	if err == nil && isOwnedBy(serviceAccount, owner) {
+		err = client.CoreV1().ServiceAccounts(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			logger.Error(err, "MongoDB ServiceAccount deletion is failed")
+			return err
+		}
+		logger.Info("MongoDB ServiceAccount is not used anymore and deleted")
+	}
+	return nil
+}
+
+// getServiceAccountName is a method to get the ServiceAccount name of MongoDB pods, an existing ServiceAccount takes precedence over the generated one
+func getServiceAccountName(kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig, name string) string {
+	if kubernetesConfig.ServiceAccountName != "" || !kubernetesConfig.CreateServiceAccount {
+		return kubernetesConfig.ServiceAccountName
+	}
+	return name
+}
+
+// validateServiceAccountImagePullSecrets is a method to validate that the ServiceAccount the image pull secrets are attached to is created by the operator
+func validateServiceAccountImagePullSecrets(kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig) error {
+	if len(kubernetesConfig.ServiceAccountImagePullSecrets) == 0 {
+		return nil
+	}
+	if !kubernetesConfig.CreateServiceAccount || kubernetesConfig.ServiceAccountName != "" {
+		return fmt.Errorf("serviceAccountImagePullSecrets requires createServiceAccount without a serviceAccountName")
+	}
+	return nil
+}
+
+// getServiceAccountImagePullSecrets is a method to generate the image pull secret references of the ServiceAccount
+func getServiceAccountImagePullSecrets(secretNames []string) []corev1.LocalObjectReference {
+	var secrets []corev1.LocalObjectReference
+	for _, name := range secretNames {
+		secrets = append(secrets, corev1.LocalObjectReference{Name: name})
+	}
+	return secrets
+}
+
+// getAutomountServiceAccountToken is a method to get if the ServiceAccount token is mounted in MongoDB pods, it is only mounted by default for a restore using the cloud IAM credentials
+func getAutomountServiceAccountToken(kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig, restoreParams *restoreParameters) *bool {
+	if kubernetesConfig.AutomountServiceAccountToken != nil {
+		return kubernetesConfig.AutomountServiceAccountToken
+	}
+	automount := restoreParams != nil && restoreParams.S3SecretName == nil
+	return &automount
+}
+
+// getRBACRules is a method to generate the rules for reading the secrets and configmaps used by MongoDB pods
+func getRBACRules(secretNames []string, configMapNames []string) []rbacv1.PolicyRule {
+	var rules []rbacv1.PolicyRule
+	if len(secretNames) > 0 {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: secretNames,
+			Verbs:         []string{"get"},
+		})
+	}
+	if len(configMapNames) > 0 {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups:     []string{""},
+			Resources:     []string{"configmaps"},
+			ResourceNames: configMapNames,
+			Verbs:         []string{"get"},
+		})
+	}
+	return rules
+}
+
+// generateServiceAccountDef is a method to generate ServiceAccount definition
+func generateServiceAccountDef(params rbacParameters) *corev1.ServiceAccount {
+	serviceAccount := &corev1.ServiceAccount{
+		TypeMeta:         generateMetaInformation("ServiceAccount", "v1"),
+		ObjectMeta:       *params.RBACMeta.DeepCopy(),
+		ImagePullSecrets: getServiceAccountImagePullSecrets(params.ImagePullSecrets),
+	}
+	AddOwnerRefToObject(serviceAccount, params.OwnerDef)
+	return serviceAccount
+}
+
+// generateRoleDef is a method to generate Role definition
+func generateRoleDef(params rbacParameters) *rbacv1.Role {
+	role := &rbacv1.Role{
+		TypeMeta:   generateMetaInformation("Role", "rbac.authorization.k8s.io/v1"),
+		ObjectMeta: *params.RBACMeta.DeepCopy(),
+		Rules:      params.Rules,
+	}
+	AddOwnerRefToObject(role, params.OwnerDef)
+	return role
+}
+
+// generateRoleBindingDef is a method to generate RoleBinding definition
+func generateRoleBindingDef(params rbacParameters) *rbacv1.RoleBinding {
+	roleBinding := &rbacv1.RoleBinding{
+		TypeMeta:   generateMetaInformation("RoleBinding", "rbac.authorization.k8s.io/v1"),
+		ObjectMeta: *params.RBACMeta.DeepCopy(),
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "Role",
+			Name:     params.RBACMeta.Name,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      params.RBACMeta.Name,
+				Namespace: params.Namespace,
+			},
+		},
+	}
+	AddOwnerRefToObject(roleBinding, params.OwnerDef)
+	return roleBinding
+}
@@ -0,0 +1,80 @@
+package k8sgo
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestGetAutomountServiceAccountToken(t *testing.T) {
+	trueValue, falseValue := true, false
+	s3Secret := "mongodb-restore-s3"
+	tests := []struct {
+		name          string
+		automount     *bool
+		restoreParams *restoreParameters
+		want          bool
+	}{
+		{name: "not mounted by default", want: false},
+		{name: "explicitly mounted", automount: &trueValue, want: true},
+		{name: "explicitly not mounted", automount: &falseValue, want: false},
+		{name: "restore with cloud IAM credentials", restoreParams: &restoreParameters{}, want: true},
+		{name: "restore with an s3 secret", restoreParams: &restoreParameters{S3SecretName: &s3Secret}, want: false},
+		{name: "explicitly not mounted for a restore with cloud IAM credentials", automount: &falseValue, restoreParams: &restoreParameters{}, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := getAutomountServiceAccountToken(opstreelabsinv1alpha1.KubernetesConfig{AutomountServiceAccountToken: test.automount}, test.restoreParams)
+			if got == nil || *got != test.want {
+				t.Errorf("getAutomountServiceAccountToken() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateServiceAccountImagePullSecrets(t *testing.T) {
+	tests := []struct {
+		name             string
+		kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig
+		wantErr          bool
+	}{
+		{name: "no image pull secrets"},
+		{name: "no image pull secrets with a custom service account", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{ServiceAccountName: "mongodb"}},
+		{name: "created service account", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{CreateServiceAccount: true, ServiceAccountImagePullSecrets: []string{"registry"}}},
+		{name: "service account not created", kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{ServiceAccountImagePullSecrets: []string{"registry"}}, wantErr: true},
+		{
+			name:             "custom service account",
+			kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{CreateServiceAccount: true, ServiceAccountName: "mongodb", ServiceAccountImagePullSecrets: []string{"registry"}},
+			wantErr:          true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateServiceAccountImagePullSecrets(test.kubernetesConfig); (err != nil) != test.wantErr {
+				t.Errorf("validateServiceAccountImagePullSecrets() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateServiceAccountDefImagePullSecrets(t *testing.T) {
+	tests := []struct {
+		name             string
+		imagePullSecrets []string
+		want             []corev1.LocalObjectReference
+	}{
+		{name: "no image pull secrets"},
+		{name: "image pull secrets", imagePullSecrets: []string{"registry", "mirror"}, want: []corev1.LocalObjectReference{{Name: "registry"}, {Name: "mirror"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			serviceAccount := generateServiceAccountDef(rbacParameters{RBACMeta: metav1.ObjectMeta{Name: "mongodb", Namespace: "database"}, ImagePullSecrets: test.imagePullSecrets})
+			if got := serviceAccount.ImagePullSecrets; !reflect.DeepEqual(got, test.want) {
+				t.Errorf("generateServiceAccountDef() imagePullSecrets = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
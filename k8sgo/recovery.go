@@ -0,0 +1,144 @@
+package k8sgo
+
+import (
+	"errors"
+	"fmt"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/mongo"
+	"time"
+)
+
+// defaultNoPrimaryTimeoutSeconds is the time the replica set can be without a primary before mongodb cluster is reported degraded
+const defaultNoPrimaryTimeoutSeconds int32 = 300
+
+// ErrForceReconfigUnsafe is returned when the replica set configuration cannot be forced without risking the loss of data
+var ErrForceReconfigUnsafe = errors.New("forcing the replica set configuration is not safe")
+
+// IsForceReconfigUnsafe is a method to check if the error is caused by a forced reconfiguration which was refused
+func IsForceReconfigUnsafe(err error) bool {
+	return errors.Is(err, ErrForceReconfigUnsafe)
+}
+
+// memberState is the state of a mongodb cluster member as reported by the member itself
+type memberState struct {
+	Member    mongogo.MongoDBMember
+	Status    mongogo.MongoDBMemberStatus
+	Reachable bool
+}
+
+// GetNoPrimaryTimeout is a method to get the time the replica set can be without a primary before it is recovered
+func GetNoPrimaryTimeout(cr *opstreelabsinv1alpha1.MongoDBCluster) time.Duration {
+	var timeout *int32
+	if cr.Spec.PrimaryRecovery != nil {
+		timeout = cr.Spec.PrimaryRecovery.NoPrimaryTimeoutSeconds
+	}
+	return time.Duration(*getInt32OrDefault(timeout, defaultNoPrimaryTimeoutSeconds)) * time.Second
+}
+
+// IsForceReconfigEnabled is a method to check if the configuration of a replica set without a primary can be forced
+func IsForceReconfigEnabled(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	return cr.Spec.PrimaryRecovery != nil && cr.Spec.PrimaryRecovery.ForceReconfig
+}
+
+// CheckMongoDBClusterPrimary is a method to check if any member of mongodb cluster sees a primary, the status is read from any reachable member as the primary cannot be selected without one
+func CheckMongoDBClusterPrimary(cr *opstreelabsinv1alpha1.MongoDBCluster) (bool, error) {
+	mongoParams, password, err := getMemberStatusParams(cr)
+	if err != nil {
+		return false, err
+	}
+	mongoParams.MongoURL = getMongoDBClusterURL(cr, mongoParams, password)
+	return mongogo.CheckMongoClusterPrimary(mongoParams)
+}
+
+// ForceReconfigureMongoDBCluster is a method to force the member configuration of mongodb cluster on its most recent secondary, it returns the host the configuration was forced on
+func ForceReconfigureMongoDBCluster(cr *opstreelabsinv1alpha1.MongoDBCluster) (string, error) {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Setup")
+	mongoParams, password, err := getMemberStatusParams(cr)
+	if err != nil {
+		return "", err
+	}
+	members := getMongoDBClusterMembers(cr, mongoParams)
+	var states []memberState
+	for _, member := range members {
+		states = append(states, getMongoDBMemberState(cr, mongoParams, password, member))
+	}
+	host, err := getForceReconfigMember(states)
+	if err != nil {
+		logger.Info("Skipping the forced reconfiguration of MongoDB cluster", "reason", err.Error())
+		return "", err
+	}
+	mongoParams.MongoURL = fmt.Sprintf("mongodb://%s:%s@%s/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, host)
+	mongoParams.Members = members
+	changed, err := mongogo.ForceReconfigureMongoClusterMembers(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to force the reconfiguration of MongoDB cluster", "host", host)
+		return "", err
+	}
+	if !changed {
+		return "", fmt.Errorf("%w: the replica set config of %s already matches the spec", ErrForceReconfigUnsafe, host)
+	}
+	logger.Info("Successfully forced the reconfiguration of MongoDB cluster", "host", host)
+	return host, nil
+}
+
+// getMemberStatusParams is a method to get the parameters and the admin password to connect to the members of mongodb cluster
+func getMemberStatusParams(cr *opstreelabsinv1alpha1.MongoDBCluster) (mongogo.MongoDBParameters, string, error) {
+	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return mongogo.MongoDBParameters{}, "", err
+	}
+	mongoParams := mongogo.MongoDBParameters{
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		Port:      getMongoDBPort(cr.Spec.MongoDBConfig),
+		SetupType: "cluster",
+	}
+	return mongoParams, password, nil
+}
+
+// getMongoDBMemberState is a method to get the state of a mongodb cluster member, a member which cannot report its state is unreachable
+func getMongoDBMemberState(cr *opstreelabsinv1alpha1.MongoDBCluster, mongoParams mongogo.MongoDBParameters, password string, member mongogo.MongoDBMember) memberState {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Cluster Setup")
+	mongoParams.MongoURL = fmt.Sprintf("mongodb://%s:%s@%s/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, member.Host)
+	status, err := mongogo.GetMongoClusterMemberStatus(mongoParams)
+	if err != nil {
+		logger.Info("Unable to get the state of MongoDB cluster member", "host", member.Host, "error", err.Error())
+		return memberState{Member: member}
+	}
+	return memberState{Member: member, Status: status, Reachable: true}
+}
+
+// getForceReconfigMember is a method to pick the member the configuration is forced on, it is only picked when a majority of the votes is reachable and every reachable member is a healthy secondary, an arbiter or a member which doesn't find itself in the config anymore
+func getForceReconfigMember(states []memberState) (string, error) {
+	totalVotes, reachableVotes := 0, 0
+	var candidate *memberState
+	for index := range states {
+		state := &states[index]
+		totalVotes += state.Member.Votes
+		if !state.Reachable {
+			continue
+		}
+		if state.Status.Primary != "" {
+			return "", fmt.Errorf("%w: member %s sees %s as primary", ErrForceReconfigUnsafe, state.Member.Host, state.Status.Primary)
+		}
+		switch state.Status.State {
+		case "SECONDARY", "REMOVED":
+			// the most recent member holds every write the reachable members replicated, a member whose host drifted from the config holds its data as well
+			if candidate == nil || state.Status.Optime.After(candidate.Status.Optime) {
+				candidate = state
+			}
+		case "ARBITER":
+		default:
+			return "", fmt.Errorf("%w: member %s is in state %s", ErrForceReconfigUnsafe, state.Member.Host, state.Status.State)
+		}
+		reachableVotes += state.Member.Votes
+	}
+	if reachableVotes*2 <= totalVotes {
+		return "", fmt.Errorf("%w: only %d of %d votes are reachable", ErrForceReconfigUnsafe, reachableVotes, totalVotes)
+	}
+	if candidate == nil {
+		return "", fmt.Errorf("%w: no secondary is reachable", ErrForceReconfigUnsafe)
+	}
+	return candidate.Member.Host, nil
+}
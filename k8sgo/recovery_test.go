@@ -0,0 +1,145 @@
+package k8sgo
+
+import (
+	"testing"
+	"time"
+
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/mongo"
+)
+
+func TestGetNoPrimaryTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		recovery *opstreelabsinv1alpha1.MongoDBPrimaryRecovery
+		want     time.Duration
+	}{
+		{name: "no primary recovery", want: 300 * time.Second},
+		{name: "timeout unset", recovery: &opstreelabsinv1alpha1.MongoDBPrimaryRecovery{ForceReconfig: true}, want: 300 * time.Second},
+		{name: "custom timeout", recovery: &opstreelabsinv1alpha1.MongoDBPrimaryRecovery{NoPrimaryTimeoutSeconds: int32Ptr(60)}, want: 60 * time.Second},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := &opstreelabsinv1alpha1.MongoDBCluster{}
+			cr.Spec.PrimaryRecovery = test.recovery
+			if got := GetNoPrimaryTimeout(cr); got != test.want {
+				t.Errorf("GetNoPrimaryTimeout() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsForceReconfigEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		recovery *opstreelabsinv1alpha1.MongoDBPrimaryRecovery
+		want     bool
+	}{
+		{name: "no primary recovery"},
+		{name: "force reconfig disabled", recovery: &opstreelabsinv1alpha1.MongoDBPrimaryRecovery{}},
+		{name: "force reconfig enabled", recovery: &opstreelabsinv1alpha1.MongoDBPrimaryRecovery{ForceReconfig: true}, want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := &opstreelabsinv1alpha1.MongoDBCluster{}
+			cr.Spec.PrimaryRecovery = test.recovery
+			if got := IsForceReconfigEnabled(cr); got != test.want {
+				t.Errorf("IsForceReconfigEnabled() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetForceReconfigMember(t *testing.T) {
+	now := time.Now()
+	member := func(host string) mongogo.MongoDBMember {
+		return mongogo.MongoDBMember{Host: host, Votes: 1}
+	}
+	reachable := func(host, state string, optime time.Time) memberState {
+		return memberState{Member: member(host), Status: mongogo.MongoDBMemberStatus{State: state, Optime: optime}, Reachable: true}
+	}
+	tests := []struct {
+		name    string
+		states  []memberState
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "most recent secondary",
+			states: []memberState{
+				reachable("mongodb-0", "SECONDARY", now.Add(-time.Minute)),
+				reachable("mongodb-1", "SECONDARY", now),
+				{Member: member("mongodb-2")},
+			},
+			want: "mongodb-1",
+		},
+		{
+			name: "secondary and arbiter",
+			states: []memberState{
+				reachable("mongodb-0", "SECONDARY", now),
+				reachable("mongodb-arbiter-0", "ARBITER", time.Time{}),
+				{Member: member("mongodb-1")},
+			},
+			want: "mongodb-0",
+		},
+		{
+			name: "removed member",
+			states: []memberState{
+				reachable("mongodb-0", "REMOVED", now),
+				reachable("mongodb-1", "SECONDARY", now.Add(-time.Minute)),
+				{Member: member("mongodb-2")},
+			},
+			want: "mongodb-0",
+		},
+		{
+			name: "member sees a primary",
+			states: []memberState{
+				reachable("mongodb-0", "SECONDARY", now),
+				{Member: member("mongodb-1"), Status: mongogo.MongoDBMemberStatus{State: "SECONDARY", Primary: "mongodb-2"}, Reachable: true},
+				{Member: member("mongodb-2")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "minority reachable",
+			states: []memberState{
+				reachable("mongodb-0", "SECONDARY", now),
+				{Member: member("mongodb-1")},
+				{Member: member("mongodb-2")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "recovering member",
+			states: []memberState{
+				reachable("mongodb-0", "SECONDARY", now),
+				reachable("mongodb-1", "RECOVERING", now),
+				{Member: member("mongodb-2")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "no secondary",
+			states: []memberState{
+				reachable("mongodb-arbiter-0", "ARBITER", time.Time{}),
+				reachable("mongodb-arbiter-1", "ARBITER", time.Time{}),
+				{Member: member("mongodb-0")},
+			},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := getForceReconfigMember(test.states)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("getForceReconfigMember() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if err != nil && !IsForceReconfigUnsafe(err) {
+				t.Errorf("IsForceReconfigUnsafe(%v) = false, want true", err)
+			}
+			if got != test.want {
+				t.Errorf("getForceReconfigMember() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
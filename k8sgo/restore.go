@@ -0,0 +1,191 @@
+package k8sgo
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"path"
+)
+
+const (
+	restoreVolumeName   = "restore-data"
+	restoreMountPath    = "/restore"
+	restoreArchiveFile  = "/restore/mongodb.archive"
+	restoreMarkerFile   = ".mongodb-operator-restore-completed"
+	defaultRestoreImage = "amazon/aws-cli:2.7.0"
+	// restoreCABundleVolumeName is the volume of the CA bundle trusted by the backup download
+	restoreCABundleVolumeName = "restore-ca-bundle"
+	restoreCABundleMountPath  = "/etc/ssl/restore"
+	defaultCABundleKey        = "ca.crt"
+)
+
+// restoreParameters is the input struct for MongoDB restore on bootstrap
+type restoreParameters struct {
+	S3BackupPath    string
+	S3Endpoint      *string
+	S3Region        *string
+	S3SecretName    *string
+	Image           string
+	ImagePullPolicy corev1.PullPolicy
+	Resources       *corev1.ResourceRequirements
+	CABundle        *opstreelabsinv1alpha1.CABundleSource
+}
+
+// getRestoreParams is a method to generate restore params, it returns nil if restore is not required
+// the init containers are kept after the restore completed, so the pod template doesn't change and roll the pods, the marker file skips them instead
+func getRestoreParams(restore *opstreelabsinv1alpha1.MongoDBRestore) *restoreParameters {
+	if restore == nil {
+		return nil
+	}
+	params := &restoreParameters{
+		S3BackupPath:    restore.S3BackupPath,
+		S3Endpoint:      restore.S3Endpoint,
+		S3Region:        restore.S3Region,
+		S3SecretName:    restore.S3SecretRef,
+		Image:           restore.Image,
+		ImagePullPolicy: restore.ImagePullPolicy,
+		Resources:       restore.Resources,
+		CABundle:        restore.CABundle,
+	}
+	if params.Image == "" {
+		params.Image = defaultRestoreImage
+	}
+	return params
+}
+
+// generateRestoreInitContainers is a method to generate init containers for restoring MongoDB from a backup
+func generateRestoreInitContainers(name string, params statefulSetParameters) []corev1.Container {
+	dbPath := getDBPath(params.ContainerParams.DBPath)
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      name,
+			MountPath: dbPath,
+		},
+		{
+			Name:      restoreVolumeName,
+			MountPath: restoreMountPath,
+		},
+	}
+	downloadContainer := corev1.Container{
+		Name:            "restore-download",
+		Image:           params.RestoreParams.Image,
+		ImagePullPolicy: getImagePullPolicy(params.RestoreParams.Image, params.RestoreParams.ImagePullPolicy),
+		Command:         []string{"/bin/sh", "-c", getRestoreDownloadScript(params.RestoreParams, dbPath)},
+		VolumeMounts:    volumeMounts,
+	}
+	if params.RestoreParams.S3Region != nil {
+		downloadContainer.Env = append(downloadContainer.Env, corev1.EnvVar{
+			Name:  "AWS_DEFAULT_REGION",
+			Value: *params.RestoreParams.S3Region,
+		})
+	}
+	if params.RestoreParams.CABundle != nil {
+		downloadContainer.VolumeMounts = append(downloadContainer.VolumeMounts, corev1.VolumeMount{
+			Name:      restoreCABundleVolumeName,
+			MountPath: restoreCABundleMountPath,
+			ReadOnly:  true,
+		})
+		downloadContainer.Env = append(downloadContainer.Env, corev1.EnvVar{
+			Name:  "AWS_CA_BUNDLE",
+			Value: path.Join(restoreCABundleMountPath, getCABundleKey(params.RestoreParams.CABundle)),
+		})
+	}
+	if params.RestoreParams.S3SecretName != nil {
+		downloadContainer.EnvFrom = []corev1.EnvFromSource{
+			{
+				SecretRef: &corev1.SecretEnvSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: *params.RestoreParams.S3SecretName,
+					},
+				},
+			},
+		}
+	}
+	restoreContainer := corev1.Container{
+		Name:            "restore",
+		Image:           params.ContainerParams.Image,
+		ImagePullPolicy: getImagePullPolicy(params.ContainerParams.Image, params.ContainerParams.ImagePullPolicy),
+		Command:         []string{"/bin/sh", "-c", getRestoreScript(dbPath)},
+		VolumeMounts:    volumeMounts,
+	}
+	if params.RestoreParams.Resources != nil {
+		downloadContainer.Resources = *params.RestoreParams.Resources
+		restoreContainer.Resources = *params.RestoreParams.Resources
+	}
+	return []corev1.Container{downloadContainer, restoreContainer}
+}
+
+// getRestoreDownloadScript is a method to generate the script for downloading the backup archive
+func getRestoreDownloadScript(params *restoreParameters, dbPath string) string {
+	downloadCommand := fmt.Sprintf("aws s3 cp %s %s", params.S3BackupPath, restoreArchiveFile)
+	if params.S3Endpoint != nil {
+		downloadCommand = fmt.Sprintf("%s --endpoint-url %s", downloadCommand, *params.S3Endpoint)
+	}
+	return fmt.Sprintf(`set -e
+if [ -f %[1]s ]; then echo "Restore is already completed, skipping download"; exit 0; fi
+case "$(hostname)" in *-0) ;; *) echo "Restore only runs on the first member, skipping download"; exit 0 ;; esac
+%[2]s`, path.Join(dbPath, restoreMarkerFile), downloadCommand)
+}
+
+// getRestoreScript is a method to generate the script for restoring the backup archive with mongorestore
+func getRestoreScript(dbPath string) string {
+	return fmt.Sprintf(`set -e
+if [ -f %[1]s ]; then echo "Restore is already completed, skipping restore"; exit 0; fi
+if [ ! -f %[2]s ]; then echo "No backup archive found, skipping restore"; exit 0; fi
+mongod --dbpath %[4]s --bind_ip 127.0.0.1 --port %[3]d --fork --logpath /tmp/mongod-restore.log
+mongorestore --host 127.0.0.1 --port %[3]d --archive=%[2]s --gzip
+mongod --dbpath %[4]s --shutdown
+rm -f %[2]s
+touch %[1]s`, path.Join(dbPath, restoreMarkerFile), restoreArchiveFile, mongoDBPort, dbPath)
+}
+
+// getRestoreVolume is a method to generate the scratch volume for the backup archive
+func getRestoreVolume() corev1.Volume {
+	return corev1.Volume{
+		Name: restoreVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	}
+}
+
+// getRestoreCABundleVolume is a method to generate the volume of the CA bundle trusted by the backup download
+func getRestoreCABundleVolume(caBundle *opstreelabsinv1alpha1.CABundleSource) corev1.Volume {
+	items := []corev1.KeyToPath{{Key: getCABundleKey(caBundle), Path: getCABundleKey(caBundle)}}
+	if caBundle.SecretName != nil {
+		return corev1.Volume{
+			Name: restoreCABundleVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{SecretName: *caBundle.SecretName, Items: items},
+			},
+		}
+	}
+	return corev1.Volume{
+		Name: restoreCABundleVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: *caBundle.ConfigMapName},
+				Items:                items,
+			},
+		},
+	}
+}
+
+// getCABundleKey is a method to get the key of the CA bundle in its configmap or secret, it defaults to ca.crt
+func getCABundleKey(caBundle *opstreelabsinv1alpha1.CABundleSource) string {
+	if caBundle.Key == "" {
+		return defaultCABundleKey
+	}
+	return caBundle.Key
+}
+
+// validateRestoreCABundle is a method to validate that the CA bundle of the restore is read from exactly one configmap or secret
+func validateRestoreCABundle(restore *opstreelabsinv1alpha1.MongoDBRestore) error {
+	if restore == nil || restore.CABundle == nil {
+		return nil
+	}
+	if (restore.CABundle.ConfigMapName == nil) == (restore.CABundle.SecretName == nil) {
+		return fmt.Errorf("mongoDBRestore caBundle requires exactly one of configMapName or secretName")
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package k8sgo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestGenerateRestoreInitContainers(t *testing.T) {
+	tests := []struct {
+		name           string
+		restore        *opstreelabsinv1alpha1.MongoDBRestore
+		wantContainers int
+	}{
+		{name: "no restore"},
+		{name: "restore", restore: &opstreelabsinv1alpha1.MongoDBRestore{S3BackupPath: "s3://backups/mongodb.archive"}, wantContainers: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params := statefulSetParameters{RestoreParams: getRestoreParams(test.restore)}
+			var containers int
+			if params.RestoreParams != nil {
+				containers = len(generateRestoreInitContainers("mongodb", params))
+			}
+			if containers != test.wantContainers {
+				t.Errorf("generateRestoreInitContainers() = %d containers, want %d", containers, test.wantContainers)
+			}
+		})
+	}
+}
+
+func TestGetRestoreScript(t *testing.T) {
+	// the init containers stay in the pod template after the restore, so the scripts have to skip on the marker file
+	for _, script := range []string{getRestoreDownloadScript(&restoreParameters{S3BackupPath: "s3://backups/mongodb.archive"}, defaultDBPath), getRestoreScript(defaultDBPath)} {
+		if !strings.Contains(script, "if [ -f /data/db/"+restoreMarkerFile+" ]") {
+			t.Errorf("restore script doesn't skip on the marker file:\n%s", script)
+		}
+	}
+}
+
+func TestGetRestoreCABundleVolume(t *testing.T) {
+	configMapName := "mongodb-ca"
+	secretName := "mongodb-ca-secret"
+	tests := []struct {
+		name     string
+		caBundle *opstreelabsinv1alpha1.CABundleSource
+		want     corev1.VolumeSource
+	}{
+		{
+			name:     "configmap with the default key",
+			caBundle: &opstreelabsinv1alpha1.CABundleSource{ConfigMapName: &configMapName},
+			want:     corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: configMapName}, Items: []corev1.KeyToPath{{Key: defaultCABundleKey, Path: defaultCABundleKey}}}},
+		},
+		{
+			name:     "secret with a custom key",
+			caBundle: &opstreelabsinv1alpha1.CABundleSource{SecretName: &secretName, Key: "bundle.pem"},
+			want:     corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secretName, Items: []corev1.KeyToPath{{Key: "bundle.pem", Path: "bundle.pem"}}}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			volume := getRestoreCABundleVolume(test.caBundle)
+			if volume.Name != restoreCABundleVolumeName || !reflect.DeepEqual(volume.VolumeSource, test.want) {
+				t.Errorf("getRestoreCABundleVolume() = %v, want %v", volume.VolumeSource, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateRestoreCABundle(t *testing.T) {
+	name := "mongodb-ca"
+	tests := []struct {
+		name     string
+		caBundle *opstreelabsinv1alpha1.CABundleSource
+		wantErr  bool
+	}{
+		{name: "no CA bundle"},
+		{name: "configmap", caBundle: &opstreelabsinv1alpha1.CABundleSource{ConfigMapName: &name}},
+		{name: "secret", caBundle: &opstreelabsinv1alpha1.CABundleSource{SecretName: &name}},
+		{name: "no source", caBundle: &opstreelabsinv1alpha1.CABundleSource{}, wantErr: true},
+		{name: "configmap and secret", caBundle: &opstreelabsinv1alpha1.CABundleSource{ConfigMapName: &name, SecretName: &name}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			restore := &opstreelabsinv1alpha1.MongoDBRestore{S3BackupPath: "s3://backups/mongodb.archive", CABundle: test.caBundle}
+			if err := validateRestoreCABundle(restore); (err != nil) != test.wantErr {
+				t.Errorf("validateRestoreCABundle() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package k8sgo
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+// getSeccompProfile is a method to get the seccomp profile of MongoDB pods, the profile of kubernetesConfig takes precedence over the one of securityContext and RuntimeDefault is used when neither is set
+func getSeccompProfile(kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig) *corev1.SeccompProfile {
+	if kubernetesConfig.SeccompProfile != nil {
+		return kubernetesConfig.SeccompProfile
+	}
+	if kubernetesConfig.SecurityContext != nil && kubernetesConfig.SecurityContext.SeccompProfile != nil {
+		return kubernetesConfig.SecurityContext.SeccompProfile
+	}
+	return &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+}
+
+// validateSeccompProfile is a method to validate that only a Localhost seccomp profile sets the path of its profile, which it requires
+func validateSeccompProfile(kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig) error {
+	profile := getSeccompProfile(kubernetesConfig)
+	switch profile.Type {
+	case corev1.SeccompProfileTypeLocalhost:
+		if profile.LocalhostProfile == nil || *profile.LocalhostProfile == "" {
+			return fmt.Errorf("seccompProfile of type Localhost requires localhostProfile")
+		}
+	case corev1.SeccompProfileTypeRuntimeDefault, corev1.SeccompProfileTypeUnconfined:
+		if profile.LocalhostProfile != nil {
+			return fmt.Errorf("localhostProfile can only be set for seccompProfile of type Localhost, not %s", profile.Type)
+		}
+	default:
+		return fmt.Errorf("seccompProfile type %s must be one of RuntimeDefault, Localhost or Unconfined", profile.Type)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package k8sgo
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestGetSeccompProfile(t *testing.T) {
+	localhostProfile := "profiles/mongod.json"
+	tests := []struct {
+		name             string
+		kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig
+		want             *corev1.SeccompProfile
+	}{
+		{
+			name: "RuntimeDefault by default",
+			want: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+		},
+		{
+			name: "profile of the security context",
+			kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{
+				SecurityContext: &corev1.PodSecurityContext{SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}},
+			},
+			want: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined},
+		},
+		{
+			name: "profile of kubernetesConfig takes precedence",
+			kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{
+				SecurityContext: &corev1.PodSecurityContext{SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}},
+				SeccompProfile:  &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost, LocalhostProfile: &localhostProfile},
+			},
+			want: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost, LocalhostProfile: &localhostProfile},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getSeccompProfile(test.kubernetesConfig); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getSeccompProfile() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateSeccompProfile(t *testing.T) {
+	localhostProfile := "profiles/mongod.json"
+	tests := []struct {
+		name    string
+		profile *corev1.SeccompProfile
+		wantErr bool
+	}{
+		{name: "RuntimeDefault by default"},
+		{name: "RuntimeDefault", profile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}},
+		{name: "Localhost with a path", profile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost, LocalhostProfile: &localhostProfile}},
+		{name: "Localhost without a path", profile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost}, wantErr: true},
+		{name: "Unconfined", profile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}},
+		{name: "Unconfined with a path", profile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined, LocalhostProfile: &localhostProfile}, wantErr: true},
+		{name: "unknown type", profile: &corev1.SeccompProfile{Type: "Custom"}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validateSeccompProfile(opstreelabsinv1alpha1.KubernetesConfig{SeccompProfile: test.profile})
+			if (err != nil) != test.wantErr {
+				t.Errorf("validateSeccompProfile() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package k8sgo
 
 import (
 	"context"
+	"fmt"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -23,7 +24,12 @@ type secretsParameters struct {
 func CreateSecret(params secretsParameters) error {
 	secretDef := generateSecret(params)
 	logger := logGenerator(params.Name, params.Namespace, "Secret")
-	_, err := generateK8sClient().CoreV1().Secrets(params.Namespace).Create(context.TODO(), secretDef, metav1.CreateOptions{})
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	_, err = client.CoreV1().Secrets(params.Namespace).Create(context.TODO(), secretDef, metav1.CreateOptions{})
 	if err != nil {
 		logger.Error(err, "MongoDB secret creation is failed")
 		return err
@@ -47,20 +53,33 @@ func generateSecret(params secretsParameters) *corev1.Secret {
 }
 
 // getMongoDBPassword method will return the mongodb password
-func getMongoDBPassword(params secretsParameters) string {
+func getMongoDBPassword(params secretsParameters) (string, error) {
 	logger := logGenerator(params.Name, params.Namespace, "Secret")
-	secretName, err := generateK8sClient().CoreV1().Secrets(params.Namespace).Get(context.TODO(), params.SecretName, metav1.GetOptions{})
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return "", err
+	}
+	secretName, err := client.CoreV1().Secrets(params.Namespace).Get(context.TODO(), params.SecretName, metav1.GetOptions{})
 	if err != nil {
 		logger.Error(err, "Failed in getting existing secret for mongodb admin")
+		return "", err
 	}
-	value := string(secretName.Data[params.SecretKey])
-	return value
+	value, ok := secretName.Data[params.SecretKey]
+	if !ok || len(value) == 0 {
+		return "", fmt.Errorf("secret %s has no password in key %s", params.SecretName, params.SecretKey)
+	}
+	return string(value), nil
 }
 
 //nolint:gosimple
 // CheckSecretExist is a method to check secret exists
 func CheckSecretExist(namespace string, secret string) bool {
-	_, err := generateK8sClient().CoreV1().Secrets(namespace).Get(context.TODO(), secret, metav1.GetOptions{})
+	client, err := generateK8sClient()
+	if err != nil {
+		return false
+	}
+	_, err = client.CoreV1().Secrets(namespace).Get(context.TODO(), secret, metav1.GetOptions{})
 	if err != nil {
 		return false
 	}
@@ -2,28 +2,44 @@ package k8sgo
 
 import (
 	"context"
+	"fmt"
 	"github.com/banzaicloud/k8s-objectmatcher/patch"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/mongo"
+	"strings"
 )
 
 const (
 	mongoDBPort           = 27017
 	mongoDBMonitoringPort = 9216
+	podNameLabel          = "statefulset.kubernetes.io/pod-name"
 )
 
 // serviceParameters is a structure for service inputs
 type serviceParameters struct {
-	ServiceMeta     metav1.ObjectMeta
-	OwnerDef        metav1.OwnerReference
-	Labels          map[string]string
-	Annotations     map[string]string
-	Namespace       string
-	HeadlessService bool
-	Port            int32
-	PortName        string
+	ServiceMeta              metav1.ObjectMeta
+	OwnerDef                 metav1.OwnerReference
+	Labels                   map[string]string
+	Annotations              map[string]string
+	Namespace                string
+	HeadlessService          bool
+	Port                     int32
+	ServicePort              *int32
+	PortName                 string
+	ServiceType              corev1.ServiceType
+	NodePort                 *int32
+	SourceRanges             []string
+	ExternalTrafficPolicy    corev1.ServiceExternalTrafficPolicyType
+	SessionAffinity          corev1.ServiceAffinity
+	SessionAffinityTimeout   *int32
+	ExternalName             string
+	PublishNotReadyAddresses bool
+	ExtraPorts               []corev1.ServicePort
 }
 
 // CreateOrUpdateService method will create or update MongoDB service
@@ -51,6 +67,10 @@ func patchService(storedService *corev1.Service, newService *corev1.Service, nam
 	newService.CreationTimestamp = storedService.CreationTimestamp
 	newService.ManagedFields = storedService.ManagedFields
 	newService.Spec.ClusterIP = storedService.Spec.ClusterIP
+	// the health check port is allocated for LoadBalancer services with the Local policy
+	if newService.Spec.Type == corev1.ServiceTypeLoadBalancer && newService.Spec.ExternalTrafficPolicy == corev1.ServiceExternalTrafficPolicyTypeLocal {
+		newService.Spec.HealthCheckNodePort = storedService.Spec.HealthCheckNodePort
+	}
 
 	patchResult, err := patch.DefaultPatchMaker.Calculate(storedService, newService,
 		patch.IgnoreStatusFields(),
@@ -62,11 +82,7 @@ func patchService(storedService *corev1.Service, newService *corev1.Service, nam
 		return err
 	}
 	if !patchResult.IsEmpty() {
-		for key, value := range storedService.Annotations {
-			if _, present := newService.Annotations[key]; !present {
-				newService.Annotations[key] = value
-			}
-		}
+		newService.Annotations = preserveExternalAnnotations(storedService.Annotations, newService.Annotations)
 		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(newService); err != nil {
 			logger.Error(err, "Unable to patch MongoDB service with comparison object")
 			return err
@@ -81,7 +97,12 @@ func patchService(storedService *corev1.Service, newService *corev1.Service, nam
 // createService is a method to create service
 func createService(namespace string, service *corev1.Service) error {
 	logger := logGenerator(service.Name, namespace, "Service")
-	_, err := generateK8sClient().CoreV1().Services(namespace).Create(context.TODO(), service, metav1.CreateOptions{})
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	_, err = client.CoreV1().Services(namespace).Create(context.TODO(), service, metav1.CreateOptions{})
 	if err != nil {
 		logger.Error(err, "MongoDB service creation is failed")
 		return err
@@ -93,7 +114,12 @@ func createService(namespace string, service *corev1.Service) error {
 // updateService is a method to update service
 func updateService(namespace string, service *corev1.Service) error {
 	logger := logGenerator(service.Name, namespace, "Service")
-	_, err := generateK8sClient().CoreV1().Services(namespace).Update(context.TODO(), service, metav1.UpdateOptions{})
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	_, err = client.CoreV1().Services(namespace).Update(context.TODO(), service, metav1.UpdateOptions{})
 	if err != nil {
 		logger.Error(err, "MongoDB service updation is failed")
 		return err
@@ -105,7 +131,12 @@ func updateService(namespace string, service *corev1.Service) error {
 // getService is a method to get service
 func getService(namespace string, service string) (*corev1.Service, error) {
 	logger := logGenerator(service, namespace, "Service")
-	serviceInfo, err := generateK8sClient().CoreV1().Services(namespace).Get(context.TODO(), service, metav1.GetOptions{})
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return nil, err
+	}
+	serviceInfo, err := client.CoreV1().Services(namespace).Get(context.TODO(), service, metav1.GetOptions{})
 	if err != nil {
 		logger.Info("MongoDB service get action is failed")
 		return nil, err
@@ -114,7 +145,131 @@ func getService(namespace string, service string) (*corev1.Service, error) {
 	return serviceInfo, nil
 }
 
-// generateServiceDef is a method to generate service definition
+// listServices is a method to list services matching the label selector
+func listServices(namespace string, labelSelector string) (*corev1.ServiceList, error) {
+	logger := logGenerator(labelSelector, namespace, "Service")
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return nil, err
+	}
+	serviceList, err := client.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		logger.Error(err, "MongoDB service list action is failed")
+		return nil, err
+	}
+	return serviceList, nil
+}
+
+// deleteService is a method to delete service
+func deleteService(namespace string, service string) error {
+	logger := logGenerator(service, namespace, "Service")
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	err = client.CoreV1().Services(namespace).Delete(context.TODO(), service, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		logger.Error(err, "MongoDB service deletion is failed")
+		return err
+	}
+	logger.Info("MongoDB service deletion is successful")
+	return nil
+}
+
+// getExtraServicePorts is a method to default the target port and protocol of the extra service ports like the API server does, so they don't show up as a change
+func getExtraServicePorts(ports []corev1.ServicePort) []corev1.ServicePort {
+	var servicePorts []corev1.ServicePort
+	for _, port := range ports {
+		if port.TargetPort.Type == intstr.Int && port.TargetPort.IntVal == 0 {
+			port.TargetPort = intstr.FromInt(int(port.Port))
+		}
+		if port.Protocol == "" {
+			port.Protocol = corev1.ProtocolTCP
+		}
+		servicePorts = append(servicePorts, port)
+	}
+	return servicePorts
+}
+
+// CreateOrDeleteClientService method will create or delete the MongoDB client service based on service config
+func CreateOrDeleteClientService(params serviceParameters, serviceConfig *opstreelabsinv1alpha1.ServiceConfig) error {
+	if serviceConfig == nil {
+		return deleteService(params.Namespace, params.ServiceMeta.Name)
+	}
+	for key, value := range serviceConfig.ServiceAnnotations {
+		params.ServiceMeta.Annotations[key] = value
+	}
+	params = applyServiceMetadata(params, serviceConfig.ServiceLabels, nil)
+	params.HeadlessService = false
+	params.ServiceType = serviceConfig.ServiceType
+	params.NodePort = serviceConfig.NodePort
+	params.SourceRanges = serviceConfig.LoadBalancerSourceRanges
+	params.ExternalTrafficPolicy = serviceConfig.ExternalTrafficPolicy
+	params.SessionAffinity = serviceConfig.SessionAffinity
+	params.SessionAffinityTimeout = serviceConfig.SessionAffinityTimeoutSeconds
+	params.ServicePort = serviceConfig.Port
+	return CreateOrUpdateService(params)
+}
+
+// validateClientService is a method to validate that the session affinity timeout of the client service is only set for the ClientIP affinity, and that its port is not used by the extra service ports
+func validateClientService(kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig) error {
+	serviceConfig := kubernetesConfig.Service
+	if serviceConfig == nil {
+		return nil
+	}
+	if serviceConfig.SessionAffinityTimeoutSeconds != nil && serviceConfig.SessionAffinity != corev1.ServiceAffinityClientIP {
+		return fmt.Errorf("sessionAffinityTimeoutSeconds requires sessionAffinity ClientIP")
+	}
+	if serviceConfig.Port == nil {
+		return nil
+	}
+	for _, servicePort := range kubernetesConfig.ExtraServicePorts {
+		if servicePort.Port == *serviceConfig.Port {
+			return fmt.Errorf("service port %d is already used by extraServicePorts %s", *serviceConfig.Port, servicePort.Name)
+		}
+	}
+	return nil
+}
+
+// applyHeadlessServiceConfig is a method to add the labels and annotations configured for the headless service, the ExternalName alias doesn't get them
+func applyHeadlessServiceConfig(params serviceParameters, config *opstreelabsinv1alpha1.HeadlessServiceConfig) serviceParameters {
+	if config == nil {
+		return params
+	}
+	return applyServiceMetadata(params, config.Labels, config.Annotations)
+}
+
+// applyServiceMetadata is a method to add the labels and annotations of a single service, the selector labels and the annotations managed by the operator cannot be overridden
+func applyServiceMetadata(params serviceParameters, labels map[string]string, annotations map[string]string) serviceParameters {
+	serviceLabels := make(map[string]string)
+	for key, value := range params.ServiceMeta.Labels {
+		serviceLabels[key] = value
+	}
+	for key, value := range labels {
+		if _, ok := params.Labels[key]; !ok {
+			serviceLabels[key] = value
+		}
+	}
+	serviceAnnotations := make(map[string]string)
+	for key, value := range params.ServiceMeta.Annotations {
+		serviceAnnotations[key] = value
+	}
+	for key, value := range annotations {
+		if !isOperatorManagedAnnotation(key) {
+			serviceAnnotations[key] = value
+		}
+	}
+	params.ServiceMeta.Labels = serviceLabels
+	params.ServiceMeta.Annotations = serviceAnnotations
+	return params
+}
+
+// generateServiceDef is a method to generate service definition, the service port defaults to the container port it targets
 func generateServiceDef(params serviceParameters) *corev1.Service {
 	service := &corev1.Service{
 		TypeMeta:   generateMetaInformation("Service", "core/v1"),
@@ -124,16 +279,200 @@ func generateServiceDef(params serviceParameters) *corev1.Service {
 			Ports: []corev1.ServicePort{
 				{
 					Name:       params.PortName,
-					Port:       params.Port,
+					Port:       *getInt32OrDefault(params.ServicePort, params.Port),
 					TargetPort: intstr.FromInt(int(params.Port)),
 					Protocol:   corev1.ProtocolTCP,
 				},
 			},
 		},
 	}
+	service.Spec.Ports = append(service.Spec.Ports, getExtraServicePorts(params.ExtraPorts)...)
 	if params.HeadlessService {
 		service.Spec.ClusterIP = "None"
+		service.Spec.PublishNotReadyAddresses = params.PublishNotReadyAddresses
+	}
+	if params.ExternalName != "" {
+		service.Spec = corev1.ServiceSpec{
+			Type:         corev1.ServiceTypeExternalName,
+			ExternalName: params.ExternalName,
+		}
+	}
+	if params.ServiceType != "" {
+		service.Spec.Type = params.ServiceType
+	}
+	if params.NodePort != nil {
+		if params.ServiceType == corev1.ServiceTypeNodePort || params.ServiceType == corev1.ServiceTypeLoadBalancer {
+			service.Spec.Ports[0].NodePort = *params.NodePort
+		} else {
+			log.Info("Ignoring nodePort for service type", "Name", params.ServiceMeta.Name, "Type", params.ServiceType)
+		}
+	}
+	if len(params.SourceRanges) > 0 {
+		if params.ServiceType == corev1.ServiceTypeLoadBalancer {
+			service.Spec.LoadBalancerSourceRanges = params.SourceRanges
+		} else {
+			log.Info("Ignoring loadBalancerSourceRanges for service type", "Name", params.ServiceMeta.Name, "Type", params.ServiceType)
+		}
+	}
+	if params.ExternalTrafficPolicy != "" {
+		// the policy only applies to the traffic reaching the service from outside of Kubernetes
+		if params.ServiceType == corev1.ServiceTypeNodePort || params.ServiceType == corev1.ServiceTypeLoadBalancer {
+			service.Spec.ExternalTrafficPolicy = params.ExternalTrafficPolicy
+		} else {
+			log.Info("Ignoring externalTrafficPolicy for service type", "Name", params.ServiceMeta.Name, "Type", params.ServiceType)
+		}
+	}
+	if params.SessionAffinity != "" {
+		service.Spec.SessionAffinity = params.SessionAffinity
+		if params.SessionAffinity == corev1.ServiceAffinityClientIP && params.SessionAffinityTimeout != nil {
+			timeout := *params.SessionAffinityTimeout
+			service.Spec.SessionAffinityConfig = &corev1.SessionAffinityConfig{
+				ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: &timeout},
+			}
+		}
+	}
+	// owner references cannot point to another namespace, such services are deleted on cleanup instead
+	if params.OwnerDef.Name != "" {
+		AddOwnerRefToObject(service, params.OwnerDef)
 	}
-	AddOwnerRefToObject(service, params.OwnerDef)
 	return service
 }
+
+// deleteLoadBalancerServices is a method to delete the LoadBalancer services matching the label selector
+func deleteLoadBalancerServices(namespace string, labelSelector string) error {
+	serviceList, err := listServices(namespace, labelSelector)
+	if err != nil {
+		return err
+	}
+	for _, service := range getServicesOfType(serviceList.Items, corev1.ServiceTypeLoadBalancer) {
+		err = deleteService(namespace, service.Name)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getServicesOfType is a method to filter the services by their type
+func getServicesOfType(services []corev1.Service, serviceType corev1.ServiceType) []corev1.Service {
+	var filtered []corev1.Service
+	for _, service := range services {
+		if service.Spec.Type == serviceType {
+			filtered = append(filtered, service)
+		}
+	}
+	return filtered
+}
+
+// CreateOrUpdateExternalNameService method will create or update the ExternalName alias of MongoDB service when it is configured, the aliases which are not configured anymore are deleted
+func CreateOrUpdateExternalNameService(target serviceParameters, config *opstreelabsinv1alpha1.ExternalNameServiceConfig, clusterDomain string) error {
+	if err := deleteExternalNameServices(target.OwnerDef, config, target.Namespace); err != nil {
+		return err
+	}
+	if config == nil {
+		return nil
+	}
+	params := getExternalNameServiceParams(target, config, clusterDomain)
+	storedService, err := getService(params.Namespace, params.ServiceMeta.Name)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	// the alias can live in any namespace, so a service which wasn't created for this MongoDB is never overwritten
+	if err == nil && !isExternalNameServiceManaged(storedService, target.OwnerDef, params.ExternalName) {
+		return fmt.Errorf("service %s/%s already exists and is not an ExternalName alias managed by the operator", params.Namespace, params.ServiceMeta.Name)
+	}
+	return CreateOrUpdateService(params)
+}
+
+// getExternalNameServiceParams is a method to generate the params of an ExternalName service pointing at the target service
+func getExternalNameServiceParams(target serviceParameters, config *opstreelabsinv1alpha1.ExternalNameServiceConfig, clusterDomain string) serviceParameters {
+	namespace := getExternalNameServiceNamespace(config, target.Namespace)
+	labels := mergeLabels(map[string]string{
+		managedByLabel:         managedByOperator,
+		externalNameOwnerLabel: string(target.OwnerDef.UID),
+	}, target.ServiceMeta.Labels)
+	params := serviceParameters{
+		ServiceMeta:  generateObjectMetaInformation(config.Name, namespace, labels, target.ServiceMeta.Annotations),
+		Namespace:    namespace,
+		ExternalName: getServiceFQDN(target.ServiceMeta.Name, target.Namespace, clusterDomain),
+	}
+	if namespace == target.Namespace {
+		params.OwnerDef = target.OwnerDef
+	}
+	return params
+}
+
+// isExternalNameServiceManaged is a method to check if the service is the ExternalName alias of the owner, an alias created before it was labeled is recognized by its target
+func isExternalNameServiceManaged(service *corev1.Service, owner metav1.OwnerReference, externalName string) bool {
+	if service.Spec.Type != corev1.ServiceTypeExternalName {
+		return false
+	}
+	if service.Labels[managedByLabel] == managedByOperator && service.Labels[externalNameOwnerLabel] == string(owner.UID) {
+		return true
+	}
+	return service.Labels[externalNameOwnerLabel] == "" && service.Spec.ExternalName == externalName
+}
+
+// getExternalNameServiceNamespace is a method to get the namespace of ExternalName service, defaulting to the namespace of MongoDB
+func getExternalNameServiceNamespace(config *opstreelabsinv1alpha1.ExternalNameServiceConfig, namespace string) string {
+	if config.Namespace != "" {
+		return config.Namespace
+	}
+	return namespace
+}
+
+// deleteExternalNameServices is a method to delete the ExternalName aliases of the owner in every namespace except the configured one, a nil config deletes all of them
+func deleteExternalNameServices(owner metav1.OwnerReference, config *opstreelabsinv1alpha1.ExternalNameServiceConfig, namespace string) error {
+	if owner.UID == "" {
+		return nil
+	}
+	serviceList, err := listServices(metav1.NamespaceAll, fmt.Sprintf("%s=%s,%s=%s", managedByLabel, managedByOperator, externalNameOwnerLabel, owner.UID))
+	if err != nil {
+		return err
+	}
+	for _, service := range serviceList.Items {
+		if config != nil && service.Namespace == getExternalNameServiceNamespace(config, namespace) && service.Name == config.Name {
+			continue
+		}
+		if err := deleteService(service.Namespace, service.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateExternalNameService is a method to validate the name and namespace of ExternalName service and the DNS name it points at
+func validateExternalNameService(config *opstreelabsinv1alpha1.ExternalNameServiceConfig, serviceName string, namespace string, clusterDomain string) error {
+	if config == nil {
+		return nil
+	}
+	if errs := validation.IsDNS1035Label(config.Name); len(errs) > 0 {
+		return fmt.Errorf("externalNameService name %s is invalid: %s", config.Name, strings.Join(errs, ", "))
+	}
+	if errs := validation.IsDNS1123Label(getExternalNameServiceNamespace(config, namespace)); len(errs) > 0 {
+		return fmt.Errorf("externalNameService namespace %s is invalid: %s", config.Namespace, strings.Join(errs, ", "))
+	}
+	if config.Name == serviceName && getExternalNameServiceNamespace(config, namespace) == namespace {
+		return fmt.Errorf("externalNameService %s cannot replace the MongoDB service it points at", config.Name)
+	}
+	if errs := validation.IsDNS1123Subdomain(getServiceFQDN(serviceName, namespace, clusterDomain)); len(errs) > 0 {
+		return fmt.Errorf("externalNameService target %s is not a valid DNS name: %s", getServiceFQDN(serviceName, namespace, clusterDomain), strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// getPublishNotReadyAddresses is a method to get if the headless service publishes the addresses of members which are not ready, enabled by default
+func getPublishNotReadyAddresses(kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig) bool {
+	if kubernetesConfig.PublishNotReadyAddresses != nil {
+		return *kubernetesConfig.PublishNotReadyAddresses
+	}
+	return true
+}
+
+// getServiceFQDN is a method to get the fully qualified DNS name of a service, the default cluster domain is used when none is set
+func getServiceFQDN(serviceName string, namespace string, clusterDomain string) string {
+	if clusterDomain == "" {
+		clusterDomain = mongogo.DefaultClusterDomain
+	}
+	return fmt.Sprintf("%s.%s.svc.%s", serviceName, namespace, clusterDomain)
+}
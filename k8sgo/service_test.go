@@ -0,0 +1,388 @@
+package k8sgo
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestIsExternalNameServiceManaged(t *testing.T) {
+	owner := metav1.OwnerReference{UID: "1234"}
+	externalName := "mongodb-cluster.database.svc.cluster.local"
+	tests := []struct {
+		name    string
+		service corev1.Service
+		want    bool
+	}{
+		{
+			name: "alias of the owner",
+			service: corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{managedByLabel: managedByOperator, externalNameOwnerLabel: "1234"}},
+				Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "elsewhere"},
+			},
+			want: true,
+		},
+		{
+			name: "alias of another owner",
+			service: corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{managedByLabel: managedByOperator, externalNameOwnerLabel: "5678"}},
+				Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: externalName},
+			},
+			want: false,
+		},
+		{
+			name:    "unlabeled alias pointing at the target",
+			service: corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: externalName}},
+			want:    true,
+		},
+		{
+			name:    "unlabeled alias pointing elsewhere",
+			service: corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "elsewhere"}},
+			want:    false,
+		},
+		{
+			name:    "application service",
+			service: corev1.Service{Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}},
+			want:    false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isExternalNameServiceManaged(&test.service, owner, externalName); got != test.want {
+				t.Errorf("isExternalNameServiceManaged() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIsClientServiceSelectingPrimary(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceConfig  *opstreelabsinv1alpha1.ServiceConfig
+		currentPrimary string
+		want           bool
+	}{
+		{
+			name:           "load balancer with a known primary",
+			serviceConfig:  &opstreelabsinv1alpha1.ServiceConfig{ServiceType: corev1.ServiceTypeLoadBalancer},
+			currentPrimary: "mongodb-cluster-1",
+			want:           true,
+		},
+		{
+			name:          "load balancer before the primary is known",
+			serviceConfig: &opstreelabsinv1alpha1.ServiceConfig{ServiceType: corev1.ServiceTypeLoadBalancer},
+			want:          false,
+		},
+		{
+			name:           "cluster IP service",
+			serviceConfig:  &opstreelabsinv1alpha1.ServiceConfig{ServiceType: corev1.ServiceTypeClusterIP},
+			currentPrimary: "mongodb-cluster-1",
+			want:           false,
+		},
+		{
+			name:           "no client service",
+			currentPrimary: "mongodb-cluster-1",
+			want:           false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &opstreelabsinv1alpha1.MongoDBCluster{}
+			cr.Spec.KubernetesConfig.Service = tt.serviceConfig
+			cr.Status.CurrentPrimary = tt.currentPrimary
+			if got := isClientServiceSelectingPrimary(cr); got != tt.want {
+				t.Errorf("isClientServiceSelectingPrimary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateServiceDefExternalTrafficPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceType corev1.ServiceType
+		want        corev1.ServiceExternalTrafficPolicyType
+	}{
+		{name: "load balancer", serviceType: corev1.ServiceTypeLoadBalancer, want: corev1.ServiceExternalTrafficPolicyTypeLocal},
+		{name: "node port", serviceType: corev1.ServiceTypeNodePort, want: corev1.ServiceExternalTrafficPolicyTypeLocal},
+		{name: "cluster IP ignores the policy", serviceType: corev1.ServiceTypeClusterIP, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := generateServiceDef(serviceParameters{
+				ServiceMeta:           metav1.ObjectMeta{Name: "mongodb-cluster-client"},
+				Port:                  mongoDBPort,
+				PortName:              "mongo",
+				ServiceType:           tt.serviceType,
+				ExternalTrafficPolicy: corev1.ServiceExternalTrafficPolicyTypeLocal,
+			})
+			if service.Spec.ExternalTrafficPolicy != tt.want {
+				t.Errorf("generateServiceDef() externalTrafficPolicy = %q, want %q", service.Spec.ExternalTrafficPolicy, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateServiceDefSessionAffinity(t *testing.T) {
+	timeout := int32(3600)
+	tests := []struct {
+		name            string
+		sessionAffinity corev1.ServiceAffinity
+		timeout         *int32
+		wantTimeout     *int32
+	}{
+		{name: "client IP with timeout", sessionAffinity: corev1.ServiceAffinityClientIP, timeout: &timeout, wantTimeout: &timeout},
+		{name: "client IP with the default timeout", sessionAffinity: corev1.ServiceAffinityClientIP},
+		{name: "no affinity", sessionAffinity: corev1.ServiceAffinityNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := generateServiceDef(serviceParameters{
+				ServiceMeta:            metav1.ObjectMeta{Name: "mongodb-cluster-client"},
+				Port:                   mongoDBPort,
+				PortName:               "mongo",
+				SessionAffinity:        tt.sessionAffinity,
+				SessionAffinityTimeout: tt.timeout,
+			})
+			if service.Spec.SessionAffinity != tt.sessionAffinity {
+				t.Errorf("generateServiceDef() sessionAffinity = %q, want %q", service.Spec.SessionAffinity, tt.sessionAffinity)
+			}
+			var gotTimeout *int32
+			if service.Spec.SessionAffinityConfig != nil && service.Spec.SessionAffinityConfig.ClientIP != nil {
+				gotTimeout = service.Spec.SessionAffinityConfig.ClientIP.TimeoutSeconds
+			}
+			if !reflect.DeepEqual(gotTimeout, tt.wantTimeout) {
+				t.Errorf("generateServiceDef() session affinity timeout = %v, want %v", gotTimeout, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestValidateClientService(t *testing.T) {
+	timeout := int32(3600)
+	tests := []struct {
+		name          string
+		serviceConfig *opstreelabsinv1alpha1.ServiceConfig
+		wantErr       bool
+	}{
+		{name: "no client service"},
+		{name: "client IP with timeout", serviceConfig: &opstreelabsinv1alpha1.ServiceConfig{SessionAffinity: corev1.ServiceAffinityClientIP, SessionAffinityTimeoutSeconds: &timeout}},
+		{name: "timeout without client IP", serviceConfig: &opstreelabsinv1alpha1.ServiceConfig{SessionAffinity: corev1.ServiceAffinityNone, SessionAffinityTimeoutSeconds: &timeout}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateClientService(opstreelabsinv1alpha1.KubernetesConfig{Service: tt.serviceConfig})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateClientService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateServiceDefPortMapping(t *testing.T) {
+	servicePort := int32(37017)
+	tests := []struct {
+		name        string
+		port        int32
+		servicePort *int32
+		wantPort    int32
+	}{
+		{name: "service port defaults to the container port", port: mongoDBPort, wantPort: mongoDBPort},
+		{name: "different service port", port: mongoDBPort, servicePort: &servicePort, wantPort: servicePort},
+		{name: "different service port with a custom container port", port: 27018, servicePort: &servicePort, wantPort: servicePort},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := generateServiceDef(serviceParameters{
+				ServiceMeta: metav1.ObjectMeta{Name: "mongodb-cluster-client"},
+				Port:        tt.port,
+				ServicePort: tt.servicePort,
+				PortName:    "mongo",
+			})
+			port := service.Spec.Ports[0]
+			if port.Port != tt.wantPort {
+				t.Errorf("generateServiceDef() port = %d, want %d", port.Port, tt.wantPort)
+			}
+			if port.TargetPort.IntValue() != int(tt.port) {
+				t.Errorf("generateServiceDef() targetPort = %s, want the container port %d", port.TargetPort.String(), tt.port)
+			}
+		})
+	}
+}
+
+func TestGenerateServiceDefServiceType(t *testing.T) {
+	nodePort := int32(30017)
+	sourceRanges := []string{"10.0.0.0/8"}
+	tests := []struct {
+		name             string
+		serviceType      corev1.ServiceType
+		wantNodePort     int32
+		wantSourceRanges []string
+	}{
+		{name: "cluster ip ignores the node port and source ranges", serviceType: corev1.ServiceTypeClusterIP},
+		{name: "node port", serviceType: corev1.ServiceTypeNodePort, wantNodePort: nodePort},
+		{name: "load balancer", serviceType: corev1.ServiceTypeLoadBalancer, wantNodePort: nodePort, wantSourceRanges: sourceRanges},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := generateServiceDef(serviceParameters{
+				ServiceMeta:  metav1.ObjectMeta{Name: "mongodb-cluster-client", Namespace: "database"},
+				Port:         mongoDBPort,
+				ServiceType:  tt.serviceType,
+				NodePort:     &nodePort,
+				SourceRanges: sourceRanges,
+			})
+			if service.Spec.Type != tt.serviceType {
+				t.Errorf("generateServiceDef() type = %q, want %q", service.Spec.Type, tt.serviceType)
+			}
+			if service.Spec.Ports[0].NodePort != tt.wantNodePort {
+				t.Errorf("generateServiceDef() nodePort = %d, want %d", service.Spec.Ports[0].NodePort, tt.wantNodePort)
+			}
+			if !reflect.DeepEqual(service.Spec.LoadBalancerSourceRanges, tt.wantSourceRanges) {
+				t.Errorf("generateServiceDef() loadBalancerSourceRanges = %v, want %v", service.Spec.LoadBalancerSourceRanges, tt.wantSourceRanges)
+			}
+		})
+	}
+}
+
+func TestGetServicesOfType(t *testing.T) {
+	clusterIP := corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "mongodb-cluster"}, Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP}}
+	loadBalancer := corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "mongodb-cluster-external"}, Spec: corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer}}
+	tests := []struct {
+		name     string
+		services []corev1.Service
+		want     []corev1.Service
+	}{
+		{name: "no services"},
+		{name: "no load balancer services", services: []corev1.Service{clusterIP}},
+		{name: "load balancer services", services: []corev1.Service{clusterIP, loadBalancer}, want: []corev1.Service{loadBalancer}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if services := getServicesOfType(test.services, corev1.ServiceTypeLoadBalancer); !reflect.DeepEqual(services, test.want) {
+				t.Errorf("getServicesOfType() = %v, want %v", services, test.want)
+			}
+		})
+	}
+}
+
+func TestGetPublishNotReadyAddresses(t *testing.T) {
+	trueProperty := true
+	falseProperty := false
+	tests := []struct {
+		name                     string
+		publishNotReadyAddresses *bool
+		want                     bool
+	}{
+		{name: "enabled by default", want: true},
+		{name: "explicitly enabled", publishNotReadyAddresses: &trueProperty, want: true},
+		{name: "explicitly disabled", publishNotReadyAddresses: &falseProperty},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kubernetesConfig := opstreelabsinv1alpha1.KubernetesConfig{PublishNotReadyAddresses: test.publishNotReadyAddresses}
+			if got := getPublishNotReadyAddresses(kubernetesConfig); got != test.want {
+				t.Errorf("getPublishNotReadyAddresses() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGenerateServiceDefPublishNotReadyAddresses(t *testing.T) {
+	tests := []struct {
+		name            string
+		headlessService bool
+		want            bool
+	}{
+		{name: "headless service", headlessService: true, want: true},
+		{name: "client service"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := generateServiceDef(serviceParameters{
+				ServiceMeta:              metav1.ObjectMeta{Name: "mongodb-cluster"},
+				Namespace:                "database",
+				HeadlessService:          test.headlessService,
+				PublishNotReadyAddresses: true,
+				Port:                     mongoDBPort,
+				PortName:                 "mongo",
+			})
+			if service.Spec.PublishNotReadyAddresses != test.want {
+				t.Errorf("generateServiceDef() publishNotReadyAddresses = %v, want %v", service.Spec.PublishNotReadyAddresses, test.want)
+			}
+		})
+	}
+}
+
+func TestGetExtraServicePorts(t *testing.T) {
+	tests := []struct {
+		name  string
+		ports []corev1.ServicePort
+		want  []corev1.ServicePort
+	}{
+		{name: "no extra ports"},
+		{
+			name:  "defaults",
+			ports: []corev1.ServicePort{{Name: "sidecar", Port: 8080}},
+			want:  []corev1.ServicePort{{Name: "sidecar", Port: 8080, TargetPort: intstr.FromInt(8080), Protocol: corev1.ProtocolTCP}},
+		},
+		{
+			name:  "explicit target port and protocol",
+			ports: []corev1.ServicePort{{Name: "sidecar", Port: 80, TargetPort: intstr.FromString("http"), Protocol: corev1.ProtocolUDP}},
+			want:  []corev1.ServicePort{{Name: "sidecar", Port: 80, TargetPort: intstr.FromString("http"), Protocol: corev1.ProtocolUDP}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getExtraServicePorts(test.ports); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getExtraServicePorts() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestApplyHeadlessServiceConfig(t *testing.T) {
+	selectorLabels := map[string]string{"app": "mongodb-cluster", "role": "cluster"}
+	params := serviceParameters{
+		ServiceMeta: metav1.ObjectMeta{Name: "mongodb-cluster-headless", Labels: selectorLabels, Annotations: generateAnnotations()},
+		Labels:      selectorLabels,
+	}
+	tests := []struct {
+		name            string
+		config          *opstreelabsinv1alpha1.HeadlessServiceConfig
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+	}{
+		{name: "no headless service config", wantLabels: selectorLabels, wantAnnotations: generateAnnotations()},
+		{
+			name:            "labels and annotations",
+			config:          &opstreelabsinv1alpha1.HeadlessServiceConfig{Labels: map[string]string{"team": "database"}, Annotations: map[string]string{"external-dns.alpha.kubernetes.io/hostname": "mongodb.example.org"}},
+			wantLabels:      mergeLabels(selectorLabels, map[string]string{"team": "database"}),
+			wantAnnotations: mergeAnnotations(generateAnnotations(), map[string]string{"external-dns.alpha.kubernetes.io/hostname": "mongodb.example.org"}),
+		},
+		{
+			name:            "selector labels and managed annotations are kept",
+			config:          &opstreelabsinv1alpha1.HeadlessServiceConfig{Labels: map[string]string{"app": "other"}, Annotations: map[string]string{corev1.LastAppliedConfigAnnotation: "{}"}},
+			wantLabels:      selectorLabels,
+			wantAnnotations: generateAnnotations(),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := applyHeadlessServiceConfig(params, test.config)
+			if !reflect.DeepEqual(got.ServiceMeta.Labels, test.wantLabels) {
+				t.Errorf("applyHeadlessServiceConfig() labels = %v, want %v", got.ServiceMeta.Labels, test.wantLabels)
+			}
+			if !reflect.DeepEqual(got.ServiceMeta.Annotations, test.wantAnnotations) {
+				t.Errorf("applyHeadlessServiceConfig() annotations = %v, want %v", got.ServiceMeta.Annotations, test.wantAnnotations)
+			}
+			if !reflect.DeepEqual(got.Labels, selectorLabels) {
+				t.Errorf("applyHeadlessServiceConfig() changed the selector labels to %v", got.Labels)
+			}
+		})
+	}
+}
@@ -0,0 +1,473 @@
+package k8sgo
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/mongo"
+	"strings"
+)
+
+const (
+	shardRoleConfigServer = "configsvr"
+	shardRoleShard        = "shard"
+	shardRoleMongos       = "mongos"
+)
+
+// shardedReplicaSet is the topology struct for a replica set of sharded mongodb cluster
+type shardedReplicaSet struct {
+	Name     string
+	Role     string
+	Replicas *int32
+}
+
+// getShardedReplicaSets is a method to model the config server and shard replica sets of sharded mongodb cluster
+func getShardedReplicaSets(cr *opstreelabsinv1alpha1.MongoDBCluster) []shardedReplicaSet {
+	replicaSets := []shardedReplicaSet{
+		{
+			Name:     getConfigServerName(cr),
+			Role:     shardRoleConfigServer,
+			Replicas: getInt32OrDefault(cr.Spec.Sharding.ConfigServerSize, 3),
+		},
+	}
+	shards := getInt32OrDefault(cr.Spec.Sharding.Shards, 1)
+	for shard := 0; shard < int(*shards); shard++ {
+		replicaSets = append(replicaSets, shardedReplicaSet{
+			Name:     fmt.Sprintf("%s-%s-%d", cr.ObjectMeta.Name, shardRoleShard, shard),
+			Role:     shardRoleShard,
+			Replicas: cr.Spec.MongoDBClusterSize,
+		})
+	}
+	return replicaSets
+}
+
+// getConfigServerName is a method to get the config server replica set name of sharded mongodb cluster
+func getConfigServerName(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	return fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, shardRoleConfigServer)
+}
+
+// getMongosName is a method to get the mongos router name of sharded mongodb cluster
+func getMongosName(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	return fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, shardRoleMongos)
+}
+
+// getInt32OrDefault is a method to get the value of an optional int32 field with a default
+func getInt32OrDefault(value *int32, defaultValue int32) *int32 {
+	if value != nil {
+		return value
+	}
+	return &defaultValue
+}
+
+// getShardedReplicaSetHosts is a method to get the member hosts of a replica set of sharded mongodb cluster
+func getShardedReplicaSetHosts(cr *opstreelabsinv1alpha1.MongoDBCluster, replicaSet shardedReplicaSet) []string {
+	var hosts []string
+	for node := 0; node < int(*replicaSet.Replicas); node++ {
+		hosts = append(hosts, mongogo.GetPodHost(fmt.Sprintf("%s-%d", replicaSet.Name, node), replicaSet.Name, cr.Namespace, cr.Spec.ClusterDomain, getMongoDBPort(cr.Spec.MongoDBConfig)))
+	}
+	return hosts
+}
+
+// CreateMongoShardedClusterSetup is a method to create config servers, shards and mongos for sharded MongoDB
+func CreateMongoShardedClusterSetup(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "StatefulSet")
+	if err := validateImage(cr.Spec.KubernetesConfig.Image); err != nil {
+		logger.Error(err, "Invalid image for sharded MongoDB")
+		return err
+	}
+	if err := validateSeccompProfile(cr.Spec.KubernetesConfig); err != nil {
+		logger.Error(err, "Invalid seccomp profile for sharded MongoDB")
+		return err
+	}
+	if err := validateSysctls(cr.Spec.KubernetesConfig); err != nil {
+		logger.Error(err, "Invalid sysctls for sharded MongoDB")
+		return err
+	}
+	if err := validateExtraVolumeMounts(cr.Spec.KubernetesConfig.ExtraVolumes, cr.Spec.KubernetesConfig.ExtraVolumeMounts); err != nil {
+		logger.Error(err, "Invalid extra volumes for sharded MongoDB")
+		return err
+	}
+	if err := validateProjectedVolume(cr.Spec.KubernetesConfig.ProjectedVolume, getMongoDBConfigDBPath(cr.Spec.MongoDBConfig)); err != nil {
+		logger.Error(err, "Invalid projected volume for sharded MongoDB")
+		return err
+	}
+	if err := validateMaintenanceWindow(cr.Spec.MaintenanceWindow); err != nil {
+		logger.Error(err, "Invalid maintenance window for sharded MongoDB")
+		return err
+	}
+	if len(cr.Spec.MongoDBSecurity.Users) > 0 || len(cr.Spec.MongoDBSecurity.Roles) > 0 {
+		err := fmt.Errorf("users and roles are not supported with sharding")
+		logger.Error(err, "Invalid users for sharded MongoDB")
+		return err
+	}
+	if cr.Spec.KubernetesConfig.PostStart != nil {
+		// the shards are initialized through mongos, not on their own members
+		err := fmt.Errorf("postStart is not supported with sharding")
+		logger.Error(err, "Invalid postStart for sharded MongoDB")
+		return err
+	}
+	mongosProbes := getMongosProbes(cr.Spec.Sharding)
+	if err := validateProbes(&opstreelabsinv1alpha1.ProbeConfig{ReadinessProbe: mongosProbes.ReadinessProbe, StartupProbe: mongosProbes.StartupProbe}); err != nil {
+		logger.Error(err, "Invalid mongos probes for sharded MongoDB")
+		return err
+	}
+	if err := validateAccessModes(cr.Spec.Storage); err != nil {
+		logger.Error(err, "Invalid storage for sharded MongoDB")
+		return err
+	}
+	if err := validateVolumeMode(cr.Spec.Storage); err != nil {
+		logger.Error(err, "Invalid storage for sharded MongoDB")
+		return err
+	}
+	if err := validateStorageEngine(cr.Spec.MongoDBConfig, cr.Spec.MongoDBRestore, cr.Spec.KubernetesConfig.Image); err != nil {
+		logger.Error(err, "Invalid storageEngine for sharded MongoDB")
+		return err
+	}
+	if err := validateOplogMinRetention(cr.Spec.MongoDBConfig); err != nil {
+		logger.Error(err, "Invalid oplog retention for sharded MongoDB")
+		return err
+	}
+	if err := validateContainerName(cr.Spec.KubernetesConfig.ContainerName); err != nil {
+		logger.Error(err, "Invalid containerName for sharded MongoDB")
+		return err
+	}
+	if err := validateExternalNameService(cr.Spec.KubernetesConfig.ExternalNameService, getMongosName(cr), cr.Namespace, cr.Spec.ClusterDomain); err != nil {
+		logger.Error(err, "Invalid externalNameService for sharded MongoDB")
+		return err
+	}
+	if err := validateTerminationGracePeriod(cr.Spec.KubernetesConfig.TerminationGracePeriodSeconds, *getInt32OrDefault(cr.Spec.KubernetesConfig.PreStopDelaySeconds, defaultPreStopDelaySeconds)); err != nil {
+		logger.Error(err, "Invalid termination grace period for sharded MongoDB")
+		return err
+	}
+	if cr.Spec.MongoDBConfig != nil {
+		if err := validateExtraArgs(cr.Spec.MongoDBConfig.ExtraArgs); err != nil {
+			logger.Error(err, "Invalid mongoDBConfig for sharded MongoDB")
+			return err
+		}
+		if err := validateMongoDBLogging(cr.Spec.MongoDBConfig.Logging); err != nil {
+			logger.Error(err, "Invalid logging for sharded MongoDB")
+			return err
+		}
+		if err := validateDBPath(cr.Spec.MongoDBConfig.DBPath); err != nil {
+			logger.Error(err, "Invalid dbPath for sharded MongoDB")
+			return err
+		}
+		if err := validateWarmUp(cr.Spec.MongoDBConfig.WarmUp); err != nil {
+			logger.Error(err, "Invalid warmUp for sharded MongoDB")
+			return err
+		}
+		if err := validateAuditLog(cr.Spec.MongoDBConfig, cr.Spec.KubernetesConfig.Image); err != nil {
+			logger.Error(err, "Invalid auditLog for sharded MongoDB")
+			return err
+		}
+		if err := validateBindIP(cr.Spec.MongoDBConfig); err != nil {
+			logger.Error(err, "Invalid bindIp for sharded MongoDB")
+			return err
+		}
+	}
+	if cr.Spec.MongoDBRestore != nil {
+		err := fmt.Errorf("mongoDBRestore is not supported for sharded cluster")
+		logger.Error(err, "Cannot restore sharded MongoDB")
+		return err
+	}
+	var deferred error
+	for _, replicaSet := range getShardedReplicaSets(cr) {
+		err := CreateOrUpdateService(getShardedServiceParams(cr, replicaSet.Name, replicaSet.Role, true))
+		if err != nil {
+			logger.Error(err, "Cannot create sharded Service for MongoDB", "Role", replicaSet.Role)
+			return err
+		}
+		err = CreateOrUpdateStateFul(getShardedReplicaSetParams(cr, replicaSet))
+		if IsUpdateDeferred(err) {
+			deferred = err
+		} else if err != nil {
+			logger.Error(err, "Cannot create sharded StatefulSet for MongoDB", "Role", replicaSet.Role)
+			return err
+		}
+	}
+	err := CreateOrUpdateService(getShardedServiceParams(cr, getMongosName(cr), shardRoleMongos, false))
+	if err != nil {
+		logger.Error(err, "Cannot create mongos Service for MongoDB")
+		return err
+	}
+	err = CreateOrUpdateExternalNameService(getShardedServiceParams(cr, getMongosName(cr), shardRoleMongos, false), cr.Spec.KubernetesConfig.ExternalNameService, cr.Spec.ClusterDomain)
+	if err != nil {
+		logger.Error(err, "Cannot create mongos ExternalName Service for MongoDB")
+		return err
+	}
+	err = CreateOrUpdateDeployment(getMongosParams(cr))
+	if err != nil {
+		logger.Error(err, "Cannot create mongos Deployment for MongoDB")
+		return err
+	}
+	return deferred
+}
+
+// getShardedLabels is a method to generate labels for a component of sharded mongodb cluster
+func getShardedLabels(name string, role string) map[string]string {
+	return map[string]string{
+		"app":           name,
+		"mongodb_setup": "sharded",
+		"role":          role,
+	}
+}
+
+// getShardedServiceParams is a method to generate service params for a component of sharded mongodb cluster
+func getShardedServiceParams(cr *opstreelabsinv1alpha1.MongoDBCluster, name string, role string, headless bool) serviceParameters {
+	labels := getShardedLabels(name, role)
+	params := serviceParameters{
+		ServiceMeta:              generateObjectMetaInformation(name, cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations)),
+		OwnerDef:                 mongoClusterAsOwner(cr),
+		Namespace:                cr.Namespace,
+		Labels:                   labels,
+		Annotations:              generateAnnotations(),
+		HeadlessService:          headless,
+		PublishNotReadyAddresses: getPublishNotReadyAddresses(cr.Spec.KubernetesConfig),
+		Port:                     getMongoDBPort(cr.Spec.MongoDBConfig),
+		PortName:                 "mongo",
+	}
+	if headless {
+		return applyHeadlessServiceConfig(params, cr.Spec.KubernetesConfig.HeadlessService)
+	}
+	return params
+}
+
+// getShardedReplicaSetParams is a method to generate statefulset params for a replica set of sharded mongodb cluster
+func getShardedReplicaSetParams(cr *opstreelabsinv1alpha1.MongoDBCluster, replicaSet shardedReplicaSet) statefulSetParameters {
+	if replicaSet.Role == shardRoleConfigServer {
+		cr = getConfigServerSpec(cr)
+	}
+	params := getMongoDBClusterParams(cr)
+	labels := getShardedLabels(replicaSet.Name, replicaSet.Role)
+	replicaSetName := replicaSet.Name
+	params.StatefulSetMeta = generateObjectMetaInformation(replicaSet.Name, cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations))
+	params.Labels = labels
+	params.Replicas = replicaSet.Replicas
+	params.ContainerParams.MongoReplicaSetName = &replicaSetName
+	params.ContainerParams.MongoSetupType = "cluster"
+	// config servers and shards listen on the configured port instead of their role specific default ports
+	roleArgs := []string{"--shardsvr", fmt.Sprintf("--port=%d", params.ContainerParams.Port)}
+	if replicaSet.Role == shardRoleConfigServer {
+		roleArgs[0] = "--configsvr"
+	}
+	// the monitoring user is not provisioned for sharded cluster yet
+	params.ContainerParams.MongoDBMonitoring = nil
+	params.ContainerParams.ExtraArgs = append(roleArgs, params.ContainerParams.ExtraArgs...)
+	// the ordered rollout is only orchestrated for the replica set of mongodb cluster
+	params.UpdateStrategy = ""
+	if getPersistentStorage(cr.Spec.MongoDBConfig, cr.Spec.Storage) != nil {
+		params.PVCParameters.Name = replicaSet.Name
+		params.PVCParameters.Labels = mergeLabels(labels, cr.Labels)
+	}
+	return params
+}
+
+// getConfigServerSpec is a method to get the spec the config servers are generated from, they only support wiredTiger and keep it with the in-memory storage engine of the shards
+func getConfigServerSpec(cr *opstreelabsinv1alpha1.MongoDBCluster) *opstreelabsinv1alpha1.MongoDBCluster {
+	if !isInMemoryStorageEngine(cr.Spec.MongoDBConfig) {
+		return cr
+	}
+	configServer := cr.DeepCopy()
+	configServer.Spec.MongoDBConfig.StorageEngine = ""
+	return configServer
+}
+
+// getMongosParams is a method to generate deployment params for mongos router of sharded mongodb cluster
+func getMongosParams(cr *opstreelabsinv1alpha1.MongoDBCluster) deploymentParameters {
+	name := getMongosName(cr)
+	labels := getShardedLabels(name, shardRoleMongos)
+	configServer := getShardedReplicaSets(cr)[0]
+	port := getMongoDBPort(cr.Spec.MongoDBConfig)
+	probes := getMongosProbes(cr.Spec.Sharding)
+	container := corev1.Container{
+		Name:            shardRoleMongos,
+		Image:           cr.Spec.KubernetesConfig.Image,
+		ImagePullPolicy: getImagePullPolicy(cr.Spec.KubernetesConfig.Image, cr.Spec.KubernetesConfig.ImagePullPolicy),
+		Command:         []string{shardRoleMongos},
+		Args: []string{
+			"--configdb", fmt.Sprintf("%s/%s", configServer.Name, strings.Join(getShardedReplicaSetHosts(cr, configServer), ",")),
+			"--bind_ip_all",
+			fmt.Sprintf("--port=%d", getMongoDBPort(cr.Spec.MongoDBConfig)),
+		},
+		Env:                      mergeEnvironmentVariables(getMongosEnvironmentVariables(cr.Spec.MongoDBSecurity), cr.Spec.KubernetesConfig.EnvVars),
+		EnvFrom:                  cr.Spec.KubernetesConfig.EnvFrom,
+		ReadinessProbe:           applyProbeConfig(getMongosConfigServerProbe(port, cr.Spec.MongoDBSecurity != nil), probes.ReadinessProbe),
+		LivenessProbe:            getMongoDBProbe(port),
+		SecurityContext:          cr.Spec.KubernetesConfig.ContainerSecurityContext,
+		TerminationMessagePath:   getTerminationMessagePath(cr.Spec.KubernetesConfig.TerminationMessagePath),
+		TerminationMessagePolicy: getTerminationMessagePolicy(cr.Spec.KubernetesConfig.TerminationMessagePolicy),
+	}
+	if probes.StartupProbe != nil {
+		container.StartupProbe = applyProbeConfig(getMongosConfigServerProbe(port, cr.Spec.MongoDBSecurity != nil), probes.StartupProbe)
+	}
+	if cr.Spec.KubernetesConfig.Resources != nil {
+		container.Resources = *cr.Spec.KubernetesConfig.Resources
+	}
+	if container.SecurityContext == nil {
+		container.SecurityContext = getDefaultContainerSecurityContext()
+	}
+	var initContainers []corev1.Container
+	var volumes []corev1.Volume
+	if isMongoClusterKeyFileRequired(cr) {
+		// mongos authenticates to the config servers and shards with the keyFile of the members
+		container.Args = append(container.Args, fmt.Sprintf("--keyFile=%s", getKeyFilePath()))
+		container.VolumeMounts = append(container.VolumeMounts, getKeyFileVolumeMount())
+		initContainers = append(initContainers, generateKeyFileInitContainer(cr.Spec.KubernetesConfig.InitImage))
+		volumes = append(volumes, getKeyFileVolumes(getKeyFileSecretName(cr.ObjectMeta.Name))...)
+	}
+	return deploymentParameters{
+		DeploymentMeta:     generateObjectMetaInformation(name, cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations)),
+		OwnerDef:           mongoClusterAsOwner(cr),
+		Namespace:          cr.Namespace,
+		Labels:             labels,
+		Annotations:        getPodAnnotations(cr.Spec.KubernetesConfig),
+		Replicas:           getInt32OrDefault(cr.Spec.Sharding.MongosSize, 2),
+		Containers:         []corev1.Container{container},
+		InitContainers:     initContainers,
+		Volumes:            volumes,
+		ImagePullSecrets:   getImagePullSecrets(cr.Spec.KubernetesConfig.ImagePullSecret, cr.Spec.KubernetesConfig.ImagePullSecrets),
+		Affinity:           cr.Spec.KubernetesConfig.Affinity,
+		NodeSelector:       cr.Spec.KubernetesConfig.NodeSelector,
+		Tolerations:        cr.Spec.KubernetesConfig.Tolerations,
+		PriorityClassName:  getPriorityClassName(cr.Spec.KubernetesConfig, cr.Namespace, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")),
+		SchedulerName:      cr.Spec.KubernetesConfig.SchedulerName,
+		RuntimeClassName:   cr.Spec.KubernetesConfig.RuntimeClassName,
+		ServiceAccountName: getServiceAccountName(cr.Spec.KubernetesConfig, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")),
+		SecurityContext:    getPodSecurityContext(cr.Spec.KubernetesConfig),
+	}
+}
+
+// getMongosProbes is a method to get the probe overrides of mongos, no override keeps the operator defaults
+func getMongosProbes(sharding *opstreelabsinv1alpha1.MongoDBSharding) opstreelabsinv1alpha1.MongosProbeConfig {
+	if sharding == nil || sharding.MongosProbes == nil {
+		return opstreelabsinv1alpha1.MongosProbeConfig{}
+	}
+	return *sharding.MongosProbes
+}
+
+// getMongosConfigServerProbe is a method to generate the probe of mongos which only succeeds once mongos can read from the config server replica set
+func getMongosConfigServerProbe(port int32, authEnabled bool) *corev1.Probe {
+	probe := getMongoDBProbe(port)
+	// unlike ping, a read of the config database is routed to the config servers
+	script := fmt.Sprintf("var result = db.getSiblingDB(\"config\").runCommand({find: \"shards\", limit: 1, maxTimeMS: %d}); if (!result.ok) { quit(1) }", (probe.TimeoutSeconds-1)*1000)
+	probe.Handler.Exec.Command = []string{"/bin/sh", "-c", fmt.Sprintf("mongo --port %d --quiet %s --eval '%s'", port, getShellCredentials(authEnabled), script)}
+	return probe
+}
+
+// getMongosEnvironmentVariables is a method to get the credentials the probes of mongos authenticate with
+func getMongosEnvironmentVariables(security *opstreelabsinv1alpha1.MongoDBSecurity) []corev1.EnvVar {
+	if security == nil {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{
+			Name: "MONGO_ROOT_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: *security.SecretRef.Name,
+					},
+					Key: *security.SecretRef.Key,
+				},
+			},
+		},
+		{
+			Name:  "MONGO_ROOT_USERNAME",
+			Value: security.MongoDBAdminUser,
+		},
+	}
+}
+
+// CheckMongoShardedReplicaSetsReady is a method to check if all config server and shard members are ready
+func CheckMongoShardedReplicaSetsReady(cr *opstreelabsinv1alpha1.MongoDBCluster) (bool, error) {
+	for _, replicaSet := range getShardedReplicaSets(cr) {
+		stateful, err := GetStateFulSet(cr.Namespace, replicaSet.Name)
+		if err != nil {
+			return false, err
+		}
+		if stateful.Status.ReadyReplicas != *replicaSet.Replicas {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// CheckMongosReady is a method to check if all mongos routers of sharded MongoDB run the latest spec and are ready, their readiness probe reads from the config servers
+func CheckMongosReady(cr *opstreelabsinv1alpha1.MongoDBCluster) (bool, error) {
+	deployment, err := GetDeployment(cr.Namespace, getMongosName(cr))
+	if err != nil {
+		return false, err
+	}
+	return isDeploymentRolledOut(deployment), nil
+}
+
+// InitializeMongoShardedReplicaSets is a method to initiate the config server and shard replica sets
+func InitializeMongoShardedReplicaSets(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Sharded Cluster Setup")
+	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return err
+	}
+	for _, replicaSet := range getShardedReplicaSets(cr) {
+		hosts := getShardedReplicaSetHosts(cr, replicaSet)
+		mongoParams := mongogo.MongoDBParameters{
+			MongoURL:     fmt.Sprintf("mongodb://%s:%s@%s/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, hosts[0]),
+			Namespace:    cr.Namespace,
+			Name:         replicaSet.Name,
+			SetupType:    "standalone",
+			ConfigServer: replicaSet.Role == shardRoleConfigServer,
+		}
+		state, err := mongogo.CheckMongoClusterInitialized(mongoParams)
+		if err == nil && state {
+			continue
+		}
+		for node, host := range hosts {
+			mongoParams.Members = append(mongoParams.Members, mongogo.MongoDBMember{ID: node, Host: host, Priority: 1, Votes: 1})
+		}
+		err = mongogo.InitiateMongoClusterRS(mongoParams)
+		if err != nil {
+			logger.Error(err, "Unable to initiate MongoDB sharded replica set", "ReplicaSet", replicaSet.Name)
+			return err
+		}
+		logger.Info("Successfully initiated the MongoDB sharded replica set", "ReplicaSet", replicaSet.Name)
+	}
+	return nil
+}
+
+// AddMongoShards is a method to register the shard replica sets with mongos
+func AddMongoShards(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "MongoDB Sharded Cluster Setup")
+	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return err
+	}
+	mongoParams := mongogo.MongoDBParameters{
+		MongoURL:  fmt.Sprintf("mongodb://%s:%s@%s.%s:%d/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, getMongosName(cr), cr.Namespace, getMongoDBPort(cr.Spec.MongoDBConfig)),
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		SetupType: "standalone",
+	}
+	registeredShards, err := mongogo.ListMongoShards(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to list the shards of MongoDB sharded cluster")
+		return err
+	}
+	registered := make(map[string]bool)
+	for _, shard := range registeredShards {
+		registered[shard] = true
+	}
+	for _, replicaSet := range getShardedReplicaSets(cr) {
+		if replicaSet.Role != shardRoleShard || registered[replicaSet.Name] {
+			continue
+		}
+		err := mongogo.AddMongoShard(mongoParams, replicaSet.Name, getShardedReplicaSetHosts(cr, replicaSet))
+		if err != nil {
+			logger.Error(err, "Unable to add shard to MongoDB sharded cluster", "Shard", replicaSet.Name)
+			return err
+		}
+		logger.Info("Successfully added shard to MongoDB sharded cluster", "Shard", replicaSet.Name)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package k8sgo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestGetMongoDBPort(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *opstreelabsinv1alpha1.MongoDBConfig
+		want   int32
+	}{
+		{name: "no mongodb config", want: mongoDBPort},
+		{name: "no port", config: &opstreelabsinv1alpha1.MongoDBConfig{}, want: mongoDBPort},
+		{name: "custom port", config: &opstreelabsinv1alpha1.MongoDBConfig{Port: int32Ptr(27018)}, want: 27018},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if port := getMongoDBPort(test.config); port != test.want {
+				t.Errorf("getMongoDBPort() = %d, want %d", port, test.want)
+			}
+		})
+	}
+}
+
+func TestGetShardedReplicaSetHosts(t *testing.T) {
+	tests := []struct {
+		name          string
+		port          *int32
+		clusterDomain string
+		want          []string
+	}{
+		{
+			name: "default port",
+			want: []string{"mongodb-shard-0-0.mongodb-shard-0.database.svc.cluster.local:27017", "mongodb-shard-0-1.mongodb-shard-0.database.svc.cluster.local:27017"},
+		},
+		{
+			name: "custom port",
+			port: int32Ptr(27018),
+			want: []string{"mongodb-shard-0-0.mongodb-shard-0.database.svc.cluster.local:27018", "mongodb-shard-0-1.mongodb-shard-0.database.svc.cluster.local:27018"},
+		},
+		{
+			name:          "custom port and cluster domain",
+			port:          int32Ptr(27018),
+			clusterDomain: "example.org",
+			want:          []string{"mongodb-shard-0-0.mongodb-shard-0.database.svc.example.org:27018", "mongodb-shard-0-1.mongodb-shard-0.database.svc.example.org:27018"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := newTestMongoDBCluster(2)
+			cr.Spec.ClusterDomain = test.clusterDomain
+			cr.Spec.MongoDBConfig = &opstreelabsinv1alpha1.MongoDBConfig{Port: test.port}
+			replicaSet := shardedReplicaSet{Name: "mongodb-shard-0", Role: shardRoleShard, Replicas: int32Ptr(2)}
+			if hosts := getShardedReplicaSetHosts(cr, replicaSet); !reflect.DeepEqual(hosts, test.want) {
+				t.Errorf("getShardedReplicaSetHosts() = %v, want %v", hosts, test.want)
+			}
+		})
+	}
+}
+
+func TestGetMongosProbes(t *testing.T) {
+	readinessProbe := &opstreelabsinv1alpha1.Probe{PeriodSeconds: int32Ptr(30)}
+	tests := []struct {
+		name     string
+		sharding *opstreelabsinv1alpha1.MongoDBSharding
+		want     opstreelabsinv1alpha1.MongosProbeConfig
+	}{
+		{name: "no sharding"},
+		{name: "no mongos probes", sharding: &opstreelabsinv1alpha1.MongoDBSharding{Enabled: true}},
+		{
+			name:     "mongos probes",
+			sharding: &opstreelabsinv1alpha1.MongoDBSharding{Enabled: true, MongosProbes: &opstreelabsinv1alpha1.MongosProbeConfig{ReadinessProbe: readinessProbe}},
+			want:     opstreelabsinv1alpha1.MongosProbeConfig{ReadinessProbe: readinessProbe},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getMongosProbes(test.sharding); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getMongosProbes() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetMongosConfigServerProbe(t *testing.T) {
+	tests := []struct {
+		name            string
+		port            int32
+		authEnabled     bool
+		wantCredentials bool
+	}{
+		{name: "default port without auth", port: 27017},
+		{name: "custom port with auth", port: 27018, authEnabled: true, wantCredentials: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			probe := getMongosConfigServerProbe(test.port, test.authEnabled)
+			command := strings.Join(probe.Handler.Exec.Command, " ")
+			if !strings.Contains(command, fmt.Sprintf("mongo --port %d ", test.port)) {
+				t.Errorf("getMongosConfigServerProbe() command = %s, want port %d", command, test.port)
+			}
+			if !strings.Contains(command, `getSiblingDB("config").runCommand({find: "shards", limit: 1, maxTimeMS: 4000})`) {
+				t.Errorf("getMongosConfigServerProbe() command = %s, want a config server read within the probe timeout", command)
+			}
+			if got := strings.Contains(command, "$MONGO_ROOT_PASSWORD"); got != test.wantCredentials {
+				t.Errorf("getMongosConfigServerProbe() credentials = %v, want %v", got, test.wantCredentials)
+			}
+		})
+	}
+}
+
+func TestGetMongosEnvironmentVariables(t *testing.T) {
+	secretName, secretKey := "mongodb-secret", "password"
+	tests := []struct {
+		name     string
+		security *opstreelabsinv1alpha1.MongoDBSecurity
+		want     []corev1.EnvVar
+	}{
+		{name: "no security"},
+		{
+			name:     "security",
+			security: &opstreelabsinv1alpha1.MongoDBSecurity{MongoDBAdminUser: "admin", SecretRef: opstreelabsinv1alpha1.ExistingPasswordSecret{Name: &secretName, Key: &secretKey}},
+			want: []corev1.EnvVar{
+				{Name: "MONGO_ROOT_PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}, Key: secretKey}}},
+				{Name: "MONGO_ROOT_USERNAME", Value: "admin"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getMongosEnvironmentVariables(test.security); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getMongosEnvironmentVariables() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetMongosParamsProbes(t *testing.T) {
+	tests := []struct {
+		name              string
+		probes            *opstreelabsinv1alpha1.MongosProbeConfig
+		wantPeriodSeconds int32
+		wantStartupProbe  bool
+	}{
+		{name: "default probes", wantPeriodSeconds: 15},
+		{name: "readiness probe timings", probes: &opstreelabsinv1alpha1.MongosProbeConfig{ReadinessProbe: &opstreelabsinv1alpha1.Probe{PeriodSeconds: int32Ptr(30)}}, wantPeriodSeconds: 30},
+		{name: "startup probe", probes: &opstreelabsinv1alpha1.MongosProbeConfig{StartupProbe: &opstreelabsinv1alpha1.Probe{FailureThreshold: int32Ptr(30)}}, wantPeriodSeconds: 15, wantStartupProbe: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := newTestMongoDBCluster(3)
+			cr.Spec.Sharding = &opstreelabsinv1alpha1.MongoDBSharding{Enabled: true, MongosProbes: test.probes}
+			container := generateDeploymentDef(getMongosParams(cr)).Spec.Template.Spec.Containers[0]
+			if got := container.ReadinessProbe.PeriodSeconds; got != test.wantPeriodSeconds {
+				t.Errorf("getMongosParams() readiness periodSeconds = %d, want %d", got, test.wantPeriodSeconds)
+			}
+			if got := container.StartupProbe != nil; got != test.wantStartupProbe {
+				t.Errorf("getMongosParams() startupProbe = %v, want %v", got, test.wantStartupProbe)
+			}
+			if !strings.Contains(strings.Join(container.ReadinessProbe.Handler.Exec.Command, " "), "getSiblingDB(\"config\")") {
+				t.Errorf("getMongosParams() readinessProbe = %v, want the config server probe", container.ReadinessProbe.Handler.Exec.Command)
+			}
+		})
+	}
+}
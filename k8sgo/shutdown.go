@@ -0,0 +1,123 @@
+package k8sgo
+
+import (
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"strings"
+)
+
+const (
+	// defaultPreStopDelaySeconds is the time given to remove a terminating pod from the service endpoints before mongod stops
+	defaultPreStopDelaySeconds int32 = 5
+	// stepDownCatchUpSeconds is the time a primary waits for an electable secondary to catch up before stepping down
+	stepDownCatchUpSeconds int64 = 10
+	// electionTimeoutSeconds is the default electionTimeoutMillis of MongoDB replica set
+	electionTimeoutSeconds int64 = 10
+	// shutdownTimeoutSeconds is the time left for mongod to shut down cleanly once the new primary is elected
+	shutdownTimeoutSeconds int64 = 30
+	// shutdownMillisecondsPerGi is the time mongod is assumed to need per Gi of data to flush its last checkpoint on shutdown
+	shutdownMillisecondsPerGi int64 = 300
+	// maxTerminationGracePeriodSeconds caps the termination grace period derived from the storage size
+	maxTerminationGracePeriodSeconds int64 = 600
+)
+
+// getMinimumTerminationGracePeriod is a method to get the grace period required to drain connections, step down the primary, elect a new one and shut down cleanly
+func getMinimumTerminationGracePeriod(preStopDelay int32) int64 {
+	return int64(preStopDelay) + stepDownCatchUpSeconds + electionTimeoutSeconds + shutdownTimeoutSeconds
+}
+
+// getTerminationGracePeriod is a method to get the termination grace period of MongoDB pods, members stepping down default to the minimum required one and large data volumes get more time to shut down
+func getTerminationGracePeriod(gracePeriod *int64, preStopDelay int32, stepDown bool, storage *opstreelabsinv1alpha1.Storage) *int64 {
+	if gracePeriod != nil {
+		return gracePeriod
+	}
+	shutdownTimeout := getShutdownTimeout(storage)
+	if !stepDown && shutdownTimeout == shutdownTimeoutSeconds {
+		// the default grace period of Kubernetes is kept for small volumes
+		return nil
+	}
+	derived := int64(preStopDelay) + shutdownTimeout
+	if stepDown {
+		derived += stepDownCatchUpSeconds + electionTimeoutSeconds
+	}
+	if derived > maxTerminationGracePeriodSeconds {
+		derived = maxTerminationGracePeriodSeconds
+	}
+	// the cap never cuts into the time required to hand over the primary
+	if stepDown && derived < getMinimumTerminationGracePeriod(preStopDelay) {
+		derived = getMinimumTerminationGracePeriod(preStopDelay)
+	}
+	return &derived
+}
+
+// getShutdownTimeout is a method to get the time left for mongod to shut down cleanly, it grows with the storage size of the data volume
+func getShutdownTimeout(storage *opstreelabsinv1alpha1.Storage) int64 {
+	if storage == nil || storage.StorageSize == "" {
+		return shutdownTimeoutSeconds
+	}
+	storageSize, err := resource.ParseQuantity(storage.StorageSize)
+	if err != nil {
+		return shutdownTimeoutSeconds
+	}
+	timeout := storageSize.Value() / (1024 * 1024 * 1024) * shutdownMillisecondsPerGi / 1000
+	if timeout < shutdownTimeoutSeconds {
+		return shutdownTimeoutSeconds
+	}
+	return timeout
+}
+
+// validateTerminationGracePeriod is a method to validate that the termination grace period leaves enough time for the election of a new primary
+func validateTerminationGracePeriod(gracePeriod *int64, preStopDelay int32) error {
+	if gracePeriod != nil && *gracePeriod < getMinimumTerminationGracePeriod(preStopDelay) {
+		return fmt.Errorf("terminationGracePeriodSeconds must be at least %d to drain connections for %ds, step down the primary and complete the election within electionTimeout of %ds", getMinimumTerminationGracePeriod(preStopDelay), preStopDelay, electionTimeoutSeconds)
+	}
+	return nil
+}
+
+// getShutdownLifecycle is a method to generate the preStop hook which drains connections, flushes the data files and hands over the primary before mongod is terminated
+func getShutdownLifecycle(port int32, authEnabled bool, preStopDelay int32, fsync bool, stepDown bool) *corev1.Lifecycle {
+	var commands []string
+	if preStopDelay > 0 {
+		commands = append(commands, fmt.Sprintf("sleep %d", preStopDelay))
+	}
+	if fsync {
+		commands = append(commands, getFsyncCommand(port, authEnabled))
+	}
+	if stepDown {
+		commands = append(commands, getStepDownCommand(port, authEnabled))
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+	return &corev1.Lifecycle{
+		PreStop: &corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"/bin/sh", "-c", strings.Join(commands, "; ")},
+			},
+		},
+	}
+}
+
+// getFsyncCommand is a method to generate the shell command which flushes the data files to disk, so the checkpoint on shutdown and the recovery on start have less to do
+func getFsyncCommand(port int32, authEnabled bool) string {
+	return fmt.Sprintf("mongo --port %d --quiet %s --eval 'db.adminCommand({fsync: 1})' || true", port, getShellCredentials(authEnabled))
+}
+
+// getShellCredentials is a method to generate the mongo shell flags authenticating as the root user
+func getShellCredentials(authEnabled bool) string {
+	if !authEnabled {
+		return ""
+	}
+	return "--username \"$MONGO_ROOT_USERNAME\" --password \"$MONGO_ROOT_PASSWORD\" --authenticationDatabase admin"
+}
+
+// getStepDownCommand is a method to generate the shell command which steps down the primary and waits for the election of a new one
+func getStepDownCommand(port int32, authEnabled bool) string {
+	credentials := getShellCredentials(authEnabled)
+	// the connection can be closed while stepping down, so the election is awaited with fresh isMaster calls
+	script := fmt.Sprintf("if (db.isMaster().ismaster) { try { rs.stepDown(%d, %d) } catch (e) {} ; for (var i = 0; i < %d && (db.isMaster().ismaster || !db.isMaster().primary); i++) { sleep(1000) } }",
+		stepDownCatchUpSeconds+electionTimeoutSeconds, stepDownCatchUpSeconds, stepDownCatchUpSeconds+electionTimeoutSeconds)
+	return fmt.Sprintf("mongo --port %d --quiet %s --eval '%s' || true", port, credentials, script)
+}
@@ -0,0 +1,118 @@
+package k8sgo
+
+import (
+	"strings"
+	"testing"
+
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestGetTerminationGracePeriod(t *testing.T) {
+	override := int64(45)
+	tests := []struct {
+		name         string
+		gracePeriod  *int64
+		preStopDelay int32
+		stepDown     bool
+		storageSize  string
+		want         *int64
+	}{
+		{name: "kubernetes default without storage", preStopDelay: 5},
+		{name: "kubernetes default for small volumes", preStopDelay: 5, storageSize: "50Gi"},
+		{name: "invalid storage size", preStopDelay: 5, storageSize: "lots"},
+		{name: "large volume", preStopDelay: 5, storageSize: "200Gi", want: int64Ptr(65)},
+		{name: "terabyte volume", preStopDelay: 5, storageSize: "1Ti", want: int64Ptr(312)},
+		{name: "capped", preStopDelay: 5, storageSize: "4Ti", want: int64Ptr(maxTerminationGracePeriodSeconds)},
+		{name: "step down without storage", preStopDelay: 5, stepDown: true, want: int64Ptr(55)},
+		{name: "step down without pre stop delay", stepDown: true, storageSize: "10Gi", want: int64Ptr(50)},
+		{name: "step down with large volume", preStopDelay: 5, stepDown: true, storageSize: "200Gi", want: int64Ptr(85)},
+		{name: "step down capped", preStopDelay: 5, stepDown: true, storageSize: "4Ti", want: int64Ptr(maxTerminationGracePeriodSeconds)},
+		{name: "step down minimum beyond the cap", preStopDelay: 600, stepDown: true, storageSize: "4Ti", want: int64Ptr(650)},
+		{name: "explicit override", gracePeriod: &override, preStopDelay: 5, stepDown: true, storageSize: "4Ti", want: &override},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var storage *opstreelabsinv1alpha1.Storage
+			if test.storageSize != "" {
+				storage = &opstreelabsinv1alpha1.Storage{StorageSize: test.storageSize}
+			}
+			got := getTerminationGracePeriod(test.gracePeriod, test.preStopDelay, test.stepDown, storage)
+			if (got == nil) != (test.want == nil) || (got != nil && *got != *test.want) {
+				t.Errorf("getTerminationGracePeriod() = %v, want %v", formatInt64Ptr(got), formatInt64Ptr(test.want))
+			}
+		})
+	}
+}
+
+func TestValidateTerminationGracePeriod(t *testing.T) {
+	tests := []struct {
+		name         string
+		gracePeriod  *int64
+		preStopDelay int32
+		wantErr      bool
+	}{
+		{name: "derived", preStopDelay: 5},
+		{name: "minimum", gracePeriod: int64Ptr(55), preStopDelay: 5},
+		{name: "below the minimum", gracePeriod: int64Ptr(54), preStopDelay: 5, wantErr: true},
+		{name: "minimum with a longer pre stop delay", gracePeriod: int64Ptr(70), preStopDelay: 20},
+		{name: "below the minimum with a longer pre stop delay", gracePeriod: int64Ptr(55), preStopDelay: 20, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateTerminationGracePeriod(test.gracePeriod, test.preStopDelay); (err != nil) != test.wantErr {
+				t.Errorf("validateTerminationGracePeriod() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetShutdownLifecycle(t *testing.T) {
+	tests := []struct {
+		name         string
+		authEnabled  bool
+		preStopDelay int32
+		fsync        bool
+		stepDown     bool
+		wantCommands []string
+	}{
+		{name: "no step down"},
+		{name: "step down", stepDown: true, wantCommands: []string{"rs.stepDown(20, 10)", "--port 27017"}},
+		{name: "step down with authentication", authEnabled: true, stepDown: true, wantCommands: []string{"rs.stepDown(20, 10)", "--authenticationDatabase admin"}},
+		{name: "pre stop delay", preStopDelay: 5, wantCommands: []string{"sleep 5"}},
+		{name: "pre stop delay before the step down", preStopDelay: 5, stepDown: true, wantCommands: []string{"sleep 5; mongo", "rs.stepDown(20, 10)"}},
+		{name: "fsync", fsync: true, wantCommands: []string{"db.adminCommand({fsync: 1})"}},
+		{name: "fsync with authentication", authEnabled: true, fsync: true, wantCommands: []string{"db.adminCommand({fsync: 1})", "--authenticationDatabase admin"}},
+		{name: "fsync before the step down", preStopDelay: 5, fsync: true, stepDown: true, wantCommands: []string{"sleep 5; mongo", "{fsync: 1})' || true; mongo", "rs.stepDown(20, 10)"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lifecycle := getShutdownLifecycle(mongoDBPort, test.authEnabled, test.preStopDelay, test.fsync, test.stepDown)
+			if test.wantCommands == nil {
+				if lifecycle != nil {
+					t.Errorf("getShutdownLifecycle() = %v, want no lifecycle", lifecycle)
+				}
+				return
+			}
+			command := strings.Join(lifecycle.PreStop.Exec.Command, " ")
+			for _, want := range test.wantCommands {
+				if !strings.Contains(command, want) {
+					t.Errorf("getShutdownLifecycle() command = %s, missing %s", command, want)
+				}
+			}
+			if (test.fsync || test.stepDown) && !strings.HasSuffix(command, "|| true") {
+				t.Errorf("getShutdownLifecycle() command = %s, doesn't ignore the failures of the hook", command)
+			}
+		})
+	}
+}
+
+func int64Ptr(value int64) *int64 {
+	return &value
+}
+
+func formatInt64Ptr(value *int64) interface{} {
+	if value == nil {
+		return nil
+	}
+	return *value
+}
@@ -0,0 +1,238 @@
+package k8sgo
+
+import (
+	"context"
+	"fmt"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/mongo"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultSnapshotRetention is the number of volume snapshots kept for each data volume of mongodb cluster
+	defaultSnapshotRetention int32 = 3
+	// snapshotCutTimeout is the time a secondary stays locked waiting for the storage to take the snapshot
+	snapshotCutTimeout = time.Minute
+	// minimumSnapshotInterval is the shortest interval allowed between two volume snapshots of mongodb cluster
+	minimumSnapshotInterval = 10 * time.Minute
+	// SnapshotPollInterval is the time after which a volume snapshot being cut is checked again
+	SnapshotPollInterval = 5 * time.Second
+	// snapshotMemberUnlockedAnnotation marks the volume snapshots whose member was unlocked after the snapshot
+	snapshotMemberUnlockedAnnotation = "mongodb.opstreelabs.in/member-unlocked"
+)
+
+// volumeSnapshotResource is the resource of CSI VolumeSnapshots, it has no typed client in client-go
+var volumeSnapshotResource = schema.GroupVersionResource{Group: "snapshot.storage.k8s.io", Version: "v1", Resource: "volumesnapshots"}
+
+// IsVolumeSnapshotEnabled is a method to check if the data volumes of mongodb cluster are backed up with volume snapshots
+func IsVolumeSnapshotEnabled(cr *opstreelabsinv1alpha1.MongoDBCluster) bool {
+	return cr.Spec.VolumeSnapshot != nil && cr.Spec.VolumeSnapshot.Enabled
+}
+
+// validateVolumeSnapshot is a method to validate the interval of volume snapshots and that mongodb cluster has persistent volumes
+func validateVolumeSnapshot(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	if !IsVolumeSnapshotEnabled(cr) {
+		return nil
+	}
+	if getPersistentStorage(cr.Spec.MongoDBConfig, cr.Spec.Storage) == nil {
+		return fmt.Errorf("volumeSnapshot requires storage, the data of MongoDB cluster is not persisted")
+	}
+	if cr.Spec.VolumeSnapshot.Interval.Duration < minimumSnapshotInterval {
+		return fmt.Errorf("volumeSnapshot interval must be at least %s", minimumSnapshotInterval)
+	}
+	return nil
+}
+
+// GetMongoClusterSnapshotDelay is a method to get the time until the next volume snapshot of mongodb cluster is due, it is 0 if it is due now
+func GetMongoClusterSnapshotDelay(cr *opstreelabsinv1alpha1.MongoDBCluster, now time.Time) time.Duration {
+	if cr.Status.LastSnapshotTime == nil {
+		return 0
+	}
+	delay := cr.Status.LastSnapshotTime.Add(cr.Spec.VolumeSnapshot.Interval.Duration).Sub(now)
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// ReconcileMongoClusterSnapshots is a method to advance the volume snapshots of the round started at the snapshot start time of mongodb cluster, the primary is skipped so writes are never blocked
+// a secondary stays locked while the storage cuts its snapshot and the reconciliation is requeued instead of waiting for it, the snapshots of the round are returned once every secondary is done
+func ReconcileMongoClusterSnapshots(cr *opstreelabsinv1alpha1.MongoDBCluster, now time.Time) ([]string, bool, error) {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "VolumeSnapshot")
+	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return nil, false, err
+	}
+	mongoParams := mongogo.MongoDBParameters{
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		Port:      getMongoDBPort(cr.Spec.MongoDBConfig),
+		SetupType: "cluster",
+	}
+	mongoParams.MongoURL = getMongoDBClusterURL(cr, mongoParams, password)
+	secondaries, err := mongogo.GetMongoClusterSecondaries(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to get the secondaries of MongoDB cluster")
+		return nil, false, err
+	}
+	if len(secondaries) == 0 {
+		return nil, false, fmt.Errorf("MongoDB cluster has no healthy secondary to snapshot")
+	}
+	client, err := generateK8sDynamicClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return nil, false, err
+	}
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	var snapshots []string
+	for _, secondary := range secondaries {
+		// the claims of the statefulset are named after the claim template and the pod
+		pvcName := fmt.Sprintf("%s-%s", appName, strings.Split(secondary, ".")[0])
+		snapshotDef := generateVolumeSnapshotDef(cr, pvcName, cr.Status.SnapshotStartTime.Time)
+		memberParams := mongoParams
+		memberParams.MongoURL = generateCredentialURI(cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, secondary)
+		completed, err := reconcileLockedVolumeSnapshot(client, memberParams, snapshotDef, now)
+		if err != nil {
+			logger.Error(err, "Unable to snapshot the data volume of MongoDB secondary", "member", secondary)
+			return snapshots, false, err
+		}
+		if !completed {
+			// a single member is locked at a time
+			return snapshots, false, nil
+		}
+		snapshots = append(snapshots, snapshotDef.GetName())
+	}
+	if err := pruneMongoClusterSnapshots(client, cr); err != nil {
+		logger.Error(err, "Unable to delete the expired volume snapshots of MongoDB cluster")
+		return snapshots, false, err
+	}
+	logger.Info("Successfully created the volume snapshots of MongoDB cluster", "snapshots", snapshots)
+	return snapshots, true, nil
+}
+
+// reconcileLockedVolumeSnapshot is a method to advance the volume snapshot of a member by one step, the member is locked when the snapshot is created and unlocked once it is cut, failed or timed out
+func reconcileLockedVolumeSnapshot(client dynamic.Interface, memberParams mongogo.MongoDBParameters, snapshotDef *unstructured.Unstructured, now time.Time) (bool, error) {
+	snapshots := client.Resource(volumeSnapshotResource).Namespace(snapshotDef.GetNamespace())
+	snapshot, err := snapshots.Get(context.TODO(), snapshotDef.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		if err := mongogo.LockMongoNode(memberParams); err != nil {
+			return false, err
+		}
+		if _, err := snapshots.Create(context.TODO(), snapshotDef, metav1.CreateOptions{}); err != nil {
+			// the member is unlocked right away, otherwise its replication stays blocked
+			if unlockErr := mongogo.UnlockMongoNode(memberParams); unlockErr != nil {
+				return false, unlockErr
+			}
+			return false, err
+		}
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	state, err := getVolumeSnapshotState(snapshot, now)
+	if snapshot.GetAnnotations()[snapshotMemberUnlockedAnnotation] == "true" {
+		return state == volumeSnapshotCut, err
+	}
+	if state == volumeSnapshotCutting {
+		return false, nil
+	}
+	if unlockErr := unlockVolumeSnapshotMember(snapshots, snapshot, memberParams); unlockErr != nil {
+		return false, unlockErr
+	}
+	return state == volumeSnapshotCut, err
+}
+
+// volumeSnapshotState is the progress of a volume snapshot while its member is locked
+type volumeSnapshotState int
+
+const (
+	volumeSnapshotCutting volumeSnapshotState = iota
+	volumeSnapshotCut
+	volumeSnapshotFailed
+)
+
+// getVolumeSnapshotState is a method to get if the storage has cut the volume snapshot, failed to or didn't cut it within the timeout
+func getVolumeSnapshotState(snapshot *unstructured.Unstructured, now time.Time) (volumeSnapshotState, error) {
+	if message, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found {
+		return volumeSnapshotFailed, fmt.Errorf("volume snapshot %s failed: %s", snapshot.GetName(), message)
+	}
+	// the creation time is set once the snapshot is cut, uploading it doesn't need the member to be locked
+	if _, found, _ := unstructured.NestedString(snapshot.Object, "status", "creationTime"); found {
+		return volumeSnapshotCut, nil
+	}
+	if now.Sub(snapshot.GetCreationTimestamp().Time) > snapshotCutTimeout {
+		return volumeSnapshotFailed, fmt.Errorf("volume snapshot %s was not cut within %s", snapshot.GetName(), snapshotCutTimeout)
+	}
+	return volumeSnapshotCutting, nil
+}
+
+// unlockVolumeSnapshotMember is a method to unlock the member of the volume snapshot and to mark the snapshot, so the member is only unlocked once
+func unlockVolumeSnapshotMember(snapshots dynamic.ResourceInterface, snapshot *unstructured.Unstructured, memberParams mongogo.MongoDBParameters) error {
+	if err := mongogo.UnlockMongoNode(memberParams); err != nil {
+		return err
+	}
+	snapshot.SetAnnotations(mergeAnnotations(map[string]string{snapshotMemberUnlockedAnnotation: "true"}, snapshot.GetAnnotations()))
+	_, err := snapshots.Update(context.TODO(), snapshot, metav1.UpdateOptions{})
+	return err
+}
+
+// pruneMongoClusterSnapshots is a method to delete the oldest volume snapshots of each data volume beyond the retention
+func pruneMongoClusterSnapshots(client dynamic.Interface, cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	retention := *getInt32OrDefault(cr.Spec.VolumeSnapshot.Retention, defaultSnapshotRetention)
+	snapshots, err := client.Resource(volumeSnapshotResource).Namespace(cr.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=%s-%s,role=snapshot", cr.ObjectMeta.Name, "cluster"),
+	})
+	if err != nil {
+		return err
+	}
+	snapshotsByClaim := map[string][]unstructured.Unstructured{}
+	for _, snapshot := range snapshots.Items {
+		claim, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+		snapshotsByClaim[claim] = append(snapshotsByClaim[claim], snapshot)
+	}
+	for _, claimSnapshots := range snapshotsByClaim {
+		sort.Slice(claimSnapshots, func(i, j int) bool {
+			return claimSnapshots[i].GetCreationTimestamp().After(claimSnapshots[j].GetCreationTimestamp().Time)
+		})
+		for index := int(retention); index < len(claimSnapshots); index++ {
+			err := client.Resource(volumeSnapshotResource).Namespace(cr.Namespace).Delete(context.TODO(), claimSnapshots[index].GetName(), metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// generateVolumeSnapshotDef is a method to generate the volume snapshot of a data volume, it is not owned by mongodb cluster so it outlives the cluster
+func generateVolumeSnapshotDef(cr *opstreelabsinv1alpha1.MongoDBCluster, pvcName string, now time.Time) *unstructured.Unstructured {
+	labels := map[string]string{
+		"app":           fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster"),
+		"mongodb_setup": "cluster",
+		"role":          "snapshot",
+	}
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": pvcName,
+		},
+	}
+	if cr.Spec.VolumeSnapshot.VolumeSnapshotClassName != nil {
+		spec["volumeSnapshotClassName"] = *cr.Spec.VolumeSnapshot.VolumeSnapshotClassName
+	}
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	snapshot.SetAPIVersion("snapshot.storage.k8s.io/v1")
+	snapshot.SetKind("VolumeSnapshot")
+	snapshot.SetName(fmt.Sprintf("%s-%s", pvcName, now.UTC().Format("20060102150405")))
+	snapshot.SetNamespace(cr.Namespace)
+	snapshot.SetLabels(mergeLabels(labels, cr.Labels))
+	return snapshot
+}
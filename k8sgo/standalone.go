@@ -15,23 +15,18 @@ func CreateMongoStandaloneService(cr *opstreelabsinv1alpha1.MongoDB) error {
 		"mongodb_setup": "standalone",
 		"role":          "standalone",
 	}
-	params := serviceParameters{
-		ServiceMeta:     generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations()),
-		OwnerDef:        mongoAsOwner(cr),
-		Namespace:       cr.Namespace,
-		Labels:          labels,
-		Annotations:     generateAnnotations(),
-		HeadlessService: true,
-		Port:            mongoDBPort,
-		PortName:        "mongo",
-	}
-	err := CreateOrUpdateService(params)
+	err := CreateOrUpdateService(applyHeadlessServiceConfig(getMongoDBStandaloneServiceParams(cr), cr.Spec.KubernetesConfig.HeadlessService))
 	if err != nil {
 		logger.Error(err, "Cannot create standalone Service for MongoDB")
 		return err
 	}
+	err = CreateOrUpdateExternalNameService(getMongoDBStandaloneServiceParams(cr), cr.Spec.KubernetesConfig.ExternalNameService, "")
+	if err != nil {
+		logger.Error(err, "Cannot create standalone ExternalName Service for MongoDB")
+		return err
+	}
 	monitoringParams := serviceParameters{
-		ServiceMeta:     generateObjectMetaInformation(fmt.Sprintf("%s-%s", appName, "metrics"), cr.Namespace, labels, generateAnnotations()),
+		ServiceMeta:     generateObjectMetaInformation(fmt.Sprintf("%s-%s", appName, "metrics"), cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations)),
 		OwnerDef:        mongoAsOwner(cr),
 		Namespace:       cr.Namespace,
 		Labels:          labels,
@@ -45,18 +40,225 @@ func CreateMongoStandaloneService(cr *opstreelabsinv1alpha1.MongoDB) error {
 		logger.Error(err, "Cannot create standalone metrics Service for MongoDB")
 		return err
 	}
+	clientParams := serviceParameters{
+		ServiceMeta: generateObjectMetaInformation(fmt.Sprintf("%s-%s", appName, "client"), cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations)),
+		OwnerDef:    mongoAsOwner(cr),
+		Namespace:   cr.Namespace,
+		Labels:      labels,
+		Annotations: generateAnnotations(),
+		Port:        getMongoDBPort(cr.Spec.MongoDBConfig),
+		PortName:    "mongo",
+		ExtraPorts:  cr.Spec.KubernetesConfig.ExtraServicePorts,
+	}
+	err = CreateOrDeleteClientService(clientParams, cr.Spec.KubernetesConfig.Service)
+	if err != nil {
+		logger.Error(err, "Cannot create standalone client Service for MongoDB")
+		return err
+	}
+	return nil
+}
+
+// getMongoDBStandaloneServiceParams is a method to generate the headless service params for mongodb standalone
+func getMongoDBStandaloneServiceParams(cr *opstreelabsinv1alpha1.MongoDB) serviceParameters {
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone")
+	labels := map[string]string{
+		"app":           appName,
+		"mongodb_setup": "standalone",
+		"role":          "standalone",
+	}
+	return serviceParameters{
+		ServiceMeta:              generateObjectMetaInformation(appName, cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations)),
+		OwnerDef:                 mongoAsOwner(cr),
+		Namespace:                cr.Namespace,
+		Labels:                   labels,
+		Annotations:              generateAnnotations(),
+		HeadlessService:          true,
+		PublishNotReadyAddresses: getPublishNotReadyAddresses(cr.Spec.KubernetesConfig),
+		Port:                     getMongoDBPort(cr.Spec.MongoDBConfig),
+		PortName:                 "mongo",
+		ExtraPorts:               cr.Spec.KubernetesConfig.ExtraServicePorts,
+	}
+}
+
+// CleanupMongoStandaloneResources is a method to release the external resources of standalone mongodb before deletion
+func CleanupMongoStandaloneResources(cr *opstreelabsinv1alpha1.MongoDB) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "Service")
+	// cloud load balancers are released with their services, so they don't leak if the namespace deletion races the garbage collector
+	err := deleteLoadBalancerServices(cr.Namespace, fmt.Sprintf("app=%s-%s", cr.ObjectMeta.Name, "standalone"))
+	if err != nil {
+		logger.Error(err, "Cannot delete LoadBalancer Services for standalone MongoDB")
+		return err
+	}
+	err = deleteExternalNameServices(mongoAsOwner(cr), nil, cr.Namespace)
+	if err != nil {
+		logger.Error(err, "Cannot delete ExternalName Service for standalone MongoDB")
+		return err
+	}
+	// priorityclasses are cluster scoped, so they are not garbage collected with mongodb standalone
+	err = deleteMongoDBPriorityClass(cr.Spec.KubernetesConfig, cr.Namespace, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone"), "standalone")
+	if err != nil {
+		logger.Error(err, "Cannot delete PriorityClass for standalone MongoDB")
+		return err
+	}
+	return nil
+}
+
+// CreateMongoStandaloneRBAC is a method to create or delete the ServiceAccount, Role and RoleBinding for mongodb standalone pod
+func CreateMongoStandaloneRBAC(cr *opstreelabsinv1alpha1.MongoDB) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "ServiceAccount")
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone")
+	var err error
+	if cr.Spec.KubernetesConfig.CreateServiceAccount && cr.Spec.KubernetesConfig.ServiceAccountName == "" {
+		labels := map[string]string{
+			"app":           appName,
+			"mongodb_setup": "standalone",
+			"role":          "standalone",
+		}
+		secretNames := []string{fmt.Sprintf("%s-%s", appName, "monitoring")}
+		if cr.Spec.MongoDBSecurity != nil && cr.Spec.MongoDBSecurity.SecretRef.Name != nil {
+			secretNames = append(secretNames, *cr.Spec.MongoDBSecurity.SecretRef.Name)
+		}
+		var configMapNames []string
+		if cr.Spec.MongoDBAdditionalConfig != nil {
+			configMapNames = append(configMapNames, *cr.Spec.MongoDBAdditionalConfig)
+		}
+		err = CreateOrUpdateRBAC(rbacParameters{
+			RBACMeta:         generateObjectMetaInformation(appName, cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations)),
+			OwnerDef:         mongoAsOwner(cr),
+			Namespace:        cr.Namespace,
+			Rules:            getRBACRules(secretNames, configMapNames),
+			ImagePullSecrets: cr.Spec.KubernetesConfig.ServiceAccountImagePullSecrets,
+		})
+	} else {
+		err = deleteRBAC(cr.Namespace, appName, mongoAsOwner(cr))
+	}
+	if err != nil {
+		logger.Error(err, "Cannot create ServiceAccount for MongoDB standalone")
+		return err
+	}
 	return nil
 }
 
 // CreateMongoStandaloneSetup is a method to create standalone statefulset for MongoDB
 func CreateMongoStandaloneSetup(cr *opstreelabsinv1alpha1.MongoDB) error {
 	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "StatefulSet")
+	if err := validateSeccompProfile(cr.Spec.KubernetesConfig); err != nil {
+		logger.Error(err, "Invalid seccomp profile for standalone MongoDB")
+		return err
+	}
+	if err := validateSysctls(cr.Spec.KubernetesConfig); err != nil {
+		logger.Error(err, "Invalid sysctls for standalone MongoDB")
+		return err
+	}
+	if err := validateServiceAccountImagePullSecrets(cr.Spec.KubernetesConfig); err != nil {
+		logger.Error(err, "Invalid ServiceAccount for standalone MongoDB")
+		return err
+	}
+	if err := validateClientService(cr.Spec.KubernetesConfig); err != nil {
+		logger.Error(err, "Invalid client service for standalone MongoDB")
+		return err
+	}
+	if err := validateExtraVolumeMounts(cr.Spec.KubernetesConfig.ExtraVolumes, cr.Spec.KubernetesConfig.ExtraVolumeMounts); err != nil {
+		logger.Error(err, "Invalid extra volumes for standalone MongoDB")
+		return err
+	}
+	if err := validateMongoDBUsers(cr.Spec.MongoDBSecurity); err != nil {
+		logger.Error(err, "Invalid users for standalone MongoDB")
+		return err
+	}
+	if err := validateImage(cr.Spec.KubernetesConfig.Image); err != nil {
+		logger.Error(err, "Invalid image for standalone MongoDB")
+		return err
+	}
+	if err := validateMonitoringResources(cr.Spec.MongoDBMonitoring); err != nil {
+		logger.Error(err, "Invalid exporter resources for standalone MongoDB")
+		return err
+	}
+	if err := validateExtraPorts(cr.Spec.KubernetesConfig, getMongoDBPort(cr.Spec.MongoDBConfig), cr.Spec.MongoDBMonitoring != nil); err != nil {
+		logger.Error(err, "Invalid extra ports for standalone MongoDB")
+		return err
+	}
+	if err := validateHostNetwork(cr.Spec.KubernetesConfig, getMongoDBPort(cr.Spec.MongoDBConfig), cr.Spec.MongoDBMonitoring != nil); err != nil {
+		logger.Error(err, "Invalid host network configuration for standalone MongoDB")
+		return err
+	}
+	if err := validateProjectedVolume(cr.Spec.KubernetesConfig.ProjectedVolume, getMongoDBConfigDBPath(cr.Spec.MongoDBConfig)); err != nil {
+		logger.Error(err, "Invalid projected volume for standalone MongoDB")
+		return err
+	}
+	if err := validateProbes(cr.Spec.KubernetesConfig.Probes); err != nil {
+		logger.Error(err, "Invalid probes for standalone MongoDB")
+		return err
+	}
+	if err := validateReadinessScript(cr.Spec.KubernetesConfig.Probes, getMongoDBConfigDBPath(cr.Spec.MongoDBConfig)); err != nil {
+		logger.Error(err, "Invalid readiness script for standalone MongoDB")
+		return err
+	}
+	if err := validateMaintenanceWindow(cr.Spec.MaintenanceWindow); err != nil {
+		logger.Error(err, "Invalid maintenance window for standalone MongoDB")
+		return err
+	}
+	if err := validateAccessModes(cr.Spec.Storage); err != nil {
+		logger.Error(err, "Invalid storage for standalone MongoDB")
+		return err
+	}
+	if err := validateVolumeMode(cr.Spec.Storage); err != nil {
+		logger.Error(err, "Invalid storage for standalone MongoDB")
+		return err
+	}
+	if err := validateStorageEngine(cr.Spec.MongoDBConfig, cr.Spec.MongoDBRestore, cr.Spec.KubernetesConfig.Image); err != nil {
+		logger.Error(err, "Invalid storageEngine for standalone MongoDB")
+		return err
+	}
+	if err := validateContainerName(cr.Spec.KubernetesConfig.ContainerName); err != nil {
+		logger.Error(err, "Invalid containerName for standalone MongoDB")
+		return err
+	}
+	if err := validateExternalNameService(cr.Spec.KubernetesConfig.ExternalNameService, fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "standalone"), cr.Namespace, ""); err != nil {
+		logger.Error(err, "Invalid externalNameService for standalone MongoDB")
+		return err
+	}
+	if cr.Spec.MongoDBConfig != nil {
+		if err := validateExtraArgs(cr.Spec.MongoDBConfig.ExtraArgs); err != nil {
+			logger.Error(err, "Invalid mongoDBConfig for standalone MongoDB")
+			return err
+		}
+		if err := validateMongoDBLogging(cr.Spec.MongoDBConfig.Logging); err != nil {
+			logger.Error(err, "Invalid logging for standalone MongoDB")
+			return err
+		}
+		if err := validateDBPath(cr.Spec.MongoDBConfig.DBPath); err != nil {
+			logger.Error(err, "Invalid dbPath for standalone MongoDB")
+			return err
+		}
+		if err := validateWarmUp(cr.Spec.MongoDBConfig.WarmUp); err != nil {
+			logger.Error(err, "Invalid warmUp for standalone MongoDB")
+			return err
+		}
+		if err := validateAuditLog(cr.Spec.MongoDBConfig, cr.Spec.KubernetesConfig.Image); err != nil {
+			logger.Error(err, "Invalid auditLog for standalone MongoDB")
+			return err
+		}
+		if err := validateBindIP(cr.Spec.MongoDBConfig); err != nil {
+			logger.Error(err, "Invalid bindIp for standalone MongoDB")
+			return err
+		}
+	}
+	if cr.Spec.MongoDBRestore != nil && cr.Spec.Storage == nil {
+		err := fmt.Errorf("mongoDBRestore requires storage to be configured")
+		logger.Error(err, "Cannot restore standalone MongoDB without persistence")
+		return err
+	}
+	if err := validateRestoreCABundle(cr.Spec.MongoDBRestore); err != nil {
+		logger.Error(err, "Invalid mongoDBRestore for standalone MongoDB")
+		return err
+	}
 	err := CreateOrUpdateStateFul(getMongoDBStandaloneParams(cr))
-	if err != nil {
+	if err != nil && !IsUpdateDeferred(err) {
 		logger.Error(err, "Cannot create standalone StatefulSet for MongoDB")
 		return err
 	}
-	return nil
+	return err
 }
 
 // CreateMongoMonitoringSecret is a method to create secret for monitoring
@@ -104,28 +306,54 @@ func getMongoDBStandaloneParams(cr *opstreelabsinv1alpha1.MongoDB) statefulSetPa
 		"role":          "standalone",
 	}
 	params := statefulSetParameters{
-		StatefulSetMeta: generateObjectMetaInformation(appName, cr.Namespace, labels, generateAnnotations()),
+		StatefulSetMeta: generateObjectMetaInformation(appName, cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations)),
 		OwnerDef:        mongoAsOwner(cr),
 		Namespace:       cr.Namespace,
 		ContainerParams: containerParameters{
-			Image:           cr.Spec.KubernetesConfig.Image,
-			ImagePullPolicy: cr.Spec.KubernetesConfig.ImagePullPolicy,
-			Resources:       cr.Spec.KubernetesConfig.Resources,
-			MongoSetupType:  "standalone",
+			Image:                    cr.Spec.KubernetesConfig.Image,
+			Port:                     getMongoDBPort(cr.Spec.MongoDBConfig),
+			ImagePullPolicy:          cr.Spec.KubernetesConfig.ImagePullPolicy,
+			Resources:                cr.Spec.KubernetesConfig.Resources,
+			EnvVars:                  cr.Spec.KubernetesConfig.EnvVars,
+			EnvFrom:                  cr.Spec.KubernetesConfig.EnvFrom,
+			ExtraVolumeMounts:        cr.Spec.KubernetesConfig.ExtraVolumeMounts,
+			ExtraPorts:               cr.Spec.KubernetesConfig.ExtraPorts,
+			SecurityContext:          cr.Spec.KubernetesConfig.ContainerSecurityContext,
+			ReadinessProbeMode:       cr.Spec.KubernetesConfig.ReadinessProbeMode,
+			TerminationMessagePath:   cr.Spec.KubernetesConfig.TerminationMessagePath,
+			TerminationMessagePolicy: cr.Spec.KubernetesConfig.TerminationMessagePolicy,
+			ContainerName:            cr.Spec.KubernetesConfig.ContainerName,
+			MongoSetupType:           "standalone",
+			PreStopDelaySeconds:      *getInt32OrDefault(cr.Spec.KubernetesConfig.PreStopDelaySeconds, defaultPreStopDelaySeconds),
+			// the in-memory storage engine has no data files to flush
+			FsyncOnShutdown: cr.Spec.KubernetesConfig.FsyncOnShutdown && !isInMemoryStorageEngine(cr.Spec.MongoDBConfig),
+			PostStart:       cr.Spec.KubernetesConfig.PostStart,
 		},
-		Replicas:          &replicas,
-		Labels:            labels,
-		Annotations:       generateAnnotations(),
-		NodeSelector:      cr.Spec.KubernetesConfig.NodeSelector,
-		Affinity:          cr.Spec.KubernetesConfig.Affinity,
-		PriorityClassName: cr.Spec.KubernetesConfig.PriorityClassName,
-		Tolerations:       cr.Spec.KubernetesConfig.Tolerations,
-		SecurityContext:   cr.Spec.KubernetesConfig.SecurityContext,
+		Replicas:                      &replicas,
+		Labels:                        labels,
+		Annotations:                   getPodAnnotations(cr.Spec.KubernetesConfig),
+		NodeSelector:                  cr.Spec.KubernetesConfig.NodeSelector,
+		Affinity:                      cr.Spec.KubernetesConfig.Affinity,
+		PriorityClassName:             getPriorityClassName(cr.Spec.KubernetesConfig, cr.Namespace, appName),
+		SchedulerName:                 cr.Spec.KubernetesConfig.SchedulerName,
+		RuntimeClassName:              cr.Spec.KubernetesConfig.RuntimeClassName,
+		ServiceAccountName:            getServiceAccountName(cr.Spec.KubernetesConfig, appName),
+		Tolerations:                   cr.Spec.KubernetesConfig.Tolerations,
+		SecurityContext:               getPodSecurityContext(cr.Spec.KubernetesConfig),
+		FSGroupChangePolicy:           cr.Spec.KubernetesConfig.FSGroupChangePolicy,
+		ExtraVolumes:                  &cr.Spec.KubernetesConfig.ExtraVolumes,
+		RevisionHistoryLimit:          cr.Spec.KubernetesConfig.RevisionHistoryLimit,
+		MinReadySeconds:               cr.Spec.KubernetesConfig.MinReadySeconds,
+		DNSPolicy:                     cr.Spec.KubernetesConfig.DNSPolicy,
+		DNSConfig:                     cr.Spec.KubernetesConfig.DNSConfig,
+		HostNetwork:                   cr.Spec.KubernetesConfig.HostNetwork,
+		HostAliases:                   cr.Spec.KubernetesConfig.HostAliases,
+		ImagePullSecrets:              getImagePullSecrets(cr.Spec.KubernetesConfig.ImagePullSecret, cr.Spec.KubernetesConfig.ImagePullSecrets),
+		MaintenanceWindow:             cr.Spec.MaintenanceWindow,
+		RecreateOnImmutableChange:     cr.Spec.KubernetesConfig.ImmutableFieldChangePolicy == immutableFieldChangeRecreate,
+		TerminationGracePeriodSeconds: getTerminationGracePeriod(cr.Spec.KubernetesConfig.TerminationGracePeriodSeconds, *getInt32OrDefault(cr.Spec.KubernetesConfig.PreStopDelaySeconds, defaultPreStopDelaySeconds), false, getPersistentStorage(cr.Spec.MongoDBConfig, cr.Spec.Storage)),
 	}
 
-	if cr.Spec.KubernetesConfig.ImagePullSecret != nil {
-		params.ImagePullSecret = cr.Spec.KubernetesConfig.ImagePullSecret
-	}
 	if cr.Spec.MongoDBSecurity != nil {
 		params.ContainerParams.MongoDBUser = &cr.Spec.MongoDBSecurity.MongoDBAdminUser
 		params.ContainerParams.SecretName = cr.Spec.MongoDBSecurity.SecretRef.Name
@@ -142,19 +370,51 @@ func getMongoDBStandaloneParams(cr *opstreelabsinv1alpha1.MongoDB) statefulSetPa
 		params.ContainerParams.AdditonalConfig = cr.Spec.MongoDBAdditionalConfig
 		params.AdditionalConfig = cr.Spec.MongoDBAdditionalConfig
 	}
-	if cr.Spec.Storage != nil {
+	if storage := getPersistentStorage(cr.Spec.MongoDBConfig, cr.Spec.Storage); storage != nil {
 		params.ContainerParams.PersistenceEnabled = &trueProperty
 		params.PVCParameters = pvcParameters{
 			Name:             appName,
 			Namespace:        cr.Namespace,
-			Labels:           labels,
-			Annotations:      generateAnnotations(),
-			StorageSize:      cr.Spec.Storage.StorageSize,
-			StorageClassName: cr.Spec.Storage.StorageClassName,
-			AccessModes:      cr.Spec.Storage.AccessModes,
+			Labels:           mergeLabels(labels, cr.Labels),
+			Annotations:      mergeAnnotations(generateAnnotations(), cr.Annotations),
+			StorageSize:      storage.StorageSize,
+			StorageClassName: storage.StorageClassName,
+			AccessModes:      storage.AccessModes,
+			VolumeMode:       storage.VolumeMode,
+		}
+		if storage.VolumePermissions {
+			params.VolumePermissionsImage = getInitImage(cr.Spec.KubernetesConfig.InitImage)
 		}
 	} else {
 		params.ContainerParams.PersistenceEnabled = &falseProperty
 	}
+	params.ContainerParams.CacheAutoTuning = true
+	if cr.Spec.MongoDBConfig != nil {
+		params.ContainerParams.ExtraArgs = cr.Spec.MongoDBConfig.ExtraArgs
+		params.ContainerParams.Command = cr.Spec.MongoDBConfig.Command
+		params.ContainerParams.Logging = cr.Spec.MongoDBConfig.Logging
+		params.ContainerParams.DBPath = cr.Spec.MongoDBConfig.DBPath
+		params.ContainerParams.WarmUp = cr.Spec.MongoDBConfig.WarmUp
+		params.ContainerParams.AuditLog = getAuditLog(cr.Spec.MongoDBConfig)
+		params.AuditPVCParameters = getAuditPVCParameters(cr.Spec.MongoDBConfig, cr.Namespace, mergeLabels(labels, cr.Labels), mergeAnnotations(generateAnnotations(), cr.Annotations))
+		params.ContainerParams.StorageEngine = cr.Spec.MongoDBConfig.StorageEngine
+		params.ContainerParams.BindIP = cr.Spec.MongoDBConfig.BindIP
+		if cr.Spec.MongoDBConfig.WiredTigerCacheAutoTuning != nil {
+			params.ContainerParams.CacheAutoTuning = *cr.Spec.MongoDBConfig.WiredTigerCacheAutoTuning
+		}
+	}
+	params.ProjectedVolume, params.ContainerParams.ExtraVolumeMount = getProjectedVolume(cr.Spec.KubernetesConfig.ProjectedVolume)
+	params.ScratchVolume = getScratchVolume(cr.Spec.KubernetesConfig.ScratchVolume)
+	params.ContainerParams.ScratchVolumeEnabled = params.ScratchVolume != nil
+	if cr.Spec.KubernetesConfig.Probes != nil {
+		params.ContainerParams.LivenessProbe = cr.Spec.KubernetesConfig.Probes.LivenessProbe
+		params.ContainerParams.ReadinessProbe = cr.Spec.KubernetesConfig.Probes.ReadinessProbe
+		params.ContainerParams.StartupProbe = cr.Spec.KubernetesConfig.Probes.StartupProbe
+		params.ContainerParams.ReadinessScript = cr.Spec.KubernetesConfig.Probes.ReadinessScript
+	}
+	if cr.Spec.MongoDBRestore != nil && getPersistentStorage(cr.Spec.MongoDBConfig, cr.Spec.Storage) != nil {
+		params.RestoreParams = getRestoreParams(cr.Spec.MongoDBRestore)
+	}
+	params.AutomountServiceAccountToken = getAutomountServiceAccountToken(cr.Spec.KubernetesConfig, params.RestoreParams)
 	return params
 }
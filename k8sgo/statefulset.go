@@ -1,36 +1,76 @@
 package k8sgo
 
 import (
-	"fmt"
 	"context"
+	"fmt"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	resource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/iamabhishek-dubey/k8s-objectmatcher/patch"
 	appsv1 "k8s.io/api/apps/v1"
 )
 
+const (
+	// defaultRevisionHistoryLimit is the number of ControllerRevisions kept for MongoDB statefulset
+	defaultRevisionHistoryLimit int32 = 5
+	// defaultInitImage is the image of the utility init containers, it only needs a shell with find and chown
+	defaultInitImage               = "busybox:1.36"
+	volumePermissionsContainerName = "volume-permissions"
+	projectedVolumeName            = "projected-secrets"
+	scratchVolumeName              = "scratch"
+	// statefulSetDeletionTimeout is the time to wait for the orphan deletion of a statefulset before it is recreated
+	statefulSetDeletionTimeout = 30 * time.Second
+	// immutableFieldChangeRecreate recreates the statefulset with its pods orphaned when immutable fields change
+	immutableFieldChangeRecreate = "Recreate"
+)
+
 // statefulSetParameters is the input struct for MongoDB statefulset
 type statefulSetParameters struct {
-	StatefulSetMeta   metav1.ObjectMeta
-	OwnerDef          metav1.OwnerReference
-	Namespace         string
-	ContainerParams   containerParameters
-	Labels            map[string]string
-	Annotations       map[string]string
-	Replicas          *int32
-	PVCParameters     pvcParameters
-	ExtraVolumes      *[]corev1.Volume
-	ImagePullSecret   *string
-	Affinity          *corev1.Affinity
-	NodeSelector      map[string]string
-	Tolerations       *[]corev1.Toleration
-	PriorityClassName string
-	AdditionalConfig  *string
-	SecurityContext   *corev1.PodSecurityContext
+	StatefulSetMeta               metav1.ObjectMeta
+	OwnerDef                      metav1.OwnerReference
+	Namespace                     string
+	ContainerParams               containerParameters
+	Labels                        map[string]string
+	Annotations                   map[string]string
+	Replicas                      *int32
+	PVCParameters                 pvcParameters
+	AuditPVCParameters            *pvcParameters
+	ExtraVolumes                  *[]corev1.Volume
+	ImagePullSecrets              []string
+	Affinity                      *corev1.Affinity
+	NodeSelector                  map[string]string
+	Tolerations                   *[]corev1.Toleration
+	PriorityClassName             string
+	SchedulerName                 string
+	RuntimeClassName              *string
+	ServiceAccountName            string
+	AdditionalConfig              *string
+	SecurityContext               *corev1.PodSecurityContext
+	RestoreParams                 *restoreParameters
+	RevisionHistoryLimit          *int32
+	MinReadySeconds               int32
+	DNSPolicy                     corev1.DNSPolicy
+	DNSConfig                     *corev1.PodDNSConfig
+	HostNetwork                   bool
+	HostAliases                   []corev1.HostAlias
+	ProjectedVolume               *corev1.ProjectedVolumeSource
+	ScratchVolume                 *corev1.EmptyDirVolumeSource
+	MaintenanceWindow             *opstreelabsinv1alpha1.MaintenanceWindow
+	TerminationGracePeriodSeconds *int64
+	FSGroupChangePolicy           *corev1.PodFSGroupChangePolicy
+	VolumePermissionsImage        string
+	InitImage                     string
+	AutomountServiceAccountToken  *bool
+	UpdateStrategy                appsv1.StatefulSetUpdateStrategyType
+	RecreateOnImmutableChange     bool
 }
 
 // pvcParameters is the structure for MongoDB PVC
@@ -42,115 +82,192 @@ type pvcParameters struct {
 	AccessModes      []corev1.PersistentVolumeAccessMode
 	StorageClassName *string
 	StorageSize      string
+	VolumeMode       *corev1.PersistentVolumeMode
 }
 
 // CreateOrUpdateStateFul method will create or update StatefulSet
 func CreateOrUpdateStateFul(params statefulSetParameters) error {
-    logger := logGenerator(params.StatefulSetMeta.Name, params.Namespace, "StatefulSet")
-
-    storedStateful, err := GetStateFulSet(params.Namespace, params.StatefulSetMeta.Name)
-    if err != nil && !errors.IsNotFound(err) {
-        logger.Error(err, "Error retrieving existing StatefulSet")
-        return err
-    }
-
-    if storedStateful == nil {
-        logger.Info("StatefulSet does not exist, creating new one...")
-    }
-
-    if params.Replicas == nil {
-        logger.Info("Replicas is nil, defaulting to 1")
-        var defaultReplicas int32 = 1
-        params.Replicas = &defaultReplicas
-    }
-
-    if params.PVCParameters.StorageSize == "" {
-        logger.Error(fmt.Errorf("invalid PVCParameters"), "PVC storage size is missing")
-        params.PVCParameters = pvcParameters{
-            StorageSize: "1Gi", // Default value
-        }
-    }
-
-    statefulSetDef := generateStatefulSetDef(params)
-    if statefulSetDef == nil {
-        return fmt.Errorf("failed to generate StatefulSet definition")
-    }
-
-    if err != nil && errors.IsNotFound(err) {
-        if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(statefulSetDef); err != nil {
-            logger.Error(err, "Unable to patch MongoDB StatefulSet with comparison object")
-            return err
-        }
-        return createStateFulSet(params.Namespace, statefulSetDef)
-    }
-
-    if storedStateful == nil {
-        return fmt.Errorf("storedStateful is nil, skipping patch")
-    }
-
-    return patchStateFulSet(storedStateful, statefulSetDef, params.Namespace)
-}
-
-
-
-// patchStateFulSet will patch Statefulset
-func patchStateFulSet(storedStateful *appsv1.StatefulSet, newStateful *appsv1.StatefulSet, namespace string) error {
-    logger := logGenerator(storedStateful.Name, namespace, "StatefulSet")
-
-    if storedStateful == nil || newStateful == nil {
-        return fmt.Errorf("storedStateful or newStateful is nil")
-    }
-
-    newStateful.ResourceVersion = storedStateful.ResourceVersion
-    newStateful.CreationTimestamp = storedStateful.CreationTimestamp
-    newStateful.ManagedFields = storedStateful.ManagedFields
-
-    patchResult, err := patch.DefaultPatchMaker.Calculate(storedStateful, newStateful,
-        patch.IgnoreStatusFields(),
-        patch.IgnoreVolumeClaimTemplateTypeMetaAndStatus(),
-        patch.IgnorePersistenVolumeFields(),
-        patch.IgnoreField("kind"),
-        patch.IgnoreField("apiVersion"),
-        patch.IgnoreField("metadata"),
-    )
-    if err != nil {
-        logger.Error(err, "Unable to patch MongoDB StatefulSet with comparison object")
-        return err
-    }
-
-    if !patchResult.IsEmpty() {
-        logger.Info("Changes in StatefulSet detected, updating...", "patch", string(patchResult.Patch))
-
-        if storedStateful.Annotations == nil {
-            storedStateful.Annotations = make(map[string]string)
-        }
-        if newStateful.Annotations == nil {
-            newStateful.Annotations = make(map[string]string)
-        }
-
-        for key, value := range storedStateful.Annotations {
-            if _, present := newStateful.Annotations[key]; !present {
-                newStateful.Annotations[key] = value
-            }
-        }
-
-        if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(newStateful); err != nil {
-            logger.Error(err, "Unable to patch MongoDB StatefulSet with comparison object")
-            return err
-        }
-        return updateStateFulSet(namespace, newStateful)
-    }
-
-    logger.Info("Reconciliation complete, no changes required.")
-    return nil
+	logger := logGenerator(params.StatefulSetMeta.Name, params.Namespace, "StatefulSet")
+
+	storedStateful, err := GetStateFulSet(params.Namespace, params.StatefulSetMeta.Name)
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Error retrieving existing StatefulSet")
+		return err
+	}
+
+	if storedStateful == nil {
+		logger.Info("StatefulSet does not exist, creating new one...")
+	}
+
+	if params.Replicas == nil {
+		logger.Info("Replicas is nil, defaulting to 1")
+		var defaultReplicas int32 = 1
+		params.Replicas = &defaultReplicas
+	}
+
+	if params.PVCParameters.StorageSize == "" {
+		logger.Error(fmt.Errorf("invalid PVCParameters"), "PVC storage size is missing")
+		params.PVCParameters = pvcParameters{
+			StorageSize: "1Gi", // Default value
+		}
+	}
+
+	statefulSetDef := generateStatefulSetDef(params)
+	if statefulSetDef == nil {
+		return fmt.Errorf("failed to generate StatefulSet definition")
+	}
+
+	if err != nil && errors.IsNotFound(err) {
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(statefulSetDef); err != nil {
+			logger.Error(err, "Unable to patch MongoDB StatefulSet with comparison object")
+			return err
+		}
+		return createStateFulSet(params.Namespace, statefulSetDef)
+	}
+
+	if storedStateful == nil {
+		return fmt.Errorf("storedStateful is nil, skipping patch")
+	}
+	// the data files are not converted between storage engines, the members would start empty
+	if storedEngine, newEngine := getStatefulSetStorageEngine(storedStateful), getStatefulSetStorageEngine(statefulSetDef); storedEngine != newEngine {
+		err := fmt.Errorf("storageEngine of StatefulSet %s cannot be changed from %s to %s, the data is not migrated between storage engines, create a new cluster and migrate the data instead", storedStateful.Name, storedEngine, newEngine)
+		logger.Error(err, "Unable to patch MongoDB StatefulSet with changed storage engine")
+		return err
+	}
+
+	return patchStateFulSet(storedStateful, statefulSetDef, params.Namespace, !isInMaintenanceWindow(params.MaintenanceWindow, time.Now()), params.RecreateOnImmutableChange)
 }
 
+// patchStateFulSet will patch Statefulset, changes are deferred while the maintenance window is closed
+func patchStateFulSet(storedStateful *appsv1.StatefulSet, newStateful *appsv1.StatefulSet, namespace string, deferUpdate bool, recreate bool) error {
+	logger := logGenerator(storedStateful.Name, namespace, "StatefulSet")
+
+	if storedStateful == nil || newStateful == nil {
+		return fmt.Errorf("storedStateful or newStateful is nil")
+	}
 
+	newStateful.ResourceVersion = storedStateful.ResourceVersion
+	newStateful.CreationTimestamp = storedStateful.CreationTimestamp
+	newStateful.ManagedFields = storedStateful.ManagedFields
+	if changes := getImmutableFieldChanges(storedStateful, newStateful); len(changes) > 0 {
+		if !recreate {
+			err := fmt.Errorf("immutable fields %s of StatefulSet %s cannot be changed, set kubernetesConfig.immutableFieldChangePolicy to Recreate to recreate the StatefulSet while keeping its pods", strings.Join(changes, ", "), storedStateful.Name)
+			logger.Error(err, "Unable to patch MongoDB StatefulSet with changed immutable fields")
+			return err
+		}
+		if deferUpdate {
+			logger.Info("Changes in immutable fields of StatefulSet detected, deferring until the maintenance window opens", "fields", changes)
+			return ErrUpdateDeferred
+		}
+		// the recreated StatefulSet adopts the claims of the pods as they are, only the claims created afterwards get the changed volumeClaimTemplates
+		mismatches, err := getVolumeClaimMismatches(storedStateful, newStateful, namespace)
+		if err != nil {
+			logger.Error(err, "Unable to check the claims of MongoDB StatefulSet")
+			return err
+		}
+		if err := recreateStateFulSet(namespace, newStateful); err != nil {
+			return err
+		}
+		if len(mismatches) > 0 {
+			err := fmt.Errorf("StatefulSet %s was recreated, but its PersistentVolumeClaims %s keep their storage class, access modes and volume mode, delete the claim and the pod of one member at a time so its claim is created from the changed volumeClaimTemplates and it resyncs from the replica set, a standalone has to be restored from a backup instead", storedStateful.Name, strings.Join(mismatches, ", "))
+			logger.Error(err, "MongoDB StatefulSet claims don't match the changed volumeClaimTemplates")
+			return err
+		}
+		return nil
+	}
+	// the volumeClaimTemplates are immutable, a changed storage size is applied on the claims of the pods instead
+	resizeDeferred := deferUpdate && len(getVolumeClaimTemplateResizes(storedStateful.Spec.VolumeClaimTemplates, newStateful.Spec.VolumeClaimTemplates)) > 0
+	if resizeDeferred {
+		logger.Info("Changes in the storage size of StatefulSet detected, deferring until the maintenance window opens")
+	} else if err := expandVolumeClaims(storedStateful, newStateful, namespace); err != nil {
+		logger.Error(err, "Unable to apply the storage size on the MongoDB StatefulSet claims")
+		return err
+	}
+	newStateful.Spec.VolumeClaimTemplates = storedStateful.Spec.VolumeClaimTemplates
+
+	patchResult, err := calculateStatefulSetPatch(storedStateful, newStateful)
+	if err != nil {
+		logger.Error(err, "Unable to patch MongoDB StatefulSet with comparison object")
+		return err
+	}
+
+	if !patchResult.IsEmpty() && deferUpdate {
+		return deferStatefulSetTemplateChanges(storedStateful, newStateful, namespace)
+	}
+	if resizeDeferred {
+		return ErrUpdateDeferred
+	}
+
+	if !patchResult.IsEmpty() {
+		logger.Info("Changes in StatefulSet detected, updating...", "patch", string(patchResult.Patch))
+
+		newStateful.Annotations = preserveExternalAnnotations(storedStateful.Annotations, newStateful.Annotations)
+
+		if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(newStateful); err != nil {
+			logger.Error(err, "Unable to patch MongoDB StatefulSet with comparison object")
+			return err
+		}
+		return updateStateFulSet(namespace, newStateful)
+	}
+
+	logger.Info("Reconciliation complete, no changes required.")
+	return nil
+}
+
+// calculateStatefulSetPatch is a method to calculate the patch between the stored and the generated StatefulSet
+func calculateStatefulSetPatch(storedStateful *appsv1.StatefulSet, newStateful *appsv1.StatefulSet) (*patch.PatchResult, error) {
+	return patch.DefaultPatchMaker.Calculate(storedStateful, newStateful,
+		patch.IgnoreStatusFields(),
+		patch.IgnoreVolumeClaimTemplateTypeMetaAndStatus(),
+		patch.IgnorePersistenVolumeFields(),
+		patch.IgnoreField("kind"),
+		patch.IgnoreField("apiVersion"),
+		patch.IgnoreField("metadata"),
+	)
+}
+
+// deferStatefulSetTemplateChanges is a method to apply the changes which don't roll the pods, like the replicas, while the maintenance window is closed, the pod template changes are deferred
+func deferStatefulSetTemplateChanges(storedStateful *appsv1.StatefulSet, newStateful *appsv1.StatefulSet, namespace string) error {
+	logger := logGenerator(storedStateful.Name, namespace, "StatefulSet")
+	partialStateful := getStatefulSetWithoutTemplateChanges(storedStateful, newStateful)
+	if !reflect.DeepEqual(partialStateful.Spec, storedStateful.Spec) {
+		logger.Info("Changes in StatefulSet outside of the pod template detected, updating...")
+		// the last applied annotation is kept, so the deferred pod template changes are still detected
+		if err := updateStateFulSet(namespace, partialStateful); err != nil {
+			return err
+		}
+	}
+	patchResult, err := calculateStatefulSetPatch(partialStateful, newStateful)
+	if err != nil {
+		logger.Error(err, "Unable to patch MongoDB StatefulSet with comparison object")
+		return err
+	}
+	if patchResult.IsEmpty() {
+		return nil
+	}
+	logger.Info("Changes in the pod template of StatefulSet detected, deferring until the maintenance window opens", "patch", string(patchResult.Patch))
+	return ErrUpdateDeferred
+}
+
+// getStatefulSetWithoutTemplateChanges is a method to apply the changes of the statefulset which don't roll the pods on the stored one
+func getStatefulSetWithoutTemplateChanges(storedStateful *appsv1.StatefulSet, newStateful *appsv1.StatefulSet) *appsv1.StatefulSet {
+	partialStateful := storedStateful.DeepCopy()
+	partialStateful.Spec.Replicas = newStateful.Spec.Replicas
+	partialStateful.Spec.MinReadySeconds = newStateful.Spec.MinReadySeconds
+	partialStateful.Spec.RevisionHistoryLimit = newStateful.Spec.RevisionHistoryLimit
+	partialStateful.Spec.UpdateStrategy = newStateful.Spec.UpdateStrategy
+	return partialStateful
+}
 
 // createStateFulSet is a method to create statefulset in Kubernetes
 func createStateFulSet(namespace string, stateful *appsv1.StatefulSet) error {
 	logger := logGenerator(stateful.Name, namespace, "StatefulSet")
-	_, err := generateK8sClient().AppsV1().StatefulSets(namespace).Create(context.TODO(), stateful, metav1.CreateOptions{})
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	_, err = client.AppsV1().StatefulSets(namespace).Create(context.TODO(), stateful, metav1.CreateOptions{})
 	if err != nil {
 		logger.Error(err, "MongoDB Statefulset creation failed")
 		return err
@@ -162,7 +279,12 @@ func createStateFulSet(namespace string, stateful *appsv1.StatefulSet) error {
 // updateStateFulSet is a method to update statefulset in Kubernetes
 func updateStateFulSet(namespace string, stateful *appsv1.StatefulSet) error {
 	logger := logGenerator(stateful.Name, namespace, "StatefulSet")
-	_, err := generateK8sClient().AppsV1().StatefulSets(namespace).Update(context.TODO(), stateful, metav1.UpdateOptions{})
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	_, err = client.AppsV1().StatefulSets(namespace).Update(context.TODO(), stateful, metav1.UpdateOptions{})
 	if err != nil {
 		logger.Error(err, "MongoDB Statefulset update failed")
 		return err
@@ -174,7 +296,12 @@ func updateStateFulSet(namespace string, stateful *appsv1.StatefulSet) error {
 // GetStateFulSet is a method to get statefulset in Kubernetes
 func GetStateFulSet(namespace string, stateful string) (*appsv1.StatefulSet, error) {
 	logger := logGenerator(stateful, namespace, "StatefulSet")
-	statefulInfo, err := generateK8sClient().AppsV1().StatefulSets(namespace).Get(context.TODO(), stateful, metav1.GetOptions{})
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return nil, err
+	}
+	statefulInfo, err := client.AppsV1().StatefulSets(namespace).Get(context.TODO(), stateful, metav1.GetOptions{})
 	if err != nil {
 		logger.Info("MongoDB Statefulset get action failed")
 		return nil, err
@@ -183,143 +310,494 @@ func GetStateFulSet(namespace string, stateful string) (*appsv1.StatefulSet, err
 	return statefulInfo, err
 }
 
+// deleteStateFulSet is a method to delete statefulset in Kubernetes
+func deleteStateFulSet(namespace string, stateful string) error {
+	logger := logGenerator(stateful, namespace, "StatefulSet")
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	err = client.AppsV1().StatefulSets(namespace).Delete(context.TODO(), stateful, metav1.DeleteOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		logger.Error(err, "MongoDB Statefulset deletion is failed")
+		return err
+	}
+	logger.Info("MongoDB Statefulset deletion is successful")
+	return nil
+}
+
 // generateStatefulSetDef is a method to generate statefulset definition
 
 func generateStatefulSetDef(params statefulSetParameters) *appsv1.StatefulSet {
-    if params.StatefulSetMeta.Name == "" || params.Namespace == "" {
-        log.Error(fmt.Errorf("invalid parameters"), "StatefulSet name or namespace is empty")
-        return nil
-    }
-
-    log.Info("Generating StatefulSet", "Name", params.StatefulSetMeta.Name, "Namespace", params.Namespace)
-
-    // **âœ… Fix: Ensure required pointer fields are initialized**
-    if params.Replicas == nil {
-        log.Info("Replicas is nil, setting default to 1")
-        var defaultReplicas int32 = 1
-        params.Replicas = &defaultReplicas
-    }
-
-    if params.PVCParameters.StorageSize == "" {
-        log.Error(fmt.Errorf("invalid PVCParameters"), "PVC storage size is missing")
-    }
-
-    if params.SecurityContext == nil {
-        log.Info("SecurityContext is nil, setting default")
-        params.SecurityContext = &corev1.PodSecurityContext{}
-    }
-
-    if params.Affinity == nil {
-        log.Info("Affinity is nil, setting default")
-        params.Affinity = &corev1.Affinity{}
-    }
-
-    if params.Tolerations == nil {
-        log.Info("Tolerations is nil, initializing empty list")
-        params.Tolerations = &[]corev1.Toleration{}
-    }
-
-    // **âœ… Fix: Ensure All Maps Are Initialized**
-    if params.Labels == nil {
-        log.Info("Labels map is nil, initializing empty map")
-        params.Labels = make(map[string]string)
-    }
-
-    if params.Annotations == nil {
-        log.Info("Annotations map is nil, initializing empty map")
-        params.Annotations = make(map[string]string)
-    }
-
-    if params.NodeSelector == nil {
-        log.Info("NodeSelector is nil, initializing empty map")
-        params.NodeSelector = make(map[string]string)
-    }
-
-    if params.PVCParameters.Labels == nil {
-        log.Info("PVCParameters Labels is nil, initializing empty map")
-        params.PVCParameters.Labels = make(map[string]string)
-    }
-
-    if params.PVCParameters.Annotations == nil {
-        log.Info("PVCParameters Annotations is nil, initializing empty map")
-        params.PVCParameters.Annotations = make(map[string]string)
-    }
-
-    if params.ExtraVolumes == nil {
-        log.Info("ExtraVolumes is nil, initializing empty list")
-        params.ExtraVolumes = &[]corev1.Volume{}
-    }
-
-    // **âœ… Fix: Ensure StatefulSetMeta is Not Nil**
-    if params.StatefulSetMeta.Labels == nil {
-        log.Info("StatefulSetMeta Labels is nil, initializing empty map")
-        params.StatefulSetMeta.Labels = make(map[string]string)
-    }
-
-    if params.StatefulSetMeta.Annotations == nil {
-        log.Info("StatefulSetMeta Annotations is nil, initializing empty map")
-        params.StatefulSetMeta.Annotations = make(map[string]string)
-    }
-
-    statefulset := &appsv1.StatefulSet{
-        TypeMeta: generateMetaInformation("StatefulSet", "apps/v1"),
-        ObjectMeta: params.StatefulSetMeta,
-        Spec: appsv1.StatefulSetSpec{
-            Selector:    LabelSelectors(params.Labels),
-            ServiceName: params.StatefulSetMeta.Name,
-            Replicas:    params.Replicas,
-            Template: corev1.PodTemplateSpec{
-                ObjectMeta: metav1.ObjectMeta{
-                    Labels:      params.Labels,
-                    Annotations: params.Annotations,
-                },
-                Spec: corev1.PodSpec{
-                    Containers:        generateContainerDef(params.StatefulSetMeta.Name, params.ContainerParams),
-                    NodeSelector:      params.NodeSelector,
-                    Affinity:          params.Affinity,
-                    PriorityClassName: params.PriorityClassName,
-                    SecurityContext:   params.SecurityContext,
-                },
-            },
-        },
-    }
-
-    if params.ContainerParams.PersistenceEnabled != nil && *params.ContainerParams.PersistenceEnabled {
-        if params.PVCParameters.StorageSize != "" {
-            statefulset.Spec.VolumeClaimTemplates = append(statefulset.Spec.VolumeClaimTemplates, generatePersistentVolumeTemplate(params.PVCParameters))
-        }
-    }
-
-    if params.AdditionalConfig != nil {
-        statefulset.Spec.Template.Spec.Volumes = getAdditionalConfig(params)
-    }
-
-    if params.ImagePullSecret != nil {
-        statefulset.Spec.Template.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: *params.ImagePullSecret}}
-    }
-
-    AddOwnerRefToObject(statefulset, params.OwnerDef)
-
-    return statefulset
+	if params.StatefulSetMeta.Name == "" || params.Namespace == "" {
+		log.Error(fmt.Errorf("invalid parameters"), "StatefulSet name or namespace is empty")
+		return nil
+	}
+
+	log.Info("Generating StatefulSet", "Name", params.StatefulSetMeta.Name, "Namespace", params.Namespace)
+
+	// **âœ… Fix: Ensure required pointer fields are initialized**
+	if params.Replicas == nil {
+		log.Info("Replicas is nil, setting default to 1")
+		var defaultReplicas int32 = 1
+		params.Replicas = &defaultReplicas
+	}
+
+	if params.PVCParameters.StorageSize == "" {
+		log.Error(fmt.Errorf("invalid PVCParameters"), "PVC storage size is missing")
+	}
+
+	if params.SecurityContext == nil {
+		log.Info("SecurityContext is nil, setting default")
+		params.SecurityContext = getDefaultPodSecurityContext()
+	}
+	if params.SecurityContext.FSGroup != nil && params.SecurityContext.FSGroupChangePolicy == nil {
+		params.SecurityContext = params.SecurityContext.DeepCopy()
+		params.SecurityContext.FSGroupChangePolicy = getFSGroupChangePolicy(params.FSGroupChangePolicy)
+	}
+
+	if params.Affinity == nil {
+		log.Info("Affinity is nil, setting default")
+		params.Affinity = &corev1.Affinity{}
+	}
+
+	if params.Tolerations == nil {
+		log.Info("Tolerations is nil, initializing empty list")
+		params.Tolerations = &[]corev1.Toleration{}
+	}
+
+	// **âœ… Fix: Ensure All Maps Are Initialized**
+	if params.Labels == nil {
+		log.Info("Labels map is nil, initializing empty map")
+		params.Labels = make(map[string]string)
+	}
+
+	if params.Annotations == nil {
+		log.Info("Annotations map is nil, initializing empty map")
+		params.Annotations = make(map[string]string)
+	}
+
+	if params.NodeSelector == nil {
+		log.Info("NodeSelector is nil, initializing empty map")
+		params.NodeSelector = make(map[string]string)
+	}
+
+	if params.PVCParameters.Labels == nil {
+		log.Info("PVCParameters Labels is nil, initializing empty map")
+		params.PVCParameters.Labels = make(map[string]string)
+	}
+
+	if params.PVCParameters.Annotations == nil {
+		log.Info("PVCParameters Annotations is nil, initializing empty map")
+		params.PVCParameters.Annotations = make(map[string]string)
+	}
+
+	if params.ExtraVolumes == nil {
+		log.Info("ExtraVolumes is nil, initializing empty list")
+		params.ExtraVolumes = &[]corev1.Volume{}
+	}
+
+	// **âœ… Fix: Ensure StatefulSetMeta is Not Nil**
+	if params.StatefulSetMeta.Labels == nil {
+		log.Info("StatefulSetMeta Labels is nil, initializing empty map")
+		params.StatefulSetMeta.Labels = make(map[string]string)
+	}
+
+	if params.StatefulSetMeta.Annotations == nil {
+		log.Info("StatefulSetMeta Annotations is nil, initializing empty map")
+		params.StatefulSetMeta.Annotations = make(map[string]string)
+	}
+
+	statefulset := &appsv1.StatefulSet{
+		TypeMeta:   generateMetaInformation("StatefulSet", "apps/v1"),
+		ObjectMeta: params.StatefulSetMeta,
+		Spec: appsv1.StatefulSetSpec{
+			Selector:             LabelSelectors(params.Labels),
+			ServiceName:          params.StatefulSetMeta.Name,
+			Replicas:             params.Replicas,
+			RevisionHistoryLimit: getInt32OrDefault(params.RevisionHistoryLimit, defaultRevisionHistoryLimit),
+			MinReadySeconds:      params.MinReadySeconds,
+			UpdateStrategy:       appsv1.StatefulSetUpdateStrategy{Type: params.UpdateStrategy},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      params.Labels,
+					Annotations: params.Annotations,
+				},
+				Spec: corev1.PodSpec{
+					Containers:                    generateContainerDef(params.StatefulSetMeta.Name, params.ContainerParams),
+					NodeSelector:                  params.NodeSelector,
+					Affinity:                      params.Affinity,
+					PriorityClassName:             params.PriorityClassName,
+					SchedulerName:                 params.SchedulerName,
+					RuntimeClassName:              params.RuntimeClassName,
+					ServiceAccountName:            params.ServiceAccountName,
+					AutomountServiceAccountToken:  params.AutomountServiceAccountToken,
+					SecurityContext:               params.SecurityContext,
+					DNSPolicy:                     getDNSPolicy(params.DNSPolicy, params.HostNetwork),
+					DNSConfig:                     params.DNSConfig,
+					HostNetwork:                   params.HostNetwork,
+					HostAliases:                   params.HostAliases,
+					TerminationGracePeriodSeconds: params.TerminationGracePeriodSeconds,
+				},
+			},
+		},
+	}
+
+	if params.ContainerParams.PersistenceEnabled != nil && *params.ContainerParams.PersistenceEnabled {
+		if params.PVCParameters.StorageSize != "" {
+			statefulset.Spec.VolumeClaimTemplates = append(statefulset.Spec.VolumeClaimTemplates, generatePersistentVolumeTemplate(params.PVCParameters))
+		}
+	}
+
+	if len(statefulset.Spec.VolumeClaimTemplates) == 0 {
+		statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name:         params.StatefulSetMeta.Name,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	}
+
+	// the audit log is kept on its own claim, also when the data directory isn't persisted
+	if params.AuditPVCParameters != nil {
+		statefulset.Spec.VolumeClaimTemplates = append(statefulset.Spec.VolumeClaimTemplates, generatePersistentVolumeTemplate(*params.AuditPVCParameters))
+	}
+
+	statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes, *params.ExtraVolumes...)
+
+	if params.AdditionalConfig != nil {
+		statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes, getAdditionalConfig(params)...)
+	}
+
+	if params.ContainerParams.ReadinessScript != nil {
+		statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes, getReadinessScriptVolume(params.ContainerParams.ReadinessScript))
+	}
+
+	if params.ProjectedVolume != nil {
+		statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name:         projectedVolumeName,
+			VolumeSource: corev1.VolumeSource{Projected: params.ProjectedVolume},
+		})
+	}
+
+	if params.ScratchVolume != nil {
+		statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name:         scratchVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: params.ScratchVolume},
+		})
+	}
+
+	if isLogFileEnabled(params.ContainerParams.Logging) && params.ScratchVolume == nil {
+		statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name:         logVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	}
+
+	if params.VolumePermissionsImage != "" {
+		statefulset.Spec.Template.Spec.InitContainers = append(statefulset.Spec.Template.Spec.InitContainers, generateVolumePermissionsInitContainer(params.StatefulSetMeta.Name, params))
+	}
+
+	if params.ContainerParams.KeyFileSecret != "" {
+		statefulset.Spec.Template.Spec.InitContainers = append(statefulset.Spec.Template.Spec.InitContainers, generateKeyFileInitContainer(params.InitImage))
+		statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes, getKeyFileVolumes(params.ContainerParams.KeyFileSecret)...)
+	}
+
+	if params.RestoreParams != nil {
+		statefulset.Spec.Template.Spec.InitContainers = append(statefulset.Spec.Template.Spec.InitContainers, generateRestoreInitContainers(params.StatefulSetMeta.Name, params)...)
+		statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes, getRestoreVolume())
+		if params.RestoreParams.CABundle != nil {
+			statefulset.Spec.Template.Spec.Volumes = append(statefulset.Spec.Template.Spec.Volumes, getRestoreCABundleVolume(params.RestoreParams.CABundle))
+		}
+	}
+
+	for _, imagePullSecret := range params.ImagePullSecrets {
+		statefulset.Spec.Template.Spec.ImagePullSecrets = append(statefulset.Spec.Template.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: imagePullSecret})
+	}
+
+	AddOwnerRefToObject(statefulset, params.OwnerDef)
+
+	return statefulset
+}
+
+// getImmutableFieldChanges is a method to list the changed fields of the StatefulSet which the API server rejects on update
+func getImmutableFieldChanges(storedStateful *appsv1.StatefulSet, newStateful *appsv1.StatefulSet) []string {
+	var changes []string
+	if storedStateful.Spec.ServiceName != newStateful.Spec.ServiceName {
+		changes = append(changes, "serviceName")
+	}
+	if !reflect.DeepEqual(storedStateful.Spec.Selector, newStateful.Spec.Selector) {
+		changes = append(changes, "selector")
+	}
+	if getPodManagementPolicy(storedStateful.Spec.PodManagementPolicy) != getPodManagementPolicy(newStateful.Spec.PodManagementPolicy) {
+		changes = append(changes, "podManagementPolicy")
+	}
+	return append(changes, getVolumeClaimTemplateChanges(storedStateful.Spec.VolumeClaimTemplates, newStateful.Spec.VolumeClaimTemplates)...)
+}
+
+// getVolumeClaimTemplateChanges is a method to list the added and removed claim templates and the templates whose storage class, access modes or volume mode changed
+func getVolumeClaimTemplateChanges(storedTemplates []corev1.PersistentVolumeClaim, newTemplates []corev1.PersistentVolumeClaim) []string {
+	var changes []string
+	for _, newTemplate := range newTemplates {
+		storedTemplate := getVolumeClaimTemplate(storedTemplates, newTemplate.Name)
+		if storedTemplate == nil {
+			changes = append(changes, fmt.Sprintf("volumeClaimTemplates (%s added)", newTemplate.Name))
+			continue
+		}
+		if getStorageClassName(storedTemplate.Spec.StorageClassName) != getStorageClassName(newTemplate.Spec.StorageClassName) ||
+			!reflect.DeepEqual(getAccessModes(storedTemplate.Spec.AccessModes), getAccessModes(newTemplate.Spec.AccessModes)) ||
+			isBlockVolumeMode(storedTemplate.Spec.VolumeMode) != isBlockVolumeMode(newTemplate.Spec.VolumeMode) {
+			changes = append(changes, fmt.Sprintf("volumeClaimTemplates (%s changed)", newTemplate.Name))
+		}
+	}
+	for _, storedTemplate := range storedTemplates {
+		if getVolumeClaimTemplate(newTemplates, storedTemplate.Name) == nil {
+			changes = append(changes, fmt.Sprintf("volumeClaimTemplates (%s removed)", storedTemplate.Name))
+		}
+	}
+	return changes
+}
+
+// getVolumeClaimTemplate is a method to find the claim template by its name, it is nil when there is none
+func getVolumeClaimTemplate(templates []corev1.PersistentVolumeClaim, name string) *corev1.PersistentVolumeClaim {
+	for index := range templates {
+		if templates[index].Name == name {
+			return &templates[index]
+		}
+	}
+	return nil
 }
 
+// getStatefulSetStorageEngine is a method to get the storage engine mongod runs with from the arguments of the StatefulSet containers, it defaults to wiredTiger
+func getStatefulSetStorageEngine(stateful *appsv1.StatefulSet) string {
+	for _, container := range stateful.Spec.Template.Spec.Containers {
+		for index, arg := range container.Args {
+			if engine, found := strings.CutPrefix(arg, "--storageEngine="); found {
+				return engine
+			}
+			if arg == "--storageEngine" && index+1 < len(container.Args) {
+				return container.Args[index+1]
+			}
+		}
+	}
+	return storageEngineWiredTiger
+}
+
+// getPodManagementPolicy is a method to get the pod management policy of StatefulSet, it defaults to OrderedReady like the API server does
+func getPodManagementPolicy(policy appsv1.PodManagementPolicyType) appsv1.PodManagementPolicyType {
+	if policy == "" {
+		return appsv1.OrderedReadyPodManagement
+	}
+	return policy
+}
+
+// recreateStateFulSet is a method to delete the StatefulSet with its pods orphaned and to create it again, the pods are adopted if they match the new selector
+func recreateStateFulSet(namespace string, stateful *appsv1.StatefulSet) error {
+	logger := logGenerator(stateful.Name, namespace, "StatefulSet")
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	propagation := metav1.DeletePropagationOrphan
+	err = client.AppsV1().StatefulSets(namespace).Delete(context.TODO(), stateful.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "MongoDB Statefulset orphan deletion failed")
+		return err
+	}
+	err = wait.PollImmediate(time.Second, statefulSetDeletionTimeout, func() (bool, error) {
+		_, err := client.AppsV1().StatefulSets(namespace).Get(context.TODO(), stateful.Name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		logger.Error(err, "MongoDB Statefulset was not deleted")
+		return err
+	}
+	logger.Info("MongoDB Statefulset deleted with its pods orphaned, recreating...")
+	stateful.ResourceVersion = ""
+	stateful.ManagedFields = nil
+	if err := patch.DefaultAnnotator.SetLastAppliedAnnotation(stateful); err != nil {
+		logger.Error(err, "Unable to patch MongoDB StatefulSet with comparison object")
+		return err
+	}
+	return createStateFulSet(namespace, stateful)
+}
 
+// getStorageClassName is a method to get the storage class name, it is empty for the default storage class
+func getStorageClassName(storageClassName *string) string {
+	if storageClassName == nil {
+		return ""
+	}
+	return *storageClassName
+}
 
 // generatePersistentVolumeTemplate is a method to create the persistent volume claim template
 func generatePersistentVolumeTemplate(params pvcParameters) corev1.PersistentVolumeClaim {
 	return corev1.PersistentVolumeClaim{
-		TypeMeta:   generateMetaInformation("PersistentVolumeClaim", "v1"),
-		ObjectMeta: metav1.ObjectMeta{Name: params.Name},
+		TypeMeta: generateMetaInformation("PersistentVolumeClaim", "v1"),
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        params.Name,
+			Labels:      params.Labels,
+			Annotations: params.Annotations,
+		},
 		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes: params.AccessModes,
+			AccessModes: getAccessModes(params.AccessModes),
 			Resources: corev1.ResourceRequirements{
 				Requests: corev1.ResourceList{
 					corev1.ResourceName(corev1.ResourceStorage): resource.MustParse(params.StorageSize),
 				},
 			},
 			StorageClassName: params.StorageClassName,
+			VolumeMode:       params.VolumeMode,
+		},
+	}
+}
+
+// getAccessModes is a method to get the access modes of the data volume, it defaults to ReadWriteOnce
+func getAccessModes(accessModes []corev1.PersistentVolumeAccessMode) []corev1.PersistentVolumeAccessMode {
+	if len(accessModes) == 0 {
+		return []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}
+	}
+	return accessModes
+}
+
+// validateAccessModes is a method to validate that the access modes of the data volume let mongod write to it
+func validateAccessModes(storage *opstreelabsinv1alpha1.Storage) error {
+	if storage == nil {
+		return nil
+	}
+	for _, accessMode := range storage.AccessModes {
+		switch accessMode {
+		case corev1.ReadWriteOnce, corev1.ReadWriteOncePod, corev1.ReadWriteMany:
+		case corev1.ReadOnlyMany:
+			return fmt.Errorf("storage accessMode %s cannot be used, mongod must write to the data volume", accessMode)
+		default:
+			return fmt.Errorf("storage accessMode %s is not supported", accessMode)
+		}
+	}
+	return nil
+}
+
+// isBlockVolumeMode is a method to check if the data volume is attached as a raw block device
+func isBlockVolumeMode(volumeMode *corev1.PersistentVolumeMode) bool {
+	return volumeMode != nil && *volumeMode == corev1.PersistentVolumeBlock
+}
+
+// validateVolumeMode is a method to reject a raw block data volume, mongod can only store its data on a mounted filesystem and a container cannot format and mount the device without privileges
+func validateVolumeMode(storage *opstreelabsinv1alpha1.Storage) error {
+	if storage == nil || !isBlockVolumeMode(storage.VolumeMode) {
+		return nil
+	}
+	return fmt.Errorf("storage volumeMode Block is not supported, mongod requires the data volume mounted as a filesystem")
+}
+
+// getInitImage is a method to get the image of the utility init containers, it can be set to a mirrored image for air-gapped clusters
+func getInitImage(image string) string {
+	if image == "" {
+		return defaultInitImage
+	}
+	return image
+}
+
+// generateVolumePermissionsInitContainer is a method to generate the init container which hands the data volume over to the mongod user, for volumes ignoring the fsGroup
+func generateVolumePermissionsInitContainer(name string, params statefulSetParameters) corev1.Container {
+	user, group := mongoDBUserID, mongoDBUserID
+	if params.SecurityContext != nil && params.SecurityContext.RunAsUser != nil {
+		user = *params.SecurityContext.RunAsUser
+	}
+	if params.SecurityContext != nil && params.SecurityContext.RunAsGroup != nil {
+		group = *params.SecurityContext.RunAsGroup
+	} else if params.SecurityContext != nil && params.SecurityContext.FSGroup != nil {
+		group = *params.SecurityContext.FSGroup
+	}
+	dbPath := getDBPath(params.ContainerParams.DBPath)
+	rootUser := int64(0)
+	runAsNonRoot := false
+	return corev1.Container{
+		Name:            volumePermissionsContainerName,
+		Image:           params.VolumePermissionsImage,
+		ImagePullPolicy: getImagePullPolicy(params.VolumePermissionsImage, ""),
+		// only the files with another owner are changed, so restarts don't walk through the whole volume again
+		Command: []string{"/bin/sh", "-c", fmt.Sprintf("find %[1]s \\( ! -user %[2]d -o ! -group %[3]d \\) -exec chown %[2]d:%[3]d {} +", dbPath, user, group)},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      name,
+				MountPath: dbPath,
+			},
 		},
+		// changing the owner of files requires root, which the pod security context doesn't grant
+		SecurityContext: &corev1.SecurityContext{
+			RunAsUser:    &rootUser,
+			RunAsNonRoot: &runAsNonRoot,
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{"ALL"},
+				Add:  []corev1.Capability{"CHOWN", "DAC_OVERRIDE"},
+			},
+		},
+	}
+}
+
+// getDNSPolicy is a method to get the pod DNS policy, it defaults to ClusterFirst or ClusterFirstWithHostNet for host network pods
+func getDNSPolicy(dnsPolicy corev1.DNSPolicy, hostNetwork bool) corev1.DNSPolicy {
+	if dnsPolicy != "" {
+		return dnsPolicy
+	}
+	if hostNetwork {
+		return corev1.DNSClusterFirstWithHostNet
+	}
+	return corev1.DNSClusterFirst
+}
+
+// getPodSecurityContext is a method to get the pod security context of MongoDB pods with the sysctls and the seccomp profile of kubernetesConfig, the default security context is used when none is set
+func getPodSecurityContext(kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig) *corev1.PodSecurityContext {
+	securityContext := getDefaultPodSecurityContext()
+	if kubernetesConfig.SecurityContext != nil {
+		securityContext = kubernetesConfig.SecurityContext.DeepCopy()
+	}
+	securityContext.Sysctls = append(securityContext.Sysctls, kubernetesConfig.Sysctls...)
+	securityContext.SeccompProfile = getSeccompProfile(kubernetesConfig)
+	return securityContext
+}
+
+// getDefaultPodSecurityContext will return the default pod security context for the mongodb user
+func getDefaultPodSecurityContext() *corev1.PodSecurityContext {
+	runAsNonRoot := true
+	mongoDBUser := mongoDBUserID
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot: &runAsNonRoot,
+		RunAsUser:    &mongoDBUser,
+		RunAsGroup:   &mongoDBUser,
+		FSGroup:      &mongoDBUser,
+	}
+}
+
+// getFSGroupChangePolicy will return the fsGroup change policy of the mongodb pod, by default only a volume with mismatching root ownership is changed
+func getFSGroupChangePolicy(policy *corev1.PodFSGroupChangePolicy) *corev1.PodFSGroupChangePolicy {
+	if policy != nil {
+		return policy
+	}
+	onRootMismatch := corev1.FSGroupChangeOnRootMismatch
+	return &onRootMismatch
+}
+
+// getImagePullSecrets will return the image pull secrets, combining the single secret form for backward compatibility
+func getImagePullSecrets(imagePullSecret *string, imagePullSecrets []string) []string {
+	var secrets []string
+	if imagePullSecret != nil {
+		secrets = append(secrets, *imagePullSecret)
+	}
+	for _, secret := range imagePullSecrets {
+		if imagePullSecret != nil && secret == *imagePullSecret {
+			continue
+		}
+		secrets = append(secrets, secret)
 	}
+	return secrets
 }
 
 // getAdditionalConfig will return the MongoDB additional configuration
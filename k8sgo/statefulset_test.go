@@ -0,0 +1,482 @@
+package k8sgo
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestGetVolumeClaimTemplateChanges(t *testing.T) {
+	storageClass := "fast"
+	data := generatePersistentVolumeTemplate(pvcParameters{Name: "mongodb-cluster", StorageSize: "1Gi"})
+	audit := generatePersistentVolumeTemplate(pvcParameters{Name: auditVolumeName, StorageSize: "1Gi"})
+	tests := []struct {
+		name            string
+		storedTemplates []corev1.PersistentVolumeClaim
+		newTemplates    []corev1.PersistentVolumeClaim
+		want            []string
+	}{
+		{
+			name:            "unchanged templates",
+			storedTemplates: []corev1.PersistentVolumeClaim{data, audit},
+			newTemplates:    []corev1.PersistentVolumeClaim{data, audit},
+		},
+		{
+			name:            "storage size is not immutable",
+			storedTemplates: []corev1.PersistentVolumeClaim{data},
+			newTemplates:    []corev1.PersistentVolumeClaim{generatePersistentVolumeTemplate(pvcParameters{Name: "mongodb-cluster", StorageSize: "2Gi"})},
+		},
+		{
+			name:            "audit volume added",
+			storedTemplates: []corev1.PersistentVolumeClaim{data},
+			newTemplates:    []corev1.PersistentVolumeClaim{data, audit},
+			want:            []string{"volumeClaimTemplates (audit added)"},
+		},
+		{
+			name:            "audit volume removed",
+			storedTemplates: []corev1.PersistentVolumeClaim{data, audit},
+			newTemplates:    []corev1.PersistentVolumeClaim{data},
+			want:            []string{"volumeClaimTemplates (audit removed)"},
+		},
+		{
+			name:            "storage class changed",
+			storedTemplates: []corev1.PersistentVolumeClaim{data},
+			newTemplates:    []corev1.PersistentVolumeClaim{generatePersistentVolumeTemplate(pvcParameters{Name: "mongodb-cluster", StorageSize: "1Gi", StorageClassName: &storageClass})},
+			want:            []string{"volumeClaimTemplates (mongodb-cluster changed)"},
+		},
+		{
+			name:            "storage class unset",
+			storedTemplates: []corev1.PersistentVolumeClaim{generatePersistentVolumeTemplate(pvcParameters{Name: "mongodb-cluster", StorageSize: "1Gi", StorageClassName: &storageClass})},
+			newTemplates:    []corev1.PersistentVolumeClaim{data},
+			want:            []string{"volumeClaimTemplates (mongodb-cluster changed)"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getVolumeClaimTemplateChanges(test.storedTemplates, test.newTemplates); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getVolumeClaimTemplateChanges() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetStatefulSetReplicaSetName(t *testing.T) {
+	tests := []struct {
+		name       string
+		containers []corev1.Container
+		want       string
+	}{
+		{
+			name:       "replica set name of the mongo container",
+			containers: []corev1.Container{{Name: "mongo", Env: []corev1.EnvVar{{Name: "MONGO_MODE", Value: "cluster"}, {Name: "MONGO_REPL", Value: "rs0"}}}},
+			want:       "rs0",
+		},
+		{
+			name:       "no replica set name",
+			containers: []corev1.Container{{Name: "mongo", Env: []corev1.EnvVar{{Name: "MONGO_MODE", Value: "standalone"}}}},
+			want:       "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stateful := &appsv1.StatefulSet{}
+			stateful.Spec.Template.Spec.Containers = tt.containers
+			if got := getStatefulSetReplicaSetName(stateful); got != tt.want {
+				t.Errorf("getStatefulSetReplicaSetName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetStatefulSetWithoutTemplateChanges(t *testing.T) {
+	storedStateful := newTestStatefulSet()
+	newStateful := storedStateful.DeepCopy()
+	newStateful.Spec.Replicas = int32Ptr(5)
+	newStateful.Spec.MinReadySeconds = 30
+	newStateful.Spec.Template.Spec.Containers[0].Image = "mongo:6.0"
+	partialStateful := getStatefulSetWithoutTemplateChanges(storedStateful, newStateful)
+	if *partialStateful.Spec.Replicas != 5 || partialStateful.Spec.MinReadySeconds != 30 {
+		t.Errorf("getStatefulSetWithoutTemplateChanges() replicas = %d, minReadySeconds = %d, want 5 and 30", *partialStateful.Spec.Replicas, partialStateful.Spec.MinReadySeconds)
+	}
+	if !reflect.DeepEqual(partialStateful.Spec.Template, storedStateful.Spec.Template) {
+		t.Errorf("getStatefulSetWithoutTemplateChanges() pod template = %v, want the stored one", partialStateful.Spec.Template)
+	}
+	if *storedStateful.Spec.Replicas != 3 {
+		t.Errorf("getStatefulSetWithoutTemplateChanges() changed the stored StatefulSet")
+	}
+}
+
+func newTestStatefulSet() *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "mongodb-cluster", Namespace: "database"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: int32Ptr(3),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "mongod", Image: "mongo:5.0"}}},
+			},
+		},
+	}
+}
+
+func TestGetPodSecurityContext(t *testing.T) {
+	runAsUser := int64(1001)
+	tests := []struct {
+		name            string
+		securityContext *corev1.PodSecurityContext
+		wantUser        int64
+		wantNonRoot     bool
+		wantFSGroup     bool
+	}{
+		{name: "non-root default", wantUser: mongoDBUserID, wantNonRoot: true, wantFSGroup: true},
+		{name: "user security context takes precedence", securityContext: &corev1.PodSecurityContext{RunAsUser: &runAsUser}, wantUser: runAsUser},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			kubernetesConfig := opstreelabsinv1alpha1.KubernetesConfig{SecurityContext: test.securityContext, Sysctls: []corev1.Sysctl{{Name: "net.ipv4.tcp_keepalive_time", Value: "120"}}}
+			securityContext := getPodSecurityContext(kubernetesConfig)
+			if securityContext.RunAsUser == nil || *securityContext.RunAsUser != test.wantUser {
+				t.Errorf("getPodSecurityContext() runAsUser = %v, want %d", securityContext.RunAsUser, test.wantUser)
+			}
+			if nonRoot := securityContext.RunAsNonRoot != nil && *securityContext.RunAsNonRoot; nonRoot != test.wantNonRoot {
+				t.Errorf("getPodSecurityContext() runAsNonRoot = %v, want %v", nonRoot, test.wantNonRoot)
+			}
+			if fsGroup := securityContext.FSGroup != nil; fsGroup != test.wantFSGroup {
+				t.Errorf("getPodSecurityContext() fsGroup set = %v, want %v", fsGroup, test.wantFSGroup)
+			}
+			if len(securityContext.Sysctls) != 1 || securityContext.SeccompProfile == nil {
+				t.Errorf("getPodSecurityContext() sysctls = %v, seccompProfile = %v, want the ones of kubernetesConfig", securityContext.Sysctls, securityContext.SeccompProfile)
+			}
+			if test.securityContext != nil && len(test.securityContext.Sysctls) != 0 {
+				t.Errorf("getPodSecurityContext() changed the security context of kubernetesConfig")
+			}
+		})
+	}
+}
+
+func TestGetDefaultPodSecurityContext(t *testing.T) {
+	securityContext := getDefaultPodSecurityContext()
+	if securityContext.RunAsNonRoot == nil || !*securityContext.RunAsNonRoot {
+		t.Errorf("getDefaultPodSecurityContext() runAsNonRoot = %v, want true", securityContext.RunAsNonRoot)
+	}
+	for field, value := range map[string]*int64{"runAsUser": securityContext.RunAsUser, "runAsGroup": securityContext.RunAsGroup, "fsGroup": securityContext.FSGroup} {
+		if value == nil || *value != mongoDBUserID {
+			t.Errorf("getDefaultPodSecurityContext() %s = %v, want %d", field, value, mongoDBUserID)
+		}
+	}
+}
+
+func TestGetFSGroupChangePolicy(t *testing.T) {
+	always := corev1.FSGroupChangeAlways
+	runAsUser := int64(1001)
+	tests := []struct {
+		name                string
+		securityContext     *corev1.PodSecurityContext
+		fsGroupChangePolicy *corev1.PodFSGroupChangePolicy
+		want                *corev1.PodFSGroupChangePolicy
+	}{
+		{name: "on root mismatch by default", want: fsGroupChangePolicyPtr(corev1.FSGroupChangeOnRootMismatch)},
+		{name: "explicit always", fsGroupChangePolicy: &always, want: &always},
+		{name: "explicit always in the security context", securityContext: &corev1.PodSecurityContext{FSGroup: &runAsUser, FSGroupChangePolicy: &always}, want: &always},
+		{name: "no fsGroup", securityContext: &corev1.PodSecurityContext{RunAsUser: &runAsUser}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statefulSet := generateStatefulSetDef(statefulSetParameters{
+				StatefulSetMeta:     metav1.ObjectMeta{Name: "mongodb-cluster"},
+				Namespace:           "database",
+				SecurityContext:     test.securityContext,
+				FSGroupChangePolicy: test.fsGroupChangePolicy,
+				PVCParameters:       pvcParameters{StorageSize: "1Gi"},
+			})
+			got := statefulSet.Spec.Template.Spec.SecurityContext.FSGroupChangePolicy
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("generateStatefulSetDef() fsGroupChangePolicy = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func fsGroupChangePolicyPtr(policy corev1.PodFSGroupChangePolicy) *corev1.PodFSGroupChangePolicy {
+	return &policy
+}
+
+func TestGetImagePullSecrets(t *testing.T) {
+	registry := "registry-credentials"
+	tests := []struct {
+		name             string
+		imagePullSecret  *string
+		imagePullSecrets []string
+		want             []string
+	}{
+		{name: "none"},
+		{name: "single secret", imagePullSecret: &registry, want: []string{"registry-credentials"}},
+		{name: "multiple secrets", imagePullSecrets: []string{"registry-credentials", "mirror-credentials"}, want: []string{"registry-credentials", "mirror-credentials"}},
+		{name: "both forms", imagePullSecret: &registry, imagePullSecrets: []string{"mirror-credentials"}, want: []string{"registry-credentials", "mirror-credentials"}},
+		{name: "duplicate secret", imagePullSecret: &registry, imagePullSecrets: []string{"mirror-credentials", "registry-credentials"}, want: []string{"registry-credentials", "mirror-credentials"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if secrets := getImagePullSecrets(test.imagePullSecret, test.imagePullSecrets); !reflect.DeepEqual(secrets, test.want) {
+				t.Errorf("getImagePullSecrets() = %v, want %v", secrets, test.want)
+			}
+		})
+	}
+}
+
+func TestGenerateStatefulSetDefRevisionHistoryLimit(t *testing.T) {
+	tests := []struct {
+		name                 string
+		revisionHistoryLimit *int32
+		want                 int32
+	}{
+		{name: "default revision history limit", want: defaultRevisionHistoryLimit},
+		{name: "custom revision history limit", revisionHistoryLimit: int32Ptr(2), want: 2},
+		{name: "no revision history", revisionHistoryLimit: int32Ptr(0), want: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statefulSet := generateStatefulSetDef(statefulSetParameters{
+				StatefulSetMeta:      metav1.ObjectMeta{Name: "mongodb-cluster"},
+				Namespace:            "database",
+				RevisionHistoryLimit: test.revisionHistoryLimit,
+				PVCParameters:        pvcParameters{StorageSize: "1Gi"},
+			})
+			if got := *statefulSet.Spec.RevisionHistoryLimit; got != test.want {
+				t.Errorf("generateStatefulSetDef() revisionHistoryLimit = %d, want %d", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGenerateStatefulSetDefMinReadySeconds(t *testing.T) {
+	tests := []struct {
+		name            string
+		minReadySeconds int32
+	}{
+		{name: "ready immediately"},
+		{name: "custom min ready seconds", minReadySeconds: 30},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statefulSet := generateStatefulSetDef(statefulSetParameters{
+				StatefulSetMeta: metav1.ObjectMeta{Name: "mongodb-cluster"},
+				Namespace:       "database",
+				MinReadySeconds: test.minReadySeconds,
+				PVCParameters:   pvcParameters{StorageSize: "1Gi"},
+			})
+			if got := statefulSet.Spec.MinReadySeconds; got != test.minReadySeconds {
+				t.Errorf("generateStatefulSetDef() minReadySeconds = %d, want %d", got, test.minReadySeconds)
+			}
+		})
+	}
+}
+
+func TestGetStorageClassName(t *testing.T) {
+	fast := "fast"
+	empty := ""
+	tests := []struct {
+		name             string
+		storageClassName *string
+		want             string
+	}{
+		{name: "default storage class"},
+		{name: "empty storage class", storageClassName: &empty},
+		{name: "custom storage class", storageClassName: &fast, want: "fast"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getStorageClassName(test.storageClassName); got != test.want {
+				t.Errorf("getStorageClassName() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetDNSPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		dnsPolicy   corev1.DNSPolicy
+		hostNetwork bool
+		want        corev1.DNSPolicy
+	}{
+		{name: "cluster first by default", want: corev1.DNSClusterFirst},
+		{name: "explicit dns policy", dnsPolicy: corev1.DNSNone, want: corev1.DNSNone},
+		{name: "cluster first with host net for host network pods", hostNetwork: true, want: corev1.DNSClusterFirstWithHostNet},
+		{name: "explicit dns policy for host network pods", dnsPolicy: corev1.DNSDefault, hostNetwork: true, want: corev1.DNSDefault},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getDNSPolicy(test.dnsPolicy, test.hostNetwork); got != test.want {
+				t.Errorf("getDNSPolicy() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGenerateStatefulSetDefDNSConfig(t *testing.T) {
+	ndots := "2"
+	dnsConfig := &corev1.PodDNSConfig{Nameservers: []string{"10.0.0.10"}, Options: []corev1.PodDNSConfigOption{{Name: "ndots", Value: &ndots}}}
+	statefulSet := generateStatefulSetDef(statefulSetParameters{
+		StatefulSetMeta: metav1.ObjectMeta{Name: "mongodb-cluster"},
+		Namespace:       "database",
+		DNSPolicy:       corev1.DNSNone,
+		DNSConfig:       dnsConfig,
+		PVCParameters:   pvcParameters{StorageSize: "1Gi"},
+	})
+	podSpec := statefulSet.Spec.Template.Spec
+	if podSpec.DNSPolicy != corev1.DNSNone || !reflect.DeepEqual(podSpec.DNSConfig, dnsConfig) {
+		t.Errorf("generateStatefulSetDef() dnsPolicy = %s, dnsConfig = %v, want %s and %v", podSpec.DNSPolicy, podSpec.DNSConfig, corev1.DNSNone, dnsConfig)
+	}
+}
+
+func TestGenerateStatefulSetDefDataVolume(t *testing.T) {
+	trueProperty := true
+	falseProperty := false
+	tests := []struct {
+		name               string
+		persistenceEnabled *bool
+		wantClaimTemplate  bool
+	}{
+		{name: "persistence not set"},
+		{name: "persistence disabled", persistenceEnabled: &falseProperty},
+		{name: "persistence enabled", persistenceEnabled: &trueProperty, wantClaimTemplate: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statefulSet := generateStatefulSetDef(statefulSetParameters{
+				StatefulSetMeta: metav1.ObjectMeta{Name: "mongodb-cluster"},
+				Namespace:       "database",
+				ContainerParams: containerParameters{Image: "mongo:4.4", PersistenceEnabled: test.persistenceEnabled},
+				PVCParameters:   pvcParameters{Name: "mongodb-cluster", StorageSize: "1Gi"},
+			})
+			hasClaimTemplate := getVolumeClaimTemplate(statefulSet.Spec.VolumeClaimTemplates, "mongodb-cluster") != nil
+			var emptyDir bool
+			for _, volume := range statefulSet.Spec.Template.Spec.Volumes {
+				if volume.Name == "mongodb-cluster" && volume.EmptyDir != nil {
+					emptyDir = true
+				}
+			}
+			if hasClaimTemplate != test.wantClaimTemplate || emptyDir == test.wantClaimTemplate {
+				t.Errorf("generateStatefulSetDef() claim template = %v, emptyDir = %v, want claim template %v", hasClaimTemplate, emptyDir, test.wantClaimTemplate)
+			}
+			volumeMounts := statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts
+			if len(volumeMounts) == 0 || volumeMounts[0].Name != "mongodb-cluster" || volumeMounts[0].MountPath != "/data/db" {
+				t.Errorf("generateStatefulSetDef() volume mounts = %v, want the data directory mounted", volumeMounts)
+			}
+		})
+	}
+}
+
+func TestGetAccessModes(t *testing.T) {
+	tests := []struct {
+		name        string
+		accessModes []corev1.PersistentVolumeAccessMode
+		want        []corev1.PersistentVolumeAccessMode
+	}{
+		{name: "read write once by default", want: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+		{name: "explicit access modes", accessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod}, want: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getAccessModes(test.accessModes); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getAccessModes() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidateAccessModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage *opstreelabsinv1alpha1.Storage
+		wantErr bool
+	}{
+		{name: "no storage"},
+		{name: "default access modes", storage: &opstreelabsinv1alpha1.Storage{}},
+		{name: "writable access modes", storage: &opstreelabsinv1alpha1.Storage{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce, corev1.ReadWriteOncePod, corev1.ReadWriteMany}}},
+		{name: "read only access mode", storage: &opstreelabsinv1alpha1.Storage{AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany}}, wantErr: true},
+		{name: "unknown access mode", storage: &opstreelabsinv1alpha1.Storage{AccessModes: []corev1.PersistentVolumeAccessMode{"ReadWriteAlways"}}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateAccessModes(test.storage); (err != nil) != test.wantErr {
+				t.Errorf("validateAccessModes() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateVolumeMode(t *testing.T) {
+	filesystem := corev1.PersistentVolumeFilesystem
+	block := corev1.PersistentVolumeBlock
+	tests := []struct {
+		name    string
+		storage *opstreelabsinv1alpha1.Storage
+		wantErr bool
+	}{
+		{name: "no storage"},
+		{name: "default volume mode", storage: &opstreelabsinv1alpha1.Storage{}},
+		{name: "filesystem volume mode", storage: &opstreelabsinv1alpha1.Storage{VolumeMode: &filesystem}},
+		{name: "block volume mode", storage: &opstreelabsinv1alpha1.Storage{VolumeMode: &block}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateVolumeMode(test.storage); (err != nil) != test.wantErr {
+				t.Errorf("validateVolumeMode() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetInitImage(t *testing.T) {
+	tests := []struct {
+		name  string
+		image string
+		want  string
+	}{
+		{name: "default init image", want: defaultInitImage},
+		{name: "mirrored init image", image: "registry.example.org/busybox:1.36", want: "registry.example.org/busybox:1.36"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getInitImage(test.image); got != test.want {
+				t.Errorf("getInitImage() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGenerateVolumePermissionsInitContainer(t *testing.T) {
+	user := int64(1001)
+	group := int64(2000)
+	tests := []struct {
+		name            string
+		securityContext *corev1.PodSecurityContext
+		dbPath          string
+		wantCommand     string
+	}{
+		{name: "default user", wantCommand: "find /data/db \\( ! -user 999 -o ! -group 999 \\) -exec chown 999:999 {} +"},
+		{name: "custom user and group", securityContext: &corev1.PodSecurityContext{RunAsUser: &user, RunAsGroup: &group}, wantCommand: "find /data/db \\( ! -user 1001 -o ! -group 2000 \\) -exec chown 1001:2000 {} +"},
+		{name: "fsGroup as group", securityContext: &corev1.PodSecurityContext{RunAsUser: &user, FSGroup: &group}, wantCommand: "find /data/db \\( ! -user 1001 -o ! -group 2000 \\) -exec chown 1001:2000 {} +"},
+		{name: "custom data directory", dbPath: "/var/lib/mongodb", wantCommand: "find /var/lib/mongodb \\( ! -user 999 -o ! -group 999 \\) -exec chown 999:999 {} +"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			container := generateVolumePermissionsInitContainer("mongodb-cluster", statefulSetParameters{
+				SecurityContext:        test.securityContext,
+				VolumePermissionsImage: defaultInitImage,
+				ContainerParams:        containerParameters{DBPath: test.dbPath},
+			})
+			if command := container.Command[len(container.Command)-1]; command != test.wantCommand {
+				t.Errorf("generateVolumePermissionsInitContainer() command = %s, want %s", command, test.wantCommand)
+			}
+			if *container.SecurityContext.RunAsUser != 0 || container.Image != defaultInitImage {
+				t.Errorf("generateVolumePermissionsInitContainer() doesn't run %s as root", defaultInitImage)
+			}
+		})
+	}
+}
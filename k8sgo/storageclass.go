@@ -0,0 +1,81 @@
+package k8sgo
+
+import (
+	"context"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+// defaultStorageClassAnnotation marks the storageclass used by the claims which don't set one
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// zoneTopologyKeys are the node labels spreading the pods across zones
+var zoneTopologyKeys = []string{corev1.LabelTopologyZone, corev1.LabelFailureDomainBetaZone}
+
+// CheckMongoClusterStorageBinding is a method to warn when the data volumes of mongodb cluster are bound immediately while its pods are spread across zones
+func CheckMongoClusterStorageBinding(cr *opstreelabsinv1alpha1.MongoDBCluster) string {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "StorageClass")
+	storage := getPersistentStorage(cr.Spec.MongoDBConfig, cr.Spec.Storage)
+	if storage == nil || !isZoneSpread(cr.Spec.KubernetesConfig.Affinity) {
+		return ""
+	}
+	storageClass, err := getStorageClass(storage.StorageClassName)
+	if err != nil {
+		// the binding mode is only a hint, so the reconciliation goes on without it
+		logger.Info("Unable to get the binding mode of the storageclass", "error", err.Error())
+		return ""
+	}
+	return getStorageBindingWarning(storageClass)
+}
+
+// getStorageClass is a method to get the storageclass of the data volumes, the default storageclass is used when none is set
+func getStorageClass(storageClassName *string) (*storagev1.StorageClass, error) {
+	client, err := generateK8sClient()
+	if err != nil {
+		return nil, err
+	}
+	if storageClassName != nil && *storageClassName != "" {
+		return client.StorageV1().StorageClasses().Get(context.TODO(), *storageClassName, metav1.GetOptions{})
+	}
+	storageClasses, err := client.StorageV1().StorageClasses().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for index := range storageClasses.Items {
+		if storageClasses.Items[index].Annotations[defaultStorageClassAnnotation] == "true" {
+			return &storageClasses.Items[index], nil
+		}
+	}
+	return nil, fmt.Errorf("no default storageclass found")
+}
+
+// getStorageBindingWarning is a method to generate the warning for a storageclass binding its volumes before the pod is scheduled, Immediate is the default binding mode
+func getStorageBindingWarning(storageClass *storagev1.StorageClass) string {
+	if storageClass.VolumeBindingMode != nil && *storageClass.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer {
+		return ""
+	}
+	return fmt.Sprintf("StorageClass %s binds volumes Immediately while the pods are spread across zones, a volume provisioned in another zone than the one a pod is allowed in leaves the pod Pending, use a StorageClass with volumeBindingMode WaitForFirstConsumer", storageClass.Name)
+}
+
+// isZoneSpread is a method to check if the pod anti-affinity spreads the MongoDB pods across zones
+func isZoneSpread(affinity *corev1.Affinity) bool {
+	if affinity == nil || affinity.PodAntiAffinity == nil {
+		return false
+	}
+	var terms []corev1.PodAffinityTerm
+	terms = append(terms, affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution...)
+	for _, term := range affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+		terms = append(terms, term.PodAffinityTerm)
+	}
+	for _, term := range terms {
+		for _, key := range zoneTopologyKeys {
+			if term.TopologyKey == key {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package k8sgo
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetStorageBindingWarning(t *testing.T) {
+	immediate := storagev1.VolumeBindingImmediate
+	waitForFirstConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+	tests := []struct {
+		name        string
+		bindingMode *storagev1.VolumeBindingMode
+		wantWarning bool
+	}{
+		{name: "immediate by default", wantWarning: true},
+		{name: "immediate", bindingMode: &immediate, wantWarning: true},
+		{name: "wait for first consumer", bindingMode: &waitForFirstConsumer},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			storageClass := &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}, VolumeBindingMode: test.bindingMode}
+			if warning := getStorageBindingWarning(storageClass); (warning != "") != test.wantWarning {
+				t.Errorf("getStorageBindingWarning() = %q, wantWarning %v", warning, test.wantWarning)
+			}
+		})
+	}
+}
+
+func TestIsZoneSpread(t *testing.T) {
+	tests := []struct {
+		name     string
+		affinity *corev1.Affinity
+		want     bool
+	}{
+		{name: "no affinity"},
+		{name: "node affinity", affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{}}},
+		{
+			name:     "spread across hosts",
+			affinity: &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{TopologyKey: corev1.LabelHostname}}}},
+		},
+		{
+			name:     "required spread across zones",
+			affinity: &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{TopologyKey: corev1.LabelTopologyZone}}}},
+			want:     true,
+		},
+		{
+			name:     "preferred spread across zones",
+			affinity: &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{Weight: 100, PodAffinityTerm: corev1.PodAffinityTerm{TopologyKey: corev1.LabelTopologyZone}}}}},
+			want:     true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := isZoneSpread(test.affinity); got != test.want {
+				t.Errorf("isZoneSpread() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package k8sgo
+
+import (
+	"fmt"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"sort"
+	"strings"
+)
+
+// allowedSysctls are the namespaced sysctls the MongoDB pods can set, all but the safe ones have to be allowed on the kubelet with --allowed-unsafe-sysctls
+var allowedSysctls = map[string]bool{
+	"kernel.shm_rmid_forced":              true,
+	"net.ipv4.ip_local_port_range":        true,
+	"net.ipv4.ip_unprivileged_port_start": true,
+	"net.ipv4.ping_group_range":           true,
+	"net.ipv4.tcp_syncookies":             true,
+	"net.core.somaxconn":                  true,
+	"net.ipv4.tcp_max_syn_backlog":        true,
+	"net.ipv4.tcp_keepalive_time":         true,
+	"net.ipv4.tcp_keepalive_intvl":        true,
+	"net.ipv4.tcp_keepalive_probes":       true,
+	"net.ipv4.tcp_fin_timeout":            true,
+	"net.ipv4.tcp_tw_reuse":               true,
+}
+
+// validateSysctls is a method to validate that the sysctls of MongoDB pods are set once and that the ones of kubernetesConfig are allowed, the sysctls of the pod security context are passed through as before
+func validateSysctls(kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig) error {
+	for _, sysctl := range kubernetesConfig.Sysctls {
+		if !allowedSysctls[sysctl.Name] {
+			return fmt.Errorf("sysctl %s is not allowed, the allowed sysctls are %s", sysctl.Name, strings.Join(getAllowedSysctlNames(), ", "))
+		}
+		if kubernetesConfig.HostNetwork && strings.HasPrefix(sysctl.Name, "net.") {
+			return fmt.Errorf("sysctl %s cannot be set with hostNetwork", sysctl.Name)
+		}
+	}
+	names := map[string]bool{}
+	for _, sysctl := range getPodSecurityContext(kubernetesConfig).Sysctls {
+		if names[sysctl.Name] {
+			return fmt.Errorf("sysctl %s is set more than once", sysctl.Name)
+		}
+		names[sysctl.Name] = true
+	}
+	return nil
+}
+
+// getAllowedSysctlNames is a method to get the sorted names of the allowed sysctls
+func getAllowedSysctlNames() []string {
+	var names []string
+	for name := range allowedSysctls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,54 @@
+package k8sgo
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestValidateSysctls(t *testing.T) {
+	tests := []struct {
+		name             string
+		kubernetesConfig opstreelabsinv1alpha1.KubernetesConfig
+		wantErr          bool
+	}{
+		{
+			name:             "allowed sysctl",
+			kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{Sysctls: []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}}},
+			wantErr:          false,
+		},
+		{
+			name:             "sysctl outside of the allowlist",
+			kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{Sysctls: []corev1.Sysctl{{Name: "kernel.msgmax", Value: "65536"}}},
+			wantErr:          true,
+		},
+		{
+			name: "security context sysctl outside of the allowlist",
+			kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{
+				SecurityContext: &corev1.PodSecurityContext{Sysctls: []corev1.Sysctl{{Name: "kernel.msgmax", Value: "65536"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "sysctl set in both",
+			kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{
+				SecurityContext: &corev1.PodSecurityContext{Sysctls: []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "512"}}},
+				Sysctls:         []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:             "network sysctl with hostNetwork",
+			kubernetesConfig: opstreelabsinv1alpha1.KubernetesConfig{HostNetwork: true, Sysctls: []corev1.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}}},
+			wantErr:          true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSysctls(tt.kubernetesConfig); (err != nil) != tt.wantErr {
+				t.Errorf("validateSysctls() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
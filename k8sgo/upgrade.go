@@ -0,0 +1,216 @@
+package k8sgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/mongo"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRolloutInProgress is returned while the pods of mongodb cluster are replaced one at a time with the latest revision
+var ErrRolloutInProgress = errors.New("rollout of MongoDB cluster members is in progress")
+
+// IsRolloutInProgress is a method to check if the error is caused by a rollout which is not completed yet
+func IsRolloutInProgress(err error) bool {
+	return errors.Is(err, ErrRolloutInProgress)
+}
+
+// isStatefulSetRolledOut is a method to check if every pod of the statefulset runs the latest revision and is ready
+func isStatefulSetRolledOut(statefulSet *appsv1.StatefulSet) bool {
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	// the current revision is never promoted with the OnDelete strategy, so only the updated replicas are compared
+	return statefulSet.Status.ObservedGeneration >= statefulSet.Generation &&
+		statefulSet.Status.UpdatedReplicas == replicas &&
+		statefulSet.Status.ReadyReplicas == replicas
+}
+
+// RollMongoDBClusterPods is a method to replace the outdated pods of mongodb cluster one at a time, secondaries first and the primary last after it stepped down
+func RollMongoDBClusterPods(cr *opstreelabsinv1alpha1.MongoDBCluster) error {
+	logger := logGenerator(cr.ObjectMeta.Name, cr.Namespace, "StatefulSet")
+	appName := fmt.Sprintf("%s-%s", cr.ObjectMeta.Name, "cluster")
+	statefulSet, err := GetStateFulSet(cr.Namespace, appName)
+	if err != nil {
+		return err
+	}
+	if isStatefulSetRolledOut(statefulSet) {
+		return nil
+	}
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	pods, err := client.CoreV1().Pods(cr.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("app=%s,role=cluster", appName),
+	})
+	if err != nil {
+		logger.Error(err, "Unable to list the pods of MongoDB cluster")
+		return err
+	}
+	var outdated, failing []string
+	for _, pod := range pods.Items {
+		isOutdated := pod.Labels[appsv1.ControllerRevisionHashLabelKey] != statefulSet.Status.UpdateRevision
+		if pod.DeletionTimestamp != nil {
+			return ErrRolloutInProgress
+		}
+		if !isPodReady(pod) && isOutdated {
+			// the update may be the fix of a failing member, it is replaced first once the other members are healthy
+			failing = append(failing, pod.Name)
+			continue
+		}
+		// a member is only replaced once the previous one is back and ready
+		if !isPodReady(pod) {
+			logger.Info("Waiting for MongoDB member to rejoin before the next one is updated", "pod", pod.Name)
+			return ErrRolloutInProgress
+		}
+		if isOutdated {
+			outdated = append(outdated, pod.Name)
+		}
+	}
+	if len(outdated) == 0 && len(failing) == 0 {
+		return ErrRolloutInProgress
+	}
+	if !isInMaintenanceWindow(cr.Spec.MaintenanceWindow, time.Now()) {
+		return ErrUpdateDeferred
+	}
+	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return err
+	}
+	mongoParams := mongogo.MongoDBParameters{
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		Port:      getMongoDBPort(cr.Spec.MongoDBConfig),
+		SetupType: "cluster",
+	}
+	mongoParams.MongoURL = getMongoDBClusterURL(cr, mongoParams, password)
+	states, err := mongogo.GetMongoClusterMemberStates(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to get the member states of MongoDB cluster")
+		return err
+	}
+	memberStates := getPodMemberStates(states)
+	if len(failing) > 0 {
+		sort.Strings(failing)
+		// replacing a failing member doesn't reduce the availability as long as every other member is healthy
+		if member, healthy := isReplicaSetHealthy(getOtherMemberStates(memberStates, failing[0])); !healthy {
+			logger.Info("Waiting for MongoDB member to recover before the failing one is updated", "member", member, "pod", failing[0])
+			return ErrRolloutInProgress
+		}
+		return deleteMongoDBClusterPod(cr.Namespace, failing[0])
+	}
+	if member, healthy := isReplicaSetHealthy(memberStates); !healthy {
+		logger.Info("Waiting for MongoDB member to recover before the next one is updated", "member", member)
+		return ErrRolloutInProgress
+	}
+	next := getNextPodToUpdate(outdated, memberStates)
+	if memberStates[next] == "PRIMARY" {
+		// the pod is deleted on a later reconciliation once another member has been elected
+		logger.Info("Stepping down the primary of MongoDB cluster before it is updated", "pod", next)
+		if err := mongogo.StepDownMongoPrimary(mongoParams, stepDownCatchUpSeconds+electionTimeoutSeconds, stepDownCatchUpSeconds); err != nil {
+			logger.Error(err, "Unable to step down the primary of MongoDB cluster")
+			return err
+		}
+		return ErrRolloutInProgress
+	}
+	return deleteMongoDBClusterPod(cr.Namespace, next)
+}
+
+// deleteMongoDBClusterPod is a method to delete the outdated pod of mongodb cluster so the statefulset recreates it with the latest revision
+func deleteMongoDBClusterPod(namespace string, name string) error {
+	logger := logGenerator(name, namespace, "Pod")
+	client, err := generateK8sClient()
+	if err != nil {
+		logger.Error(err, "Unable to create Kubernetes client")
+		return err
+	}
+	err = client.CoreV1().Pods(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		logger.Error(err, "Unable to delete the outdated pod of MongoDB cluster")
+		return err
+	}
+	logger.Info("Deleted the outdated pod of MongoDB cluster")
+	return ErrRolloutInProgress
+}
+
+// getPodMemberStates is a method to key the replica set member states by the pod name of the member
+func getPodMemberStates(states map[string]string) map[string]string {
+	memberStates := map[string]string{}
+	for host, state := range states {
+		memberStates[strings.Split(host, ".")[0]] = state
+	}
+	return memberStates
+}
+
+// getOtherMemberStates is a method to get the member states without the one of the given pod
+func getOtherMemberStates(memberStates map[string]string, pod string) map[string]string {
+	otherStates := map[string]string{}
+	for member, state := range memberStates {
+		if member != pod {
+			otherStates[member] = state
+		}
+	}
+	return otherStates
+}
+
+// isReplicaSetHealthy is a method to check that every replica set member is primary, secondary or arbiter, the first unhealthy member is returned otherwise
+func isReplicaSetHealthy(memberStates map[string]string) (string, bool) {
+	var members []string
+	for member := range memberStates {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	for _, member := range members {
+		switch memberStates[member] {
+		case "PRIMARY", "SECONDARY", "ARBITER":
+		default:
+			return member, false
+		}
+	}
+	return "", true
+}
+
+// getNextPodToUpdate is a method to pick the outdated pod to update next, the secondaries go first from the highest ordinal and the primary goes last
+func getNextPodToUpdate(outdated []string, memberStates map[string]string) string {
+	pods := append([]string{}, outdated...)
+	sort.Slice(pods, func(i, j int) bool {
+		iPrimary, jPrimary := memberStates[pods[i]] == "PRIMARY", memberStates[pods[j]] == "PRIMARY"
+		if iPrimary != jPrimary {
+			return jPrimary
+		}
+		return getPodOrdinal(pods[i]) > getPodOrdinal(pods[j])
+	})
+	return pods[0]
+}
+
+// getPodOrdinal is a method to get the ordinal of statefulset pod from its name
+func getPodOrdinal(name string) int {
+	ordinal, err := strconv.Atoi(name[strings.LastIndex(name, "-")+1:])
+	if err != nil {
+		return -1
+	}
+	return ordinal
+}
+
+// isPodReady is a method to check if the Ready condition of the pod is true
+func isPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package k8sgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetNextPodToUpdate(t *testing.T) {
+	memberStates := map[string]string{"mongodb-cluster-0": "PRIMARY", "mongodb-cluster-1": "SECONDARY", "mongodb-cluster-2": "SECONDARY"}
+	tests := []struct {
+		name     string
+		outdated []string
+		want     string
+	}{
+		{name: "secondaries from the highest ordinal", outdated: []string{"mongodb-cluster-0", "mongodb-cluster-1", "mongodb-cluster-2"}, want: "mongodb-cluster-2"},
+		{name: "primary last", outdated: []string{"mongodb-cluster-0"}, want: "mongodb-cluster-0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if next := getNextPodToUpdate(test.outdated, memberStates); next != test.want {
+				t.Errorf("getNextPodToUpdate() = %s, want %s", next, test.want)
+			}
+		})
+	}
+}
+
+func TestIsReplicaSetHealthyWithoutFailingMember(t *testing.T) {
+	tests := []struct {
+		name         string
+		memberStates map[string]string
+		want         bool
+	}{
+		{name: "other members healthy", memberStates: map[string]string{"mongodb-cluster-0": "PRIMARY", "mongodb-cluster-1": "SECONDARY", "mongodb-cluster-2": "RECOVERING"}, want: true},
+		{name: "another member recovering", memberStates: map[string]string{"mongodb-cluster-0": "PRIMARY", "mongodb-cluster-1": "RECOVERING", "mongodb-cluster-2": "(not reachable/healthy)"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, healthy := isReplicaSetHealthy(getOtherMemberStates(test.memberStates, "mongodb-cluster-2")); healthy != test.want {
+				t.Errorf("isReplicaSetHealthy() = %v, want %v", healthy, test.want)
+			}
+		})
+	}
+}
+
+func TestGetNextPodToUpdateOrdering(t *testing.T) {
+	tests := []struct {
+		name         string
+		outdated     []string
+		memberStates map[string]string
+		want         string
+	}{
+		{name: "primary on the highest ordinal", outdated: []string{"mongodb-cluster-0", "mongodb-cluster-1", "mongodb-cluster-2"}, memberStates: map[string]string{"mongodb-cluster-0": "SECONDARY", "mongodb-cluster-1": "SECONDARY", "mongodb-cluster-2": "PRIMARY"}, want: "mongodb-cluster-1"},
+		{name: "ordinals beyond nine", outdated: []string{"mongodb-cluster-2", "mongodb-cluster-10", "mongodb-cluster-9"}, memberStates: map[string]string{"mongodb-cluster-0": "PRIMARY"}, want: "mongodb-cluster-10"},
+		{name: "only updated secondaries left", outdated: []string{"mongodb-cluster-1"}, memberStates: map[string]string{"mongodb-cluster-0": "PRIMARY", "mongodb-cluster-1": "SECONDARY"}, want: "mongodb-cluster-1"},
+		{name: "member missing in the replica set status", outdated: []string{"mongodb-cluster-0", "mongodb-cluster-3"}, memberStates: map[string]string{"mongodb-cluster-0": "PRIMARY"}, want: "mongodb-cluster-3"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if next := getNextPodToUpdate(test.outdated, test.memberStates); next != test.want {
+				t.Errorf("getNextPodToUpdate() = %s, want %s", next, test.want)
+			}
+		})
+	}
+}
+
+func TestGetPodMemberStates(t *testing.T) {
+	states := map[string]string{
+		"mongodb-cluster-0.mongodb-cluster.database.svc.cluster.local:27017": "PRIMARY",
+		"mongodb-cluster-1.mongodb-cluster.database.svc.cluster.local:27017": "SECONDARY",
+		"mongodb-arbiter-0.mongodb-arbiter.database:27017":                   "ARBITER",
+	}
+	want := map[string]string{"mongodb-cluster-0": "PRIMARY", "mongodb-cluster-1": "SECONDARY", "mongodb-arbiter-0": "ARBITER"}
+	if memberStates := getPodMemberStates(states); !reflect.DeepEqual(memberStates, want) {
+		t.Errorf("getPodMemberStates() = %v, want %v", memberStates, want)
+	}
+}
+
+func TestIsReplicaSetHealthy(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       string
+		wantHealthy bool
+	}{
+		{name: "primary", state: "PRIMARY", wantHealthy: true},
+		{name: "secondary", state: "SECONDARY", wantHealthy: true},
+		{name: "arbiter", state: "ARBITER", wantHealthy: true},
+		{name: "initial sync", state: "STARTUP2"},
+		{name: "recovering", state: "RECOVERING"},
+		{name: "rollback", state: "ROLLBACK"},
+		{name: "down", state: "DOWN"},
+		{name: "not reachable", state: "(not reachable/healthy)"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			memberStates := map[string]string{"mongodb-cluster-0": "PRIMARY", "mongodb-cluster-1": test.state, "mongodb-cluster-2": "SECONDARY"}
+			member, healthy := isReplicaSetHealthy(memberStates)
+			if healthy != test.wantHealthy {
+				t.Errorf("isReplicaSetHealthy() = %v, want %v", healthy, test.wantHealthy)
+			}
+			if !healthy && member != "mongodb-cluster-1" {
+				t.Errorf("isReplicaSetHealthy() member = %s, want mongodb-cluster-1", member)
+			}
+		})
+	}
+}
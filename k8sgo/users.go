@@ -0,0 +1,368 @@
+package k8sgo
+
+import (
+	"context"
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"mongodb-operator/mongo"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	// defaultUserDatabase is the authentication database of the users and roles which don't set one
+	defaultUserDatabase = "admin"
+	// defaultUserPasswordKey is the key of the password in the secret of an application user
+	defaultUserPasswordKey = "password"
+)
+
+// ReconcileMongoStandaloneUsers is a method to create, update and drop the application users and custom roles of mongodb standalone, it returns the users and custom roles managed by the operator
+func ReconcileMongoStandaloneUsers(cr *opstreelabsinv1alpha1.MongoDB) ([]string, []string, error) {
+	if !hasMongoDBUsers(cr.Spec.MongoDBSecurity, cr.Status.ManagedUsers, cr.Status.ManagedRoles) {
+		return nil, nil, nil
+	}
+	serviceName := fmt.Sprintf("%s-%s.%s", cr.ObjectMeta.Name, "standalone", cr.Namespace)
+	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	mongoParams := mongogo.MongoDBParameters{
+		MongoURL:  fmt.Sprintf("mongodb://%s:%s@%s:%d/", cr.Spec.MongoDBSecurity.MongoDBAdminUser, password, serviceName, getMongoDBPort(cr.Spec.MongoDBConfig)),
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		Port:      getMongoDBPort(cr.Spec.MongoDBConfig),
+		SetupType: "standalone",
+	}
+	return reconcileMongoDBUsers(cr.Spec.MongoDBSecurity, cr.Status.ManagedRoles, mongoParams)
+}
+
+// ReconcileMongoClusterUsers is a method to create, update and drop the application users and custom roles of mongodb cluster, it returns the users and custom roles managed by the operator
+func ReconcileMongoClusterUsers(cr *opstreelabsinv1alpha1.MongoDBCluster) ([]string, []string, error) {
+	if !hasMongoDBUsers(cr.Spec.MongoDBSecurity, cr.Status.ManagedUsers, cr.Status.ManagedRoles) {
+		return nil, nil, nil
+	}
+	passwordParams := secretsParameters{Name: cr.ObjectMeta.Name, Namespace: cr.Namespace, SecretName: *cr.Spec.MongoDBSecurity.SecretRef.Name, SecretKey: *cr.Spec.MongoDBSecurity.SecretRef.Key}
+	password, err := getMongoDBPassword(passwordParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	mongoParams := mongogo.MongoDBParameters{
+		Namespace: cr.Namespace,
+		Name:      cr.ObjectMeta.Name,
+		Port:      getMongoDBPort(cr.Spec.MongoDBConfig),
+		SetupType: "cluster",
+	}
+	mongoParams.MongoURL = getMongoDBClusterURL(cr, mongoParams, password)
+	return reconcileMongoDBUsers(cr.Spec.MongoDBSecurity, cr.Status.ManagedRoles, mongoParams)
+}
+
+// hasMongoDBUsers is a method to check if any user or custom role is declared or still managed by the operator, so the users of MongoDB are only listed when there is something to reconcile
+func hasMongoDBUsers(security *opstreelabsinv1alpha1.MongoDBSecurity, managedUsers []string, managedRoles []string) bool {
+	if len(managedUsers) > 0 || len(managedRoles) > 0 {
+		return true
+	}
+	return security != nil && (len(security.Users) > 0 || len(security.Roles) > 0)
+}
+
+// reconcileMongoDBUsers is a method to apply the custom roles and then the users, the managed users and custom roles which are not declared anymore are dropped
+func reconcileMongoDBUsers(security *opstreelabsinv1alpha1.MongoDBSecurity, managedRoles []string, mongoParams mongogo.MongoDBParameters) ([]string, []string, error) {
+	logger := logGenerator(mongoParams.Name, mongoParams.Namespace, "MongoDB Users")
+	declaredRoles, err := reconcileMongoDBRoles(security.Roles, mongoParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	storedUsers, err := mongogo.GetMongoUsers(mongoParams)
+	if err != nil {
+		logger.Error(err, "Unable to list the users of MongoDB")
+		return nil, nil, err
+	}
+	stored := map[string]mongogo.MongoDBUserInfo{}
+	for _, user := range storedUsers {
+		stored[fmt.Sprintf("%s.%s", user.Database, user.Name)] = user
+	}
+	declared := map[string]bool{}
+	for _, userConfig := range security.Users {
+		user, err := getMongoDBUser(mongoParams.Namespace, userConfig)
+		if err != nil {
+			logger.Error(err, "Unable to get the password of MongoDB user", "user", userConfig.Name)
+			return nil, nil, err
+		}
+		key := fmt.Sprintf("%s.%s", user.Database, user.Name)
+		declared[key] = true
+		storedUser, found := stored[key]
+		if !found {
+			if err := mongogo.CreateMongoUser(mongoParams, user); err != nil {
+				logger.Error(err, "Unable to create MongoDB user", "user", key)
+				return nil, nil, err
+			}
+			logger.Info("Successfully created MongoDB user", "user", key)
+			continue
+		}
+		if !mongogo.IsMongoUserManaged(storedUser) {
+			err := fmt.Errorf("user %s already exists and is not managed by the operator", key)
+			logger.Error(err, "MongoDB user cannot be updated")
+			return nil, nil, err
+		}
+		passwordChanged := mongogo.GetMongoUserPasswordVersion(storedUser) != user.PasswordVersion
+		if !passwordChanged && rolesEqual(storedUser.Roles, user.Roles) {
+			continue
+		}
+		// roles granted or revoked outside of the operator are reverted as well
+		if err := mongogo.UpdateMongoUser(mongoParams, user, passwordChanged); err != nil {
+			logger.Error(err, "Unable to update MongoDB user", "user", key)
+			return nil, nil, err
+		}
+		logger.Info("Successfully updated MongoDB user", "user", key, "passwordChanged", passwordChanged)
+	}
+	for key, storedUser := range stored {
+		if declared[key] || !mongogo.IsMongoUserManaged(storedUser) {
+			continue
+		}
+		if err := mongogo.DropMongoUser(mongoParams, storedUser.Name, storedUser.Database); err != nil {
+			logger.Error(err, "Unable to drop MongoDB user", "user", key)
+			return nil, nil, err
+		}
+		logger.Info("Successfully dropped MongoDB user", "user", key)
+	}
+	// the users are updated first, so a dropped role is not granted by the declared users anymore
+	if err := dropStaleMongoDBRoles(declaredRoles, managedRoles, mongoParams); err != nil {
+		return nil, nil, err
+	}
+	var users []string
+	for key := range declared {
+		users = append(users, key)
+	}
+	sort.Strings(users)
+	return users, declaredRoles, nil
+}
+
+// reconcileMongoDBRoles is a method to create the custom roles and to revert the changes made to them outside of the operator, it returns the declared roles
+func reconcileMongoDBRoles(roleConfigs []opstreelabsinv1alpha1.MongoDBCustomRole, mongoParams mongogo.MongoDBParameters) ([]string, error) {
+	if len(roleConfigs) == 0 {
+		return nil, nil
+	}
+	logger := logGenerator(mongoParams.Name, mongoParams.Namespace, "MongoDB Roles")
+	var databases []string
+	seen := map[string]bool{}
+	for _, roleConfig := range roleConfigs {
+		if database := getUserDatabase(roleConfig.Database); !seen[database] {
+			seen[database] = true
+			databases = append(databases, database)
+		}
+	}
+	storedRoles, err := mongogo.GetMongoRoles(mongoParams, databases)
+	if err != nil {
+		logger.Error(err, "Unable to list the roles of MongoDB")
+		return nil, err
+	}
+	stored := map[string]mongogo.MongoDBCustomRole{}
+	for _, role := range storedRoles {
+		stored[fmt.Sprintf("%s.%s", role.Database, role.Name)] = role
+	}
+	var declared []string
+	for _, roleConfig := range roleConfigs {
+		role := getMongoDBCustomRole(roleConfig)
+		key := fmt.Sprintf("%s.%s", role.Database, role.Name)
+		declared = append(declared, key)
+		storedRole, found := stored[key]
+		if !found {
+			if err := mongogo.CreateMongoRole(mongoParams, role); err != nil {
+				logger.Error(err, "Unable to create MongoDB role", "role", key)
+				return nil, err
+			}
+			logger.Info("Successfully created MongoDB role", "role", key)
+			continue
+		}
+		if customRoleEqual(storedRole, role) {
+			continue
+		}
+		if err := mongogo.UpdateMongoRole(mongoParams, role); err != nil {
+			logger.Error(err, "Unable to update MongoDB role", "role", key)
+			return nil, err
+		}
+		logger.Info("Successfully updated MongoDB role", "role", key)
+	}
+	sort.Strings(declared)
+	return declared, nil
+}
+
+// dropStaleMongoDBRoles is a method to drop the custom roles created by the operator which are not declared anymore
+func dropStaleMongoDBRoles(declaredRoles []string, managedRoles []string, mongoParams mongogo.MongoDBParameters) error {
+	logger := logGenerator(mongoParams.Name, mongoParams.Namespace, "MongoDB Roles")
+	declared := map[string]bool{}
+	for _, key := range declaredRoles {
+		declared[key] = true
+	}
+	for _, key := range managedRoles {
+		if declared[key] {
+			continue
+		}
+		database, name, found := strings.Cut(key, ".")
+		if !found {
+			continue
+		}
+		if err := mongogo.DropMongoRole(mongoParams, name, database); err != nil {
+			logger.Error(err, "Unable to drop MongoDB role", "role", key)
+			return err
+		}
+		logger.Info("Successfully dropped MongoDB role", "role", key)
+	}
+	return nil
+}
+
+// getMongoDBUser is a method to generate the user of the user management commands, the password version is the resource version of its secret
+func getMongoDBUser(namespace string, userConfig opstreelabsinv1alpha1.MongoDBUser) (mongogo.MongoDBUser, error) {
+	database := getUserDatabase(userConfig.Database)
+	user := mongogo.MongoDBUser{
+		Name:     userConfig.Name,
+		Database: database,
+		Roles:    getMongoDBRoles(userConfig.Roles, database),
+	}
+	client, err := generateK8sClient()
+	if err != nil {
+		return user, err
+	}
+	secret, err := client.CoreV1().Secrets(namespace).Get(context.TODO(), *userConfig.PasswordSecretRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return user, err
+	}
+	key := getUserPasswordKey(userConfig.PasswordSecretRef)
+	password, found := secret.Data[key]
+	if !found || len(password) == 0 {
+		return user, fmt.Errorf("secret %s has no password in key %s", secret.Name, key)
+	}
+	user.Password = string(password)
+	user.PasswordVersion = secret.ResourceVersion
+	return user, nil
+}
+
+// getMongoDBCustomRole is a method to generate the custom role of the role management commands
+func getMongoDBCustomRole(roleConfig opstreelabsinv1alpha1.MongoDBCustomRole) mongogo.MongoDBCustomRole {
+	database := getUserDatabase(roleConfig.Database)
+	role := mongogo.MongoDBCustomRole{
+		Name:     roleConfig.Name,
+		Database: database,
+		Roles:    getMongoDBRoles(roleConfig.Roles, database),
+	}
+	for _, privilege := range roleConfig.Privileges {
+		resource := mongogo.MongoDBResource{Cluster: privilege.Resource.Cluster}
+		if privilege.Resource.Database != nil {
+			resource.Database = *privilege.Resource.Database
+		}
+		if privilege.Resource.Collection != nil {
+			resource.Collection = *privilege.Resource.Collection
+		}
+		role.Privileges = append(role.Privileges, mongogo.MongoDBPrivilege{Resource: resource, Actions: privilege.Actions})
+	}
+	return role
+}
+
+// getMongoDBRoles is a method to convert the role references, a role without database belongs to the database of the user or custom role
+func getMongoDBRoles(roleRefs []opstreelabsinv1alpha1.MongoDBRoleRef, database string) []mongogo.MongoDBRole {
+	var roles []mongogo.MongoDBRole
+	for _, roleRef := range roleRefs {
+		roleDatabase := roleRef.Database
+		if roleDatabase == "" {
+			roleDatabase = database
+		}
+		roles = append(roles, mongogo.MongoDBRole{Name: roleRef.Name, Database: roleDatabase})
+	}
+	return roles
+}
+
+// getUserDatabase is a method to get the database of a user or custom role, it defaults to admin
+func getUserDatabase(database string) string {
+	if database == "" {
+		return defaultUserDatabase
+	}
+	return database
+}
+
+// getUserPasswordKey is a method to get the key of the password in the secret of a user, it defaults to password
+func getUserPasswordKey(secretRef opstreelabsinv1alpha1.ExistingPasswordSecret) string {
+	if secretRef.Key == nil || *secretRef.Key == "" {
+		return defaultUserPasswordKey
+	}
+	return *secretRef.Key
+}
+
+// rolesEqual is a method to compare the roles granted to a user regardless of their order
+func rolesEqual(current []mongogo.MongoDBRole, desired []mongogo.MongoDBRole) bool {
+	return reflect.DeepEqual(sortedRoles(current), sortedRoles(desired))
+}
+
+// sortedRoles is a method to sort a copy of the roles by database and name
+func sortedRoles(roles []mongogo.MongoDBRole) []mongogo.MongoDBRole {
+	sorted := append([]mongogo.MongoDBRole{}, roles...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Database != sorted[j].Database {
+			return sorted[i].Database < sorted[j].Database
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// customRoleEqual is a method to compare the privileges and inherited roles of a custom role, the order of the actions doesn't matter
+func customRoleEqual(current mongogo.MongoDBCustomRole, desired mongogo.MongoDBCustomRole) bool {
+	if !rolesEqual(current.Roles, desired.Roles) || len(current.Privileges) != len(desired.Privileges) {
+		return false
+	}
+	for index := range current.Privileges {
+		if current.Privileges[index].Resource != desired.Privileges[index].Resource {
+			return false
+		}
+		currentActions := append([]string{}, current.Privileges[index].Actions...)
+		desiredActions := append([]string{}, desired.Privileges[index].Actions...)
+		sort.Strings(currentActions)
+		sort.Strings(desiredActions)
+		if !reflect.DeepEqual(currentActions, desiredActions) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateMongoDBUsers is a method to validate that the users and custom roles are unique and don't replace the users of the operator
+func validateMongoDBUsers(security *opstreelabsinv1alpha1.MongoDBSecurity) error {
+	if security == nil {
+		return nil
+	}
+	users := map[string]bool{}
+	for _, user := range security.Users {
+		key := fmt.Sprintf("%s.%s", getUserDatabase(user.Database), user.Name)
+		if users[key] {
+			return fmt.Errorf("user %s is declared more than once", key)
+		}
+		users[key] = true
+		if getUserDatabase(user.Database) == defaultUserDatabase && (user.Name == security.MongoDBAdminUser || user.Name == "monitoring") {
+			return fmt.Errorf("user %s is managed by the operator", key)
+		}
+		if user.PasswordSecretRef.Name == nil || *user.PasswordSecretRef.Name == "" {
+			return fmt.Errorf("user %s requires a passwordSecretRef name", key)
+		}
+		if len(user.Roles) == 0 {
+			return fmt.Errorf("user %s requires at least one role", key)
+		}
+	}
+	roles := map[string]bool{}
+	for _, role := range security.Roles {
+		key := fmt.Sprintf("%s.%s", getUserDatabase(role.Database), role.Name)
+		if roles[key] {
+			return fmt.Errorf("role %s is declared more than once", key)
+		}
+		roles[key] = true
+		for _, privilege := range role.Privileges {
+			if privilege.Resource.Cluster && (privilege.Resource.Database != nil || privilege.Resource.Collection != nil) {
+				return fmt.Errorf("role %s privilege on the cluster cannot set a db or collection", key)
+			}
+			if len(privilege.Actions) == 0 {
+				return fmt.Errorf("role %s privilege requires at least one action", key)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package k8sgo
+
+import (
+	"testing"
+
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestHasMongoDBUsers(t *testing.T) {
+	tests := []struct {
+		name         string
+		security     *opstreelabsinv1alpha1.MongoDBSecurity
+		managedUsers []string
+		managedRoles []string
+		want         bool
+	}{
+		{name: "no security", want: false},
+		{name: "nothing declared or managed", security: &opstreelabsinv1alpha1.MongoDBSecurity{}, want: false},
+		{name: "user declared", security: &opstreelabsinv1alpha1.MongoDBSecurity{Users: []opstreelabsinv1alpha1.MongoDBUser{{Name: "app"}}}, want: true},
+		{name: "role declared", security: &opstreelabsinv1alpha1.MongoDBSecurity{Roles: []opstreelabsinv1alpha1.MongoDBCustomRole{{Name: "reader"}}}, want: true},
+		{name: "user still managed", security: &opstreelabsinv1alpha1.MongoDBSecurity{}, managedUsers: []string{"admin.app"}, want: true},
+		{name: "role still managed", security: &opstreelabsinv1alpha1.MongoDBSecurity{}, managedRoles: []string{"admin.reader"}, want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := hasMongoDBUsers(test.security, test.managedUsers, test.managedRoles); got != test.want {
+				t.Errorf("hasMongoDBUsers() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package k8sgo
+
+import (
+	"fmt"
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+	"strings"
+)
+
+// defaultWarmUpTimeoutSeconds is the time the indexes of each collection are loaded for before the warm-up moves on
+const defaultWarmUpTimeoutSeconds int32 = 60
+
+// isWarmUpEnabled is a method to check if the indexes are loaded into the cache when mongod starts
+func isWarmUpEnabled(warmUp *opstreelabsinv1alpha1.MongoDBWarmUp) bool {
+	return warmUp != nil && warmUp.Enabled
+}
+
+// validateWarmUp is a method to validate the collections of the warm-up
+func validateWarmUp(warmUp *opstreelabsinv1alpha1.MongoDBWarmUp) error {
+	if !isWarmUpEnabled(warmUp) {
+		return nil
+	}
+	if len(warmUp.Collections) == 0 {
+		return fmt.Errorf("warmUp requires at least one collection")
+	}
+	for _, collection := range warmUp.Collections {
+		if !isCollectionNamespace(collection) {
+			return fmt.Errorf("warmUp collection %s must be in <database>.<collection> format", collection)
+		}
+	}
+	return nil
+}
+
+// getWarmUpCommand is a method to generate the shell command which reads every index of the collections once mongod is up
+func getWarmUpCommand(warmUp *opstreelabsinv1alpha1.MongoDBWarmUp, port int32, authEnabled bool) string {
+	credentials := getShellCredentials(authEnabled)
+	timeout := *getInt32OrDefault(warmUp.TimeoutSeconds, defaultWarmUpTimeoutSeconds)
+	collections := fmt.Sprintf("[\"%s\"]", strings.Join(warmUp.Collections, "\", \""))
+	// a covered query on each index pulls it into the cache, the _id field can only be excluded when it isn't part of the index, and secondaries are read as well
+	script := fmt.Sprintf("db.getMongo().setSlaveOk(); %s.forEach(function (ns) { var index = ns.indexOf(\".\"); var collection = db.getSiblingDB(ns.substring(0, index)).getCollection(ns.substring(index + 1)); collection.getIndexes().forEach(function (spec) { var projection = spec.key._id === undefined ? {_id: 0} : {}; Object.keys(spec.key).forEach(function (key) { projection[key] = 1 }); try { print(ns + \" \" + spec.name + \": \" + collection.find({}, projection).hint(spec.name).maxTimeMS(%d).itcount()) } catch (e) { print(ns + \" \" + spec.name + \": \" + e) } }) })",
+		collections, timeout*1000)
+	// a failing postStart hook kills the container, so the warm-up never fails
+	return fmt.Sprintf("mongo --port %d --quiet %s --eval '%s' || true", port, credentials, script)
+}
@@ -0,0 +1,59 @@
+package k8sgo
+
+import (
+	"strings"
+	"testing"
+
+	opstreelabsinv1alpha1 "mongodb-operator/api/v1alpha1"
+)
+
+func TestValidateWarmUp(t *testing.T) {
+	tests := []struct {
+		name    string
+		warmUp  *opstreelabsinv1alpha1.MongoDBWarmUp
+		wantErr bool
+	}{
+		{name: "no warm-up"},
+		{name: "disabled warm-up", warmUp: &opstreelabsinv1alpha1.MongoDBWarmUp{}},
+		{name: "collections", warmUp: &opstreelabsinv1alpha1.MongoDBWarmUp{Enabled: true, Collections: []string{"shop.orders", "shop.customers"}}},
+		{name: "no collections", warmUp: &opstreelabsinv1alpha1.MongoDBWarmUp{Enabled: true}, wantErr: true},
+		{name: "collection without database", warmUp: &opstreelabsinv1alpha1.MongoDBWarmUp{Enabled: true, Collections: []string{"orders"}}, wantErr: true},
+		{name: "collection with quotes", warmUp: &opstreelabsinv1alpha1.MongoDBWarmUp{Enabled: true, Collections: []string{"shop.orders'"}}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateWarmUp(test.warmUp); (err != nil) != test.wantErr {
+				t.Errorf("validateWarmUp() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetWarmUpCommand(t *testing.T) {
+	tests := []struct {
+		name         string
+		warmUp       *opstreelabsinv1alpha1.MongoDBWarmUp
+		wantCommands []string
+	}{
+		{
+			name:         "default timeout",
+			warmUp:       &opstreelabsinv1alpha1.MongoDBWarmUp{Enabled: true, Collections: []string{"shop.orders"}},
+			wantCommands: []string{`["shop.orders"]`, "maxTimeMS(60000)", "|| true"},
+		},
+		{
+			name:         "custom timeout",
+			warmUp:       &opstreelabsinv1alpha1.MongoDBWarmUp{Enabled: true, Collections: []string{"shop.orders", "shop.customers"}, TimeoutSeconds: int32Ptr(10)},
+			wantCommands: []string{`["shop.orders", "shop.customers"]`, "maxTimeMS(10000)"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			command := getWarmUpCommand(test.warmUp, mongoDBPort, false)
+			for _, want := range test.wantCommands {
+				if !strings.Contains(command, want) {
+					t.Errorf("getWarmUpCommand() = %s, missing %s", command, want)
+				}
+			}
+		})
+	}
+}
@@ -18,7 +18,10 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -26,8 +29,10 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
@@ -52,11 +57,29 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var leaderElectionNamespace string
+	var leaseDuration time.Duration
+	var renewDeadline time.Duration
+	var retryPeriod time.Duration
+	var watchNamespace string
+	var resyncPeriod time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.StringVar(&leaderElectionNamespace, "leader-elect-namespace", os.Getenv("LEADER_ELECT_NAMESPACE"),
+		"The namespace of the leader election lease, defaults to the namespace of the controller manager.")
+	flag.DurationVar(&leaseDuration, "leader-elect-lease-duration", getEnvDuration("LEADER_ELECT_LEASE_DURATION", 15*time.Second),
+		"The duration non-leader candidates wait before forcing to acquire the leadership.")
+	flag.DurationVar(&renewDeadline, "leader-elect-renew-deadline", getEnvDuration("LEADER_ELECT_RENEW_DEADLINE", 10*time.Second),
+		"The duration the leader retries refreshing the leadership before giving it up.")
+	flag.DurationVar(&retryPeriod, "leader-elect-retry-period", getEnvDuration("LEADER_ELECT_RETRY_PERIOD", 2*time.Second),
+		"The duration the candidates wait between tries of acquiring or renewing the leadership.")
+	flag.StringVar(&watchNamespace, "watch-namespace", os.Getenv("WATCH_NAMESPACE"),
+		"The comma-separated namespaces watched by the controller manager, all namespaces are watched if it is empty.")
+	flag.DurationVar(&resyncPeriod, "resync-period", getEnvDuration("RESYNC_PERIOD", controllers.DefaultResyncPeriod),
+		"The interval the MongoDB objects are reconciled at without any change, 0 disables the periodic reconciliation.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -65,29 +88,55 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:                 scheme,
-		MetricsBindAddress:     metricsAddr,
-		Port:                   9443,
-		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "224f6774.opstreelabs.in",
-	})
+	if err := validateLeaderElection(leaseDuration, renewDeadline, retryPeriod); err != nil {
+		setupLog.Error(err, "invalid leader election configuration")
+		os.Exit(1)
+	}
+	if resyncPeriod < 0 {
+		setupLog.Error(fmt.Errorf("resync-period %s must not be negative", resyncPeriod), "invalid resync configuration")
+		os.Exit(1)
+	}
+
+	watchNamespaces, err := parseWatchNamespaces(watchNamespace)
+	if err != nil {
+		setupLog.Error(err, "invalid watch namespace configuration")
+		os.Exit(1)
+	}
+
+	options := ctrl.Options{
+		Scheme:                  scheme,
+		MetricsBindAddress:      metricsAddr,
+		Port:                    9443,
+		HealthProbeBindAddress:  probeAddr,
+		LeaderElection:          enableLeaderElection,
+		LeaderElectionID:        "224f6774.opstreelabs.in",
+		LeaderElectionNamespace: leaderElectionNamespace,
+		LeaseDuration:           &leaseDuration,
+		RenewDeadline:           &renewDeadline,
+		RetryPeriod:             &retryPeriod,
+	}
+	setWatchNamespaces(&options, watchNamespaces)
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
 	if err = (&controllers.MongoDBReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Recorder:     mgr.GetEventRecorderFor("mongodb-controller"),
+		ResyncPeriod: resyncPeriod,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MongoDB")
 		os.Exit(1)
 	}
 	if err = (&controllers.MongoDBClusterReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:       mgr.GetClient(),
+		Scheme:       mgr.GetScheme(),
+		Recorder:     mgr.GetEventRecorderFor("mongodbcluster-controller"),
+		ResyncPeriod: resyncPeriod,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MongoDBCluster")
 		os.Exit(1)
@@ -109,3 +158,64 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// getEnvDuration returns the duration set in the environment variable as the default of a flag, the process exits if it is invalid
+func getEnvDuration(name string, defaultValue time.Duration) time.Duration {
+	value, found := os.LookupEnv(name)
+	if !found || value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid duration %q in %s: %v\n", value, name, err)
+		os.Exit(1)
+	}
+	return duration
+}
+
+// validateLeaderElection checks the leader election timings, the leader must renew the lease before it expires
+func validateLeaderElection(leaseDuration, renewDeadline, retryPeriod time.Duration) error {
+	if retryPeriod <= 0 {
+		return fmt.Errorf("leader-elect-retry-period must be greater than 0")
+	}
+	// client-go retries with a jitter of up to 20% of the retry period
+	if renewDeadline <= retryPeriod*12/10 {
+		return fmt.Errorf("leader-elect-renew-deadline %s must be greater than 1.2 times leader-elect-retry-period %s", renewDeadline, retryPeriod)
+	}
+	if leaseDuration <= renewDeadline {
+		return fmt.Errorf("leader-elect-lease-duration %s must be greater than leader-elect-renew-deadline %s", leaseDuration, renewDeadline)
+	}
+	return nil
+}
+
+// parseWatchNamespaces parses the comma-separated namespaces watched by the operator, an empty list means all namespaces
+func parseWatchNamespaces(value string) ([]string, error) {
+	var namespaces []string
+	seen := map[string]bool{}
+	for _, namespace := range strings.Split(value, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == "" || seen[namespace] {
+			continue
+		}
+		if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+			return nil, fmt.Errorf("watch namespace %q is invalid: %s", namespace, strings.Join(errs, ", "))
+		}
+		seen[namespace] = true
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces, nil
+}
+
+// setWatchNamespaces restricts the cache of the manager to the watched namespaces, a single namespace uses the namespaced cache and several use one cache per namespace
+func setWatchNamespaces(options *ctrl.Options, namespaces []string) {
+	switch len(namespaces) {
+	case 0:
+		setupLog.Info("watching all namespaces")
+	case 1:
+		options.Namespace = namespaces[0]
+		setupLog.Info("watching a single namespace", "namespace", namespaces[0])
+	default:
+		options.NewCache = cache.MultiNamespacedCacheBuilder(namespaces)
+		setupLog.Info("watching multiple namespaces", "namespaces", namespaces)
+	}
+}
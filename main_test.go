@@ -0,0 +1,83 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestParseWatchNamespaces(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "all namespaces", value: "", want: nil},
+		{name: "single namespace", value: "database", want: []string{"database"}},
+		{name: "several namespaces", value: "database, analytics ,,reporting", want: []string{"database", "analytics", "reporting"}},
+		{name: "duplicate namespace", value: "database,database", want: []string{"database"}},
+		{name: "invalid namespace", value: "database,Analytics", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parseWatchNamespaces(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("parseWatchNamespaces() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseWatchNamespaces() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSetWatchNamespaces(t *testing.T) {
+	tests := []struct {
+		name          string
+		namespaces    []string
+		wantNamespace string
+		wantNewCache  bool
+	}{
+		{name: "all namespaces"},
+		{name: "single namespace", namespaces: []string{"database"}, wantNamespace: "database"},
+		{name: "several namespaces", namespaces: []string{"database", "analytics"}, wantNewCache: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := ctrl.Options{}
+			setWatchNamespaces(&options, test.namespaces)
+			if options.Namespace != test.wantNamespace {
+				t.Errorf("setWatchNamespaces() namespace = %q, want %q", options.Namespace, test.wantNamespace)
+			}
+			if (options.NewCache != nil) != test.wantNewCache {
+				t.Errorf("setWatchNamespaces() sets the cache builder = %v, want %v", options.NewCache != nil, test.wantNewCache)
+			}
+		})
+	}
+}
+
+func TestValidateLeaderElection(t *testing.T) {
+	tests := []struct {
+		name          string
+		leaseDuration time.Duration
+		renewDeadline time.Duration
+		retryPeriod   time.Duration
+		wantErr       bool
+	}{
+		{name: "defaults", leaseDuration: 15 * time.Second, renewDeadline: 10 * time.Second, retryPeriod: 2 * time.Second},
+		{name: "zero retry period", leaseDuration: 15 * time.Second, renewDeadline: 10 * time.Second, retryPeriod: 0, wantErr: true},
+		{name: "renew deadline within the retry jitter", leaseDuration: 15 * time.Second, renewDeadline: 12 * time.Second, retryPeriod: 10 * time.Second, wantErr: true},
+		{name: "lease duration equal to the renew deadline", leaseDuration: 10 * time.Second, renewDeadline: 10 * time.Second, retryPeriod: 2 * time.Second, wantErr: true},
+		{name: "lease duration below the renew deadline", leaseDuration: 5 * time.Second, renewDeadline: 10 * time.Second, retryPeriod: 2 * time.Second, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := validateLeaderElection(test.leaseDuration, test.renewDeadline, test.retryPeriod); (err != nil) != test.wantErr {
+				t.Errorf("validateLeaderElection() error = %v, wantErr %v", err, test.wantErr)
+			}
+		})
+	}
+}
@@ -2,12 +2,18 @@ package mongogo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/go-logr/logr"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+	"net"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"strings"
 	"time"
 )
 
@@ -16,8 +22,70 @@ var log = logf.Log.WithName("controller_mongo")
 const (
 	dbName         = "admin"
 	monitoringUser = "monitoring"
+	// customDataManagedBy is the custom data field marking the users created by the operator
+	customDataManagedBy = "managedBy"
+	// customDataPasswordVersion is the custom data field holding the resource version of the password secret
+	customDataPasswordVersion = "passwordVersion"
+	managedByOperator         = "mongodb-operator"
+	// hostResolveTimeout is the time the hostname of a member is resolved for before its host is reconfigured
+	hostResolveTimeout = time.Second * 5
+	// memberStatusTimeout is the time a member is given to report its state, so an unreachable member doesn't stall the reconciliation
+	memberStatusTimeout = time.Second * 5
+	// memberRemovalStepDownSecs is the time a primary which is removed from the replica set cannot be re-elected for
+	memberRemovalStepDownSecs int64 = 60
+	// memberRemovalCatchUpSecs is the time a primary which is removed from the replica set waits for a secondary to catch up
+	memberRemovalCatchUpSecs int64 = 10
+	// roleNotFoundErrorCode is the MongoDB error code raised for a role which doesn't exist
+	roleNotFoundErrorCode = 31
+	// invalidReplicaSetConfigErrorCode is the MongoDB error code raised by a member which doesn't find itself in the replica set config
+	invalidReplicaSetConfigErrorCode = 93
+	// memberStateRemoved is the state of a member which doesn't find itself in the replica set config
+	memberStateRemoved = "REMOVED"
+	// DefaultClusterDomain is the DNS domain of Kubernetes clusters which don't configure another one
+	DefaultClusterDomain = "cluster.local"
 )
 
+// transientErrorCodes are the MongoDB error codes raised while members are unreachable, the replica set is electing a primary or its config is not committed yet
+var transientErrorCodes = []int{6, 7, 89, 91, 94, 109, 189, 308, 10107, 11600, 11602, 13435, 13436}
+
+// replicaSetStatus is a struct for the output of replSetGetStatus command
+type replicaSetStatus struct {
+	Term    int64                    `bson:"term"`
+	Members []replicaSetMemberStatus `bson:"members"`
+}
+
+// replicaSetMemberStatus is a struct for the member status of replSetGetStatus command
+type replicaSetMemberStatus struct {
+	ID         int       `bson:"_id"`
+	Name       string    `bson:"name"`
+	StateStr   string    `bson:"stateStr"`
+	OptimeDate time.Time `bson:"optimeDate"`
+	Self       bool      `bson:"self"`
+}
+
+// buildInfo is a struct for the output of buildInfo command
+type buildInfo struct {
+	Version string `bson:"version"`
+}
+
+// featureCompatibilityVersionParameter is a struct for the output of getParameter command for featureCompatibilityVersion
+type featureCompatibilityVersionParameter struct {
+	FeatureCompatibilityVersion struct {
+		Version string `bson:"version"`
+	} `bson:"featureCompatibilityVersion"`
+}
+
+// shardList is a struct for the output of listShards command
+type shardList struct {
+	Shards []shardInfo `bson:"shards"`
+}
+
+// shardInfo is a struct for the shard info of listShards command
+type shardInfo struct {
+	ID   string `bson:"_id"`
+	Host string `bson:"host"`
+}
+
 // MongoDBParameters is a struct for MongoDB related inputs
 type MongoDBParameters struct {
 	MongoURL     string
@@ -27,6 +95,52 @@ type MongoDBParameters struct {
 	Password     string
 	UserName     *string
 	ClusterNodes *int32
+	Members      []MongoDBMember
+	ConfigServer bool
+	Port         int32
+	ReplicaSet   string
+	// ClusterDomain qualifies the member hosts, DefaultClusterDomain is used when it is empty
+	ClusterDomain string
+}
+
+// MongoDBMember is a struct for MongoDB replica set member configuration
+type MongoDBMember struct {
+	ID                 int
+	Host               string
+	Hidden             bool
+	Priority           float64
+	Votes              int
+	SecondaryDelaySecs int64
+	Tags               map[string]string
+	ArbiterOnly        bool
+}
+
+// MongoDBMemberStatus is a struct for the state of a replica set member as reported by the member itself
+type MongoDBMemberStatus struct {
+	State  string
+	Optime time.Time
+	// Primary is the member the reporting member sees as primary, it is empty when it sees none
+	Primary string
+}
+
+// IsTransientError is a method to check if the error is expected to resolve itself, like connection failures or a missing primary
+func IsTransientError(err error) bool {
+	if mongo.IsNetworkError(err) || mongo.IsTimeout(err) {
+		return true
+	}
+	var selectionError topology.ServerSelectionError
+	if errors.As(err, &selectionError) {
+		return true
+	}
+	var commandError mongo.CommandError
+	if errors.As(err, &commandError) {
+		for _, code := range transientErrorCodes {
+			if commandError.HasErrorCode(code) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // initiateMongoClient is a method to create client connection with MongoDB
@@ -63,7 +177,6 @@ func discconnectMongoClient(client *mongo.Client) error {
 	return nil
 }
 
-//nolint:govet
 // CreateMonitoringUser is a method to create monitoring user inside MongoDB
 func CreateMonitoringUser(params MongoDBParameters) error {
 	var client *mongo.Client
@@ -73,8 +186,8 @@ func CreateMonitoringUser(params MongoDBParameters) error {
 		client = initiateMongoClient(params)
 	}
 	response := client.Database(dbName).RunCommand(context.Background(), bson.D{
-		{"createUser", monitoringUser}, {"pwd", params.Password},
-		{"roles", []bson.M{{"role": "clusterMonitor", "db": "admin"}, {"role": "read", "db": "local"}}}},
+		{Key: "createUser", Value: monitoringUser}, {Key: "pwd", Value: params.Password},
+		{Key: "roles", Value: []bson.M{{"role": "clusterMonitor", "db": "admin"}, {"role": "read", "db": "local"}}}},
 	)
 	if response.Err() != nil {
 		return response.Err()
@@ -86,7 +199,6 @@ func CreateMonitoringUser(params MongoDBParameters) error {
 	return nil
 }
 
-//nolint:govet
 // GetMongoDBUser is a method to check if user exists in MongoDB
 func GetMongoDBUser(params MongoDBParameters) (bool, error) {
 	var client *mongo.Client
@@ -99,7 +211,7 @@ func GetMongoDBUser(params MongoDBParameters) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	opts := options.Count().SetMaxTime(2 * time.Second)
-	docsCount, err := collection.CountDocuments(ctx, bson.D{{"user", *params.UserName}}, opts)
+	docsCount, err := collection.CountDocuments(ctx, bson.D{{Key: "user", Value: *params.UserName}}, opts)
 	if err != nil {
 		return false, err
 	}
@@ -117,13 +229,26 @@ func GetMongoDBUser(params MongoDBParameters) (bool, error) {
 func InitiateMongoClusterRS(params MongoDBParameters) error {
 	var mongoNodeInfo []bson.M
 	client := initiateMongoClient(params)
-	for node := 0; node < int(*params.ClusterNodes); node++ {
-		mongoNodeInfo = append(mongoNodeInfo, bson.M{"_id": node, "host": GetMongoNodeInfo(params, node)})
+	if len(params.Members) > 0 {
+		for _, member := range params.Members {
+			mongoNodeInfo = append(mongoNodeInfo, getMemberConfig(member))
+		}
+	} else {
+		for node := 0; node < int(*params.ClusterNodes); node++ {
+			mongoNodeInfo = append(mongoNodeInfo, bson.M{"_id": node, "host": GetMongoNodeInfo(params, node)})
+		}
+	}
+	replicaSetName := params.Name
+	if params.ReplicaSet != "" {
+		replicaSetName = params.ReplicaSet
 	}
 	config := bson.M{
-		"_id":     params.Name,
+		"_id":     replicaSetName,
 		"members": mongoNodeInfo,
 	}
+	if params.ConfigServer {
+		config["configsvr"] = true
+	}
 	response := client.Database(dbName).RunCommand(context.Background(), bson.M{"replSetInitiate": config})
 	if response.Err() != nil {
 		return response.Err()
@@ -135,6 +260,296 @@ func InitiateMongoClusterRS(params MongoDBParameters) error {
 	return nil
 }
 
+// ReconfigureMongoClusterMembers is a method to apply the member configuration on an initialized MongoDB cluster, it reports the member changes which are held back for a later reconfiguration as pending
+func ReconfigureMongoClusterMembers(params MongoDBParameters) (bool, bool, error) {
+	client := initiateMongoClusterClient(params)
+	defer discconnectMongoClient(client)
+	var result bson.M
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetGetConfig", Value: 1}}).Decode(&result)
+	if err != nil {
+		return false, false, err
+	}
+	config, ok := result["config"].(bson.M)
+	if !ok {
+		return false, false, fmt.Errorf("unable to parse replica set config")
+	}
+	members, ok := config["members"].(bson.A)
+	if !ok {
+		return false, false, fmt.Errorf("unable to parse replica set members")
+	}
+	var status replicaSetStatus
+	err = client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&status)
+	if err != nil {
+		return false, false, err
+	}
+	members, changed, pending, serialChange := updateDesiredMembers(members, params.Members, status)
+	if drift := getMemberHostDrift(members, params.Members); len(drift) > 0 {
+		if err := resolveMemberHosts(drift); err != nil {
+			// the hosts are only moved once all of them resolve, so a DNS outage never points the replica set at unreachable hosts
+			logGenerator(params.Name, params.Namespace, "MongoDB Cluster Setup").Info("Skipping the reconfiguration of drifted member hosts", "reason", err.Error())
+		} else {
+			for index := range members {
+				memberConfig, ok := members[index].(bson.M)
+				if host, drifted := drift[toFloat(memberConfig["_id"])]; ok && drifted {
+					memberConfig["host"] = host
+					changed = true
+				}
+			}
+		}
+	}
+	members, removed, removalPending, stepDown := removeStaleMembers(members, params.Members, status, !serialChange)
+	pending = pending || removalPending
+	if !changed && !removed {
+		if stepDown {
+			// the primary is removed by the next reconfiguration, once a remaining member took over
+			logGenerator(params.Name, params.Namespace, "MongoDB Cluster Setup").Info("Stepping down the primary which is removed from the replica set")
+			if err := StepDownMongoPrimary(params, memberRemovalStepDownSecs, memberRemovalCatchUpSecs); err != nil {
+				return false, pending, err
+			}
+		}
+		return false, pending, nil
+	}
+	config["members"] = members
+	config["version"] = int64(toFloat(config["version"])) + 1
+	response := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetReconfig", Value: config}})
+	if response.Err() != nil {
+		return false, pending, response.Err()
+	}
+	return true, pending, nil
+}
+
+// updateDesiredMembers is a method to apply the desired settings on the members of the replica set config and to add a missing member, it returns the changed members, whether they changed, whether changes are held back and whether a voting member is added or changes its votes
+func updateDesiredMembers(members bson.A, desiredMembers []MongoDBMember, status replicaSetStatus) (bson.A, bool, bool, bool) {
+	changed := false
+	pending := false
+	added := false
+	votesChanged := false
+	for _, member := range desiredMembers {
+		found := false
+		for index := range members {
+			memberConfig, ok := members[index].(bson.M)
+			if !ok || toFloat(memberConfig["_id"]) != float64(member.ID) {
+				continue
+			}
+			found = true
+			// MongoDB only accepts a single voting member to be added or removed per reconfiguration, the other members follow in the next one
+			if toFloat(memberConfig["votes"]) != float64(member.Votes) {
+				if votesChanged {
+					pending = true
+					continue
+				}
+				votesChanged = true
+			}
+			for key, value := range getMemberConfig(member) {
+				if key == "_id" || key == "host" {
+					continue
+				}
+				if !memberConfigEqual(memberConfig[key], value) {
+					memberConfig[key] = value
+					changed = true
+				}
+			}
+		}
+		if found {
+			continue
+		}
+		// a single member is added once the others are healthy, so the members which are still syncing never hold back the election majority
+		if added || votesChanged || !isReplicaSetSettled(status, desiredMembers) {
+			pending = true
+			continue
+		}
+		members = append(members, getMemberConfig(member))
+		changed = true
+		added = true
+		votesChanged = member.Votes > 0
+	}
+	return members, changed, pending, added || votesChanged
+}
+
+// GetMongoClusterDataMembers is a method to get the number of pods the data bearing members in the replica set config of MongoDB cluster run on, the member id of a data bearing member is the ordinal of its pod
+func GetMongoClusterDataMembers(params MongoDBParameters) (int32, error) {
+	client := initiateMongoClusterClient(params)
+	defer discconnectMongoClient(client)
+	var result bson.M
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetGetConfig", Value: 1}}).Decode(&result)
+	if err != nil {
+		return 0, err
+	}
+	config, ok := result["config"].(bson.M)
+	if !ok {
+		return 0, fmt.Errorf("unable to parse replica set config")
+	}
+	members, ok := config["members"].(bson.A)
+	if !ok {
+		return 0, fmt.Errorf("unable to parse replica set members")
+	}
+	var dataMembers int32
+	for _, member := range members {
+		memberConfig, ok := member.(bson.M)
+		if !ok {
+			continue
+		}
+		if arbiterOnly, _ := memberConfig["arbiterOnly"].(bool); arbiterOnly {
+			continue
+		}
+		if id := int32(toFloat(memberConfig["_id"])); id >= dataMembers {
+			dataMembers = id + 1
+		}
+	}
+	return dataMembers, nil
+}
+
+// getMemberHostDrift is a method to get the expected host of the members whose host in the replica set config differs from it, keyed by the member id
+func getMemberHostDrift(members bson.A, desiredMembers []MongoDBMember) map[float64]string {
+	drift := make(map[float64]string)
+	for _, member := range desiredMembers {
+		for _, current := range members {
+			memberConfig, ok := current.(bson.M)
+			if !ok || toFloat(memberConfig["_id"]) != float64(member.ID) {
+				continue
+			}
+			// hostnames are case insensitive, so only a different name counts as drift
+			if host, _ := memberConfig["host"].(string); !strings.EqualFold(host, member.Host) {
+				drift[float64(member.ID)] = member.Host
+			}
+		}
+	}
+	return drift
+}
+
+// resolveMemberHosts is a method to check that the hostname of every drifted member resolves
+func resolveMemberHosts(drift map[float64]string) error {
+	for _, host := range drift {
+		hostname, _, err := net.SplitHostPort(host)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), hostResolveTimeout)
+		_, err = net.DefaultResolver.LookupHost(ctx, hostname)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("host %s cannot be resolved: %w", host, err)
+		}
+	}
+	return nil
+}
+
+// getDesiredMemberIDs is a method to get the ids of the desired replica set members
+func getDesiredMemberIDs(desiredMembers []MongoDBMember) map[float64]bool {
+	desired := make(map[float64]bool)
+	for _, member := range desiredMembers {
+		desired[float64(member.ID)] = true
+	}
+	return desired
+}
+
+// isReplicaSetSettled is a method to check if every desired member of the replica set is primary, secondary or arbiter, the members being removed are not waited for
+func isReplicaSetSettled(status replicaSetStatus, desiredMembers []MongoDBMember) bool {
+	desired := getDesiredMemberIDs(desiredMembers)
+	for _, member := range status.Members {
+		if !desired[float64(member.ID)] {
+			continue
+		}
+		if member.StateStr != "PRIMARY" && member.StateStr != "SECONDARY" && member.StateStr != "ARBITER" {
+			return false
+		}
+	}
+	return true
+}
+
+// removeStaleMembers is a method to remove a member which is not desired anymore from the replica set config, one member is removed per reconfiguration and never the primary, the others are reported as pending along with a primary which has to step down first
+func removeStaleMembers(members bson.A, desiredMembers []MongoDBMember, status replicaSetStatus, allowed bool) (bson.A, bool, bool, bool) {
+	desired := getDesiredMemberIDs(desiredMembers)
+	primary := make(map[float64]bool)
+	for _, member := range status.Members {
+		if member.StateStr == "PRIMARY" {
+			primary[float64(member.ID)] = true
+		}
+	}
+	var remainingMembers bson.A
+	removed := false
+	pending := false
+	stalePrimary := false
+	for _, member := range members {
+		memberConfig, ok := member.(bson.M)
+		if !ok || desired[toFloat(memberConfig["_id"])] {
+			remainingMembers = append(remainingMembers, member)
+			continue
+		}
+		if !allowed || removed || primary[toFloat(memberConfig["_id"])] {
+			stalePrimary = stalePrimary || primary[toFloat(memberConfig["_id"])]
+			remainingMembers = append(remainingMembers, member)
+			pending = true
+			continue
+		}
+		removed = true
+	}
+	return remainingMembers, removed, pending, allowed && stalePrimary && !removed
+}
+
+// getMemberConfig is a method to generate the replica set configuration of a member
+func getMemberConfig(member MongoDBMember) bson.M {
+	tags := bson.M{}
+	for key, value := range member.Tags {
+		tags[key] = value
+	}
+	memberConfig := bson.M{
+		"_id":                member.ID,
+		"host":               member.Host,
+		"arbiterOnly":        member.ArbiterOnly,
+		"hidden":             member.Hidden,
+		"priority":           member.Priority,
+		"votes":              member.Votes,
+		"secondaryDelaySecs": member.SecondaryDelaySecs,
+	}
+	// arbiters don't hold data, so they cannot be targeted with tags
+	if !member.ArbiterOnly {
+		memberConfig["tags"] = tags
+	}
+	return memberConfig
+}
+
+// memberConfigEqual is a method to compare a replica set config value with the desired one
+func memberConfigEqual(current interface{}, desired interface{}) bool {
+	switch desiredValue := desired.(type) {
+	case float64:
+		return toFloat(current) == desiredValue
+	case int, int64:
+		return toFloat(current) == toFloat(desiredValue)
+	case bson.M:
+		currentValue, ok := current.(bson.M)
+		if !ok {
+			return len(desiredValue) == 0 && current == nil
+		}
+		if len(currentValue) != len(desiredValue) {
+			return false
+		}
+		for key, value := range desiredValue {
+			if currentValue[key] != value {
+				return false
+			}
+		}
+		return true
+	default:
+		return current == desired
+	}
+}
+
+// toFloat is a method to convert a numeric bson value into float
+func toFloat(value interface{}) float64 {
+	switch number := value.(type) {
+	case int:
+		return float64(number)
+	case int32:
+		return float64(number)
+	case int64:
+		return float64(number)
+	case float64:
+		return number
+	}
+	return 0
+}
+
 // CheckMongoClusterInitialized is a method to check if cluster is initailized or not
 func CheckMongoClusterInitialized(params MongoDBParameters) (bool, error) {
 	client := initiateMongoClient(params)
@@ -149,9 +564,348 @@ func CheckMongoClusterInitialized(params MongoDBParameters) (bool, error) {
 	return false, nil
 }
 
+// GetMongoClusterPrimary is a method to get the current primary node and election term of MongoDB cluster
+func GetMongoClusterPrimary(params MongoDBParameters) (string, int64, error) {
+	client := initiateMongoClusterClient(params)
+	defer discconnectMongoClient(client)
+	var result replicaSetStatus
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&result)
+	if err != nil {
+		return "", 0, err
+	}
+	for _, member := range result.Members {
+		if member.StateStr == "PRIMARY" {
+			return member.Name, result.Term, nil
+		}
+	}
+	return "", result.Term, nil
+}
+
+// CheckMongoClusterPrimary is a method to check if MongoDB cluster has a primary, the status is read from any reachable member so it works while the replica set has no primary
+func CheckMongoClusterPrimary(params MongoDBParameters) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), memberStatusTimeout)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(params.MongoURL).SetReadPreference(readpref.PrimaryPreferred()).SetConnectTimeout(memberStatusTimeout).SetServerSelectionTimeout(memberStatusTimeout))
+	if err != nil {
+		return false, err
+	}
+	defer discconnectMongoClient(client)
+	var result replicaSetStatus
+	err = client.Database(dbName).RunCommand(ctx, bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&result)
+	if err != nil {
+		// no member being reachable is a replica set without a primary as well
+		if IsTransientError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, member := range result.Members {
+		if member.StateStr == "PRIMARY" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetMongoClusterMemberStatus is a method to get the state of a single MongoDB cluster member through a direct connection, it works while the replica set has no primary, and a member which doesn't find itself in the replica set config is REMOVED with the optime of its last oplog entry
+func GetMongoClusterMemberStatus(params MongoDBParameters) (MongoDBMemberStatus, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), memberStatusTimeout)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(params.MongoURL).SetDirect(true).SetConnectTimeout(memberStatusTimeout).SetServerSelectionTimeout(memberStatusTimeout))
+	if err != nil {
+		return MongoDBMemberStatus{}, err
+	}
+	defer discconnectMongoClient(client)
+	var result replicaSetStatus
+	err = client.Database(dbName).RunCommand(ctx, bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&result)
+	var commandError mongo.CommandError
+	if errors.As(err, &commandError) && commandError.HasErrorCode(invalidReplicaSetConfigErrorCode) {
+		optime, err := getLastOplogTime(ctx, client)
+		if err != nil {
+			return MongoDBMemberStatus{}, err
+		}
+		return MongoDBMemberStatus{State: memberStateRemoved, Optime: optime}, nil
+	}
+	if err != nil {
+		return MongoDBMemberStatus{}, err
+	}
+	var status MongoDBMemberStatus
+	for _, member := range result.Members {
+		if member.Self {
+			status.State = member.StateStr
+			status.Optime = member.OptimeDate
+		}
+		if member.StateStr == "PRIMARY" {
+			status.Primary = member.Name
+		}
+	}
+	return status, nil
+}
+
+// getLastOplogTime is a method to get the wall clock time of the last oplog entry of a member
+func getLastOplogTime(ctx context.Context, client *mongo.Client) (time.Time, error) {
+	var entry struct {
+		Timestamp primitive.Timestamp `bson:"ts"`
+	}
+	err := client.Database("local").Collection("oplog.rs").FindOne(ctx, bson.D{}, options.FindOne().SetSort(bson.D{{Key: "$natural", Value: -1}})).Decode(&entry)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(int64(entry.Timestamp.T), 0), nil
+}
+
+// ForceReconfigureMongoClusterMembers is a method to force the desired member configuration on the member of MongoDB cluster the connection URL points to, it is only meant for a replica set which cannot elect a primary and reports whether the config differed from the desired one
+func ForceReconfigureMongoClusterMembers(params MongoDBParameters) (bool, error) {
+	client := initiateMongoClient(params)
+	defer discconnectMongoClient(client)
+	var result bson.M
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetGetConfig", Value: 1}}).Decode(&result)
+	if err != nil {
+		return false, err
+	}
+	config, ok := result["config"].(bson.M)
+	if !ok {
+		return false, fmt.Errorf("unable to parse replica set config")
+	}
+	members, ok := config["members"].(bson.A)
+	if !ok {
+		return false, fmt.Errorf("unable to parse replica set members")
+	}
+	changed := false
+	for _, member := range params.Members {
+		found := false
+		for index := range members {
+			memberConfig, ok := members[index].(bson.M)
+			if !ok || toFloat(memberConfig["_id"]) != float64(member.ID) {
+				continue
+			}
+			found = true
+			// the settings which aren't managed by the operator, like buildIndexes, are kept as they are
+			for key, value := range getMemberConfig(member) {
+				if key == "host" && strings.EqualFold(fmt.Sprint(memberConfig[key]), member.Host) {
+					continue
+				}
+				if !memberConfigEqual(memberConfig[key], value) {
+					memberConfig[key] = value
+					changed = true
+				}
+			}
+		}
+		if !found && member.ArbiterOnly {
+			members = append(members, getMemberConfig(member))
+			changed = true
+		}
+	}
+	// forcing the config the members already have cannot help them to elect a primary
+	if !changed {
+		return false, nil
+	}
+	// members are never removed, so no member holding data is left out of the replica set
+	config["members"] = members
+	config["version"] = int64(toFloat(config["version"])) + 1
+	response := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetReconfig", Value: config}, {Key: "force", Value: true}})
+	if response.Err() != nil {
+		return false, response.Err()
+	}
+	return true, nil
+}
+
+// GetMongoClusterReplicationLag is a method to get the maximum replication lag of the secondaries of MongoDB cluster in seconds
+func GetMongoClusterReplicationLag(params MongoDBParameters) (int64, error) {
+	client := initiateMongoClusterClient(params)
+	defer discconnectMongoClient(client)
+	var result replicaSetStatus
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&result)
+	if err != nil {
+		return 0, err
+	}
+	return getMaxReplicationLag(result.Members), nil
+}
+
+// GetMongoClusterSecondaries is a method to get the healthy secondary nodes of MongoDB cluster
+func GetMongoClusterSecondaries(params MongoDBParameters) ([]string, error) {
+	client := initiateMongoClusterClient(params)
+	defer discconnectMongoClient(client)
+	var result replicaSetStatus
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	var secondaries []string
+	for _, member := range result.Members {
+		if member.StateStr == "SECONDARY" {
+			secondaries = append(secondaries, member.Name)
+		}
+	}
+	return secondaries, nil
+}
+
+// LockMongoNode is a method to flush the writes of MongoDB node to disk and block new writes until it is unlocked
+func LockMongoNode(params MongoDBParameters) error {
+	client := initiateMongoClient(params)
+	defer discconnectMongoClient(client)
+	return client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "fsync", Value: 1}, {Key: "lock", Value: true}}).Err()
+}
+
+// UnlockMongoNode is a method to unblock the writes of MongoDB node locked with fsync
+func UnlockMongoNode(params MongoDBParameters) error {
+	client := initiateMongoClient(params)
+	defer discconnectMongoClient(client)
+	return client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "fsyncUnlock", Value: 1}}).Err()
+}
+
+// GetMongoOplogSize is a method to get the maximum size of the oplog of MongoDB node in megabytes
+func GetMongoOplogSize(params MongoDBParameters) (int64, error) {
+	client := initiateMongoClient(params)
+	defer discconnectMongoClient(client)
+	var result struct {
+		MaxSize int64 `bson:"maxSize"`
+	}
+	err := client.Database("local").RunCommand(context.Background(), bson.D{{Key: "collStats", Value: "oplog.rs"}}).Decode(&result)
+	if err != nil {
+		return 0, err
+	}
+	return result.MaxSize / (1024 * 1024), nil
+}
+
+// ResizeMongoOplog is a method to change the maximum size of the oplog of MongoDB node, it only applies to the node the client is connected to
+func ResizeMongoOplog(params MongoDBParameters, sizeMB int32) error {
+	client := initiateMongoClient(params)
+	defer discconnectMongoClient(client)
+	return client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetResizeOplog", Value: 1}, {Key: "size", Value: float64(sizeMB)}}).Err()
+}
+
+// GetMongoNodeVersion is a method to get the binary version of MongoDB node
+func GetMongoNodeVersion(params MongoDBParameters) (string, error) {
+	client := initiateMongoClient(params)
+	defer discconnectMongoClient(client)
+	var result buildInfo
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "buildInfo", Value: 1}}).Decode(&result)
+	if err != nil {
+		return "", err
+	}
+	return result.Version, nil
+}
+
+// GetMongoFeatureCompatibilityVersion is a method to get the feature compatibility version of MongoDB cluster
+func GetMongoFeatureCompatibilityVersion(params MongoDBParameters) (string, error) {
+	client := initiateMongoClusterClient(params)
+	defer discconnectMongoClient(client)
+	var result featureCompatibilityVersionParameter
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "getParameter", Value: 1}, {Key: "featureCompatibilityVersion", Value: 1}}).Decode(&result)
+	if err != nil {
+		return "", err
+	}
+	return result.FeatureCompatibilityVersion.Version, nil
+}
+
+// SetMongoFeatureCompatibilityVersion is a method to set the feature compatibility version of MongoDB cluster
+func SetMongoFeatureCompatibilityVersion(params MongoDBParameters, version string, confirm bool) error {
+	client := initiateMongoClusterClient(params)
+	defer discconnectMongoClient(client)
+	command := bson.D{{Key: "setFeatureCompatibilityVersion", Value: version}}
+	if confirm {
+		// MongoDB 7.0 and later refuse to change the feature compatibility version without confirmation
+		command = append(command, bson.E{Key: "confirm", Value: true})
+	}
+	return client.Database(dbName).RunCommand(context.Background(), command).Err()
+}
+
+// GetMongoClusterMemberStates is a method to get the replica set state of each member of MongoDB cluster
+func GetMongoClusterMemberStates(params MongoDBParameters) (map[string]string, error) {
+	client := initiateMongoClusterClient(params)
+	defer discconnectMongoClient(client)
+	var result replicaSetStatus
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	states := map[string]string{}
+	for _, member := range result.Members {
+		states[member.Name] = member.StateStr
+	}
+	return states, nil
+}
+
+// StepDownMongoPrimary is a method to hand over the primary of MongoDB cluster to a caught up secondary
+func StepDownMongoPrimary(params MongoDBParameters, stepDownSecs int64, catchUpSecs int64) error {
+	client := initiateMongoClusterClient(params)
+	defer discconnectMongoClient(client)
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{
+		{Key: "replSetStepDown", Value: stepDownSecs},
+		{Key: "secondaryCatchUpPeriodSecs", Value: catchUpSecs},
+	}).Err()
+	// releases before 4.2 close every connection on step down, which is not a failure
+	if err != nil && !mongo.IsNetworkError(err) {
+		return err
+	}
+	return nil
+}
+
+// getMaxReplicationLag is a method to compare the optime of the primary with each secondary, it is 0 without a primary
+func getMaxReplicationLag(members []replicaSetMemberStatus) int64 {
+	var primaryOptime time.Time
+	for _, member := range members {
+		if member.StateStr == "PRIMARY" {
+			primaryOptime = member.OptimeDate
+		}
+	}
+	if primaryOptime.IsZero() {
+		return 0
+	}
+	var maxLag int64
+	for _, member := range members {
+		if member.StateStr != "SECONDARY" {
+			continue
+		}
+		if lag := int64(primaryOptime.Sub(member.OptimeDate).Seconds()); lag > maxLag {
+			maxLag = lag
+		}
+	}
+	return maxLag
+}
+
+// ListMongoShards is a method to list the shards registered in a sharded MongoDB cluster
+func ListMongoShards(params MongoDBParameters) ([]string, error) {
+	client := initiateMongoClient(params)
+	defer discconnectMongoClient(client)
+	var result shardList
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "listShards", Value: 1}}).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	var shards []string
+	for _, shard := range result.Shards {
+		shards = append(shards, shard.ID)
+	}
+	return shards, nil
+}
+
+// AddMongoShard is a method to register a shard replica set in a sharded MongoDB cluster
+func AddMongoShard(params MongoDBParameters, shardName string, shardHosts []string) error {
+	client := initiateMongoClient(params)
+	defer discconnectMongoClient(client)
+	return client.Database(dbName).RunCommand(context.Background(), bson.D{
+		{Key: "addShard", Value: fmt.Sprintf("%s/%s", shardName, strings.Join(shardHosts, ","))},
+		{Key: "name", Value: shardName},
+	}).Err()
+}
+
 // GetMongoNodeInfo is a method to get info for MongoDB node
 func GetMongoNodeInfo(params MongoDBParameters, count int) string {
-	return fmt.Sprintf("%s-cluster-%v.%s-cluster.%s:27017", params.Name, count, params.Name, params.Namespace)
+	port := params.Port
+	if port == 0 {
+		port = 27017
+	}
+	return GetPodHost(fmt.Sprintf("%s-cluster-%d", params.Name, count), fmt.Sprintf("%s-cluster", params.Name), params.Namespace, params.ClusterDomain, port)
+}
+
+// GetPodHost is a method to get the fully qualified host of a statefulset pod behind its headless service, the default cluster domain is used when none is set
+func GetPodHost(podName string, serviceName string, namespace string, clusterDomain string, port int32) string {
+	if clusterDomain == "" {
+		clusterDomain = DefaultClusterDomain
+	}
+	return fmt.Sprintf("%s.%s.%s.svc.%s:%d", podName, serviceName, namespace, clusterDomain, port)
 }
 
 // logGenerator is a method to generate logging interface
@@ -159,3 +913,210 @@ func logGenerator(name, namespace, resourceType string) logr.Logger {
 	reqLogger := log.WithValues("Namespace", namespace, "Name", name, "Resource Type", resourceType)
 	return reqLogger
 }
+
+// MongoDBUser is a struct for an application user of MongoDB managed by the operator
+type MongoDBUser struct {
+	Name            string
+	Database        string
+	Password        string
+	PasswordVersion string
+	Roles           []MongoDBRole
+}
+
+// MongoDBRole is a struct for a role granted to a user or inherited by a custom role
+type MongoDBRole struct {
+	Name     string `bson:"role"`
+	Database string `bson:"db"`
+}
+
+// MongoDBCustomRole is a struct for a user defined role of MongoDB
+type MongoDBCustomRole struct {
+	Name       string             `bson:"role"`
+	Database   string             `bson:"db"`
+	Privileges []MongoDBPrivilege `bson:"privileges"`
+	Roles      []MongoDBRole      `bson:"roles"`
+}
+
+// MongoDBPrivilege is a struct for the actions a custom role allows on a resource
+type MongoDBPrivilege struct {
+	Resource MongoDBResource `bson:"resource"`
+	Actions  []string        `bson:"actions"`
+}
+
+// MongoDBResource is a struct for the resource of a privilege, either the cluster or a database and collection where empty means all
+type MongoDBResource struct {
+	Database   string `bson:"db"`
+	Collection string `bson:"collection"`
+	Cluster    bool   `bson:"cluster"`
+}
+
+// MongoDBUserInfo is a struct for the output of usersInfo command
+type MongoDBUserInfo struct {
+	Name       string        `bson:"user"`
+	Database   string        `bson:"db"`
+	Roles      []MongoDBRole `bson:"roles"`
+	CustomData bson.M        `bson:"customData"`
+}
+
+// usersInfo is a struct for the users of usersInfo command
+type usersInfo struct {
+	Users []MongoDBUserInfo `bson:"users"`
+}
+
+// rolesInfo is a struct for the roles of rolesInfo command
+type rolesInfo struct {
+	Roles []MongoDBCustomRole `bson:"roles"`
+}
+
+// initiateMongoSetupClient is a method to create client connection with MongoDB or MongoDB Cluster depending on the setup type
+func initiateMongoSetupClient(params MongoDBParameters) *mongo.Client {
+	if params.SetupType == "cluster" {
+		return initiateMongoClusterClient(params)
+	}
+	return initiateMongoClient(params)
+}
+
+// IsMongoUserManaged is a method to check if the user was created by the operator, the other users are never changed or dropped
+func IsMongoUserManaged(user MongoDBUserInfo) bool {
+	return user.CustomData[customDataManagedBy] == managedByOperator
+}
+
+// GetMongoUserPasswordVersion is a method to get the version of the password secret the user was last updated with
+func GetMongoUserPasswordVersion(user MongoDBUserInfo) string {
+	version, _ := user.CustomData[customDataPasswordVersion].(string)
+	return version
+}
+
+// GetMongoUsers is a method to list the users of every database of MongoDB
+func GetMongoUsers(params MongoDBParameters) ([]MongoDBUserInfo, error) {
+	client := initiateMongoSetupClient(params)
+	defer discconnectMongoClient(client)
+	var result usersInfo
+	err := client.Database(dbName).RunCommand(context.Background(), bson.D{{Key: "usersInfo", Value: bson.M{"forAllDBs": true}}, {Key: "showCustomData", Value: true}}).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+	return result.Users, nil
+}
+
+// CreateMongoUser is a method to create an application user inside MongoDB
+func CreateMongoUser(params MongoDBParameters, user MongoDBUser) error {
+	client := initiateMongoSetupClient(params)
+	defer discconnectMongoClient(client)
+	response := client.Database(user.Database).RunCommand(context.Background(), getCreateUserCommand(user))
+	return response.Err()
+}
+
+// UpdateMongoUser is a method to update the roles of an application user inside MongoDB, and its password when the secret changed
+func UpdateMongoUser(params MongoDBParameters, user MongoDBUser, updatePassword bool) error {
+	client := initiateMongoSetupClient(params)
+	defer discconnectMongoClient(client)
+	response := client.Database(user.Database).RunCommand(context.Background(), getUpdateUserCommand(user, updatePassword))
+	return response.Err()
+}
+
+// DropMongoUser is a method to drop an application user from MongoDB
+func DropMongoUser(params MongoDBParameters, name string, database string) error {
+	client := initiateMongoSetupClient(params)
+	defer discconnectMongoClient(client)
+	response := client.Database(database).RunCommand(context.Background(), bson.D{{Key: "dropUser", Value: name}})
+	return response.Err()
+}
+
+// DropMongoRole is a method to drop a user defined role from MongoDB, a role which doesn't exist anymore is not an error
+func DropMongoRole(params MongoDBParameters, name string, database string) error {
+	client := initiateMongoSetupClient(params)
+	defer discconnectMongoClient(client)
+	response := client.Database(database).RunCommand(context.Background(), bson.D{{Key: "dropRole", Value: name}})
+	var commandError mongo.CommandError
+	if errors.As(response.Err(), &commandError) && commandError.HasErrorCode(roleNotFoundErrorCode) {
+		return nil
+	}
+	return response.Err()
+}
+
+// getCreateUserCommand is a method to generate the createUser command of an application user, it is marked as managed by the operator
+func getCreateUserCommand(user MongoDBUser) bson.D {
+	return bson.D{
+		{Key: "createUser", Value: user.Name},
+		{Key: "pwd", Value: user.Password},
+		{Key: "roles", Value: getRoleDocuments(user.Roles)},
+		{Key: "customData", Value: getUserCustomData(user)},
+	}
+}
+
+// getUpdateUserCommand is a method to generate the updateUser command of an application user, the password is only sent when it changed
+func getUpdateUserCommand(user MongoDBUser, updatePassword bool) bson.D {
+	command := bson.D{
+		{Key: "updateUser", Value: user.Name},
+		{Key: "roles", Value: getRoleDocuments(user.Roles)},
+		{Key: "customData", Value: getUserCustomData(user)},
+	}
+	if updatePassword {
+		command = append(command, bson.E{Key: "pwd", Value: user.Password})
+	}
+	return command
+}
+
+// getUserCustomData is a method to generate the custom data marking the user as managed by the operator along with its password version
+func getUserCustomData(user MongoDBUser) bson.M {
+	return bson.M{customDataManagedBy: managedByOperator, customDataPasswordVersion: user.PasswordVersion}
+}
+
+// getRoleDocuments is a method to convert the roles of a user or custom role into the documents of the user management commands
+func getRoleDocuments(roles []MongoDBRole) []bson.M {
+	documents := []bson.M{}
+	for _, role := range roles {
+		documents = append(documents, bson.M{"role": role.Name, "db": role.Database})
+	}
+	return documents
+}
+
+// GetMongoRoles is a method to list the user defined roles of the databases in MongoDB
+func GetMongoRoles(params MongoDBParameters, databases []string) ([]MongoDBCustomRole, error) {
+	client := initiateMongoSetupClient(params)
+	defer discconnectMongoClient(client)
+	var roles []MongoDBCustomRole
+	for _, database := range databases {
+		var result rolesInfo
+		err := client.Database(database).RunCommand(context.Background(), bson.D{{Key: "rolesInfo", Value: 1}, {Key: "showPrivileges", Value: true}}).Decode(&result)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, result.Roles...)
+	}
+	return roles, nil
+}
+
+// CreateMongoRole is a method to create a user defined role inside MongoDB
+func CreateMongoRole(params MongoDBParameters, role MongoDBCustomRole) error {
+	client := initiateMongoSetupClient(params)
+	defer discconnectMongoClient(client)
+	response := client.Database(role.Database).RunCommand(context.Background(), getRoleCommand("createRole", role))
+	return response.Err()
+}
+
+// UpdateMongoRole is a method to replace the privileges and inherited roles of a user defined role inside MongoDB
+func UpdateMongoRole(params MongoDBParameters, role MongoDBCustomRole) error {
+	client := initiateMongoSetupClient(params)
+	defer discconnectMongoClient(client)
+	response := client.Database(role.Database).RunCommand(context.Background(), getRoleCommand("updateRole", role))
+	return response.Err()
+}
+
+// getRoleCommand is a method to generate the createRole or updateRole command of a user defined role
+func getRoleCommand(command string, role MongoDBCustomRole) bson.D {
+	privileges := []bson.M{}
+	for _, privilege := range role.Privileges {
+		resource := bson.M{"db": privilege.Resource.Database, "collection": privilege.Resource.Collection}
+		if privilege.Resource.Cluster {
+			resource = bson.M{"cluster": true}
+		}
+		privileges = append(privileges, bson.M{"resource": resource, "actions": privilege.Actions})
+	}
+	return bson.D{
+		{Key: command, Value: role.Name},
+		{Key: "privileges", Value: privileges},
+		{Key: "roles", Value: getRoleDocuments(role.Roles)},
+	}
+}
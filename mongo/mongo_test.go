@@ -0,0 +1,497 @@
+package mongogo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// getTestMembers is a method to generate the desired members with the ids 0 up to count
+func getTestMembers(count int) []MongoDBMember {
+	var members []MongoDBMember
+	for id := 0; id < count; id++ {
+		members = append(members, MongoDBMember{ID: id, Host: "host", Priority: 1, Votes: 1})
+	}
+	return members
+}
+
+// getTestConfigMembers is a method to generate the members of a replica set config as they are decoded from replSetGetConfig
+func getTestConfigMembers(count int) bson.A {
+	var members bson.A
+	for _, member := range getTestMembers(count) {
+		memberConfig := getMemberConfig(member)
+		memberConfig["_id"] = int32(member.ID)
+		members = append(members, memberConfig)
+	}
+	return members
+}
+
+// getTestStatus is a method to generate the output of replSetGetStatus from the state of each member
+func getTestStatus(states ...string) replicaSetStatus {
+	var status replicaSetStatus
+	for id, state := range states {
+		status.Members = append(status.Members, replicaSetMemberStatus{ID: id, StateStr: state})
+	}
+	return status
+}
+
+// getTestMemberIDs is a method to get the ids of the members of a replica set config
+func getTestMemberIDs(members bson.A) []int {
+	var ids []int
+	for _, member := range members {
+		ids = append(ids, int(toFloat(member.(bson.M)["_id"])))
+	}
+	return ids
+}
+
+func TestUpdateDesiredMembers(t *testing.T) {
+	tests := []struct {
+		name        string
+		members     bson.A
+		desired     []MongoDBMember
+		status      replicaSetStatus
+		wantIDs     []int
+		wantChanged bool
+		wantPending bool
+		wantSerial  bool
+	}{
+		{
+			name:    "unchanged",
+			members: getTestConfigMembers(3),
+			desired: getTestMembers(3),
+			status:  getTestStatus("PRIMARY", "SECONDARY", "SECONDARY"),
+			wantIDs: []int{0, 1, 2},
+		},
+		{
+			name:        "single member added per reconfiguration",
+			members:     getTestConfigMembers(3),
+			desired:     getTestMembers(5),
+			status:      getTestStatus("PRIMARY", "SECONDARY", "SECONDARY"),
+			wantIDs:     []int{0, 1, 2, 3},
+			wantChanged: true,
+			wantPending: true,
+			wantSerial:  true,
+		},
+		{
+			name:        "member added once the previous one is healthy",
+			members:     getTestConfigMembers(4),
+			desired:     getTestMembers(5),
+			status:      getTestStatus("PRIMARY", "SECONDARY", "SECONDARY", "STARTUP2"),
+			wantIDs:     []int{0, 1, 2, 3},
+			wantPending: true,
+		},
+		{
+			name:        "last member added",
+			members:     getTestConfigMembers(4),
+			desired:     getTestMembers(5),
+			status:      getTestStatus("PRIMARY", "SECONDARY", "SECONDARY", "SECONDARY"),
+			wantIDs:     []int{0, 1, 2, 3, 4},
+			wantChanged: true,
+			wantSerial:  true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			members, changed, pending, serial := updateDesiredMembers(test.members, test.desired, test.status)
+			if ids := getTestMemberIDs(members); !equalInts(ids, test.wantIDs) {
+				t.Errorf("members = %v, want %v", ids, test.wantIDs)
+			}
+			if changed != test.wantChanged || pending != test.wantPending || serial != test.wantSerial {
+				t.Errorf("changed, pending, serial = %v, %v, %v, want %v, %v, %v", changed, pending, serial, test.wantChanged, test.wantPending, test.wantSerial)
+			}
+		})
+	}
+}
+
+func TestRemoveStaleMembers(t *testing.T) {
+	tests := []struct {
+		name         string
+		members      bson.A
+		desired      []MongoDBMember
+		status       replicaSetStatus
+		allowed      bool
+		wantIDs      []int
+		wantRemoved  bool
+		wantPending  bool
+		wantStepDown bool
+	}{
+		{
+			name:    "nothing to remove",
+			members: getTestConfigMembers(3),
+			desired: getTestMembers(3),
+			status:  getTestStatus("PRIMARY", "SECONDARY", "SECONDARY"),
+			allowed: true,
+			wantIDs: []int{0, 1, 2},
+		},
+		{
+			name:        "single member removed per reconfiguration",
+			members:     getTestConfigMembers(5),
+			desired:     getTestMembers(3),
+			status:      getTestStatus("PRIMARY", "SECONDARY", "SECONDARY", "SECONDARY", "SECONDARY"),
+			allowed:     true,
+			wantIDs:     []int{0, 1, 2, 4},
+			wantRemoved: true,
+			wantPending: true,
+		},
+		{
+			name:        "removal waits for another member change",
+			members:     getTestConfigMembers(5),
+			desired:     getTestMembers(3),
+			status:      getTestStatus("PRIMARY", "SECONDARY", "SECONDARY", "SECONDARY", "SECONDARY"),
+			wantIDs:     []int{0, 1, 2, 3, 4},
+			wantPending: true,
+		},
+		{
+			name:        "secondary removed before the primary",
+			members:     getTestConfigMembers(5),
+			desired:     getTestMembers(3),
+			status:      getTestStatus("SECONDARY", "SECONDARY", "SECONDARY", "PRIMARY", "SECONDARY"),
+			allowed:     true,
+			wantIDs:     []int{0, 1, 2, 3},
+			wantRemoved: true,
+			wantPending: true,
+		},
+		{
+			name:         "primary steps down before its removal",
+			members:      getTestConfigMembers(4),
+			desired:      getTestMembers(3),
+			status:       getTestStatus("SECONDARY", "SECONDARY", "SECONDARY", "PRIMARY"),
+			allowed:      true,
+			wantIDs:      []int{0, 1, 2, 3},
+			wantPending:  true,
+			wantStepDown: true,
+		},
+		{
+			name:        "former primary removed after stepping down",
+			members:     getTestConfigMembers(4),
+			desired:     getTestMembers(3),
+			status:      getTestStatus("PRIMARY", "SECONDARY", "SECONDARY", "SECONDARY"),
+			allowed:     true,
+			wantIDs:     []int{0, 1, 2},
+			wantRemoved: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			members, removed, pending, stepDown := removeStaleMembers(test.members, test.desired, test.status, test.allowed)
+			if ids := getTestMemberIDs(members); !equalInts(ids, test.wantIDs) {
+				t.Errorf("members = %v, want %v", ids, test.wantIDs)
+			}
+			if removed != test.wantRemoved || pending != test.wantPending || stepDown != test.wantStepDown {
+				t.Errorf("removed, pending, stepDown = %v, %v, %v, want %v, %v, %v", removed, pending, stepDown, test.wantRemoved, test.wantPending, test.wantStepDown)
+			}
+		})
+	}
+}
+
+// equalInts is a method to compare two lists of ints
+func equalInts(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for index := range a {
+		if a[index] != b[index] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGetCreateUserCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		user MongoDBUser
+		want bson.D
+	}{
+		{
+			name: "user with roles",
+			user: MongoDBUser{Name: "app", Database: "shop", Password: "secret", PasswordVersion: "42", Roles: []MongoDBRole{{Name: "readWrite", Database: "shop"}, {Name: "read", Database: "reporting"}}},
+			want: bson.D{
+				{Key: "createUser", Value: "app"},
+				{Key: "pwd", Value: "secret"},
+				{Key: "roles", Value: []bson.M{{"role": "readWrite", "db": "shop"}, {"role": "read", "db": "reporting"}}},
+				{Key: "customData", Value: bson.M{"managedBy": "mongodb-operator", "passwordVersion": "42"}},
+			},
+		},
+		{
+			name: "user without roles",
+			user: MongoDBUser{Name: "app", Database: "admin", Password: "secret"},
+			want: bson.D{
+				{Key: "createUser", Value: "app"},
+				{Key: "pwd", Value: "secret"},
+				{Key: "roles", Value: []bson.M{}},
+				{Key: "customData", Value: bson.M{"managedBy": "mongodb-operator", "passwordVersion": ""}},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getCreateUserCommand(test.user); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getCreateUserCommand() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetUpdateUserCommand(t *testing.T) {
+	user := MongoDBUser{Name: "app", Database: "shop", Password: "secret", PasswordVersion: "43", Roles: []MongoDBRole{{Name: "read", Database: "shop"}}}
+	tests := []struct {
+		name           string
+		updatePassword bool
+		wantPassword   bool
+	}{
+		{name: "password unchanged", updatePassword: false, wantPassword: false},
+		{name: "password changed", updatePassword: true, wantPassword: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			command := getUpdateUserCommand(user, test.updatePassword)
+			if command[0].Key != "updateUser" || command[0].Value != "app" {
+				t.Errorf("command starts with %v, want updateUser app", command[0])
+			}
+			hasPassword := false
+			for _, element := range command {
+				if element.Key == "pwd" {
+					hasPassword = element.Value == "secret"
+				}
+			}
+			if hasPassword != test.wantPassword {
+				t.Errorf("command sends the password = %v, want %v", hasPassword, test.wantPassword)
+			}
+		})
+	}
+}
+
+func TestGetMongoNodeInfo(t *testing.T) {
+	tests := []struct {
+		name   string
+		params MongoDBParameters
+		node   int
+		want   string
+	}{
+		{
+			name:   "default cluster domain",
+			params: MongoDBParameters{Name: "mongodb", Namespace: "database", Port: 27017},
+			node:   0,
+			want:   "mongodb-cluster-0.mongodb-cluster.database.svc.cluster.local:27017",
+		},
+		{
+			name:   "custom cluster domain",
+			params: MongoDBParameters{Name: "mongodb", Namespace: "database", Port: 27017, ClusterDomain: "example.internal"},
+			node:   2,
+			want:   "mongodb-cluster-2.mongodb-cluster.database.svc.example.internal:27017",
+		},
+		{
+			name:   "default port",
+			params: MongoDBParameters{Name: "mongodb", Namespace: "database", ClusterDomain: "example.internal"},
+			node:   1,
+			want:   "mongodb-cluster-1.mongodb-cluster.database.svc.example.internal:27017",
+		},
+		{
+			name:   "custom port",
+			params: MongoDBParameters{Name: "mongodb", Namespace: "database", Port: 27018},
+			node:   1,
+			want:   "mongodb-cluster-1.mongodb-cluster.database.svc.cluster.local:27018",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := GetMongoNodeInfo(test.params, test.node); got != test.want {
+				t.Errorf("GetMongoNodeInfo() = %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetMemberHostDrift(t *testing.T) {
+	desired := []MongoDBMember{
+		{ID: 0, Host: "mongodb-cluster-0.mongodb-cluster.database.svc.cluster.local:27017"},
+		{ID: 1, Host: "mongodb-cluster-1.mongodb-cluster.database.svc.cluster.local:27017"},
+	}
+	tests := []struct {
+		name    string
+		members bson.A
+		want    map[float64]string
+	}{
+		{
+			name: "hosts matching the pod names",
+			members: bson.A{
+				bson.M{"_id": int32(0), "host": "mongodb-cluster-0.mongodb-cluster.database.svc.cluster.local:27017"},
+				bson.M{"_id": int32(1), "host": "mongodb-cluster-1.mongodb-cluster.database.svc.cluster.local:27017"},
+			},
+			want: map[float64]string{},
+		},
+		{
+			name: "hostnames differing in case",
+			members: bson.A{
+				bson.M{"_id": int32(0), "host": "MongoDB-Cluster-0.mongodb-cluster.database.svc.cluster.local:27017"},
+				bson.M{"_id": int32(1), "host": "mongodb-cluster-1.mongodb-cluster.database.svc.cluster.local:27017"},
+			},
+			want: map[float64]string{},
+		},
+		{
+			name: "host of a renamed namespace",
+			members: bson.A{
+				bson.M{"_id": int32(0), "host": "mongodb-cluster-0.mongodb-cluster.legacy.svc.cluster.local:27017"},
+				bson.M{"_id": int32(1), "host": "mongodb-cluster-1.mongodb-cluster.database.svc.cluster.local:27017"},
+			},
+			want: map[float64]string{0: "mongodb-cluster-0.mongodb-cluster.database.svc.cluster.local:27017"},
+		},
+		{
+			name: "member missing from the config",
+			members: bson.A{
+				bson.M{"_id": int32(0), "host": "mongodb-cluster-0.mongodb-cluster.database.svc.cluster.local:27017"},
+			},
+			want: map[float64]string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getMemberHostDrift(test.members, desired); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("getMemberHostDrift() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestGetMaxReplicationLag(t *testing.T) {
+	primaryOptime := time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		members []replicaSetMemberStatus
+		want    int64
+	}{
+		{
+			name: "no primary",
+			members: []replicaSetMemberStatus{
+				{Name: "mongodb-cluster-0", StateStr: "SECONDARY", OptimeDate: primaryOptime},
+				{Name: "mongodb-cluster-1", StateStr: "SECONDARY", OptimeDate: primaryOptime.Add(-time.Hour)},
+			},
+		},
+		{
+			name: "all caught up",
+			members: []replicaSetMemberStatus{
+				{Name: "mongodb-cluster-0", StateStr: "PRIMARY", OptimeDate: primaryOptime},
+				{Name: "mongodb-cluster-1", StateStr: "SECONDARY", OptimeDate: primaryOptime},
+				{Name: "mongodb-cluster-2", StateStr: "SECONDARY", OptimeDate: primaryOptime},
+			},
+		},
+		{
+			name: "slowest secondary",
+			members: []replicaSetMemberStatus{
+				{Name: "mongodb-cluster-0", StateStr: "SECONDARY", OptimeDate: primaryOptime.Add(-5 * time.Second)},
+				{Name: "mongodb-cluster-1", StateStr: "PRIMARY", OptimeDate: primaryOptime},
+				{Name: "mongodb-cluster-2", StateStr: "SECONDARY", OptimeDate: primaryOptime.Add(-90 * time.Second)},
+			},
+			want: 90,
+		},
+		{
+			name: "members which are not secondaries are ignored",
+			members: []replicaSetMemberStatus{
+				{Name: "mongodb-cluster-0", StateStr: "PRIMARY", OptimeDate: primaryOptime},
+				{Name: "mongodb-cluster-1", StateStr: "STARTUP2"},
+				{Name: "mongodb-cluster-2", StateStr: "ARBITER"},
+				{Name: "mongodb-cluster-3", StateStr: "SECONDARY", OptimeDate: primaryOptime.Add(-2 * time.Second)},
+			},
+			want: 2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if lag := getMaxReplicationLag(test.members); lag != test.want {
+				t.Errorf("getMaxReplicationLag() = %d, want %d", lag, test.want)
+			}
+		})
+	}
+}
+
+func TestUpdateDesiredMembersVotes(t *testing.T) {
+	desired := getTestMembers(3)
+	for _, id := range []int{1, 2} {
+		desired[id].Votes = 0
+		desired[id].Priority = 0
+	}
+	status := getTestStatus("PRIMARY", "SECONDARY", "SECONDARY")
+	members, changed, pending, serial := updateDesiredMembers(getTestConfigMembers(3), desired, status)
+	if !changed || !pending || !serial {
+		t.Fatalf("changed, pending, serial = %v, %v, %v, want the votes of a single member changed", changed, pending, serial)
+	}
+	if votes := getTestMemberVotes(members); !equalInts(votes, []int{1, 0, 1}) {
+		t.Errorf("votes = %v, want only member 1 changed in the first reconfiguration", votes)
+	}
+	// a member loses its priority with its votes, since MongoDB rejects non-voting members with a priority
+	if priority := toFloat(members[1].(bson.M)["priority"]); priority != 0 {
+		t.Errorf("priority of member 1 = %v, want 0", priority)
+	}
+	if priority := toFloat(members[2].(bson.M)["priority"]); priority != 1 {
+		t.Errorf("priority of member 2 = %v, want 1 until its votes change", priority)
+	}
+	members, changed, pending, serial = updateDesiredMembers(members, desired, status)
+	if !changed || pending || !serial {
+		t.Fatalf("changed, pending, serial = %v, %v, %v, want the votes of the last member changed", changed, pending, serial)
+	}
+	if votes := getTestMemberVotes(members); !equalInts(votes, []int{1, 0, 0}) {
+		t.Errorf("votes = %v, want member 2 changed in the second reconfiguration", votes)
+	}
+	if _, changed, pending, _ = updateDesiredMembers(members, desired, status); changed || pending {
+		t.Errorf("changed, pending = %v, %v, want the members settled", changed, pending)
+	}
+}
+
+func TestUpdateDesiredMembersPriorities(t *testing.T) {
+	desired := getTestMembers(3)
+	desired[0].Priority = 10
+	desired[2].Priority = 0.5
+	members, changed, pending, serial := updateDesiredMembers(getTestConfigMembers(3), desired, getTestStatus("PRIMARY", "SECONDARY", "SECONDARY"))
+	// priorities don't change the voting majority, so they are applied on all members at once
+	if !changed || pending || serial {
+		t.Fatalf("changed, pending, serial = %v, %v, %v, want all priorities changed at once", changed, pending, serial)
+	}
+	for id, want := range []float64{10, 1, 0.5} {
+		if priority := toFloat(members[id].(bson.M)["priority"]); priority != want {
+			t.Errorf("priority of member %d = %v, want %v", id, priority, want)
+		}
+	}
+}
+
+func TestGetMemberConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		member MongoDBMember
+		want   bson.M
+	}{
+		{
+			name:   "voting member",
+			member: MongoDBMember{ID: 0, Host: "mongodb-cluster-0:27017", Priority: 2, Votes: 1, Tags: map[string]string{"dc": "east"}},
+			want:   bson.M{"_id": 0, "host": "mongodb-cluster-0:27017", "arbiterOnly": false, "hidden": false, "priority": float64(2), "votes": 1, "secondaryDelaySecs": int64(0), "tags": bson.M{"dc": "east"}},
+		},
+		{
+			name:   "hidden delayed member",
+			member: MongoDBMember{ID: 2, Host: "mongodb-cluster-2:27017", Hidden: true, SecondaryDelaySecs: 3600},
+			want:   bson.M{"_id": 2, "host": "mongodb-cluster-2:27017", "arbiterOnly": false, "hidden": true, "priority": float64(0), "votes": 0, "secondaryDelaySecs": int64(3600), "tags": bson.M{}},
+		},
+		{
+			name:   "arbiter without tags",
+			member: MongoDBMember{ID: 3, Host: "mongodb-arbiter-0:27017", ArbiterOnly: true, Votes: 1},
+			want:   bson.M{"_id": 3, "host": "mongodb-arbiter-0:27017", "arbiterOnly": true, "hidden": false, "priority": float64(0), "votes": 1, "secondaryDelaySecs": int64(0)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if memberConfig := getMemberConfig(test.member); !reflect.DeepEqual(memberConfig, test.want) {
+				t.Errorf("getMemberConfig() = %v, want %v", memberConfig, test.want)
+			}
+		})
+	}
+}
+
+// getTestMemberVotes is a method to get the votes of the members of a replica set config
+func getTestMemberVotes(members bson.A) []int {
+	var votes []int
+	for _, member := range members {
+		votes = append(votes, int(toFloat(member.(bson.M)["votes"])))
+	}
+	return votes
+}